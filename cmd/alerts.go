@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/adapters"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// handleAlertPayload scales the clusters of the firing alerts of an external alerting system.
+func handleAlertPayload(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, adapter adapters.Adapter, payload []byte) (*autoscaling.ScalingResult, error) {
+	triggers, err := adapter.Parse(payload)
+	if err != nil {
+		loggerInstance.Error("Failed to parse alert payload", "Source", adapter.Name(), "Error", err)
+		return nil, err
+	}
+	loggerInstance.Info("Parsed alert payload", "Source", adapter.Name(), "Triggers", len(triggers))
+
+	result := autoscaling.NewScalingResult(settings.DryRun)
+	for _, trigger := range triggers {
+		triggerResult, err := handleAlertTrigger(ctx, loggerInstance, settings, trigger)
+		if err != nil {
+			return nil, err
+		}
+		result.Merge(triggerResult)
+	}
+	return result, nil
+}
+
+// handleAlertTrigger moves the reader count of the clusters of the trigger by its number of replicas.
+// Alerts may only name the clusters of this deployment.
+func handleAlertTrigger(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, trigger adapters.Trigger) (*autoscaling.ScalingResult, error) {
+	if trigger.ClusterID != "" && !settings.HasCluster(trigger.ClusterID) {
+		return nil, fmt.Errorf("alert %s: cluster %s is not managed by this deployment", trigger.AlertName, trigger.ClusterID)
+	}
+	clusterIDs, err := expandClusterTargets(ctx, loggerInstance, settings, settings.ClusterTargets(trigger.ClusterID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := autoscaling.NewScalingResult(settings.DryRun)
+	err = forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		docdbAutoscaler, retry, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: clusterID})
+		if err != nil {
+			return err
+		}
+
+		// The desired capacity is computed once, so that retries do not move it again
+		currentCapacity, err := docdbAutoscaler.GetCurrentCapacity(ctx)
+		if err != nil {
+			loggerInstance.Error("Failed to retrieve current capacity", "Error", err)
+			return err
+		}
+		desiredCapacity := currentCapacity + trigger.Replicas
+		if trigger.Direction == adapters.DirectionIn {
+			desiredCapacity = currentCapacity - trigger.Replicas
+		}
+		loggerInstance.Info("Scaling from alert", "Source", trigger.Source, "AlertName", trigger.AlertName, "ClusterID", clusterID, "Direction", trigger.Direction, "CurrentCapacity", currentCapacity, "DesiredCapacity", desiredCapacity)
+
+		err = executeWithRetry(ctx, loggerInstance, func(ctx context.Context) error {
+			return docdbAutoscaler.ScaleToCapacity(ctx, desiredCapacity)
+		}, retry.maxRetries, retry.initialBackoff)
+		docdbAutoscaler.ReportOutcome(ctx, err)
+		if err != nil {
+			loggerInstance.Error("Scaling action failed after retries", "Error", err)
+			return err
+		}
+		result.Merge(docdbAutoscaler.LastResult())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// rejectDisallowedCluster sends a failure notification, which is critical, for an attempt to scale a cluster
+// outside ALLOWED_CLUSTERS, e.g. an event or a config copied from another environment. Failing to notify is
+// logged only.
+func rejectDisallowedCluster(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, clusterID string, err error) {
+	loggerInstance.Error("Rejected scaling of a cluster outside ALLOWED_CLUSTERS", "ClusterID", clusterID, "AllowedClusters", settings.AllowedClusters)
+
+	notifier, notifierErr := newNotifier(ctx, settings)
+	if notifierErr != nil {
+		loggerInstance.Error("Failed to initialize the notifier", "Error", notifierErr)
+		return
+	}
+	if notifyErr := notifier.SendFailureNotification(ctx, clusterID, err.Error(), "validate cluster"); notifyErr != nil {
+		loggerInstance.Error("Failed to send failure notification", "Error", notifyErr)
+	}
+}
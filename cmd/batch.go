@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	router "github.com/cheelim1/docdb-autoscaler/pkg/events"
+)
+
+// batchSource returns the name of the source of a batch of records, SNS or SQS, for its notifications.
+func batchSource(records []events.SNSEventRecord) string {
+	if len(records) > 0 && records[0].EventSource == router.SourceSQS {
+		return "SQS"
+	}
+	return "SNS"
+}
+
+// notifyFailedRecords sends a failure notification for the messages of a batch that failed while others were
+// processed, as the SNS invocation succeeds and is not retried for them. Failing to notify is logged only.
+func notifyFailedRecords(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, source string, failures []autoscaling.RecordFailure, records int) {
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		messages = append(messages, fmt.Sprintf("%s: %s", failure.MessageID, failure.Error))
+	}
+	message := fmt.Sprintf("%d of %d %s messages failed: %s", len(failures), records, source, strings.Join(messages, "; "))
+	loggerInstance.Error("Partially failed batch", "Source", source, "Failed", len(failures), "Records", records)
+
+	notifier, err := newNotifier(ctx, settings)
+	if err != nil {
+		loggerInstance.Error("Failed to initialize the notifier", "Error", err)
+		return
+	}
+	if err := notifier.SendFailureNotification(ctx, settings.ClusterID, message, "process "+source+" batch"); err != nil {
+		loggerInstance.Error("Failed to send failure notification", "Error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+)
+
+// isCustomResourceRequest reports whether the event is a CloudFormation custom resource request.
+func isCustomResourceRequest(event cfn.Event) bool {
+	return event.RequestType != "" && event.ResponseURL != "" && event.StackID != ""
+}
+
+// baselineProperties are the properties of a Custom::DocDBBaselineCapacity resource. CloudFormation
+// passes every property value as a string.
+type baselineProperties struct {
+	ClusterID        string
+	BaselineReplicas int
+	DryRun           *bool
+}
+
+// parseBaselineProperties reads the ClusterIdentifier, BaselineReplicas and DryRun resource properties.
+func parseBaselineProperties(properties map[string]interface{}) (baselineProperties, error) {
+	var parsed baselineProperties
+	if clusterID, found := properties["ClusterIdentifier"]; found {
+		parsed.ClusterID = fmt.Sprint(clusterID)
+	}
+
+	replicas, found := properties["BaselineReplicas"]
+	if !found {
+		return parsed, fmt.Errorf("BaselineReplicas property is required")
+	}
+	baselineReplicas, err := strconv.Atoi(fmt.Sprint(replicas))
+	if err != nil {
+		return parsed, fmt.Errorf("invalid BaselineReplicas property: %w", err)
+	}
+	parsed.BaselineReplicas = baselineReplicas
+
+	if dryRun, found := properties["DryRun"]; found {
+		value, err := strconv.ParseBool(fmt.Sprint(dryRun))
+		if err != nil {
+			return parsed, fmt.Errorf("invalid DryRun property: %w", err)
+		}
+		parsed.DryRun = &value
+	}
+	return parsed, nil
+}
+
+// handleCustomResource converges the baseline reader capacity declared by a CloudFormation stack:
+// to BaselineReplicas on Create and Update, and back to MIN_CAPACITY on Delete.
+// A response is always sent to CloudFormation, which otherwise waits for it until the stack times out.
+func handleCustomResource(ctx context.Context, loggerInstance *slog.Logger, event cfn.Event) error {
+	response := cfn.NewResponse(&event)
+	response.PhysicalResourceID = event.PhysicalResourceID
+
+	result, err := convergeCustomResource(ctx, loggerInstance, event, response)
+	if err != nil {
+		loggerInstance.Error("CloudFormation custom resource request failed", "RequestType", string(event.RequestType), "LogicalResourceId", event.LogicalResourceID, "Error", err)
+		response.Status = cfn.StatusFailed
+		response.Reason = fmt.Sprintf("%v (see CloudWatch log stream %s)", err, lambdacontext.LogStreamName)
+	} else {
+		response.Status = cfn.StatusSuccess
+		if result != nil {
+			response.Data = map[string]interface{}{
+				"Decision":        result.Decision,
+				"ReplicasAdded":   result.ReplicasAdded,
+				"ReplicasRemoved": result.ReplicasRemoved,
+			}
+		}
+	}
+	if response.PhysicalResourceID == "" {
+		// Failed creations still need an identifier, CloudFormation then deletes it on rollback
+		response.PhysicalResourceID = "docdb-autoscaler-baseline-" + event.RequestID
+	}
+
+	if sendErr := response.Send(); sendErr != nil {
+		loggerInstance.Error("Failed to send CloudFormation response", "Error", sendErr)
+		return sendErr
+	}
+	loggerInstance.Info("Sent CloudFormation response", "Status", string(response.Status), "PhysicalResourceId", response.PhysicalResourceID)
+	return nil
+}
+
+// convergeCustomResource takes the scaling action of the request and sets the physical resource ID.
+func convergeCustomResource(ctx context.Context, loggerInstance *slog.Logger, event cfn.Event, response *cfn.Response) (*autoscaling.ScalingResult, error) {
+	properties, err := parseBaselineProperties(event.ResourceProperties)
+	if event.RequestType == cfn.RequestDelete && err != nil {
+		// Nothing was created from invalid properties, so there is nothing to remove
+		loggerInstance.Warn("Ignoring delete of custom resource with invalid properties", "Error", err)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	settings, err := loadConfig(ctx, loggerInstance)
+	if err != nil {
+		return nil, err
+	}
+	clusterID := properties.ClusterID
+	if clusterID == "" {
+		clusterID = settings.ClusterID
+	}
+	if event.RequestType != cfn.RequestDelete {
+		// A new cluster is a new resource: CloudFormation then deletes the resource of the previous cluster
+		response.PhysicalResourceID = "docdb-autoscaler-baseline-" + clusterID
+	}
+
+	desiredReplicas := properties.BaselineReplicas
+	if event.RequestType == cfn.RequestDelete {
+		desiredReplicas = settings.ForCluster(clusterID).MinCapacity
+	}
+	loggerInstance.Info("Converging CloudFormation baseline capacity", "RequestType", string(event.RequestType), "ClusterID", clusterID, "DesiredReplicas", desiredReplicas)
+
+	return handleDirectInvocation(ctx, loggerInstance, settings, DirectInvocation{
+		ClusterID:       clusterID,
+		DesiredReplicas: &desiredReplicas,
+		DryRun:          properties.DryRun,
+	})
+}
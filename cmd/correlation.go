@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+	router "github.com/cheelim1/docdb-autoscaler/pkg/events"
+)
+
+// correlationHeader is the header of HTTP requests carrying the correlation ID of the caller.
+const correlationHeader = "x-correlation-id"
+
+// correlationID returns the correlation ID of an invocation, taken from the triggering event when it has one:
+// a CorrelationID field of the payload, the x-correlation-id header or request ID of an HTTP request, the
+// message ID of an SNS notification or the ID of an EventBridge event. Otherwise it is the request ID of the
+// invocation, or a new ID.
+func correlationID(ctx context.Context, event json.RawMessage) string {
+	var payload struct {
+		CorrelationID string `json:"CorrelationID"`
+	}
+	if err := json.Unmarshal(event, &payload); err == nil && payload.CorrelationID != "" {
+		return payload.CorrelationID
+	}
+
+	var httpRequest events.APIGatewayV2HTTPRequest
+	if err := json.Unmarshal(event, &httpRequest); err == nil && router.IsHTTP(httpRequest) {
+		if id := httpRequest.Headers[correlationHeader]; id != "" {
+			return id
+		}
+		if httpRequest.RequestContext.RequestID != "" {
+			return httpRequest.RequestContext.RequestID
+		}
+	}
+	var snsEvent events.SNSEvent
+	if err := json.Unmarshal(event, &snsEvent); err == nil && len(snsEvent.Records) > 0 && snsEvent.Records[0].SNS.MessageID != "" {
+		return snsEvent.Records[0].SNS.MessageID
+	}
+	var cwEvent events.CloudWatchEvent
+	if err := json.Unmarshal(event, &cwEvent); err == nil && cwEvent.Source != "" && cwEvent.ID != "" {
+		return cwEvent.ID
+	}
+
+	if lambdaContext, ok := lambdacontext.FromContext(ctx); ok && lambdaContext.AwsRequestID != "" {
+		return lambdaContext.AwsRequestID
+	}
+	return correlation.NewID()
+}
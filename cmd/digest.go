@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// DigestRequest asks for a digest of the scaling actions of every cluster in the audit log, typically
+// sent as the constant input of a separate EventBridge schedule, e.g. {"Digest": "weekly"}.
+type DigestRequest struct {
+	Digest string `json:"Digest"` // "weekly" or "daily"
+}
+
+// digestPeriods are the periods covered by digests.
+var digestPeriods = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// handleDigestRequest summarizes the audit records of the period per cluster and sends each summary
+// as a digest through the configured notifiers. Clusters without records in the period are skipped.
+func handleDigestRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, digestRequest DigestRequest) error {
+	period, found := digestPeriods[digestRequest.Digest]
+	if !found {
+		return fmt.Errorf("unknown digest %s, must be daily or weekly", digestRequest.Digest)
+	}
+	if settings.AuditS3URI == "" {
+		loggerInstance.Error("Environment variable AUDIT_S3_URI is not set")
+		return errors.New("AUDIT_S3_URI is not set")
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return err
+	}
+	store, err := autoscaling.NewAuditStore(cfg, settings)
+	if err != nil {
+		loggerInstance.Error("Invalid configuration", "Error", err)
+		return err
+	}
+	notifier, err := autoscaling.NewNotifier(sns.NewFromConfig(cfg), settings)
+	if err != nil {
+		loggerInstance.Error("Invalid notification settings", "Error", err)
+		return err
+	}
+
+	clusterIDs, err := store.Clusters(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to list clusters of the audit log", "Error", err)
+		return err
+	}
+	until := time.Now().UTC()
+	since := until.Add(-period)
+	return forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		records, err := store.List(ctx, clusterID, since, until)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		summary := audit.Summarize(clusterID, since, until, records)
+		if err := notifications.SendDigest(ctx, notifier, clusterID, summary.Message()); err != nil {
+			return err
+		}
+		loggerInstance.Info("Sent scaling digest", "ClusterID", clusterID, "Digest", digestRequest.Digest, "Records", len(records))
+		return nil
+	})
+}
@@ -0,0 +1,350 @@
+// Command docdb-autoscaler runs the scaling logic of the Lambda locally, with the local AWS credentials.
+// It reads the same configuration: environment variables and the optional CONFIG_FILE or CONFIG_S3_URI.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/controller"
+	"github.com/spf13/cobra"
+)
+
+// options holds the flags shared by the subcommands.
+type options struct {
+	configFile   string
+	clusterID    string
+	verbose      bool
+	desired      int
+	scheduled    bool
+	replicas     int
+	instanceType string
+	dryRun       bool
+	namespace    string
+	interval     time.Duration
+	limit        int
+	decisions    string
+	since        string
+	until        string
+	step         time.Duration
+	target       float64
+}
+
+func main() {
+	if err := newRootCommand().ExecuteContext(context.Background()); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+	rootCmd := &cobra.Command{
+		Use:          "docdb-autoscaler",
+		Short:        "Run DocumentDB autoscaler actions outside Lambda",
+		SilenceUsage: true,
+	}
+	rootCmd.PersistentFlags().StringVar(&opts.configFile, "config", "", "Config file, instead of CONFIG_FILE")
+	rootCmd.PersistentFlags().StringVar(&opts.clusterID, "cluster", "", "Cluster to act on, instead of CLUSTER_IDENTIFIER")
+	rootCmd.PersistentFlags().BoolVarP(&opts.verbose, "verbose", "v", false, "Log debug messages")
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the scaling action that apply would take, without changing the cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.dryRun = true
+			return runScaling(cmd, opts)
+		},
+	}
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Take the scaling action, as the Lambda would",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScaling(cmd, opts)
+		},
+	}
+	for _, cmd := range []*cobra.Command{planCmd, applyCmd} {
+		cmd.Flags().IntVar(&opts.desired, "desired", 0, "Scale to this number of readers instead of following the scaling policy")
+		cmd.Flags().BoolVar(&opts.scheduled, "scheduled", false, "Take the scheduled scaling action instead of the metric-based one")
+		cmd.Flags().IntVar(&opts.replicas, "replicas", 0, "Number of scheduled replicas, instead of SCHEDULE_NUMBER_REPLICAS")
+		cmd.Flags().StringVar(&opts.instanceType, "instance-type", "", "Instance type of new replicas, instead of INSTANCE_TYPE")
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the reader topology and autoscaler state of the cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docdbAutoscaler, err := newAutoscaler(cmd, opts, config.Overrides{ClusterID: opts.clusterID})
+			if err != nil {
+				return err
+			}
+			status, err := docdbAutoscaler.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, status)
+		},
+	}
+
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Remove the replicas created by the autoscaler and by scheduled scaling",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			overrides := config.Overrides{ClusterID: opts.clusterID}
+			if cmd.Flags().Changed("dry-run") {
+				overrides.DryRun = &opts.dryRun
+			}
+			docdbAutoscaler, err := newAutoscaler(cmd, opts, overrides)
+			if err != nil {
+				return err
+			}
+			if err := docdbAutoscaler.Cleanup(cmd.Context()); err != nil {
+				return err
+			}
+			return printJSON(cmd, docdbAutoscaler.LastResult())
+		},
+	}
+	cleanupCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Only report the replicas that would be removed")
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the recent scaling actions of the cluster from the audit log, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(cmd, opts)
+		},
+	}
+	historyCmd.Flags().IntVar(&opts.limit, "limit", audit.DefaultHistoryLimit, "Maximum number of scaling actions")
+	historyCmd.Flags().StringVar(&opts.decisions, "decision", "", "Comma-separated decisions to show, e.g. ScaleOut,ScaleIn, all when empty")
+	historyCmd.Flags().StringVar(&opts.since, "since", "", "Start of the period, RFC 3339 or a duration before now such as 12h (default 7 days before --until)")
+	historyCmd.Flags().StringVar(&opts.until, "until", "", "End of the period, RFC 3339 or a duration before now (default now)")
+
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Replay the recorded metric of the cluster through the scaling policy, and show the capacity timeline it would have produced",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSimulation(cmd, opts)
+		},
+	}
+	simulateCmd.Flags().StringVar(&opts.since, "since", "", "Start of the period, RFC 3339 or a duration before now such as 12h (default 7 days before --until)")
+	simulateCmd.Flags().StringVar(&opts.until, "until", "", "End of the period, RFC 3339 or a duration before now (default now)")
+	simulateCmd.Flags().DurationVar(&opts.step, "interval", autoscaling.DefaultSimulationInterval, "Interval between evaluations, in whole minutes")
+	simulateCmd.Flags().Float64Var(&opts.target, "target", 0, "Target value to simulate, instead of TARGET_VALUE")
+
+	controllerCmd := &cobra.Command{
+		Use:   "controller",
+		Short: "Reconcile DocDBAutoscaler Kubernetes resources, when running in a Kubernetes cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runController(cmd, opts)
+		},
+	}
+	controllerCmd.Flags().StringVar(&opts.namespace, "namespace", "", "Namespace of the resources, all namespaces when empty")
+	controllerCmd.Flags().DurationVar(&opts.interval, "interval", time.Minute, "Interval between reconciliations")
+
+	rootCmd.AddCommand(planCmd, applyCmd, statusCmd, cleanupCmd, historyCmd, simulateCmd, controllerCmd)
+	return rootCmd
+}
+
+// runScaling takes the scaling action of plan and apply, and prints its result.
+func runScaling(cmd *cobra.Command, opts *options) error {
+	overrides := config.Overrides{
+		ClusterID:    opts.clusterID,
+		InstanceType: opts.instanceType,
+	}
+	if opts.dryRun {
+		overrides.DryRun = &opts.dryRun
+	}
+	if cmd.Flags().Changed("scheduled") {
+		overrides.ScheduledScaling = &opts.scheduled
+	}
+	if cmd.Flags().Changed("replicas") {
+		overrides.ScheduleNumberReplicas = &opts.replicas
+	}
+
+	docdbAutoscaler, err := newAutoscaler(cmd, opts, overrides)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("desired") {
+		err = docdbAutoscaler.ScaleToCapacity(cmd.Context(), opts.desired)
+	} else {
+		err = docdbAutoscaler.ExecuteScalingAction(cmd.Context())
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, docdbAutoscaler.LastResult())
+}
+
+// newAutoscaler loads and resolves the configuration, and builds the autoscaler of the cluster.
+func newAutoscaler(cmd *cobra.Command, opts *options, overrides config.Overrides) (*autoscaling.DocumentDB, error) {
+	cfg, settings, loggerInstance, err := loadSettings(cmd, opts)
+	if err != nil {
+		return nil, err
+	}
+	settings, err = settings.Resolve(overrides)
+	if err != nil {
+		return nil, err
+	}
+	return autoscaling.NewFromConfig(cfg, settings, loggerInstance)
+}
+
+// loadSettings loads the AWS configuration and the autoscaler configuration, and creates the logger.
+func loadSettings(cmd *cobra.Command, opts *options) (aws.Config, *config.Config, *slog.Logger, error) {
+	ctx := cmd.Context()
+	logLevel := slog.LevelInfo
+	if opts.verbose {
+		logLevel = slog.LevelDebug
+	}
+	// Logs go to stderr so that stdout only carries the result
+	loggerInstance := slog.New(slog.NewTextHandler(cmd.ErrOrStderr(), &slog.HandlerOptions{Level: logLevel}))
+
+	if opts.configFile != "" {
+		if err := os.Setenv("CONFIG_FILE", opts.configFile); err != nil {
+			return aws.Config{}, nil, nil, err
+		}
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return aws.Config{}, nil, nil, err
+	}
+	if endpoints := autoscaling.EndpointOverrides(); len(endpoints) > 0 {
+		loggerInstance.Info("Using overridden AWS endpoints", "Endpoints", endpoints)
+	}
+	settings, err := config.Load(ctx, autoscaling.NewS3Client(cfg))
+	if err != nil {
+		return aws.Config{}, nil, nil, err
+	}
+	return cfg, settings, loggerInstance, nil
+}
+
+// runHistory prints the scaling actions of the cluster matching the flags, from the audit log.
+func runHistory(cmd *cobra.Command, opts *options) error {
+	cfg, settings, _, err := loadSettings(cmd, opts)
+	if err != nil {
+		return err
+	}
+	settings, err = settings.Resolve(config.Overrides{ClusterID: opts.clusterID})
+	if err != nil {
+		return err
+	}
+	if settings.AuditS3URI == "" {
+		return errors.New("AUDIT_S3_URI is not set")
+	}
+	now := time.Now().UTC()
+	since, err := audit.ParseHistoryTime(opts.since, now)
+	if err != nil {
+		return err
+	}
+	until, err := audit.ParseHistoryTime(opts.until, now)
+	if err != nil {
+		return err
+	}
+
+	store, err := autoscaling.NewAuditStore(cfg, settings)
+	if err != nil {
+		return err
+	}
+	records, err := audit.History(cmd.Context(), store, audit.Query{
+		ClusterID: settings.ClusterID,
+		Decisions: audit.ParseDecisions(opts.decisions),
+		Since:     since,
+		Until:     until,
+		Limit:     opts.limit,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, records)
+}
+
+// runSimulation prints the simulation of the scaling policy over the period of the flags.
+func runSimulation(cmd *cobra.Command, opts *options) error {
+	now := time.Now().UTC()
+	until, err := audit.ParseHistoryTime(opts.until, now)
+	if err != nil {
+		return err
+	}
+	if until.IsZero() {
+		until = now
+	}
+	since, err := audit.ParseHistoryTime(opts.since, now)
+	if err != nil {
+		return err
+	}
+	if since.IsZero() {
+		since = until.Add(-7 * 24 * time.Hour)
+	}
+
+	docdbAutoscaler, err := newAutoscaler(cmd, opts, config.Overrides{ClusterID: opts.clusterID})
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("target") {
+		docdbAutoscaler.TargetValue = opts.target
+	}
+	simulation, err := docdbAutoscaler.Simulate(cmd.Context(), since, until, opts.step)
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, simulation)
+}
+
+// runController reconciles the DocDBAutoscaler resources of the Kubernetes cluster the pod runs in.
+func runController(cmd *cobra.Command, opts *options) error {
+	cfg, settings, loggerInstance, err := loadSettings(cmd, opts)
+	if err != nil {
+		return err
+	}
+	client, err := controller.NewInClusterClient()
+	if err != nil {
+		return err
+	}
+
+	docdbController := &controller.Controller{
+		Client:    client,
+		Namespace: opts.namespace,
+		Settings:  settings,
+		NewScaler: func(settings *config.Config) (controller.Scaler, error) {
+			docdbAutoscaler, err := autoscaling.NewFromConfig(cfg, settings, loggerInstance)
+			if err != nil {
+				return nil, err
+			}
+			return docdbAutoscaler, nil
+		},
+		Interval: opts.interval,
+		Logger:   loggerInstance,
+	}
+	loggerInstance.Info("Starting DocDBAutoscaler controller", "Namespace", opts.namespace, "Interval", opts.interval.String())
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := docdbController.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(cmd *cobra.Command, v any) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+)
+
+// evaluationKey returns the key of the scaling action an SNS message calls for on a cluster. The action is
+// taken on the state of the cluster rather than on the message, so records of an invocation with the same
+// key, e.g. one alarm fanned out to several subscriptions of the topic, are evaluated once. Composite alarms
+// evaluate the child metrics of their rule, which is part of the key.
+func evaluationKey(clusterID string, scalingMessage ScalingMessage, alarmNotification *autoscaling.AlarmNotification) string {
+	intent, _ := json.Marshal(scalingMessage)
+	key := clusterID + " " + string(intent)
+	if alarmNotification.IsComposite() {
+		key += " " + alarmNotification.AlarmRule
+	}
+	return key
+}
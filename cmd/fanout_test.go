@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvaluationKey tests which records of an invocation are evaluated once on a cluster.
+func TestEvaluationKey(t *testing.T) {
+	alarm := &autoscaling.AlarmNotification{AlarmName: "cpu-high", NewStateValue: "ALARM", Trigger: autoscaling.AlarmTrigger{MetricName: "CPUUtilization"}}
+	desiredCapacity := 3
+
+	// The same alarm delivered to two subscriptions of the topic
+	assert.Equal(t,
+		evaluationKey("cluster-a", ScalingMessage{}, alarm),
+		evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmName: "cpu-high-copy", NewStateValue: "ALARM"}))
+
+	for name, keys := range map[string][2]string{
+		"different clusters": {
+			evaluationKey("cluster-a", ScalingMessage{}, alarm),
+			evaluationKey("cluster-b", ScalingMessage{}, alarm),
+		},
+		"different actions": {
+			evaluationKey("cluster-a", ScalingMessage{}, alarm),
+			evaluationKey("cluster-a", ScalingMessage{DesiredCapacity: &desiredCapacity}, alarm),
+		},
+		"composite and metric alarms": {
+			evaluationKey("cluster-a", ScalingMessage{}, alarm),
+			evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmRule: `ALARM("cpu-high")`}),
+		},
+		"composite alarms of different rules": {
+			evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmRule: `ALARM("cpu-high")`}),
+			evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmRule: `ALARM("cpu-high") OR ALARM("connections-high")`}),
+		},
+	} {
+		assert.NotEqual(t, keys[0], keys[1], name)
+	}
+
+	// Composite alarms of the same rule
+	rule := `ALARM("cpu-high") AND ALARM("connections-high")`
+	assert.Equal(t,
+		evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmName: "composite", AlarmRule: rule}),
+		evaluationKey("cluster-a", ScalingMessage{}, &autoscaling.AlarmNotification{AlarmName: "composite", AlarmRule: rule}))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// healthCheckAction is the action of health check requests.
+const healthCheckAction = "healthcheck"
+
+// HealthCheckRequest asks for a health check of the deployment without scaling, e.g. as a post-deploy
+// smoke test: {"action": "healthcheck"}, optionally with the ClusterID to check.
+type HealthCheckRequest struct {
+	Action    string `json:"action"`
+	ClusterID string `json:"ClusterID"`
+}
+
+// handleHealthCheck verifies the configuration, the reachability of the cluster and, by simulating the
+// IAM policies of the function, the permissions needed to scale it and to publish notifications.
+// Failed checks are reported rather than returned, so that the report is the response of the invocation.
+func handleHealthCheck(ctx context.Context, loggerInstance *slog.Logger, healthCheckRequest HealthCheckRequest) *autoscaling.HealthReport {
+	report := autoscaling.NewHealthReport(healthCheckRequest.ClusterID)
+	defer func() {
+		loggerInstance.Info("Completed health check", "ClusterID", report.ClusterID, "Healthy", report.Healthy)
+	}()
+
+	settings, err := loadConfig(ctx, loggerInstance)
+	if err == nil {
+		settings, err = settings.Resolve(config.Overrides{ClusterID: healthCheckRequest.ClusterID})
+	}
+	report.Check("Config", err)
+	if err != nil {
+		return report
+	}
+	report.ClusterID = settings.ClusterID
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		report.Check("AWSConfig", err)
+		return report
+	}
+	docdbAutoscaler, err := autoscaling.NewFromConfig(cfg, settings, loggerInstance)
+	report.Check("Setup", err)
+	if err != nil {
+		return report
+	}
+	clusterARN := docdbAutoscaler.CheckCluster(ctx, report)
+
+	// The function scales the cluster with its own role, or with ASSUME_ROLE_ARN in the account of the cluster
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	report.Check("CallerIdentity", err)
+	if err != nil {
+		return report
+	}
+	principalARN := autoscaling.PrincipalARN(aws.ToString(identity.Arn))
+	if clusterARN == "" {
+		report.Skip("ClusterPermissions", "the cluster could not be described")
+	} else if settings.AssumeRoleArn != "" {
+		clusterIAM := iam.NewFromConfig(autoscaling.ClusterAWSConfig(cfg, settings))
+		autoscaling.CheckPermissions(ctx, clusterIAM, settings.AssumeRoleArn, docdbAutoscaler.ClusterPermissions(clusterARN), report)
+	} else {
+		autoscaling.CheckPermissions(ctx, iam.NewFromConfig(cfg), principalARN, docdbAutoscaler.ClusterPermissions(clusterARN), report)
+	}
+	autoscaling.CheckPermissions(ctx, iam.NewFromConfig(cfg), principalARN, notificationPermissions(settings), report)
+	return report
+}
+
+// notificationPermissions returns the permissions needed to publish to the SNS topics of the notifications.
+func notificationPermissions(settings *config.Config) []autoscaling.Permission {
+	var permissions []autoscaling.Permission
+	var topicARNs []string
+	for _, topicARN := range []string{settings.SNSTopicArn, settings.ScaleOutTopicArn, settings.ScaleInTopicArn, settings.FailureTopicArn} {
+		if topicARN != "" && !slices.Contains(topicARNs, topicARN) {
+			topicARNs = append(topicARNs, topicARN)
+			permissions = append(permissions, autoscaling.Permission{Action: "sns:Publish", Resource: topicARN})
+		}
+	}
+	return permissions
+}
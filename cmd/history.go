@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// HistoryRequest asks for the recent scaling actions of a cluster in the audit log, e.g.
+// {"History": {"ClusterID": "orders", "Decisions": ["ScaleOut"], "Since": "12h"}}.
+type HistoryRequest struct {
+	History *HistoryQuery `json:"History"`
+}
+
+// HistoryQuery filters the scaling actions of a history request.
+type HistoryQuery struct {
+	ClusterID string   `json:"ClusterID"` // CLUSTER_IDENTIFIER when empty
+	Decisions []string `json:"Decisions"` // e.g. ScaleOut, ScaleIn; all decisions when empty
+	Since     string   `json:"Since"`     // RFC 3339 or a duration before now, e.g. 12h; 7 days before Until when empty
+	Until     string   `json:"Until"`     // RFC 3339 or a duration before now; now when empty
+	Limit     int      `json:"Limit"`     // 20 when zero
+}
+
+// HistoryResponse lists the scaling actions of a cluster, newest first.
+type HistoryResponse struct {
+	ClusterID string         `json:"ClusterID"`
+	Records   []audit.Record `json:"Records"`
+}
+
+// errInvalidHistoryQuery marks history requests that are rejected before the audit log is read.
+var errInvalidHistoryQuery = errors.New("invalid history query")
+
+// handleHistoryRequest returns the scaling actions of the cluster matching the query, from the audit log.
+func handleHistoryRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, historyQuery HistoryQuery) (*HistoryResponse, error) {
+	if settings.AuditS3URI == "" {
+		loggerInstance.Error("Environment variable AUDIT_S3_URI is not set")
+		return nil, errors.New("AUDIT_S3_URI is not set")
+	}
+	query, err := parseHistoryQuery(settings, historyQuery)
+	if err != nil {
+		return nil, errors.Join(errInvalidHistoryQuery, err)
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return nil, err
+	}
+	store, err := autoscaling.NewAuditStore(cfg, settings)
+	if err != nil {
+		loggerInstance.Error("Invalid configuration", "Error", err)
+		return nil, err
+	}
+	records, err := audit.History(ctx, store, query)
+	if err != nil {
+		loggerInstance.Error("Failed to query scaling history", "Error", err, "ClusterID", query.ClusterID)
+		return nil, err
+	}
+	loggerInstance.Info("Queried scaling history", "ClusterID", query.ClusterID, "Records", len(records))
+	return &HistoryResponse{ClusterID: query.ClusterID, Records: records}, nil
+}
+
+// parseHistoryQuery resolves the cluster and the times of a history query.
+func parseHistoryQuery(settings *config.Config, historyQuery HistoryQuery) (audit.Query, error) {
+	now := time.Now().UTC()
+	since, err := audit.ParseHistoryTime(historyQuery.Since, now)
+	if err != nil {
+		return audit.Query{}, err
+	}
+	until, err := audit.ParseHistoryTime(historyQuery.Until, now)
+	if err != nil {
+		return audit.Query{}, err
+	}
+	clusterID := historyQuery.ClusterID
+	if clusterID == "" {
+		clusterID = settings.ClusterID
+	}
+	if clusterID == "" {
+		return audit.Query{}, errors.New("ClusterID is required without CLUSTER_IDENTIFIER")
+	}
+	return audit.Query{
+		ClusterID: clusterID,
+		Decisions: historyQuery.Decisions,
+		Since:     since,
+		Until:     until,
+		Limit:     historyQuery.Limit,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/cheelim1/docdb-autoscaler/pkg/adapters"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// httpSecretHeader carries the shared secret when HTTP_SHARED_SECRET is set.
+const httpSecretHeader = "x-autoscaler-secret"
+
+// httpErrorBody is the JSON body returned for failed HTTP requests.
+type httpErrorBody struct {
+	Error string `json:"Error"`
+}
+
+// authorizeHTTPRequest accepts requests carrying the shared secret when HTTP_SHARED_SECRET is set,
+// otherwise only requests that were authenticated with IAM.
+func authorizeHTTPRequest(settings *config.Config, request events.APIGatewayV2HTTPRequest) error {
+	if sharedSecret := settings.HTTPSharedSecret; sharedSecret != "" {
+		provided := request.Headers[httpSecretHeader]
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(sharedSecret)) != 1 {
+			return errors.New("missing or invalid shared secret")
+		}
+		return nil
+	}
+	if request.RequestContext.Authorizer == nil || request.RequestContext.Authorizer.IAM == nil {
+		return errors.New("request is not IAM authenticated")
+	}
+	return nil
+}
+
+// httpRoute returns the "METHOD /path" route of the request, without any API Gateway stage prefix.
+func httpRoute(request events.APIGatewayV2HTTPRequest) string {
+	path := request.RawPath
+	if stage := request.RequestContext.Stage; stage != "" && stage != "$default" {
+		path = strings.TrimPrefix(path, "/"+stage)
+	}
+	return request.RequestContext.HTTP.Method + " " + strings.TrimSuffix(path, "/")
+}
+
+// httpResponse builds a JSON HTTP response.
+func httpResponse(statusCode int, body any) events.APIGatewayV2HTTPResponse {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		payload = []byte(`{"Error":"failed to encode response"}`)
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(payload),
+	}
+}
+
+// httpRequestBody returns the body of the request, decoded when API Gateway base64 encoded it.
+func httpRequestBody(request events.APIGatewayV2HTTPRequest) ([]byte, error) {
+	if !request.IsBase64Encoded {
+		return []byte(request.Body), nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(request.Body)
+	if err != nil {
+		return nil, errors.New("invalid base64 body")
+	}
+	return decoded, nil
+}
+
+// handleHTTPRequest serves manual operations for on-call engineers:
+// POST /scale, POST /prescale, GET /status, GET /history, POST /pause and POST /resume, as well as POST /alerts for alerting
+// system webhooks. With several clusters configured, the ClusterID query parameter selects the cluster of status,
+// history, pause and resume.
+func handleHTTPRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	route := httpRoute(request)
+	if err := authorizeHTTPRequest(settings, request); err != nil {
+		loggerInstance.Warn("Rejected unauthorized HTTP request", "Route", route, "Error", err)
+		return httpResponse(http.StatusUnauthorized, httpErrorBody{Error: err.Error()}), nil
+	}
+	loggerInstance.Info("Received HTTP request", "Route", route)
+
+	switch route {
+	case "POST /scale":
+		body, err := httpRequestBody(request)
+		if err != nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: err.Error()}), nil
+		}
+		var directInvocation DirectInvocation
+		if err := json.Unmarshal(body, &directInvocation); err != nil || directInvocation.DesiredReplicas == nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: "body must be JSON with DesiredReplicas"}), nil
+		}
+		result, err := handleDirectInvocation(ctx, loggerInstance, settings, directInvocation)
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, result), nil
+
+	case "POST /prescale":
+		body, err := httpRequestBody(request)
+		if err != nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: err.Error()}), nil
+		}
+		var directInvocation DirectInvocation
+		if err := json.Unmarshal(body, &directInvocation); err != nil || directInvocation.PrescaleReplicas == nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: "body must be JSON with PrescaleReplicas and PrescaleDuration"}), nil
+		}
+		if duration, err := time.ParseDuration(directInvocation.PrescaleDuration); err != nil || duration <= 0 || *directInvocation.PrescaleReplicas <= 0 {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: "PrescaleReplicas must be positive, and PrescaleDuration a positive duration, e.g. 6h"}), nil
+		}
+		result, err := handleDirectInvocation(ctx, loggerInstance, settings, directInvocation)
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, result), nil
+
+	case "GET /status":
+		docdbAutoscaler, _, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: request.QueryStringParameters["ClusterID"]})
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		status, err := docdbAutoscaler.Status(ctx)
+		if err != nil {
+			loggerInstance.Error("Failed to retrieve cluster status", "Error", err)
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, status), nil
+
+	case "GET /history":
+		parameters := request.QueryStringParameters
+		historyQuery := HistoryQuery{
+			ClusterID: parameters["ClusterID"],
+			Decisions: audit.ParseDecisions(parameters["Decision"]),
+			Since:     parameters["Since"],
+			Until:     parameters["Until"],
+		}
+		if limit := parameters["Limit"]; limit != "" {
+			parsed, err := strconv.Atoi(limit)
+			if err != nil || parsed <= 0 {
+				return httpResponse(http.StatusBadRequest, httpErrorBody{Error: "Limit must be a positive number"}), nil
+			}
+			historyQuery.Limit = parsed
+		}
+		history, err := handleHistoryRequest(ctx, loggerInstance, settings, historyQuery)
+		if errors.Is(err, errInvalidHistoryQuery) {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: err.Error()}), nil
+		}
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, history), nil
+
+	case "POST /pause", "POST /resume":
+		docdbAutoscaler, _, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: request.QueryStringParameters["ClusterID"]})
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		if route == "POST /pause" {
+			err = docdbAutoscaler.Pause(ctx)
+		} else {
+			err = docdbAutoscaler.Resume(ctx)
+		}
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, map[string]bool{"Paused": route == "POST /pause"}), nil
+
+	case "POST /alerts":
+		body, err := httpRequestBody(request)
+		if err != nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: err.Error()}), nil
+		}
+		adapter := adapters.Detect(body)
+		if adapter == nil {
+			return httpResponse(http.StatusBadRequest, httpErrorBody{Error: "body is not an Alertmanager, Grafana or Datadog webhook payload"}), nil
+		}
+		result, err := handleAlertPayload(ctx, loggerInstance, settings, adapter, body)
+		if err != nil {
+			return httpResponse(http.StatusInternalServerError, httpErrorBody{Error: err.Error()}), nil
+		}
+		return httpResponse(http.StatusOK, result), nil
+	}
+
+	return httpResponse(http.StatusNotFound, httpErrorBody{Error: "unknown route " + route}), nil
+}
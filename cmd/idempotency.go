@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/idempotency"
+)
+
+// newMessageStore returns the store of the processed SNS messages, or nil when IDEMPOTENCY_TABLE is not set.
+func newMessageStore(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config) (idempotency.Store, error) {
+	if settings.IdempotencyTable == "" {
+		return nil, nil
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return nil, err
+	}
+	return autoscaling.NewIdempotencyStore(cfg, settings), nil
+}
+
+// claimMessage records an SNS message as processed, and reports whether it should be processed: false
+// when it was already processed, e.g. by a redelivery. A message is processed when the store fails, as
+// skipping a scaling action is worse than running it twice.
+func claimMessage(ctx context.Context, loggerInstance *slog.Logger, store idempotency.Store, messageID string) bool {
+	if store == nil || messageID == "" {
+		return true
+	}
+	err := store.Claim(ctx, messageID)
+	if errors.Is(err, idempotency.ErrDuplicate) {
+		loggerInstance.Info("Skipping already processed SNS message", "MessageID", messageID)
+		return false
+	}
+	if err != nil {
+		loggerInstance.Warn("Failed to record SNS message, processing it anyway", "MessageID", messageID, "Error", err)
+	}
+	return true
+}
+
+// forgetMessage removes the record of an SNS message whose processing failed, so that its redelivery is processed.
+func forgetMessage(ctx context.Context, loggerInstance *slog.Logger, store idempotency.Store, messageID string) {
+	if store == nil || messageID == "" {
+		return
+	}
+	if err := store.Forget(context.WithoutCancel(ctx), messageID); err != nil {
+		loggerInstance.Warn("Failed to forget SNS message", "MessageID", messageID, "Error", err)
+	}
+}
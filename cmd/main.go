@@ -3,493 +3,665 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
-	"os"
-	"strconv"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/docdb"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cheelim1/docdb-autoscaler/pkg/adapters"
 	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
 	"github.com/cheelim1/docdb-autoscaler/pkg/logger"
 	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
 )
 
-// ScalingMessage defines the structure of the scaling parameters sent via SNS or EventBridge
+// ScalingMessage defines the structure of the scaling parameters sent via SNS or EventBridge.
+// DesiredCapacity sets the readers to exactly that number instead of the relative NumberReplicas,
+// and InstanceType overrides INSTANCE_TYPE for the replicas added by that action. ApproveBulkScaleIn
+// approves a scale-in beyond BULK_SCALE_IN_LIMIT. PrescaleReplicas adds that many replicas until
+// PrescaleDuration passed, see Prescale.
 type ScalingMessage struct {
-	ScalingType    string `json:"ScalingType"`
-	NumberReplicas int    `json:"NumberReplicas"`
+	ScalingType        string `json:"ScalingType"`
+	NumberReplicas     int    `json:"NumberReplicas"`
+	DesiredCapacity    *int   `json:"DesiredCapacity"`
+	InstanceType       string `json:"InstanceType"`
+	ApproveBulkScaleIn bool   `json:"ApproveBulkScaleIn"`
+	PrescaleReplicas   *int   `json:"PrescaleReplicas"`
+	PrescaleDuration   string `json:"PrescaleDuration"`
+}
+
+// ScheduleDetail carries scheduled-scaling parameters in the detail of an EventBridge event.
+// When NumberReplicas is set the invocation is treated as scheduled scaling, regardless of SCHEDULED_SCALING.
+// Schedule names a schedule from the config file, whose settings fill in the fields left unset.
+type ScheduleDetail struct {
+	Schedule           string `json:"Schedule"`
+	ClusterID          string `json:"ClusterID"`
+	NumberReplicas     *int   `json:"NumberReplicas"`
+	InstanceType       string `json:"InstanceType"`
+	Phase              string `json:"Phase"`              // ScaleOut or ScaleIn, inferred from the scheduled replicas when empty
+	Start              string `json:"Start"`              // Cron of the start of the scaling window, which decides the phase
+	End                string `json:"End"`                // Cron of the end of the scaling window
+	Timezone           string `json:"Timezone"`           // IANA timezone of the scaling window, UTC when empty
+	ApproveBulkScaleIn bool   `json:"ApproveBulkScaleIn"` // Approves a scale-in beyond BULK_SCALE_IN_LIMIT
+}
+
+// resolve fills in the unset fields of the detail from the named schedule of the configuration.
+func (s ScheduleDetail) resolve(settings *config.Config) (ScheduleDetail, error) {
+	if s.Schedule == "" {
+		return s, nil
+	}
+	schedule, found := settings.Schedules[s.Schedule]
+	if !found {
+		return s, fmt.Errorf("schedule %s is not configured", s.Schedule)
+	}
+	if s.ClusterID == "" {
+		s.ClusterID = schedule.ClusterID
+	}
+	if s.NumberReplicas == nil {
+		numberReplicas := schedule.NumberReplicas
+		s.NumberReplicas = &numberReplicas
+	}
+	if s.InstanceType == "" {
+		s.InstanceType = schedule.InstanceType
+	}
+	if s.Phase == "" {
+		s.Phase = schedule.Phase
+	}
+	if s.Start == "" {
+		s.Start = schedule.Start
+	}
+	if s.End == "" {
+		s.End = schedule.End
+	}
+	if s.Timezone == "" {
+		s.Timezone = schedule.Timezone
+	}
+	return s, nil
+}
+
+// applyWindow sets the phase of scheduled scaling at a tick of a schedule with a scaling window: ScaleOut
+// inside the window, in its timezone, and ScaleIn outside it. The tick of a configured schedule takes the
+// replicas of the schedule in effect among the overlapping windows of its cluster, see
+// config.Config.ActiveSchedule. Ticks without a time are evaluated at the time of the invocation.
+func (s ScheduleDetail) applyWindow(settings *config.Config, tick time.Time) (ScheduleDetail, error) {
+	if tick.IsZero() {
+		tick = time.Now()
+	}
+	if configured, found := settings.Schedules[s.Schedule]; found && (configured.Start != "" || configured.End != "") {
+		name, active, inWindow, err := settings.ActiveSchedule(s.Schedule, tick)
+		if err != nil {
+			return s, err
+		}
+		s.Phase = autoscaling.SchedulePhaseScaleIn
+		if inWindow {
+			s.Phase = autoscaling.SchedulePhaseScaleOut
+			s.Schedule = name
+			s.NumberReplicas = &active.NumberReplicas
+			if active.InstanceType != "" {
+				s.InstanceType = active.InstanceType
+			}
+		}
+		return s, nil
+	}
+
+	window, err := schedule.ParseWindow(s.Start, s.End, s.Timezone)
+	if err != nil {
+		return s, fmt.Errorf("invalid scaling window: %w", err)
+	}
+	s.Phase = autoscaling.SchedulePhaseScaleIn
+	if window.Contains(tick) {
+		s.Phase = autoscaling.SchedulePhaseScaleOut
+	}
+	return s, nil
+}
+
+// overrides converts the schedule detail into per-invocation overrides.
+func (s ScheduleDetail) overrides() config.Overrides {
+	overrides := config.Overrides{
+		ClusterID:          s.ClusterID,
+		InstanceType:       s.InstanceType,
+		SchedulePhase:      s.Phase,
+		ApproveBulkScaleIn: s.ApproveBulkScaleIn,
+	}
+	if s.NumberReplicas != nil {
+		scheduledScaling := true
+		overrides.ScheduledScaling = &scheduledScaling
+		overrides.ScheduleNumberReplicas = s.NumberReplicas
+	}
+	return overrides
+}
+
+// VerifyRequest is a direct invocation payload asking which of the given replicas are still pending,
+// typically sent by a Step Functions state machine polling after a scale-out.
+type VerifyRequest struct {
+	PendingInstanceIDs []string `json:"PendingInstanceIDs"`
+}
+
+// DirectInvocation is a custom payload for one-off scaling via `aws lambda invoke`.
+// Fields that are set override the environment configuration for that invocation only.
+type DirectInvocation struct {
+	ClusterID          string `json:"ClusterID"`
+	DesiredReplicas    *int   `json:"DesiredReplicas"`
+	DryRun             *bool  `json:"DryRun"`
+	ApproveBulkScaleIn bool   `json:"ApproveBulkScaleIn"` // Approves a scale-in beyond BULK_SCALE_IN_LIMIT
+	PrescaleReplicas   *int   `json:"PrescaleReplicas"`   // Adds that many replicas until PrescaleDuration passed, instead of DesiredReplicas
+	PrescaleDuration   string `json:"PrescaleDuration"`   // How long prescaled replicas are kept, e.g. "6h"
 }
 
 func main() {
 	lambda.Start(handler)
 }
 
-func handler(ctx context.Context, event json.RawMessage) error {
-	// Initialize logger
-	loggerInstance := logger.NewLogger()
+// handler returns a structured ScalingResult when STRUCTURED_OUTPUT is enabled (e.g. for Step Functions),
+// otherwise only the error is meaningful and the result is nil. HTTP requests always get an HTTP response.
+func handler(ctx context.Context, event json.RawMessage) (any, error) {
+	// Initialize logger, tagging every line with the correlation ID of the invocation
+	id := correlationID(ctx, event)
+	ctx = correlation.NewContext(ctx, id)
+	loggerInstance := logger.NewLogger().With("CorrelationID", id)
 	loggerInstance.Info("Lambda function invoked")
+	if endpoints := autoscaling.EndpointOverrides(); len(endpoints) > 0 {
+		loggerInstance.Info("Using overridden AWS endpoints", "Endpoints", endpoints)
+	}
+
+	return eventRouter.Route(ctx, loggerInstance, event)
+}
 
-	// Attempt to parse as SNSEvent
-	var snsEvent events.SNSEvent
-	if err := json.Unmarshal(event, &snsEvent); err == nil && len(snsEvent.Records) > 0 {
-		loggerInstance.Info("Detected SNSEvent")
-		return handleSNSEvent(ctx, loggerInstance, snsEvent)
+// maxPayloadSummary is the number of payload bytes included in unsupported event notifications.
+const maxPayloadSummary = 512
+
+// rejectUnsupportedEvent sends a failure notification with a summary of the payload and returns an error,
+// so the invocation is retried and eventually sent to the dead-letter queue of the trigger.
+func rejectUnsupportedEvent(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, event json.RawMessage) error {
+	summary := string(event)
+	if len(summary) > maxPayloadSummary {
+		summary = summary[:maxPayloadSummary] + "..."
 	}
+	loggerInstance.Error("Received unsupported event type", "EventData", summary)
+	err := fmt.Errorf("unsupported event payload: %s", summary)
 
-	// Attempt to parse as CloudWatchEvent
-	var cwEvent events.CloudWatchEvent
-	if err := json.Unmarshal(event, &cwEvent); err == nil && cwEvent.Source != "" {
-		loggerInstance.Info("Detected CloudWatchEvent")
-		return handleCloudWatchEvent(ctx, loggerInstance, cwEvent)
+	notifier, notifierErr := newNotifier(ctx, settings)
+	if notifierErr != nil {
+		loggerInstance.Error("Failed to initialize the notifier", "Error", notifierErr)
+		return err
 	}
+	if notifyErr := notifier.SendFailureNotification(ctx, settings.ClusterID, err.Error(), "process event"); notifyErr != nil {
+		loggerInstance.Error("Failed to send failure notification", "Error", notifyErr)
+	}
+	return err
+}
 
-	// If neither, log unsupported event type
-	loggerInstance.Warn("Received unsupported event type", "EventType", fmt.Sprintf("%T", event), "EventData", string(event))
-	return nil
+// newNotifier returns the notifier of the failures reported outside of a scaling action, e.g. of unsupported
+// events. Tests replace it to record the notifications.
+var newNotifier = func(ctx context.Context, settings *config.Config) (notifications.NotifierInterface, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return autoscaling.NewNotifier(sns.NewFromConfig(cfg), settings)
 }
 
-func handleSNSEvent(ctx context.Context, loggerInstance *slog.Logger, snsEvent events.SNSEvent) error {
+// newDocumentDB initializes the autoscaler of a resolved configuration. Tests replace it to use mock clients.
+var newDocumentDB = autoscaling.NewFromConfig
+
+// handlerResult drops the result unless structured output is enabled.
+func handlerResult(structuredOutput bool, result *autoscaling.ScalingResult) *autoscaling.ScalingResult {
+	if !structuredOutput {
+		return nil
+	}
+	return result
+}
+
+// handleVerifyRequest reports which of the requested replicas are not yet available.
+func handleVerifyRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, verifyRequest VerifyRequest) (*autoscaling.ScalingResult, error) {
 	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(ctx)
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
 	if err != nil {
 		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
-		return err
+		return nil, err
 	}
 
-	// Initialize AWS clients
-	docdbClient := docdb.NewFromConfig(cfg)
-	cloudwatchClient := cloudwatch.NewFromConfig(cfg)
-	snsClient := sns.NewFromConfig(cfg)
-	rdsClient := rds.NewFromConfig(cfg)
+	if settings.ClusterID == "" {
+		loggerInstance.Error("Environment variable CLUSTER_IDENTIFIER is not set")
+		return nil, fmt.Errorf("CLUSTER_IDENTIFIER is not set")
+	}
 
-	// Initialize notifier
-	snsTopicArn := os.Getenv("SNS_TOPIC_ARN")
-	if snsTopicArn == "" {
-		loggerInstance.Error("Environment variable SNS_TOPIC_ARN is not set")
-		return fmt.Errorf("SNS_TOPIC_ARN is not set")
+	docdbAutoscaler := &autoscaling.DocumentDB{
+		ClusterID:   settings.ClusterID,
+		DocDBClient: docdb.NewFromConfig(autoscaling.ClusterAWSConfig(cfg, settings)),
+		Logger:      loggerInstance,
 	}
-	notifier := notifications.NewNotifier(snsClient, snsTopicArn)
 
-	// Read common environment variables
-	clusterID := os.Getenv("CLUSTER_IDENTIFIER")
-	if clusterID == "" {
-		loggerInstance.Error("Environment variable CLUSTER_IDENTIFIER is not set")
-		return fmt.Errorf("CLUSTER_IDENTIFIER is not set")
+	pending, err := docdbAutoscaler.GetPendingInstances(ctx, verifyRequest.PendingInstanceIDs)
+	if err != nil {
+		loggerInstance.Error("Failed to verify pending instances", "Error", err)
+		return nil, err
 	}
+	loggerInstance.Info("Verified pending instances", "Requested", len(verifyRequest.PendingInstanceIDs), "StillPending", len(pending))
+
+	result := autoscaling.NewScalingResult(false)
+	result.Decision = autoscaling.DecisionVerify
+	result.PendingInstanceIDs = pending
+	return result, nil
+}
 
-	minCapacityStr := os.Getenv("MIN_CAPACITY")
-	if minCapacityStr == "" {
-		loggerInstance.Error("Environment variable MIN_CAPACITY is not set")
-		return fmt.Errorf("MIN_CAPACITY is not set")
+// retrySettings controls how scaling actions are retried.
+type retrySettings struct {
+	maxRetries     int
+	initialBackoff time.Duration
+}
+
+// loadConfig loads the autoscaler configuration from the optional config file and the environment variables.
+func loadConfig(ctx context.Context, loggerInstance *slog.Logger) (*config.Config, error) {
+	// Load AWS configuration, needed to read the config file from S3
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return nil, err
 	}
-	minCapacity, err := strconv.Atoi(minCapacityStr)
+
+	settings, err := config.Load(ctx, autoscaling.NewS3Client(cfg))
 	if err != nil {
-		loggerInstance.Error("Invalid MIN_CAPACITY", "Error", err)
-		return err
+		loggerInstance.Error("Failed to load configuration", "Error", err)
+		return nil, err
 	}
+	return settings, nil
+}
 
-	maxCapacityStr := os.Getenv("MAX_CAPACITY")
-	if maxCapacityStr == "" {
-		loggerInstance.Error("Environment variable MAX_CAPACITY is not set")
-		return fmt.Errorf("MAX_CAPACITY is not set")
+// newAutoscaler builds the DocumentDB autoscaler and its retry settings from the configuration,
+// applying any per-invocation overrides.
+func newAutoscaler(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, overrides config.Overrides) (*autoscaling.DocumentDB, retrySettings, error) {
+	// Resolve and validate the settings of this invocation
+	resolved, err := settings.Resolve(overrides)
+	if errors.Is(err, config.ErrClusterNotAllowed) {
+		rejectDisallowedCluster(ctx, loggerInstance, settings, settings.ForCluster(overrides.ClusterID).ClusterID, err)
+		return nil, retrySettings{}, err
 	}
-	maxCapacity, err := strconv.Atoi(maxCapacityStr)
 	if err != nil {
-		loggerInstance.Error("Invalid MAX_CAPACITY", "Error", err)
-		return err
+		loggerInstance.Error("Invalid configuration", "Error", err)
+		return nil, retrySettings{}, err
 	}
+	settings = resolved
 
-	// Read Scaling Type
-	scheduledScalingStr := os.Getenv("SCHEDULED_SCALING")
-	scheduledScaling := false
-	if scheduledScalingStr != "" {
-		scheduledScaling, err = strconv.ParseBool(scheduledScalingStr)
-		if err != nil {
-			loggerInstance.Error("Invalid SCHEDULED_SCALING value", "Error", err)
-			return err
-		}
+	// Load AWS configuration
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return nil, retrySettings{}, err
 	}
 
-	// Initialize variables for scaling type-specific environment variables
-	var (
-		metricName             string
-		targetValue            float64
-		scaleInCooldown        int
-		scaleOutCooldown       int
-		scheduleNumberReplicas int
-	)
-
-	if scheduledScaling {
-		// Scheduled Scaling: Read relevant environment variables
-		scheduleNumberReplicasStr := os.Getenv("SCHEDULE_NUMBER_REPLICAS")
-		if scheduleNumberReplicasStr == "" {
-			loggerInstance.Error("Environment variable SCHEDULE_NUMBER_REPLICAS is not set")
-			return fmt.Errorf("SCHEDULE_NUMBER_REPLICAS is not set")
-		}
-		scheduleNumberReplicas, err = strconv.Atoi(scheduleNumberReplicasStr)
-		if err != nil {
-			loggerInstance.Error("Invalid SCHEDULE_NUMBER_REPLICAS", "Error", err)
-			return err
-		}
-	} else {
-		// Metric-Based Scaling: Read relevant environment variables
-		metricName = os.Getenv("METRIC_NAME")
-		if metricName == "" {
-			loggerInstance.Error("Environment variable METRIC_NAME is not set")
-			return fmt.Errorf("METRIC_NAME is not set")
-		}
+	docdbAutoscaler, err := newDocumentDB(cfg, settings, loggerInstance)
+	if err != nil {
+		loggerInstance.Error("Invalid configuration", "Error", err)
+		return nil, retrySettings{}, err
+	}
 
-		targetValueStr := os.Getenv("TARGET_VALUE")
-		if targetValueStr == "" {
-			loggerInstance.Error("Environment variable TARGET_VALUE is not set")
-			return fmt.Errorf("TARGET_VALUE is not set")
-		}
-		targetValue, err = strconv.ParseFloat(targetValueStr, 64)
-		if err != nil {
-			loggerInstance.Error("Invalid TARGET_VALUE", "Error", err)
-			return err
-		}
+	retry := retrySettings{
+		maxRetries:     settings.MaxRetries,
+		initialBackoff: time.Duration(settings.InitialBackoff) * time.Second,
+	}
+	return docdbAutoscaler, retry, nil
+}
 
-		scaleInCooldownStr := os.Getenv("SCALE_IN_COOLDOWN")
-		if scaleInCooldownStr == "" {
-			loggerInstance.Error("Environment variable SCALE_IN_COOLDOWN is not set")
-			return fmt.Errorf("SCALE_IN_COOLDOWN is not set")
-		}
-		scaleInCooldown, err = strconv.Atoi(scaleInCooldownStr)
+func handleSNSEvent(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, snsEvent events.SNSEvent) (*autoscaling.ScalingResult, error) {
+	// Initialize aggregation variables for dry-run
+	var totalDryRunAdditions int
+	var totalDryRunRemovals int
+	dryRun := false
+	result := autoscaling.NewScalingResult(settings.DryRun)
+
+	// SNS delivers at least once, so redelivered messages are skipped when IDEMPOTENCY_TABLE is set
+	messages, err := newMessageStore(ctx, loggerInstance, settings)
+	if err != nil {
+		return nil, err
+	}
+	// Records fanned out from the same alarm are evaluated once per cluster, once an evaluation succeeded
+	evaluated := map[string]bool{}
+
+	// Process each SNS record on its own, so that a failed record does not hold back the others
+	var succeeded int
+	var errs []error
+	for _, record := range snsEvent.Records {
+		snsRecord := record.SNS
+		loggerInstance.Info("Received SNS message", "MessageID", snsRecord.MessageID, "Subject", snsRecord.Subject)
+		if !claimMessage(ctx, loggerInstance, messages, snsRecord.MessageID) {
+			result.DuplicateMessageIDs = append(result.DuplicateMessageIDs, snsRecord.MessageID)
+			continue
+		}
+		failRecord := func(err error) {
+			loggerInstance.Error("Scaling process failed", "MessageID", snsRecord.MessageID, "Error", err)
+			forgetMessage(ctx, loggerInstance, messages, snsRecord.MessageID)
+			result.FailedRecords = append(result.FailedRecords, autoscaling.RecordFailure{MessageID: snsRecord.MessageID, Error: err.Error()})
+			errs = append(errs, err)
+		}
+
+		// Alerts of external alerting systems, e.g. an Alertmanager webhook relayed to the topic
+		if adapter := adapters.Detect([]byte(snsRecord.Message)); adapter != nil {
+			alertResult, err := handleAlertPayload(ctx, loggerInstance, settings, adapter, []byte(snsRecord.Message))
+			if err != nil {
+				failRecord(err)
+				continue
+			}
+			result.Merge(alertResult)
+			succeeded++
+			continue
+		}
+
+		// RDS event notifications of an event subscription, e.g. a failed instance or a maintenance
+		if rdsEvent, ok := autoscaling.ParseRDSEvent([]byte(snsRecord.Message)); ok {
+			eventResult, err := handleRDSEvent(ctx, loggerInstance, settings, rdsEvent)
+			if err != nil {
+				failRecord(err)
+				continue
+			}
+			result.Merge(eventResult)
+			succeeded++
+			continue
+		}
+
+		// Scale the cluster the alarm watches, when it is one of the configured clusters
+		var namedClusterID string
+		var alarmNotification autoscaling.AlarmNotification
+		if err := json.Unmarshal([]byte(snsRecord.Message), &alarmNotification); err == nil && settings.HasCluster(alarmNotification.ClusterID()) {
+			namedClusterID = alarmNotification.ClusterID()
+		}
+
+		clusterIDs, err := expandClusterTargets(ctx, loggerInstance, settings, settings.ClusterTargets(namedClusterID))
 		if err != nil {
-			loggerInstance.Error("Invalid SCALE_IN_COOLDOWN", "Error", err)
-			return err
+			failRecord(err)
+			continue
 		}
 
-		scaleOutCooldownStr := os.Getenv("SCALE_OUT_COOLDOWN")
-		if scaleOutCooldownStr == "" {
-			loggerInstance.Error("Environment variable SCALE_OUT_COOLDOWN is not set")
-			return fmt.Errorf("SCALE_OUT_COOLDOWN is not set")
-		}
-		scaleOutCooldown, err = strconv.Atoi(scaleOutCooldownStr)
+		// The instance type override is validated with the rest of the configuration, parse errors are reported by processScaling
+		var scalingMessage ScalingMessage
+		_ = json.Unmarshal([]byte(snsRecord.Message), &scalingMessage)
+
+		var deduplicated int
+		err = forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+			key := evaluationKey(clusterID, scalingMessage, &alarmNotification)
+			if evaluated[key] {
+				loggerInstance.Info("Skipping cluster already evaluated for the same message in this invocation", "MessageID", snsRecord.MessageID, "ClusterID", clusterID)
+				deduplicated++
+				return nil
+			}
+
+			docdbAutoscaler, retry, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: clusterID, InstanceType: scalingMessage.InstanceType, ApproveBulkScaleIn: scalingMessage.ApproveBulkScaleIn})
+			if err != nil {
+				return err
+			}
+
+			// Proceed with scaling logic
+			additions, removals, err := processScaling(ctx, loggerInstance, docdbAutoscaler, snsRecord.Message, retry.maxRetries, retry.initialBackoff)
+			if err != nil {
+				return err
+			}
+			result.Merge(docdbAutoscaler.LastResult())
+			// A failed evaluation is not marked, so that the next record of the same action evaluates it again
+			evaluated[key] = true
+
+			// Aggregate dry-run actions
+			if docdbAutoscaler.DryRun {
+				dryRun = true
+				totalDryRunAdditions += additions
+				totalDryRunRemovals += removals
+			}
+			return nil
+		})
 		if err != nil {
-			loggerInstance.Error("Invalid SCALE_OUT_COOLDOWN", "Error", err)
-			return err
+			failRecord(err)
+			continue
+		}
+		if deduplicated > 0 && deduplicated == len(clusterIDs) {
+			result.CoalescedMessageIDs = append(result.CoalescedMessageIDs, snsRecord.MessageID)
 		}
+		succeeded++
 	}
 
-	// Read Retry Configuration environment variables
-	maxRetriesStr := os.Getenv("MAX_RETRIES")
-	maxRetries := 5 // Default value
-	if maxRetriesStr != "" {
-		maxRetries, err = strconv.Atoi(maxRetriesStr)
-		if err != nil {
-			loggerInstance.Error("Invalid MAX_RETRIES value", "Error", err)
-			return err
-		}
+	// If dry-run, log the aggregated summary
+	if dryRun {
+		loggerInstance.Info("Dry Run Summary",
+			"TotalReplicasToAdd", totalDryRunAdditions,
+			"TotalReplicasToRemove", totalDryRunRemovals,
+		)
 	}
 
-	initialBackoffStr := os.Getenv("INITIAL_BACKOFF")
-	initialBackoff := time.Second // Default 1 second
-	if initialBackoffStr != "" {
-		initialBackoffSeconds, err := strconv.Atoi(initialBackoffStr)
-		if err != nil {
-			loggerInstance.Error("Invalid INITIAL_BACKOFF value", "Error", err)
-			return err
-		}
-		initialBackoff = time.Duration(initialBackoffSeconds) * time.Second
+	// Without a record processed, the invocation fails, so that it is retried; otherwise the failed records
+	// are reported in the result and notified
+	if len(errs) > 0 && succeeded == 0 {
+		return nil, errors.Join(errs...)
 	}
+	if len(result.FailedRecords) > 0 {
+		notifyFailedRecords(ctx, loggerInstance, settings, batchSource(snsEvent.Records), result.FailedRecords, len(snsEvent.Records))
+	}
+	return result, nil
+}
 
-	// Read DRYRUN flag
-	dryRunStr := os.Getenv("DRYRUN")
-	dryRun := false
-	if dryRunStr != "" {
-		dryRun, err = strconv.ParseBool(dryRunStr)
+func handleCloudWatchEvent(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, cwEvent events.CloudWatchEvent) (*autoscaling.ScalingResult, error) {
+	// Scheduled-scaling parameters may be carried in the event detail so one Lambda can serve many schedules
+	var overrides config.Overrides
+	if len(cwEvent.Detail) > 0 {
+		var detail ScheduleDetail
+		if err := json.Unmarshal(cwEvent.Detail, &detail); err != nil {
+			loggerInstance.Error("Failed to parse event detail", "Error", err)
+			return nil, err
+		}
+		detail, err := detail.resolve(settings)
 		if err != nil {
-			loggerInstance.Error("Invalid DRYRUN value", "Error", err)
-			return err
+			loggerInstance.Error("Failed to resolve schedule", "Error", err)
+			return nil, err
+		}
+		if detail.Start != "" || detail.End != "" {
+			triggered := detail.Schedule
+			if detail, err = detail.applyWindow(settings, cwEvent.Time); err != nil {
+				loggerInstance.Error("Failed to evaluate the scaling window", "Error", err)
+				return nil, err
+			}
+			loggerInstance.Info("Evaluated the scaling window", "Schedule", triggered, "ActiveSchedule", detail.Schedule, "Start", detail.Start, "End", detail.End, "Timezone", detail.Timezone, "Tick", cwEvent.Time, "Phase", detail.Phase)
+		}
+		overrides = detail.overrides()
+		if detail.NumberReplicas != nil {
+			loggerInstance.Info("Using scheduled scaling parameters from event detail", "ClusterID", detail.ClusterID, "NumberReplicas", *detail.NumberReplicas, "InstanceType", detail.InstanceType, "Phase", detail.Phase)
 		}
 	}
 
-	// Read INSTANCE_TYPE as optional
-	instanceType := os.Getenv("INSTANCE_TYPE")
-	if instanceType == "" {
-		loggerInstance.Info("INSTANCE_TYPE not set. Will use writer instance's type for scaling.")
-	} else {
-		loggerInstance.Info("INSTANCE_TYPE set", "InstanceType", instanceType)
-	}
-
-	// Initialize the DocumentDB autoscaler with the RDS client
-	docdbAutoscaler := autoscaling.NewDocumentDB(
-		clusterID,
-		minCapacity,
-		maxCapacity,
-		metricName,
-		targetValue,
-		scaleInCooldown,
-		scaleOutCooldown,
-		instanceType,
-		dryRun,
-		scheduledScaling,
-		scheduleNumberReplicas,
-		docdbClient,
-		cloudwatchClient,
-		notifier,
-		loggerInstance,
-		rdsClient,
-	)
-
 	// Initialize aggregation variables for dry-run
 	var totalDryRunAdditions int
 	var totalDryRunRemovals int
+	dryRun := false
+	result := autoscaling.NewScalingResult(settings.DryRun)
 
-	// Process each SNS record
-	for _, record := range snsEvent.Records {
-		snsRecord := record.SNS
-		loggerInstance.Info("Received SNS message", "MessageID", snsRecord.MessageID, "Subject", snsRecord.Subject)
+	// Without a cluster in the event detail, the schedule applies to every configured cluster
+	clusterIDs, err := expandClusterTargets(ctx, loggerInstance, settings, settings.ClusterTargets(overrides.ClusterID))
+	if err != nil {
+		return nil, err
+	}
 
-		// Proceed with scaling logic
-		additions, removals, err := processScaling(ctx, loggerInstance, docdbAutoscaler, snsRecord.Message, maxRetries, initialBackoff)
+	err = forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		clusterOverrides := overrides
+		clusterOverrides.ClusterID = clusterID
+		docdbAutoscaler, retry, err := newAutoscaler(ctx, loggerInstance, settings, clusterOverrides)
 		if err != nil {
-			loggerInstance.Error("Scaling process failed", "Error", err)
 			return err
 		}
 
+		// Execute scaling action
+		additions, removals, err := processScaling(ctx, loggerInstance, docdbAutoscaler, "", retry.maxRetries, retry.initialBackoff)
+		if err != nil {
+			return err
+		}
+		result.Merge(docdbAutoscaler.LastResult())
+
 		// Aggregate dry-run actions
 		if docdbAutoscaler.DryRun {
+			dryRun = true
 			totalDryRunAdditions += additions
 			totalDryRunRemovals += removals
 		}
+		return nil
+	})
+	if err != nil {
+		loggerInstance.Error("Scheduled scaling action failed", "Error", err)
+		return nil, err
 	}
 
 	// If dry-run, log the aggregated summary
-	if docdbAutoscaler.DryRun {
+	if dryRun {
 		loggerInstance.Info("Dry Run Summary",
 			"TotalReplicasToAdd", totalDryRunAdditions,
 			"TotalReplicasToRemove", totalDryRunRemovals,
 		)
+	} else {
+		loggerInstance.Info("Scheduled scaling action executed successfully")
 	}
 
-	return nil
+	return result, nil
 }
 
-func handleCloudWatchEvent(ctx context.Context, loggerInstance *slog.Logger, cwEvent events.CloudWatchEvent) error {
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
-		return err
-	}
-
-	// Initialize AWS clients
-	docdbClient := docdb.NewFromConfig(cfg)
-	cloudwatchClient := cloudwatch.NewFromConfig(cfg)
-	snsClient := sns.NewFromConfig(cfg)
-	rdsClient := rds.NewFromConfig(cfg)
-
-	// Initialize notifier
-	snsTopicArn := os.Getenv("SNS_TOPIC_ARN")
-	if snsTopicArn == "" {
-		loggerInstance.Error("Environment variable SNS_TOPIC_ARN is not set")
-		return fmt.Errorf("SNS_TOPIC_ARN is not set")
-	}
-	notifier := notifications.NewNotifier(snsClient, snsTopicArn)
-
-	// Read common environment variables
-	clusterID := os.Getenv("CLUSTER_IDENTIFIER")
-	if clusterID == "" {
-		loggerInstance.Error("Environment variable CLUSTER_IDENTIFIER is not set")
-		return fmt.Errorf("CLUSTER_IDENTIFIER is not set")
-	}
-
-	minCapacityStr := os.Getenv("MIN_CAPACITY")
-	if minCapacityStr == "" {
-		loggerInstance.Error("Environment variable MIN_CAPACITY is not set")
-		return fmt.Errorf("MIN_CAPACITY is not set")
+// expandClusterTargets resolves cluster patterns (globs or regex:) into the matching clusters of the account,
+// so blue/green replacements are picked up without changing the configuration.
+func expandClusterTargets(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, targets []string) ([]string, error) {
+	hasPattern := false
+	for _, target := range targets {
+		hasPattern = hasPattern || config.IsClusterPattern(target)
 	}
-	minCapacity, err := strconv.Atoi(minCapacityStr)
-	if err != nil {
-		loggerInstance.Error("Invalid MIN_CAPACITY", "Error", err)
-		return err
+	if !hasPattern {
+		return targets, nil
 	}
 
-	maxCapacityStr := os.Getenv("MAX_CAPACITY")
-	if maxCapacityStr == "" {
-		loggerInstance.Error("Environment variable MAX_CAPACITY is not set")
-		return fmt.Errorf("MAX_CAPACITY is not set")
-	}
-	maxCapacity, err := strconv.Atoi(maxCapacityStr)
+	// Load AWS configuration
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
 	if err != nil {
-		loggerInstance.Error("Invalid MAX_CAPACITY", "Error", err)
-		return err
-	}
-
-	// Read Scaling Type
-	scheduledScalingStr := os.Getenv("SCHEDULED_SCALING")
-	scheduledScaling := false
-	if scheduledScalingStr != "" {
-		scheduledScaling, err = strconv.ParseBool(scheduledScalingStr)
-		if err != nil {
-			loggerInstance.Error("Invalid SCHEDULED_SCALING value", "Error", err)
-			return err
-		}
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return nil, err
 	}
 
-	// Initialize variables for scaling type-specific environment variables
-	var (
-		metricName             string
-		targetValue            float64
-		scaleInCooldown        int
-		scaleOutCooldown       int
-		scheduleNumberReplicas int
-	)
-
-	if scheduledScaling {
-		// Scheduled Scaling: Read relevant environment variables
-		scheduleNumberReplicasStr := os.Getenv("SCHEDULE_NUMBER_REPLICAS")
-		if scheduleNumberReplicasStr == "" {
-			loggerInstance.Error("Environment variable SCHEDULE_NUMBER_REPLICAS is not set")
-			return fmt.Errorf("SCHEDULE_NUMBER_REPLICAS is not set")
-		}
-		scheduleNumberReplicas, err = strconv.Atoi(scheduleNumberReplicasStr)
-		if err != nil {
-			loggerInstance.Error("Invalid SCHEDULE_NUMBER_REPLICAS", "Error", err)
-			return err
-		}
-	} else {
-		// Metric-Based Scaling: Read relevant environment variables
-		metricName = os.Getenv("METRIC_NAME")
-		if metricName == "" {
-			loggerInstance.Error("Environment variable METRIC_NAME is not set")
-			return fmt.Errorf("METRIC_NAME is not set")
-		}
-
-		targetValueStr := os.Getenv("TARGET_VALUE")
-		if targetValueStr == "" {
-			loggerInstance.Error("Environment variable TARGET_VALUE is not set")
-			return fmt.Errorf("TARGET_VALUE is not set")
-		}
-		targetValue, err = strconv.ParseFloat(targetValueStr, 64)
-		if err != nil {
-			loggerInstance.Error("Invalid TARGET_VALUE", "Error", err)
-			return err
+	// Clusters of the engine are listed with the role and in the region of the pattern, once per engine, role and region
+	clusterIDsByLocation := map[string][]string{}
+	seen := map[string]bool{}
+	var expanded []string
+	for _, target := range targets {
+		if !config.IsClusterPattern(target) {
+			if !seen[target] {
+				seen[target] = true
+				expanded = append(expanded, target)
+			}
+			continue
 		}
 
-		scaleInCooldownStr := os.Getenv("SCALE_IN_COOLDOWN")
-		if scaleInCooldownStr == "" {
-			loggerInstance.Error("Environment variable SCALE_IN_COOLDOWN is not set")
-			return fmt.Errorf("SCALE_IN_COOLDOWN is not set")
-		}
-		scaleInCooldown, err = strconv.Atoi(scaleInCooldownStr)
+		targetSettings := settings.ForCluster(target)
+		targetCfg := autoscaling.ClusterAWSConfig(cfg, targetSettings)
+		engine, err := autoscaling.LookupEngine(targetSettings.Engine)
 		if err != nil {
-			loggerInstance.Error("Invalid SCALE_IN_COOLDOWN", "Error", err)
-			return err
-		}
+			loggerInstance.Error("Invalid configuration", "Error", err)
+			return nil, err
+		}
+		location := engine.Name + ":" + targetSettings.AssumeRoleArn + "@" + targetCfg.Region
+		clusterIDs, found := clusterIDsByLocation[location]
+		if !found {
+			clusterIDs, err = autoscaling.ListClusterIdentifiers(ctx, rds.NewFromConfig(targetCfg), engine)
+			if err != nil {
+				loggerInstance.Error("Failed to list DB clusters", "Error", err, "AssumeRoleArn", targetSettings.AssumeRoleArn, "Region", targetCfg.Region)
+				return nil, err
+			}
+			clusterIDsByLocation[location] = clusterIDs
+		}
+
+		matches := 0
+		for _, clusterID := range clusterIDs {
+			matched, err := config.MatchCluster(target, clusterID)
+			if err != nil {
+				loggerInstance.Error("Invalid cluster pattern", "Error", err)
+				return nil, err
+			}
+			if matched && !seen[clusterID] {
+				seen[clusterID] = true
+				expanded = append(expanded, clusterID)
+				matches++
+			}
+		}
+		loggerInstance.Info("Resolved cluster pattern", "Pattern", target, "MatchedClusters", matches)
+	}
+	if len(expanded) == 0 {
+		loggerInstance.Warn("No clusters matched the configured patterns", "Patterns", targets)
+	}
+	return expanded, nil
+}
 
-		scaleOutCooldownStr := os.Getenv("SCALE_OUT_COOLDOWN")
-		if scaleOutCooldownStr == "" {
-			loggerInstance.Error("Environment variable SCALE_OUT_COOLDOWN is not set")
-			return fmt.Errorf("SCALE_OUT_COOLDOWN is not set")
-		}
-		scaleOutCooldown, err = strconv.Atoi(scaleOutCooldownStr)
-		if err != nil {
-			loggerInstance.Error("Invalid SCALE_OUT_COOLDOWN", "Error", err)
-			return err
+// forEachCluster runs action for each cluster. A failing cluster does not stop the others;
+// all failures are returned together.
+func forEachCluster(loggerInstance *slog.Logger, clusterIDs []string, action func(clusterID string) error) error {
+	var errs []error
+	for _, clusterID := range clusterIDs {
+		if len(clusterIDs) > 1 {
+			loggerInstance.Info("Processing cluster", "ClusterID", clusterID)
 		}
-	}
-
-	// Read Retry Configuration environment variables
-	maxRetriesStr := os.Getenv("MAX_RETRIES")
-	maxRetries := 5 // Default value
-	if maxRetriesStr != "" {
-		maxRetries, err = strconv.Atoi(maxRetriesStr)
-		if err != nil {
-			loggerInstance.Error("Invalid MAX_RETRIES value", "Error", err)
-			return err
+		if err := action(clusterID); err != nil {
+			if clusterID != "" {
+				err = fmt.Errorf("cluster %s: %w", clusterID, err)
+			}
+			errs = append(errs, err)
 		}
 	}
+	return errors.Join(errs...)
+}
 
-	initialBackoffStr := os.Getenv("INITIAL_BACKOFF")
-	initialBackoff := time.Second // Default 1 second
-	if initialBackoffStr != "" {
-		initialBackoffSeconds, err := strconv.Atoi(initialBackoffStr)
-		if err != nil {
-			loggerInstance.Error("Invalid INITIAL_BACKOFF value", "Error", err)
-			return err
-		}
-		initialBackoff = time.Duration(initialBackoffSeconds) * time.Second
+// handleDirectInvocation scales the cluster to the requested number of readers, applying the payload overrides.
+// The result is always returned so the invoker can see what happened.
+func handleDirectInvocation(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, directInvocation DirectInvocation) (*autoscaling.ScalingResult, error) {
+	docdbAutoscaler, retry, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{
+		ClusterID:          directInvocation.ClusterID,
+		DryRun:             directInvocation.DryRun,
+		ApproveBulkScaleIn: directInvocation.ApproveBulkScaleIn,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Read DRYRUN flag
-	dryRunStr := os.Getenv("DRYRUN")
-	dryRun := false
-	if dryRunStr != "" {
-		dryRun, err = strconv.ParseBool(dryRunStr)
-		if err != nil {
-			loggerInstance.Error("Invalid DRYRUN value", "Error", err)
-			return err
+	if directInvocation.PrescaleReplicas != nil {
+		loggerInstance.Info("Executing direct prescale", "ClusterID", docdbAutoscaler.ClusterID, "PrescaleReplicas", *directInvocation.PrescaleReplicas, "PrescaleDuration", directInvocation.PrescaleDuration, "DryRun", docdbAutoscaler.DryRun)
+		if err := prescale(ctx, loggerInstance, docdbAutoscaler, *directInvocation.PrescaleReplicas, directInvocation.PrescaleDuration); err != nil {
+			return nil, err
 		}
+		return docdbAutoscaler.LastResult(), nil
 	}
 
-	// Read INSTANCE_TYPE as optional
-	instanceType := os.Getenv("INSTANCE_TYPE")
-	if instanceType == "" {
-		loggerInstance.Info("INSTANCE_TYPE not set. Will use writer instance's type for scaling.")
-	} else {
-		loggerInstance.Info("INSTANCE_TYPE set", "InstanceType", instanceType)
-	}
-
-	// Initialize the DocumentDB autoscaler with the RDS client
-	docdbAutoscaler := autoscaling.NewDocumentDB(
-		clusterID,
-		minCapacity,
-		maxCapacity,
-		metricName,
-		targetValue,
-		scaleInCooldown,
-		scaleOutCooldown,
-		instanceType,
-		dryRun,
-		scheduledScaling,
-		scheduleNumberReplicas,
-		docdbClient,
-		cloudwatchClient,
-		notifier,
-		loggerInstance,
-		rdsClient,
-	)
-
-	// Initialize aggregation variables for dry-run
-	var totalDryRunAdditions int
-	var totalDryRunRemovals int
+	desiredReplicas := *directInvocation.DesiredReplicas
+	loggerInstance.Info("Executing direct invocation", "ClusterID", docdbAutoscaler.ClusterID, "DesiredReplicas", desiredReplicas, "DryRun", docdbAutoscaler.DryRun)
 
-	// Execute scaling action
-	additions, removals, err := processScaling(ctx, loggerInstance, docdbAutoscaler, "", maxRetries, initialBackoff)
+	err = executeWithRetry(ctx, loggerInstance, func(ctx context.Context) error {
+		return docdbAutoscaler.ScaleToCapacity(ctx, desiredReplicas)
+	}, retry.maxRetries, retry.initialBackoff)
+	docdbAutoscaler.ReportOutcome(ctx, err)
 	if err != nil {
-		loggerInstance.Error("Scheduled scaling action failed", "Error", err)
-		return err
+		loggerInstance.Error("Direct scaling action failed after retries", "Error", err)
+		return nil, err
 	}
 
-	// Aggregate dry-run actions
-	if docdbAutoscaler.DryRun {
-		totalDryRunAdditions += additions
-		totalDryRunRemovals += removals
-	}
+	return docdbAutoscaler.LastResult(), nil
+}
 
-	// If dry-run, log the aggregated summary
-	if docdbAutoscaler.DryRun {
-		loggerInstance.Info("Dry Run Summary",
-			"TotalReplicasToAdd", totalDryRunAdditions,
-			"TotalReplicasToRemove", totalDryRunRemovals,
-		)
-	} else {
-		loggerInstance.Info("Scheduled scaling action executed successfully")
+// prescale adds replicas of the autoscaler for a duration, e.g. "6h". It is not retried, as a retry of a
+// prescale that added some replicas before failing would add more.
+func prescale(ctx context.Context, loggerInstance *slog.Logger, autoscaler *autoscaling.DocumentDB, replicas int, duration string) error {
+	parsed, err := time.ParseDuration(duration)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("PrescaleDuration must be a positive duration, e.g. 6h, got %q", duration)
 	}
-
-	return nil
+	err = autoscaler.Prescale(ctx, replicas, parsed)
+	autoscaler.ReportOutcome(ctx, err)
+	if err != nil {
+		loggerInstance.Error("Prescale failed", "Error", err)
+	}
+	return err
 }
 
 // processScaling handles the scaling logic for both SNS-based and scheduled scaling
@@ -507,11 +679,45 @@ func processScaling(ctx context.Context, loggerInstance *slog.Logger, autoscaler
 			return 0, 0, err
 		}
 
-		loggerInstance.Info("Parsed Scaling Message from SNS", "ScalingType", scalingMessage.ScalingType, "NumberReplicas", scalingMessage.NumberReplicas)
+		loggerInstance.Info("Parsed Scaling Message from SNS", "ScalingType", scalingMessage.ScalingType, "NumberReplicas", scalingMessage.NumberReplicas, "InstanceType", scalingMessage.InstanceType)
 
 		// Update autoscaler settings based on SNS message
 		autoscaler.ScheduledScaling = false // Metric-based scaling
 		autoscaler.ScheduleNumberReplicas = scalingMessage.NumberReplicas
+
+		// Keep track of the triggering CloudWatch alarm, if the message is an alarm notification
+		var alarmNotification autoscaling.AlarmNotification
+		if err := json.Unmarshal([]byte(snsMessage), &alarmNotification); err == nil && alarmNotification.AlarmName != "" {
+			loggerInstance.Info("Parsed alarm notification from SNS", "AlarmName", alarmNotification.AlarmName, "NewStateValue", alarmNotification.NewStateValue, "Composite", alarmNotification.IsComposite())
+			autoscaler.TriggerAlarm = &alarmNotification
+		} else {
+			autoscaler.TriggerAlarm = nil
+		}
+
+		// Set the readers to exactly the desired capacity
+		if scalingMessage.DesiredCapacity != nil {
+			desiredCapacity := *scalingMessage.DesiredCapacity
+			loggerInstance.Info("Scaling to desired capacity from SNS", "DesiredCapacity", desiredCapacity)
+			err := executeWithRetry(ctx, loggerInstance, func(ctx context.Context) error {
+				return autoscaler.ScaleToCapacity(ctx, desiredCapacity)
+			}, maxRetries, initialBackoff)
+			autoscaler.ReportOutcome(ctx, err)
+			if err != nil {
+				loggerInstance.Error("Scaling action failed after retries", "Error", err)
+				return 0, 0, err
+			}
+			result := autoscaler.LastResult()
+			return result.ReplicasAdded, result.ReplicasRemoved, nil
+		}
+
+		// Add replicas for a known peak, removed once it passed
+		if scalingMessage.PrescaleReplicas != nil {
+			loggerInstance.Info("Prescaling from SNS", "PrescaleReplicas", *scalingMessage.PrescaleReplicas, "PrescaleDuration", scalingMessage.PrescaleDuration)
+			if err := prescale(ctx, loggerInstance, autoscaler, *scalingMessage.PrescaleReplicas, scalingMessage.PrescaleDuration); err != nil {
+				return 0, 0, err
+			}
+			return autoscaler.LastResult().ReplicasAdded, 0, nil
+		}
 	} else {
 		// Scheduled Scaling
 		loggerInstance.Info("Executing Scheduled Scaling", "NumberReplicas", autoscaler.ScheduleNumberReplicas)
@@ -525,7 +731,11 @@ func processScaling(ctx context.Context, loggerInstance *slog.Logger, autoscaler
 	}
 
 	// Execute scaling action with retry logic
-	err := executeWithRetry(ctx, loggerInstance, autoscaler.ExecuteScalingAction, maxRetries, initialBackoff)
+	err := executeWithRetry(ctx, loggerInstance, func(ctx context.Context) error {
+		_, err := autoscaler.Evaluate(ctx)
+		return err
+	}, maxRetries, initialBackoff)
+	autoscaler.ReportOutcome(ctx, err)
 	if err != nil {
 		loggerInstance.Error("Scaling action failed after retries", "Error", err)
 		return replicasToAdd, replicasToRemove, err
@@ -545,28 +755,3 @@ func processScaling(ctx context.Context, loggerInstance *slog.Logger, autoscaler
 
 	return replicasToAdd, replicasToRemove, nil
 }
-
-// executeWithRetry attempts to execute the provided action with exponential backoff retries
-func executeWithRetry(ctx context.Context, loggerInstance *slog.Logger, action func(context.Context) error, maxRetries int, initialBackoff time.Duration) error {
-	backoff := initialBackoff
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err := action(ctx)
-		if err == nil {
-			return nil
-		}
-
-		loggerInstance.Warn("Scaling action failed, retrying...", "Attempt", attempt, "Error", err)
-
-		// Wait before the next retry
-		time.Sleep(backoff)
-
-		// Exponential backoff with a maximum cap (e.g., 32 seconds)
-		backoff = backoff * 2
-		if backoff > 32*time.Second {
-			backoff = 32 * time.Second
-		}
-	}
-
-	return fmt.Errorf("scaling action failed after %d attempts", maxRetries)
-}
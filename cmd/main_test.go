@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/smithy-go"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	mockCloudWatch "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/cloudwatch"
+	mockDocDB "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/docdb"
+	mockRDS "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/rds"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCluster is a cluster served by gomock clients: a writer and readers, all available, whose readers
+// change with the instances the handlers create and delete.
+type mockCluster struct {
+	id      string
+	readers []string
+	classes map[string]string            // Instance classes of the readers created, by instance ID
+	tags    map[string]map[string]string // Tags of the cluster and its instances, by ARN
+	metric  float64                      // Average of the metric of every reader
+	// failDescribes is the number of descriptions of the cluster that fail first, or -1 for all of them
+	failDescribes int
+	mu            sync.Mutex
+}
+
+// errClusterNotFound is the error of the descriptions of the cluster that fail.
+var errClusterNotFound = &smithy.GenericAPIError{Code: "DBClusterNotFoundFault", Message: "cluster not found"}
+
+// newMockCluster returns a cluster of readers, with a metric at the target of the test configuration.
+func newMockCluster(id string, readers ...string) *mockCluster {
+	return &mockCluster{id: id, readers: readers, classes: map[string]string{}, tags: map[string]map[string]string{}, metric: 50}
+}
+
+// Readers returns the readers of the cluster.
+func (c *mockCluster) Readers() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.readers)
+}
+
+// writer returns the identifier of the writer of the cluster.
+func (c *mockCluster) writer() string {
+	return c.id + "-writer"
+}
+
+// instance returns the description of an instance of the cluster.
+func (c *mockCluster) instance(instanceID string) docdbTypes.DBInstance {
+	instanceClass := c.classes[instanceID]
+	if instanceClass == "" {
+		instanceClass = "db.r6g.large"
+	}
+	return docdbTypes.DBInstance{
+		DBInstanceIdentifier: aws.String(instanceID),
+		DBInstanceArn:        aws.String("arn:aws:rds:us-east-1:123456789012:db:" + instanceID),
+		DBInstanceStatus:     aws.String("available"),
+		DBInstanceClass:      aws.String(instanceClass),
+		DBClusterIdentifier:  aws.String(c.id),
+		Engine:               aws.String("docdb"),
+	}
+}
+
+// tag adds tags to a resource of the cluster.
+func (c *mockCluster) tag(arn string, tags []docdbTypes.Tag) {
+	if c.tags[arn] == nil {
+		c.tags[arn] = map[string]string{}
+	}
+	for _, tag := range tags {
+		c.tags[arn][aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+}
+
+// clients returns the gomock clients of the cluster.
+func (c *mockCluster) clients(ctrl *gomock.Controller) (*mockDocDB.MockDocDBAPI, *mockCloudWatch.MockCloudWatchAPI, *mockRDS.MockRDSAPI) {
+	docdbClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	cloudwatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+	rdsClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	rdsClient.EXPECT().DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.failDescribes != 0 {
+			if c.failDescribes > 0 {
+				c.failDescribes--
+			}
+			return nil, errClusterNotFound
+		}
+		members := []rdsTypes.DBClusterMember{{DBInstanceIdentifier: aws.String(c.writer()), IsClusterWriter: aws.Bool(true)}}
+		for _, reader := range c.readers {
+			members = append(members, rdsTypes.DBClusterMember{DBInstanceIdentifier: aws.String(reader), IsClusterWriter: aws.Bool(false)})
+		}
+		return &rds.DescribeDBClustersOutput{DBClusters: []rdsTypes.DBCluster{{
+			DBClusterIdentifier: aws.String(c.id),
+			DBClusterArn:        aws.String("arn:aws:rds:us-east-1:123456789012:cluster:" + c.id),
+			Status:              aws.String("available"),
+			Engine:              aws.String("docdb"),
+			EngineVersion:       aws.String("5.0.0"),
+			DBClusterMembers:    members,
+		}}}, nil
+	}).AnyTimes()
+	rdsClient.EXPECT().DescribeOrderableDBInstanceOptions(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *rds.DescribeOrderableDBInstanceOptionsInput, optFns ...func(*rds.Options)) (*rds.DescribeOrderableDBInstanceOptionsOutput, error) {
+		return &rds.DescribeOrderableDBInstanceOptionsOutput{OrderableDBInstanceOptions: []rdsTypes.OrderableDBInstanceOption{{DBInstanceClass: input.DBInstanceClass}}}, nil
+	}).AnyTimes()
+
+	docdbClient.EXPECT().DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.DescribeDBInstancesInput, optFns ...func(*docdb.Options)) (*docdb.DescribeDBInstancesOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		instances := []docdbTypes.DBInstance{c.instance(c.writer())}
+		for _, reader := range c.readers {
+			instances = append(instances, c.instance(reader))
+		}
+		return &docdb.DescribeDBInstancesOutput{DBInstances: instances}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		instanceID := aws.ToString(input.DBInstanceIdentifier)
+		c.readers = append(c.readers, instanceID)
+		c.classes[instanceID] = aws.ToString(input.DBInstanceClass)
+		instance := c.instance(instanceID)
+		c.tag(aws.ToString(instance.DBInstanceArn), input.Tags)
+		return &docdb.CreateDBInstanceOutput{DBInstance: &instance}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.readers = slices.DeleteFunc(c.readers, func(reader string) bool { return reader == aws.ToString(input.DBInstanceIdentifier) })
+		return &docdb.DeleteDBInstanceOutput{}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var tags []docdbTypes.Tag
+		for key, value := range c.tags[aws.ToString(input.ResourceName)] {
+			tags = append(tags, docdbTypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+		}
+		return &docdb.ListTagsForResourceOutput{TagList: tags}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.tag(aws.ToString(input.ResourceName), input.Tags)
+		return &docdb.AddTagsToResourceOutput{}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().RemoveTagsFromResource(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, key := range input.TagKeys {
+			delete(c.tags[aws.ToString(input.ResourceName)], key)
+		}
+		return &docdb.RemoveTagsFromResourceOutput{}, nil
+	}).AnyTimes()
+	docdbClient.EXPECT().DescribeGlobalClusters(gomock.Any(), gomock.Any(), gomock.Any()).Return(&docdb.DescribeGlobalClustersOutput{}, nil).AnyTimes()
+
+	cloudwatchClient.EXPECT().GetMetricStatistics(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, input *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return &cloudwatch.GetMetricStatisticsOutput{Datapoints: []cloudwatchTypes.Datapoint{{Average: aws.Float64(c.metric), Timestamp: aws.Time(time.Now().Add(-time.Minute))}}}, nil
+	}).AnyTimes()
+	cloudwatchClient.EXPECT().DescribeAlarms(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloudwatch.DescribeAlarmsOutput{}, nil).AnyTimes()
+	return docdbClient, cloudwatchClient, rdsClient
+}
+
+// testNotifier records the notifications of the handlers.
+type testNotifier struct {
+	mu       sync.Mutex
+	failures []string
+}
+
+func (n *testNotifier) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	return nil
+}
+
+func (n *testNotifier) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	return nil
+}
+
+func (n *testNotifier) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures = append(n.failures, fmt.Sprintf("%s on %s: %s", action, clusterID, errorMessage))
+	return nil
+}
+
+// Failures returns the failure notifications sent, as "action on cluster: message".
+func (n *testNotifier) Failures() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return slices.Clone(n.failures)
+}
+
+// useMockClusters makes the handlers scale the clusters through their gomock clients and record the
+// notifications, with the environment of a single cluster, cluster-a, scaled on CPUUtilization at 50.
+// Environment variables set by the test after the call take precedence.
+func useMockClusters(t *testing.T, clusters ...*mockCluster) *testNotifier {
+	ctrl := gomock.NewController(t)
+	notifier := &testNotifier{}
+	byID := map[string]*mockCluster{}
+	for _, cluster := range clusters {
+		byID[cluster.id] = cluster
+	}
+
+	previousDocumentDB, previousNotifier := newDocumentDB, newNotifier
+	t.Cleanup(func() { newDocumentDB, newNotifier = previousDocumentDB, previousNotifier })
+	newDocumentDB = func(cfg aws.Config, settings *config.Config, logger *slog.Logger) (*autoscaling.DocumentDB, error) {
+		cluster, found := byID[settings.ClusterID]
+		require.True(t, found, "unexpected cluster %s", settings.ClusterID)
+		docdbAutoscaler, err := autoscaling.NewFromConfig(cfg, settings, logger)
+		if err != nil {
+			return nil, err
+		}
+		docdbAutoscaler.DocDBClient, docdbAutoscaler.CloudWatchClient, docdbAutoscaler.RDSClient = cluster.clients(ctrl)
+		docdbAutoscaler.ElasticClient = nil
+		docdbAutoscaler.Notifier = notifier
+		return docdbAutoscaler, nil
+	}
+	newNotifier = func(ctx context.Context, settings *config.Config) (notifications.NotifierInterface, error) {
+		return notifier, nil
+	}
+
+	for env, value := range map[string]string{
+		"AWS_REGION":                "us-east-1",
+		"AWS_ACCESS_KEY_ID":         "test",
+		"AWS_SECRET_ACCESS_KEY":     "test",
+		"AWS_EC2_METADATA_DISABLED": "true",
+		"SNS_TOPIC_ARN":             "arn:aws:sns:us-east-1:123456789012:docdb-autoscaler",
+		"CLUSTER_IDENTIFIER":        "cluster-a",
+		"MIN_CAPACITY":              "1",
+		"MAX_CAPACITY":              "5",
+		"METRIC_NAME":               "CPUUtilization",
+		"TARGET_VALUE":              "50",
+		"SCALE_IN_COOLDOWN":         "0",
+		"SCALE_OUT_COOLDOWN":        "0",
+		"MAX_RETRIES":               "1",
+		"STRUCTURED_OUTPUT":         "true",
+	} {
+		t.Setenv(env, value)
+	}
+	return notifier
+}
+
+// invoke invokes the handler with a payload, and returns the structured result, if any.
+func invoke(t *testing.T, payload any) (*autoscaling.ScalingResult, error) {
+	event, err := json.Marshal(payload)
+	require.NoError(t, err)
+	response, err := handler(context.Background(), event)
+	result, _ := response.(*autoscaling.ScalingResult)
+	return result, err
+}
+
+// snsEvent returns an SNS event of a record per message, with the message IDs msg-1, msg-2...
+func snsEvent(messages ...string) events.SNSEvent {
+	var event events.SNSEvent
+	for i, message := range messages {
+		event.Records = append(event.Records, events.SNSEventRecord{EventSource: "aws:sns", SNS: events.SNSEntity{MessageID: fmt.Sprintf("msg-%d", i+1), Message: message}})
+	}
+	return event
+}
+
+// alarmMessage returns the SNS message of an alarm in ALARM on the CPU of a cluster.
+func alarmMessage(t *testing.T, alarmName, clusterID string) string {
+	message, err := json.Marshal(autoscaling.AlarmNotification{
+		AlarmName:     alarmName,
+		NewStateValue: "ALARM",
+		Trigger: autoscaling.AlarmTrigger{
+			MetricName: "CPUUtilization",
+			Dimensions: []autoscaling.AlarmDimension{{Name: "DBClusterIdentifier", Value: clusterID}},
+		},
+	})
+	require.NoError(t, err)
+	return string(message)
+}
+
+// useClusters configures the clusters of the Clusters map, without CLUSTER_IDENTIFIER.
+func useClusters(t *testing.T, clusterIDs ...string) {
+	clusters := map[string]any{}
+	for _, clusterID := range clusterIDs {
+		clusters[clusterID] = map[string]any{}
+	}
+	value, err := json.Marshal(clusters)
+	require.NoError(t, err)
+	t.Setenv("CLUSTER_IDENTIFIER", "")
+	t.Setenv("CLUSTERS", string(value))
+}
+
+// TestHandleCloudWatchEvent_ScheduleDetail tests that the scheduled scaling parameters of the event detail
+// scale the cluster they name, without SCHEDULED_SCALING.
+func TestHandleCloudWatchEvent_ScheduleDetail(t *testing.T) {
+	clusterA, clusterB := newMockCluster("cluster-a", "a-reader-1"), newMockCluster("cluster-b", "b-reader-1")
+	useMockClusters(t, clusterA, clusterB)
+
+	result, err := invoke(t, events.CloudWatchEvent{
+		Source:     "aws.scheduler",
+		DetailType: "Scheduled Event",
+		Time:       time.Now(),
+		Detail:     json.RawMessage(`{"ClusterID": "cluster-b", "NumberReplicas": 2, "InstanceType": "db.r6g.xlarge"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, autoscaling.DecisionScaleOut, result.Decision)
+	assert.Equal(t, 2, result.ReplicasAdded)
+	assert.Len(t, clusterB.Readers(), 3)
+	for _, reader := range clusterB.Readers()[1:] {
+		assert.Equal(t, "db.r6g.xlarge", clusterB.classes[reader])
+	}
+	assert.Len(t, clusterA.Readers(), 1, "the cluster of CLUSTER_IDENTIFIER is left alone")
+
+	// A schedule of the detail that is not configured fails the invocation
+	_, err = invoke(t, events.CloudWatchEvent{Source: "aws.scheduler", Detail: json.RawMessage(`{"Schedule": "nightly"}`)})
+	assert.ErrorContains(t, err, "schedule nightly is not configured")
+}
+
+// TestHandleCustomResource tests that CloudFormation custom resource requests converge the baseline
+// capacity and are always answered.
+func TestHandleCustomResource(t *testing.T) {
+	cluster := newMockCluster("cluster-a", "reader-1")
+	useMockClusters(t, cluster)
+
+	var responses []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response map[string]any
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &response))
+		responses = append(responses, response)
+	}))
+	defer server.Close()
+
+	request := func(requestType string, properties map[string]any) map[string]any {
+		_, err := invoke(t, map[string]any{
+			"RequestType":        requestType,
+			"ResponseURL":        server.URL,
+			"StackId":            "arn:aws:cloudformation:us-east-1:123456789012:stack/orders/1",
+			"RequestId":          "request-1",
+			"LogicalResourceId":  "Baseline",
+			"PhysicalResourceId": "docdb-autoscaler-baseline-cluster-a",
+			"ResourceType":       "Custom::DocDBBaselineCapacity",
+			"ResourceProperties": properties,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, responses)
+		return responses[len(responses)-1]
+	}
+
+	response := request("Create", map[string]any{"ServiceToken": "arn:aws:lambda:us-east-1:123456789012:function:docdb-autoscaler", "ClusterIdentifier": "cluster-a", "BaselineReplicas": "3"})
+	assert.Equal(t, "SUCCESS", response["Status"], response["Reason"])
+	assert.Equal(t, "docdb-autoscaler-baseline-cluster-a", response["PhysicalResourceId"])
+	assert.Equal(t, map[string]any{"Decision": autoscaling.DecisionScaleOut, "ReplicasAdded": 2.0, "ReplicasRemoved": 0.0}, response["Data"])
+	assert.Len(t, cluster.Readers(), 3)
+
+	response = request("Update", map[string]any{"BaselineReplicas": "three"})
+	assert.Equal(t, "FAILED", response["Status"])
+	assert.Contains(t, response["Reason"], "invalid BaselineReplicas property")
+	assert.Len(t, cluster.Readers(), 3)
+
+	// Deleting the resource converges back to MIN_CAPACITY
+	response = request("Delete", map[string]any{"ClusterIdentifier": "cluster-a", "BaselineReplicas": "3"})
+	assert.Equal(t, "SUCCESS", response["Status"], response["Reason"])
+	assert.Equal(t, map[string]any{"Decision": autoscaling.DecisionScaleIn, "ReplicasAdded": 0.0, "ReplicasRemoved": 2.0}, response["Data"])
+	assert.Equal(t, []string{"reader-1"}, cluster.Readers())
+}
+
+// TestHandleSNSEvent_DesiredCapacity tests that scaling messages set the readers to exactly their desired
+// capacity, with the instance type of the message.
+func TestHandleSNSEvent_DesiredCapacity(t *testing.T) {
+	cluster := newMockCluster("cluster-a", "reader-1")
+	useMockClusters(t, cluster)
+
+	result, err := invoke(t, snsEvent(`{"DesiredCapacity": 3, "InstanceType": "db.r6g.2xlarge"}`))
+	require.NoError(t, err)
+	assert.Equal(t, autoscaling.DecisionScaleOut, result.Decision)
+	assert.Equal(t, 2, result.ReplicasAdded)
+	assert.Len(t, cluster.Readers(), 3)
+	for _, reader := range cluster.Readers()[1:] {
+		assert.Equal(t, "db.r6g.2xlarge", cluster.classes[reader])
+	}
+
+	// At the desired capacity, nothing changes
+	result, err = invoke(t, snsEvent(`{"DesiredCapacity": 3}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ReplicasAdded)
+	assert.Equal(t, 0, result.ReplicasRemoved)
+	assert.Len(t, cluster.Readers(), 3)
+}
+
+// TestHandler_StrictEvents tests that unsupported payloads are ignored, or fail the invocation with a
+// failure notification with STRICT_EVENTS.
+func TestHandler_StrictEvents(t *testing.T) {
+	notifier := useMockClusters(t)
+	payload := map[string]string{"unexpected": "payload"}
+
+	response, err := handler(context.Background(), json.RawMessage(`{"unexpected": "payload"}`))
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+	assert.Empty(t, notifier.Failures())
+
+	t.Setenv("STRICT_EVENTS", "true")
+	_, err = invoke(t, payload)
+	assert.ErrorContains(t, err, "unsupported event payload")
+	require.Len(t, notifier.Failures(), 1)
+	assert.True(t, strings.HasPrefix(notifier.Failures()[0], "process event on cluster-a: unsupported event payload: "), notifier.Failures()[0])
+	assert.Contains(t, notifier.Failures()[0], `"unexpected":"payload"`)
+}
+
+// TestHandleSNSEvent_PartialFailure tests that the failed records of a batch are reported and notified
+// while the others are processed, and that the invocation fails only when every record failed.
+func TestHandleSNSEvent_PartialFailure(t *testing.T) {
+	clusterA, clusterB := newMockCluster("cluster-a", "a-reader-1"), newMockCluster("cluster-b", "b-reader-1")
+	clusterB.failDescribes = -1
+	notifier := useMockClusters(t, clusterA, clusterB)
+	useClusters(t, "cluster-a", "cluster-b")
+
+	result, err := invoke(t, snsEvent(alarmMessage(t, "cpu-a", "cluster-a"), alarmMessage(t, "cpu-b", "cluster-b")))
+	require.NoError(t, err)
+	require.Len(t, result.FailedRecords, 1)
+	assert.Equal(t, "msg-2", result.FailedRecords[0].MessageID)
+	assert.Contains(t, result.FailedRecords[0].Error, "cluster not found")
+	require.Len(t, notifier.Failures(), 1)
+	assert.Contains(t, notifier.Failures()[0], "process SNS batch on ")
+	assert.Contains(t, notifier.Failures()[0], "1 of 2 SNS messages failed: msg-2: ")
+
+	// Without a record processed, the invocation fails so that it is retried
+	_, err = invoke(t, snsEvent(alarmMessage(t, "cpu-b", "cluster-b")))
+	assert.ErrorContains(t, err, "cluster not found")
+	assert.Len(t, notifier.Failures(), 1, "a failed invocation is not notified as a partial batch")
+}
+
+// TestHandleSQSEvent_PartialFailure tests that an SQS batch with failed messages fails the invocation,
+// so that the queue retries them, and notifies them as SQS messages.
+func TestHandleSQSEvent_PartialFailure(t *testing.T) {
+	clusterA, clusterB := newMockCluster("cluster-a", "a-reader-1"), newMockCluster("cluster-b", "b-reader-1")
+	clusterB.failDescribes = -1
+	notifier := useMockClusters(t, clusterA, clusterB)
+	useClusters(t, "cluster-a", "cluster-b")
+
+	result, err := invoke(t, events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "sqs-1", EventSource: "aws:sqs", Body: alarmMessage(t, "cpu-a", "cluster-a")},
+		{MessageId: "sqs-2", EventSource: "aws:sqs", Body: alarmMessage(t, "cpu-b", "cluster-b")},
+	}})
+	assert.ErrorContains(t, err, "1 of 2 SQS messages failed")
+	require.NotNil(t, result)
+	require.Len(t, result.FailedRecords, 1)
+	assert.Equal(t, "sqs-2", result.FailedRecords[0].MessageID)
+	require.Len(t, notifier.Failures(), 1)
+	assert.Contains(t, notifier.Failures()[0], "process SQS batch on ")
+	assert.Contains(t, notifier.Failures()[0], "1 of 2 SQS messages failed: sqs-2: ")
+}
+
+// TestHandleSNSEvent_RetryFailedEvaluation tests that an evaluation that failed is not skipped as already
+// evaluated by the next record of the same alarm in the invocation.
+func TestHandleSNSEvent_RetryFailedEvaluation(t *testing.T) {
+	cluster := newMockCluster("cluster-a", "reader-1")
+	cluster.failDescribes = 1
+	useMockClusters(t, cluster)
+
+	result, err := invoke(t, snsEvent(alarmMessage(t, "cpu-a", "cluster-a"), alarmMessage(t, "cpu-a", "cluster-a"), alarmMessage(t, "cpu-a", "cluster-a")))
+	require.NoError(t, err)
+	require.Len(t, result.FailedRecords, 1)
+	assert.Equal(t, "msg-1", result.FailedRecords[0].MessageID)
+	assert.Equal(t, []string{"msg-3"}, result.CoalescedMessageIDs, "only the evaluation that succeeded is coalesced")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// handleRDSEvent reacts to an RDS event notification on the clusters it is about. Subscriptions may cover
+// more instances than those of this deployment, so events of other clusters are ignored.
+func handleRDSEvent(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, event autoscaling.RDSEvent) (*autoscaling.ScalingResult, error) {
+	result := autoscaling.NewScalingResult(settings.DryRun)
+	if event.Kind() == "" {
+		loggerInstance.Info("Ignoring RDS event", "EventCode", event.Code(), "SourceID", event.SourceID, "EventMessage", event.EventMessage)
+		return result, nil
+	}
+
+	// Cluster events name their cluster, instance events are matched against the instances of every cluster
+	var namedClusterID string
+	if event.EventSource == "db-cluster" && settings.HasCluster(event.SourceID) {
+		namedClusterID = event.SourceID
+	}
+	clusterIDs, err := expandClusterTargets(ctx, loggerInstance, settings, settings.ClusterTargets(namedClusterID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		docdbAutoscaler, retry, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: clusterID})
+		if err != nil {
+			return err
+		}
+		relates, err := docdbAutoscaler.RelatesTo(ctx, event)
+		if err != nil || !relates {
+			return err
+		}
+
+		err = executeWithRetry(ctx, loggerInstance, func(ctx context.Context) error {
+			return docdbAutoscaler.HandleRDSEvent(ctx, event)
+		}, retry.maxRetries, retry.initialBackoff)
+		docdbAutoscaler.ReportOutcome(ctx, err)
+		if err != nil {
+			loggerInstance.Error("Handling of RDS event failed after retries", "Error", err, "EventCode", event.Code())
+			return err
+		}
+		result.Merge(docdbAutoscaler.LastResult())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
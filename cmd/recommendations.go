@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// RecommendationRequest asks for rightsizing recommendations for the clusters of the invocation, typically
+// sent as the constant input of a separate EventBridge schedule, e.g. {"Recommendations": {"Days": 14}}.
+type RecommendationRequest struct {
+	Recommendations *RecommendationQuery `json:"Recommendations"`
+}
+
+// RecommendationQuery selects the clusters and the period of a recommendation request.
+type RecommendationQuery struct {
+	ClusterID string `json:"ClusterID"` // Defaults to the clusters of the deployment
+	Days      int    `json:"Days"`      // Defaults to autoscaling.DefaultRecommendationDays
+}
+
+// handleRecommendationRequest analyzes the utilization of the readers of each cluster and sends the
+// recommendations through the configured notifiers, without acting on them. The recommendations are
+// also returned, as the report of the invocation.
+func handleRecommendationRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, query RecommendationQuery) ([]*autoscaling.Recommendations, error) {
+	clusterIDs, err := expandClusterTargets(ctx, loggerInstance, settings, settings.ClusterTargets(query.ClusterID))
+	if err != nil {
+		return nil, err
+	}
+	var reports []*autoscaling.Recommendations
+	err = forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		docdbAutoscaler, _, err := newAutoscaler(ctx, loggerInstance, settings, config.Overrides{ClusterID: clusterID})
+		if err != nil {
+			return err
+		}
+		recommendations, err := docdbAutoscaler.Recommend(ctx, query.Days)
+		if err != nil {
+			loggerInstance.Error("Failed to analyze the utilization of the readers", "Error", err, "ClusterID", docdbAutoscaler.ClusterID)
+			return err
+		}
+		reports = append(reports, recommendations)
+		if err := notifications.SendRecommendations(ctx, docdbAutoscaler.Notifier, docdbAutoscaler.ClusterID, recommendations.Message()); err != nil {
+			return err
+		}
+		loggerInstance.Info("Sent rightsizing recommendations", "ClusterID", docdbAutoscaler.ClusterID, "Days", recommendations.Days, "Recommendations", len(recommendations.Recommendations))
+		return nil
+	})
+	return reports, err
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// maxBackoff caps the wait between two attempts of a scaling action.
+const maxBackoff = 32 * time.Second
+
+// transientErrorCodes are the API error codes, besides the throttling and timeout codes retried by the SDK,
+// of conditions that clear by themselves, e.g. a cluster still modifying after the previous action.
+var transientErrorCodes = map[string]struct{}{
+	"InternalFailure":            {},
+	"ServiceUnavailable":         {},
+	"InvalidDBClusterStateFault": {},
+	"InvalidDBInstanceState":     {},
+}
+
+// executeWithRetry attempts to execute the provided action, retrying throttling and transient errors
+// with capped exponential backoff and full jitter. Other errors, e.g. validation errors, are returned
+// right away. Waits end with ctx, and no attempt is started that the deadline of ctx would cut short.
+func executeWithRetry(ctx context.Context, loggerInstance *slog.Logger, action func(context.Context) error, maxRetries int, initialBackoff time.Duration) error {
+	backoff := initialBackoff
+
+	var err error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		err = action(ctx)
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if attempt == maxRetries {
+			break
+		}
+
+		// Full jitter spreads the retries of concurrent invocations, e.g. of several clusters throttled together
+		wait := time.Duration(0)
+		if backoff > 0 {
+			wait = time.Duration(rand.Int63n(int64(backoff)))
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			loggerInstance.Warn("Scaling action failed, no time left to retry", "Attempt", attempt, "Error", err, "Deadline", deadline)
+			return fmt.Errorf("scaling action failed after %d attempts: %w", attempt, err)
+		}
+		loggerInstance.Warn("Scaling action failed, retrying...", "Attempt", attempt, "Error", err, "Wait", wait)
+
+		// Wait before the next retry
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("scaling action failed after %d attempts: %w", attempt, errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	return fmt.Errorf("scaling action failed after %d attempts: %w", maxRetries, err)
+}
+
+// isRetryable reports whether a failed action may succeed when attempted again: on AWS throttling and
+// transient errors, identified by their error codes, and on server-side failures.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		if _, ok := retry.DefaultThrottleErrorCodes[code]; ok {
+			return true
+		}
+		if _, ok := retry.DefaultRetryableErrorCodes[code]; ok {
+			return true
+		}
+		if _, ok := transientErrorCodes[code]; ok {
+			return true
+		}
+	}
+	var responseErr *smithyhttp.ResponseError
+	return errors.As(err, &responseErr) && responseErr.HTTPStatusCode() >= 500
+}
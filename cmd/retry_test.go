@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// responseError returns the error of an AWS API response of a status code.
+func responseError(statusCode int) error {
+	return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: statusCode}},
+		Err:      errors.New(http.StatusText(statusCode)),
+	}}
+}
+
+// TestIsRetryable tests which errors of the AWS APIs are retried.
+func TestIsRetryable(t *testing.T) {
+	for name, test := range map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"throttling":                 {err: &smithy.GenericAPIError{Code: "Throttling"}, retryable: true},
+		"throttling exception":       {err: &smithy.GenericAPIError{Code: "ThrottlingException"}, retryable: true},
+		"request limit exceeded":     {err: &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, retryable: true},
+		"internal failure":           {err: &smithy.GenericAPIError{Code: "InternalFailure"}, retryable: true},
+		"cluster state":              {err: &docdbTypes.InvalidDBClusterStateFault{}, retryable: true},
+		"instance state":             {err: &docdbTypes.InvalidDBInstanceStateFault{}, retryable: true},
+		"wrapped transient error":    {err: fmt.Errorf("failed to add replica: %w", &smithy.GenericAPIError{Code: "ServiceUnavailable"}), retryable: true},
+		"validation error":           {err: &smithy.GenericAPIError{Code: "ValidationError"}},
+		"invalid parameter":          {err: &smithy.GenericAPIError{Code: "InvalidParameterValue"}},
+		"quota exceeded":             {err: &smithy.GenericAPIError{Code: "InstanceQuotaExceeded"}},
+		"server error":               {err: responseError(http.StatusServiceUnavailable), retryable: true},
+		"internal server error":      {err: responseError(http.StatusInternalServerError), retryable: true},
+		"client error":               {err: responseError(http.StatusBadRequest)},
+		"forbidden":                  {err: responseError(http.StatusForbidden)},
+		"canceled":                   {err: context.Canceled},
+		"deadline exceeded":          {err: fmt.Errorf("failed to describe cluster: %w", context.DeadlineExceeded)},
+		"error without an API error": {err: errors.New("no readers to remove")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.retryable, isRetryable(test.err))
+		})
+	}
+}
+
+// TestExecuteWithRetry tests that actions are attempted up to the maximum number of retries, and no longer
+// than ctx allows.
+func TestExecuteWithRetry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	throttled := &smithy.GenericAPIError{Code: "Throttling"}
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		attempts := 0
+		err := executeWithRetry(context.Background(), logger, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return throttled
+			}
+			return nil
+		}, 3, time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("honors the maximum number of retries", func(t *testing.T) {
+		attempts := 0
+		err := executeWithRetry(context.Background(), logger, func(ctx context.Context) error {
+			attempts++
+			return throttled
+		}, 4, time.Millisecond)
+		assert.ErrorIs(t, err, throttled)
+		assert.ErrorContains(t, err, "after 4 attempts")
+		assert.Equal(t, 4, attempts)
+	})
+
+	t.Run("returns errors that are not retryable", func(t *testing.T) {
+		attempts := 0
+		invalid := &smithy.GenericAPIError{Code: "InvalidParameterValue"}
+		err := executeWithRetry(context.Background(), logger, func(ctx context.Context) error {
+			attempts++
+			return invalid
+		}, 4, time.Millisecond)
+		assert.Equal(t, invalid, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("stops when ctx is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := executeWithRetry(ctx, logger, func(ctx context.Context) error {
+			attempts++
+			cancel()
+			return throttled
+		}, 4, time.Hour)
+		assert.ErrorIs(t, err, throttled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("does not wait beyond the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		attempts := 0
+		start := time.Now()
+		err := executeWithRetry(ctx, logger, func(ctx context.Context) error {
+			attempts++
+			return throttled
+		}, 4, 24*time.Hour)
+		assert.ErrorIs(t, err, throttled)
+		assert.ErrorContains(t, err, "after 1 attempts")
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second, "the retry is given up without waiting for the deadline")
+	})
+}
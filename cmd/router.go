@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	router "github.com/cheelim1/docdb-autoscaler/pkg/events"
+)
+
+// eventRouter routes the payloads of every invocation.
+var eventRouter = newRouter()
+
+// newRouter returns the router of the payloads the function is invoked with, in detection order.
+func newRouter() *router.Router {
+	r := router.NewRouter()
+
+	// CloudFormation waits for a response to its custom resource requests, even when the configuration is invalid
+	router.Register(r, "CloudFormation custom resource request", isCustomResourceRequest, func(ctx context.Context, loggerInstance *slog.Logger, cfnEvent cfn.Event) (any, error) {
+		return nil, handleCustomResource(ctx, loggerInstance, cfnEvent)
+	})
+	// Health checks report an invalid configuration instead of failing
+	router.Register(r, "HealthCheckRequest", func(request HealthCheckRequest) bool { return request.Action == healthCheckAction }, func(ctx context.Context, loggerInstance *slog.Logger, request HealthCheckRequest) (any, error) {
+		return handleHealthCheck(ctx, loggerInstance, request), nil
+	})
+
+	registerWithConfig(r, "SNSEvent", router.IsSNS, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, snsEvent events.SNSEvent) (any, error) {
+		result, err := handleSNSEvent(ctx, loggerInstance, settings, snsEvent)
+		return handlerResult(settings.StructuredOutput, result), err
+	})
+	// Messages of SQS queues, e.g. subscribed to the trigger topic, are handled as SNS messages
+	registerWithConfig(r, "SQSEvent", router.IsSQS, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, sqsEvent events.SQSEvent) (any, error) {
+		var snsEvent events.SNSEvent
+		for _, message := range sqsEvent.Records {
+			snsEvent.Records = append(snsEvent.Records, events.SNSEventRecord{EventSource: router.SourceSQS, SNS: router.SNSMessage(message)})
+		}
+		result, err := handleSNSEvent(ctx, loggerInstance, settings, snsEvent)
+		// The batch is redelivered for its failed records; with IDEMPOTENCY_TABLE, the processed ones are skipped
+		if err == nil && len(result.FailedRecords) > 0 {
+			err = fmt.Errorf("%d of %d SQS messages failed", len(result.FailedRecords), len(sqsEvent.Records))
+		}
+		return handlerResult(settings.StructuredOutput, result), err
+	})
+	registerWithConfig(r, "CloudWatchEvent", router.IsEventBridge, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, cwEvent events.CloudWatchEvent) (any, error) {
+		result, err := handleCloudWatchEvent(ctx, loggerInstance, settings, cwEvent)
+		return handlerResult(settings.StructuredOutput, result), err
+	})
+	// Lambda Function URL / API Gateway HTTP requests
+	registerWithConfig(r, "HTTP request", router.IsHTTP, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, httpRequest events.APIGatewayV2HTTPRequest) (any, error) {
+		return handleHTTPRequest(ctx, loggerInstance, settings, httpRequest)
+	})
+
+	// Digest requests of the digest schedule
+	registerWithConfig(r, "DigestRequest", func(request DigestRequest) bool { return request.Digest != "" }, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request DigestRequest) (any, error) {
+		return nil, handleDigestRequest(ctx, loggerInstance, settings, request)
+	})
+	// Savings report requests of the savings report schedule
+	registerWithConfig(r, "SavingsRequest", func(request SavingsRequest) bool { return request.SavingsReport != "" }, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request SavingsRequest) (any, error) {
+		return nil, handleSavingsRequest(ctx, loggerInstance, settings, request)
+	})
+	// History queries of operators
+	registerWithConfig(r, "HistoryRequest", func(request HistoryRequest) bool { return request.History != nil }, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request HistoryRequest) (any, error) {
+		return handleHistoryRequest(ctx, loggerInstance, settings, *request.History)
+	})
+	// Recommendation requests of the recommendation schedule
+	registerWithConfig(r, "RecommendationRequest", func(request RecommendationRequest) bool { return request.Recommendations != nil }, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request RecommendationRequest) (any, error) {
+		return handleRecommendationRequest(ctx, loggerInstance, settings, *request.Recommendations)
+	})
+	// Direct invocations of operators
+	registerWithConfig(r, "DirectInvocation", func(invocation DirectInvocation) bool {
+		return invocation.DesiredReplicas != nil || invocation.PrescaleReplicas != nil
+	}, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, invocation DirectInvocation) (any, error) {
+		return handleDirectInvocation(ctx, loggerInstance, settings, invocation)
+	})
+	// Verification requests of a Step Functions wait loop
+	registerWithConfig(r, "VerifyRequest", func(request VerifyRequest) bool { return len(request.PendingInstanceIDs) > 0 }, func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, request VerifyRequest) (any, error) {
+		return handleVerifyRequest(ctx, loggerInstance, settings, request)
+	})
+
+	r.Fallback(func(ctx context.Context, loggerInstance *slog.Logger, payload json.RawMessage) (any, error) {
+		settings, err := loadConfig(ctx, loggerInstance)
+		if err != nil {
+			return nil, err
+		}
+		if settings.StrictEvents {
+			return nil, rejectUnsupportedEvent(ctx, loggerInstance, settings, payload)
+		}
+		loggerInstance.Warn("Received unsupported event type", "EventData", string(payload))
+		return nil, nil
+	})
+	return r
+}
+
+// registerWithConfig registers the handler of the events of type T that match accepts, handing it the
+// configuration loaded from the optional config file and the environment.
+func registerWithConfig[T any](r *router.Router, name string, match func(event T) bool, handler func(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, event T) (any, error)) {
+	router.Register(r, name, match, func(ctx context.Context, loggerInstance *slog.Logger, event T) (any, error) {
+		settings, err := loadConfig(ctx, loggerInstance)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, loggerInstance, settings, event)
+	})
+}
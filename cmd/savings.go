@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// SavingsRequest asks for a savings report of every cluster in the audit log, typically sent as the
+// constant input of a separate EventBridge schedule, e.g. {"SavingsReport": "weekly"}.
+type SavingsRequest struct {
+	SavingsReport string `json:"SavingsReport"` // "weekly" or "daily"
+}
+
+// handleSavingsRequest computes the savings report of the period per cluster from the audit log, against
+// the capacity bounds of the cluster, and sends each report through the configured notifiers, archiving it
+// in REPORT_S3_URI when set. Clusters without records in the period are skipped.
+func handleSavingsRequest(ctx context.Context, loggerInstance *slog.Logger, settings *config.Config, savingsRequest SavingsRequest) error {
+	period, found := digestPeriods[savingsRequest.SavingsReport]
+	if !found {
+		return fmt.Errorf("unknown savings report %s, must be daily or weekly", savingsRequest.SavingsReport)
+	}
+	if settings.AuditS3URI == "" {
+		loggerInstance.Error("Environment variable AUDIT_S3_URI is not set")
+		return errors.New("AUDIT_S3_URI is not set")
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to load AWS configuration", "Error", err)
+		return err
+	}
+	store, err := autoscaling.NewAuditStore(cfg, settings)
+	if err != nil {
+		loggerInstance.Error("Invalid configuration", "Error", err)
+		return err
+	}
+	var reports audit.ReportStore
+	if settings.ReportS3URI != "" {
+		// Like the audit log, reports are kept in the account of the autoscaler
+		reports, err = audit.NewS3Store(autoscaling.NewS3Client(cfg), settings.ReportS3URI)
+		if err != nil {
+			loggerInstance.Error("Invalid configuration", "Error", err)
+			return err
+		}
+	}
+	notifier, err := autoscaling.NewNotifier(sns.NewFromConfig(cfg), settings)
+	if err != nil {
+		loggerInstance.Error("Invalid notification settings", "Error", err)
+		return err
+	}
+
+	clusterIDs, err := store.Clusters(ctx)
+	if err != nil {
+		loggerInstance.Error("Failed to list clusters of the audit log", "Error", err)
+		return err
+	}
+	until := time.Now().UTC()
+	since := until.Add(-period)
+	return forEachCluster(loggerInstance, clusterIDs, func(clusterID string) error {
+		records, err := store.List(ctx, clusterID, since, until)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		clusterSettings := settings.ForCluster(clusterID)
+		report := audit.Savings(clusterID, since, until, clusterSettings.MinCapacity, clusterSettings.MaxCapacity, records)
+		if reports != nil {
+			if err := reports.SaveSavingsReport(ctx, report); err != nil {
+				return err
+			}
+		}
+		if err := notifications.SendDigest(ctx, notifier, clusterID, report.Message()); err != nil {
+			return err
+		}
+		loggerInstance.Info("Sent savings report", "ClusterID", clusterID, "SavingsReport", savingsRequest.SavingsReport, "SavedReplicaHours", report.SavedReplicaHours)
+		return nil
+	})
+}
@@ -1,5 +1,13 @@
 data "aws_caller_identity" "current" {}
 
+locals {
+  # Regions of the managed clusters, for the IAM policy
+  cluster_regions = distinct(concat(
+    [var.region == "" ? var.aws_region : var.region],
+    [for cluster in values(var.clusters) : lookup(cluster, "region", var.aws_region)]
+  ))
+}
+
 resource "aws_iam_role" "lambda_docdb_autoscaler_role" {
   name = "${var.docdb_cluster_name}-docdb-autoscaler"
   assume_role_policy = jsonencode({
@@ -30,7 +38,11 @@ resource "aws_iam_role_policy" "lambda_policy" {
           "rds:DescribeDBInstances",
           "rds:ListTagsForResource",
           "rds:DescribeDBClusters",
-          "rds:AddTagsToResource"
+          "rds:DescribeGlobalClusters",
+          "rds:DescribeAccountAttributes",
+          "rds:DescribeOrderableDBInstanceOptions",
+          "rds:AddTagsToResource",
+          "rds:RemoveTagsFromResource"
         ]
         Resource = "*"
       },
@@ -40,15 +52,30 @@ resource "aws_iam_role_policy" "lambda_policy" {
           "rds:CreateDBInstance",
           "rds:DeleteDBInstance",
         ]
-        Resource = [ ## Restrict to only delete/create the instances managed by the autoscaler
-          "arn:aws:rds:${var.aws_region}:${data.aws_caller_identity.current.account_id}:db:${var.docdb_cluster_name}-*",
-          "arn:aws:rds:${var.aws_region}:${data.aws_caller_identity.current.account_id}:cluster:${var.docdb_cluster_name}"
+        Resource = flatten([ ## Restrict to only delete/create the instances managed by the autoscaler
+          for pair in setproduct(concat([var.docdb_cluster_name], keys(var.clusters)), local.cluster_regions) : [
+            "arn:aws:rds:${pair[1]}:${data.aws_caller_identity.current.account_id}:db:${pair[0]}-*",
+            "arn:aws:rds:${pair[1]}:${data.aws_caller_identity.current.account_id}:cluster:${pair[0]}"
+          ]
+        ])
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "docdb-elastic:ListClusters",
+          "docdb-elastic:GetCluster",
+          "docdb-elastic:UpdateCluster",
+          "docdb-elastic:ListTagsForResource",
+          "docdb-elastic:TagResource",
+          "docdb-elastic:UntagResource"
         ]
+        Resource = "*"
       },
       {
         Effect = "Allow"
         Action = [
-          "cloudwatch:GetMetricStatistics"
+          "cloudwatch:GetMetricStatistics",
+          "cloudwatch:DescribeAlarms"
         ]
         Resource = "*"
       },
@@ -57,9 +84,15 @@ resource "aws_iam_role_policy" "lambda_policy" {
         Action = [
           "sns:Publish"
         ]
-        Resource = [
-          aws_sns_topic.docdb_autoscaler_notification_topic.arn
-        ]
+        Resource = concat(
+          [aws_sns_topic.docdb_autoscaler_notification_topic.arn],
+          [for arn in [var.scale_out_topic_arn, var.scale_in_topic_arn, var.failure_topic_arn] : arn if arn != ""]
+        )
+      },
+      {
+        Effect   = "Allow"
+        Action   = ["iam:SimulatePrincipalPolicy"] ## For the permission checks of health check invocations
+        Resource = aws_iam_role.lambda_docdb_autoscaler_role.arn
       },
       {
         Effect = "Allow"
@@ -74,10 +107,310 @@ resource "aws_iam_role_policy" "lambda_policy" {
   })
 }
 
+# Allow reading the config file, when loaded from S3
+resource "aws_iam_role_policy" "lambda_config_policy" {
+  count = var.config_s3_uri == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-config"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:GetObject"]
+        Resource = "arn:aws:s3:::${trimprefix(var.config_s3_uri, "s3://")}"
+      }
+    ]
+  })
+}
+
+# Allow reading the holiday calendar, when loaded from S3
+resource "aws_iam_role_policy" "lambda_holiday_calendar_policy" {
+  count = var.holiday_calendar_s3_uri == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-holidays"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:GetObject"]
+        Resource = "arn:aws:s3:::${trimprefix(var.holiday_calendar_s3_uri, "s3://")}"
+      }
+    ]
+  })
+}
+
+# Allow assuming the roles of clusters in other accounts
+resource "aws_iam_role_policy" "lambda_assume_role_policy" {
+  count = length(var.assume_role_arns) == 0 ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-assume-role"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["sts:AssumeRole"]
+        Resource = var.assume_role_arns
+      }
+    ]
+  })
+}
+
+# Allow publishing lifecycle events, when an event bus is set
+resource "aws_iam_role_policy" "lambda_events_policy" {
+  count = var.event_bus_name == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-events"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["events:PutEvents"]
+        Resource = startswith(var.event_bus_name, "arn:") ? var.event_bus_name : "arn:aws:events:${var.aws_region}:${data.aws_caller_identity.current.account_id}:event-bus/${var.event_bus_name}"
+      }
+    ]
+  })
+}
+
+# Allow writing and reading the audit log, when one is set
+resource "aws_iam_role_policy" "lambda_audit_policy" {
+  count = var.audit_s3_uri == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-audit"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:GetObject", "s3:PutObject"]
+        Resource = "arn:aws:s3:::${trimsuffix(trimprefix(var.audit_s3_uri, "s3://"), "/")}/*"
+      },
+      {
+        Effect   = "Allow"
+        Action   = ["s3:ListBucket"]
+        Resource = "arn:aws:s3:::${split("/", trimprefix(var.audit_s3_uri, "s3://"))[0]}"
+      }
+    ]
+  })
+}
+
+# Allow archiving dry-run plans, when a location is set
+resource "aws_iam_role_policy" "lambda_plan_policy" {
+  count = var.plan_s3_uri == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-plans"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:PutObject"]
+        Resource = "arn:aws:s3:::${trimsuffix(trimprefix(var.plan_s3_uri, "s3://"), "/")}/*"
+      }
+    ]
+  })
+}
+
+# Allow archiving savings reports, when a location is set
+resource "aws_iam_role_policy" "lambda_report_policy" {
+  count = var.report_s3_uri == "" ? 0 : 1
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-reports"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:PutObject"]
+        Resource = "arn:aws:s3:::${trimsuffix(trimprefix(var.report_s3_uri, "s3://"), "/")}/*"
+      }
+    ]
+  })
+}
+
+# Allow reading the price list, when cost estimates or the budget are enabled
+resource "aws_iam_role_policy" "lambda_pricing_policy" {
+  count = var.cost_estimates || var.max_hourly_cost > 0 ? 1 : 0
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-pricing"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["pricing:GetProducts"]
+        Resource = "*"
+      }
+    ]
+  })
+}
+
+# Allow publishing capacity metrics, when enabled
+resource "aws_iam_role_policy" "lambda_metrics_policy" {
+  count = var.capacity_metrics || var.heartbeat_metric ? 1 : 0
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-metrics"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["cloudwatch:PutMetricData"]
+        Resource = "*"
+        Condition = {
+          StringEquals = {
+            "cloudwatch:namespace" = var.metrics_namespace == "" ? "DocDBAutoscaler" : var.metrics_namespace
+          }
+        }
+      }
+    ]
+  })
+}
+
+# Per-cluster locks, so that only one invocation scales a cluster at a time
+resource "aws_dynamodb_table" "lock_table" {
+  count        = var.scaling_lock ? 1 : 0
+  name         = "${var.docdb_cluster_name}-docdb-autoscaler-locks"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "LockKey"
+
+  attribute {
+    name = "LockKey"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+  tags = var.tags
+}
+
+resource "aws_iam_role_policy" "lambda_lock_policy" {
+  count = var.scaling_lock ? 1 : 0
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-lock"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["dynamodb:PutItem", "dynamodb:DeleteItem"]
+        Resource = aws_dynamodb_table.lock_table[0].arn
+      }
+    ]
+  })
+}
+
+# Processed SNS messages, so that redelivered messages are skipped
+resource "aws_dynamodb_table" "idempotency_table" {
+  count        = var.sns_idempotency ? 1 : 0
+  name         = "${var.docdb_cluster_name}-docdb-autoscaler-messages"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "MessageId"
+
+  attribute {
+    name = "MessageId"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+  tags = var.tags
+}
+
+resource "aws_iam_role_policy" "lambda_idempotency_policy" {
+  count = var.sns_idempotency ? 1 : 0
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-idempotency"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["dynamodb:PutItem", "dynamodb:DeleteItem"]
+        Resource = aws_dynamodb_table.idempotency_table[0].arn
+      }
+    ]
+  })
+}
+
+# Emergency pause switch of all the clusters of the autoscaler, turned on by setting it to "true"
+resource "aws_ssm_parameter" "pause_switch" {
+  count = var.pause_switch ? 1 : 0
+  name  = "/${var.docdb_cluster_name}/docdb-autoscaler/paused"
+  type  = "String"
+  value = "false"
+
+  # The switch is flipped by hand, e.g. by on-call during an incident
+  lifecycle {
+    ignore_changes = [value]
+  }
+
+  tags = var.tags
+}
+
+resource "aws_iam_role_policy" "lambda_pause_switch_policy" {
+  count = var.pause_switch ? 1 : 0
+  name  = "${var.docdb_cluster_name}-docdb-autoscaler-pause-switch"
+  role  = aws_iam_role.lambda_docdb_autoscaler_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["ssm:GetParameter"]
+        Resource = aws_ssm_parameter.pause_switch[0].arn
+      }
+    ]
+  })
+}
+
+# Alarm when a cluster has not been evaluated for heartbeat_alarm_minutes
+resource "aws_cloudwatch_metric_alarm" "heartbeat_alarm" {
+  for_each = var.heartbeat_metric && var.heartbeat_alarm_minutes > 0 ? toset(concat([var.docdb_cluster_name], keys(var.clusters))) : toset([])
+
+  alarm_name          = "${each.key}-docdb-autoscaler-heartbeat"
+  comparison_operator = "LessThanThreshold"
+  evaluation_periods  = var.heartbeat_alarm_minutes
+  metric_name         = "Heartbeat"
+  namespace           = var.metrics_namespace == "" ? "DocDBAutoscaler" : var.metrics_namespace
+  period              = 60 #seconds
+  statistic           = "SampleCount"
+  threshold           = 1
+  alarm_description   = "The DocumentDB autoscaler has not evaluated ${each.key} for ${var.heartbeat_alarm_minutes} minutes"
+  alarm_actions       = [aws_sns_topic.docdb_autoscaler_notification_topic.arn]
+  treat_missing_data  = "breaching"
+
+  dimensions = {
+    ClusterId = each.key
+  }
+}
+
 # Notification SNS Topic (for Lambda to send notifications)
 resource "aws_sns_topic" "docdb_autoscaler_notification_topic" {
-  name = "${var.docdb_cluster_name}-docdb-autoscaler-notify"
-  tags = var.tags
+  name       = var.notification_topic_fifo ? "${var.docdb_cluster_name}-docdb-autoscaler-notify.fifo" : "${var.docdb_cluster_name}-docdb-autoscaler-notify"
+  fifo_topic = var.notification_topic_fifo
+  tags       = var.tags
 }
 
 # Trigger SNS Topic (for CloudWatch Alarms to trigger Lambda)
@@ -93,7 +426,7 @@ resource "aws_sns_topic_policy" "docdb_autoscaler_trigger_topic_policy" {
 
   policy = jsonencode({
     Version = "2012-10-17",
-    Statement : [
+    Statement : concat([
       {
         Sid : "AllowCloudWatchPublish",
         Effect : "Allow",
@@ -101,7 +434,15 @@ resource "aws_sns_topic_policy" "docdb_autoscaler_trigger_topic_policy" {
         Action : "SNS:Publish",
         Resource : "${aws_sns_topic.docdb_autoscaler_trigger_topic[0].arn}"
       }
-    ]
+      ], var.rds_event_subscription ? [
+      {
+        Sid : "AllowRDSEventsPublish",
+        Effect : "Allow",
+        Principal : { Service : "events.rds.amazonaws.com" },
+        Action : "SNS:Publish",
+        Resource : "${aws_sns_topic.docdb_autoscaler_trigger_topic[0].arn}"
+      }
+    ] : [])
   })
 }
 
@@ -115,20 +456,95 @@ resource "aws_lambda_function" "docdb_autoscaler_lambda" {
   environment {
     variables = {
       CLUSTER_IDENTIFIER       = var.docdb_cluster_name
+      ALLOWED_CLUSTERS         = join(",", var.allowed_clusters)
+      ENGINE                   = var.engine
       MIN_CAPACITY             = tostring(var.min_capacity)
       MAX_CAPACITY             = tostring(var.max_capacity)
-      METRIC_NAME              = var.metric_name
-      TARGET_VALUE             = tostring(var.target_value)
-      SCALE_IN_COOLDOWN        = tostring(var.docdb_scale_in_cooldown_period)
-      SCALE_OUT_COOLDOWN       = tostring(var.docdb_scale_out_cooldown_period)
+      MANAGED_CAPACITY         = tostring(var.managed_capacity)
+      BOOTSTRAP_CAPACITY       = tostring(var.bootstrap_capacity)
+      WRITER_ONLY_BOOTSTRAP    = tostring(var.writer_only_bootstrap)
+      MANAGED_REPLICA_CAP      = tostring(var.managed_replica_cap)
+      ACCOUNT_REPLICA_CAP      = tostring(var.account_replica_cap)
+      METRIC_NAME              = var.scheduled_scaling ? "" : var.metric_name
+      TARGET_VALUE             = var.scheduled_scaling ? "" : tostring(var.target_value)
+      METRIC_TARGETS           = var.scheduled_scaling ? "" : join(",", [for metric, target in var.metric_targets : "${metric}=${target}"])
+      METRIC_CONCURRENCY       = tostring(var.metric_concurrency)
+      COUNTABLE_STATUSES       = join(",", var.countable_statuses)
+      SCALE_IN_COOLDOWN        = var.scheduled_scaling ? "" : tostring(var.docdb_scale_in_cooldown_period)
+      SCALE_OUT_COOLDOWN       = var.scheduled_scaling ? "" : tostring(var.docdb_scale_out_cooldown_period)
+      COOLDOWN_TAGS            = tostring(var.cooldown_tags)
+      SCALE_IN_STABILIZATION   = tostring(var.scale_in_stabilization)
+      TRACK_DESIRED_CAPACITY   = tostring(var.track_desired_capacity)
+      ORPHAN_CLEANUP           = tostring(var.orphan_cleanup)
+      REPLACE_FAILED_REPLICAS  = tostring(var.replace_failed_replicas)
+      INSTANCE_QUOTA_CHECK     = tostring(var.instance_quota_check)
+      BULK_SCALE_IN_LIMIT      = tostring(var.bulk_scale_in_limit)
+      ALLOW_BULK_SCALE_IN      = tostring(var.allow_bulk_scale_in)
+      MAX_HOURLY_COST          = tostring(var.max_hourly_cost)
+      CANARY_SCALE_OUT         = tostring(var.canary_scale_out)
+      CANARY_WINDOW            = tostring(var.canary_window)
+      DRAIN_CONNECTIONS        = tostring(var.drain_connections)
+      DRAIN_TIMEOUT            = tostring(var.drain_timeout)
+      MAINTENANCE_PAUSE        = tostring(var.maintenance_pause)
       INSTANCE_TYPE            = var.instance_type
       DRYRUN                   = tostring(var.dryrun)
+      SHADOW_MODE              = tostring(var.shadow_mode)
+      ALLOW_ZERO_READERS       = tostring(var.allow_zero_readers)
+      STRUCTURED_OUTPUT        = tostring(var.structured_output)
+      STRICT_EVENTS            = tostring(var.strict_events)
+      HTTP_SHARED_SECRET       = var.http_shared_secret
+      PAGERDUTY_ROUTING_KEY    = var.pagerduty_routing_key
+      DATADOG_API_KEY          = var.datadog_api_key
+      DATADOG_SITE             = var.datadog_site
+      EVENT_BUS_NAME           = var.event_bus_name
+      TICKET_SYSTEM            = var.ticket_system
+      TICKET_URL               = var.ticket_url
+      TICKET_USER              = var.ticket_user
+      TICKET_TOKEN             = var.ticket_token
+      TICKET_PROJECT           = var.ticket_project
+      TICKET_THRESHOLD         = tostring(var.ticket_threshold)
+      CIRCUIT_THRESHOLD        = tostring(var.circuit_threshold)
+      CIRCUIT_BACKOFF          = tostring(var.circuit_backoff)
+      CHURN_BUDGET_HOURLY      = tostring(var.churn_budget_hourly)
+      CHURN_BUDGET_DAILY       = tostring(var.churn_budget_daily)
+      SLACK_WEBHOOK_URL        = var.slack_webhook_url
+      NOTIFICATION_WEBHOOK_URL = var.notification_webhook_url
+      NOTIFY_MIN_SEVERITY      = var.notify_min_severity
+      NOTIFY_DEDUP_WINDOW      = tostring(var.notify_dedup_window)
+      NOTIFY_QUIET_HOURS       = var.notify_quiet_hours
+      NOTIFY_QUIET_TIMEZONE    = var.notify_quiet_timezone
+      HOLIDAYS                 = join(",", var.holidays)
+      HOLIDAY_CALENDAR_S3_URI  = var.holiday_calendar_s3_uri
+      HOLIDAY_TIMEZONE         = var.holiday_timezone
+      NOTIFICATION_TEMPLATES   = length(var.notification_templates) == 0 ? "" : jsonencode(var.notification_templates)
+      CONFIG_S3_URI            = var.config_s3_uri
+      AUDIT_S3_URI             = var.audit_s3_uri
+      PLAN_S3_URI              = var.plan_s3_uri
+      REPORT_S3_URI            = var.report_s3_uri
+      COST_ESTIMATES           = tostring(var.cost_estimates)
+      EMF_METRICS              = tostring(var.emf_metrics)
+      CAPACITY_METRICS         = tostring(var.capacity_metrics)
+      HEARTBEAT_METRIC         = tostring(var.heartbeat_metric)
+      METRICS_NAMESPACE        = var.metrics_namespace
+      CLUSTERS                 = length(var.clusters) == 0 ? "" : jsonencode(var.clusters)
+      REGION                   = var.region
+      ASSUME_ROLE_ARN          = var.assume_role_arn
+      ASSUME_ROLE_EXTERNAL_ID  = var.assume_role_external_id
+      ELASTIC_SCALE_DIMENSION  = var.elastic_scale_dimension
+      LOCK_TABLE               = var.scaling_lock ? aws_dynamodb_table.lock_table[0].name : ""
+      IDEMPOTENCY_TABLE        = var.sns_idempotency ? aws_dynamodb_table.idempotency_table[0].name : ""
+      PAUSE_PARAMETER          = var.pause_switch ? aws_ssm_parameter.pause_switch[0].name : ""
       SNS_TOPIC_ARN            = aws_sns_topic.docdb_autoscaler_notification_topic.arn
+      SCALE_OUT_TOPIC_ARN      = var.scale_out_topic_arn
+      SCALE_IN_TOPIC_ARN       = var.scale_in_topic_arn
+      FAILURE_TOPIC_ARN        = var.failure_topic_arn
       MAX_RETRIES              = tostring(var.max_retries)         # Optional: For retry logic
       INITIAL_BACKOFF          = tostring(var.initial_backoff)     # Optional: For retry delay
       RETRY_DELAY_SECONDS      = tostring(var.retry_delay_seconds) # Optional: For retry delay
+      DEADLINE_MARGIN          = tostring(var.deadline_margin)
+      API_RATE_LIMIT           = tostring(var.api_rate_limit)
       SCHEDULED_SCALING        = tostring(var.scheduled_scaling)
-      SCHEDULE_NUMBER_REPLICAS = tostring(var.schedule_number_replicas)
+      SCHEDULE_NUMBER_REPLICAS = var.scheduled_scaling ? tostring(var.schedule_number_replicas) : ""
     }
   }
 
@@ -137,6 +553,26 @@ resource "aws_lambda_function" "docdb_autoscaler_lambda" {
   tags        = var.tags
 }
 
+# Optional RDS event subscriptions, so that failed instances, failovers and maintenances trigger the Lambda
+resource "aws_docdb_event_subscription" "instance_events" {
+  count            = var.rds_event_subscription && !var.scheduled_scaling ? 1 : 0
+  name             = "${var.docdb_cluster_name}-docdb-autoscaler-instances"
+  sns_topic_arn    = aws_sns_topic.docdb_autoscaler_trigger_topic[0].arn
+  source_type      = "db-instance"
+  event_categories = ["failure", "maintenance"]
+  tags             = var.tags
+}
+
+resource "aws_docdb_event_subscription" "cluster_events" {
+  count            = var.rds_event_subscription && !var.scheduled_scaling ? 1 : 0
+  name             = "${var.docdb_cluster_name}-docdb-autoscaler-cluster"
+  sns_topic_arn    = aws_sns_topic.docdb_autoscaler_trigger_topic[0].arn
+  source_type      = "db-cluster"
+  source_ids       = [var.docdb_cluster_name]
+  event_categories = ["failover"]
+  tags             = var.tags
+}
+
 # Lambda Permission to Allow SNS to Invoke It
 resource "aws_lambda_permission" "allow_sns" {
   count = var.scheduled_scaling ? 0 : 1
@@ -156,6 +592,14 @@ resource "aws_sns_topic_subscription" "lambda_subscription" {
   endpoint  = aws_lambda_function.docdb_autoscaler_lambda.arn
 }
 
+# Optional Function URL for manual operations (POST /scale, GET /status, GET /history, POST /pause, POST /resume)
+resource "aws_lambda_function_url" "docdb_autoscaler_url" {
+  count = var.enable_function_url ? 1 : 0
+
+  function_name      = aws_lambda_function.docdb_autoscaler_lambda.function_name
+  authorization_type = var.http_shared_secret == "" ? "AWS_IAM" : "NONE"
+}
+
 # Limit Lambda concurrency to 1 (To prevent racecondition)
 resource "aws_lambda_function_event_invoke_config" "docdb_autoscaler_invocation" {
   function_name = aws_lambda_function.docdb_autoscaler_lambda.function_name
@@ -164,6 +608,87 @@ resource "aws_lambda_function_event_invoke_config" "docdb_autoscaler_invocation"
   maximum_event_age_in_seconds = 60
 }
 
+##### Scaling Digest #####
+resource "aws_cloudwatch_event_rule" "digest_rule" {
+  count = var.audit_s3_uri != "" && var.digest_schedule != null ? 1 : 0
+
+  name                = "${var.docdb_cluster_name}-docdb-autoscaler-digest"
+  description         = "Scheduled rule to send the scaling digest of the DocumentDB autoscaler."
+  schedule_expression = var.digest_schedule
+}
+
+resource "aws_cloudwatch_event_target" "digest_target" {
+  count = var.audit_s3_uri != "" && var.digest_schedule != null ? 1 : 0
+
+  rule  = aws_cloudwatch_event_rule.digest_rule[0].name
+  arn   = aws_lambda_function.docdb_autoscaler_lambda.arn
+  input = jsonencode({ Digest = var.digest_period })
+}
+
+resource "aws_lambda_permission" "allow_digest_eventbridge_to_invoke_lambda_docdb_autoscaler" {
+  count = var.audit_s3_uri != "" && var.digest_schedule != null ? 1 : 0
+
+  statement_id  = "AllowDigestEventBridgeInvokeLambdaDocDBAutoscaler"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.docdb_autoscaler_lambda.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.digest_rule[0].arn
+}
+
+##### Savings Report #####
+resource "aws_cloudwatch_event_rule" "savings_rule" {
+  count = var.audit_s3_uri != "" && var.savings_schedule != null ? 1 : 0
+
+  name                = "${var.docdb_cluster_name}-docdb-autoscaler-savings"
+  description         = "Scheduled rule to send the savings report of the DocumentDB autoscaler."
+  schedule_expression = var.savings_schedule
+}
+
+resource "aws_cloudwatch_event_target" "savings_target" {
+  count = var.audit_s3_uri != "" && var.savings_schedule != null ? 1 : 0
+
+  rule  = aws_cloudwatch_event_rule.savings_rule[0].name
+  arn   = aws_lambda_function.docdb_autoscaler_lambda.arn
+  input = jsonencode({ SavingsReport = var.savings_period })
+}
+
+resource "aws_lambda_permission" "allow_savings_eventbridge_to_invoke_lambda_docdb_autoscaler" {
+  count = var.audit_s3_uri != "" && var.savings_schedule != null ? 1 : 0
+
+  statement_id  = "AllowSavingsEventBridgeInvokeLambdaDocDBAutoscaler"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.docdb_autoscaler_lambda.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.savings_rule[0].arn
+}
+
+##### Rightsizing Recommendations #####
+resource "aws_cloudwatch_event_rule" "recommendation_rule" {
+  count = var.recommendation_schedule != null ? 1 : 0
+
+  name                = "${var.docdb_cluster_name}-docdb-autoscaler-recommendations"
+  description         = "Scheduled rule to send the rightsizing recommendations of the DocumentDB autoscaler."
+  schedule_expression = var.recommendation_schedule
+}
+
+resource "aws_cloudwatch_event_target" "recommendation_target" {
+  count = var.recommendation_schedule != null ? 1 : 0
+
+  rule  = aws_cloudwatch_event_rule.recommendation_rule[0].name
+  arn   = aws_lambda_function.docdb_autoscaler_lambda.arn
+  input = jsonencode({ Recommendations = { Days = var.recommendation_days } })
+}
+
+resource "aws_lambda_permission" "allow_recommendation_eventbridge_to_invoke_lambda_docdb_autoscaler" {
+  count = var.recommendation_schedule != null ? 1 : 0
+
+  statement_id  = "AllowRecommendationEventBridgeInvokeLambdaDocDBAutoscaler"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.docdb_autoscaler_lambda.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.recommendation_rule[0].arn
+}
+
 ##### Scheduled Scaling #####
 resource "aws_cloudwatch_event_rule" "scheduled_docdb_scale_out_rule" {
   count = var.scheduled_scaling ? 1 : 0
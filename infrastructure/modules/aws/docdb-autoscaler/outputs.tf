@@ -7,3 +7,13 @@ output "lambda_function_arn" {
   description = "ARN of the Lambda function for docdb-autoscaler"
   value       = aws_lambda_function.docdb_autoscaler_lambda.arn
 }
+
+output "lambda_function_url" {
+  description = "Function URL for manual docdb-autoscaler operations, if enabled"
+  value       = var.enable_function_url ? aws_lambda_function_url.docdb_autoscaler_url[0].function_url : null
+}
+
+output "pause_parameter_name" {
+  description = "Name of the SSM parameter pausing autoscaling while set to \"true\", if enabled"
+  value       = var.pause_switch ? aws_ssm_parameter.pause_switch[0].name : null
+}
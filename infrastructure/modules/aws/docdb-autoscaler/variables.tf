@@ -4,6 +4,12 @@ variable "docdb_cluster_name" {
   type        = string
 }
 
+variable "allowed_clusters" {
+  description = "Identifiers or patterns of the only clusters the autoscaler may scale, e.g. [\"prod-*\"]; scaling any other cluster is rejected with a critical notification. Empty allows any cluster"
+  type        = list(string)
+  default     = []
+}
+
 variable "cw_manager_image_uri" {
   description = "The URI of the ECR repository containing the Lambda Docker image"
   type        = string
@@ -73,12 +79,60 @@ variable "max_capacity" {
   type        = number
 }
 
+variable "managed_capacity" {
+  description = "Make min_capacity and max_capacity bound the replicas of the autoscaler only, on top of the baseline readers it did not create"
+  type        = bool
+  default     = false
+}
+
+variable "bootstrap_capacity" {
+  description = "Readers a metric-based scale-out brings a cluster without readers to. min_capacity, and at least 1, when 0"
+  type        = number
+  default     = 0
+}
+
+variable "writer_only_bootstrap" {
+  description = "Scale a cluster without readers on the metric of its writer, so that the first scale-out creates its first readers"
+  type        = bool
+  default     = false
+}
+
+variable "managed_replica_cap" {
+  description = "Hard cap on the replicas of the cluster created by the autoscaler, whatever the maximum capacity allows; hitting it fails the scale-out and pages. 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "account_replica_cap" {
+  description = "Hard cap on the replicas created by the autoscaler across the account and region; hitting it fails the scale-out and pages. 0 disables"
+  type        = number
+  default     = 0
+}
+
 variable "target_value" {
   description = "Target value for the monitored metric to trigger scaling"
   type        = number
   default     = null
 }
 
+variable "metric_targets" {
+  description = "Per-metric target values used when a composite alarm triggers on a child metric (e.g. { DatabaseConnections = 500 })"
+  type        = map(number)
+  default     = {}
+}
+
+variable "metric_concurrency" {
+  description = "Readers whose metric is fetched from CloudWatch at the same time, 10 when 0"
+  type        = number
+  default     = 0
+}
+
+variable "countable_statuses" {
+  description = "Statuses of the readers counted in the capacity and the metric average, e.g. [\"available\"]. Every status when empty"
+  type        = list(string)
+  default     = []
+}
+
 variable "instance_type" {
   description = "Instance type for new read replicas (e.g., r6g.large)"
   type        = string
@@ -91,6 +145,353 @@ variable "dryrun" {
   default     = false
 }
 
+variable "shadow_mode" {
+  description = "Run side by side with a live autoscaler: decide continuously without making changes or sending notifications, recording shadow decisions in the audit log and EMF metrics"
+  type        = bool
+  default     = false
+}
+
+variable "allow_zero_readers" {
+  description = "Allow scale-in to remove the last reader instance of the cluster"
+  type        = bool
+  default     = false
+}
+
+variable "structured_output" {
+  description = "Return a structured scaling result from the Lambda (e.g. for Step Functions)"
+  type        = bool
+  default     = false
+}
+
+variable "strict_events" {
+  description = "Fail invocations with unrecognized payloads and send a failure notification, instead of ignoring them"
+  type        = bool
+  default     = false
+}
+
+variable "config_s3_uri" {
+  description = "Optional S3 URI (s3://bucket/key) of a YAML/JSON config file. Environment variables take precedence over it"
+  type        = string
+  default     = ""
+}
+
+variable "clusters" {
+  description = "Additional clusters managed by this deployment, keyed by cluster identifier, with their own settings (e.g. { prod-orders = { minCapacity = 1, maxCapacity = 10 } })"
+  type        = any
+  default     = {}
+}
+
+variable "region" {
+  description = "Region of the DocumentDB cluster, when different from the region of the Lambda"
+  type        = string
+  default     = ""
+}
+
+variable "assume_role_arn" {
+  description = "Role to assume for the DocumentDB, RDS and CloudWatch calls when the cluster lives in another account"
+  type        = string
+  default     = ""
+}
+
+variable "assume_role_external_id" {
+  description = "External ID passed when assuming assume_role_arn"
+  type        = string
+  default     = ""
+}
+
+variable "assume_role_arns" {
+  description = "All the roles the Lambda may assume, including the assumeRoleArn of the clusters setting"
+  type        = list(string)
+  default     = []
+}
+
+variable "engine" {
+  description = "Engine of the clusters: docdb, neptune, aurora-mysql or aurora-postgresql. Defaults to docdb when empty"
+  type        = string
+  default     = ""
+}
+
+variable "elastic_scale_dimension" {
+  description = "What to scale on elastic clusters: shardCount or shardCapacity. MIN_CAPACITY/MAX_CAPACITY bound this dimension. Defaults to shardCount when empty"
+  type        = string
+  default     = ""
+}
+
+variable "enable_function_url" {
+  description = "Expose a Lambda Function URL for manual scaling operations"
+  type        = bool
+  default     = false
+}
+
+variable "http_shared_secret" {
+  description = "Shared secret required in the x-autoscaler-secret header of HTTP requests. When empty, requests must be IAM authenticated"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "slack_webhook_url" {
+  description = "Slack incoming webhook URL to also send notifications to"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "notification_webhook_url" {
+  description = "HTTP endpoint to also send notifications to, as JSON events"
+  type        = string
+  default     = ""
+}
+
+variable "notification_templates" {
+  description = "Message templates of the notifications, keyed by event (scaleOut, scaleIn, failure)"
+  type        = map(string)
+  default     = {}
+}
+
+variable "scale_out_topic_arn" {
+  description = "SNS topic for scale-out notifications, instead of the notification topic of the module"
+  type        = string
+  default     = ""
+}
+
+variable "scale_in_topic_arn" {
+  description = "SNS topic for scale-in notifications, instead of the notification topic of the module"
+  type        = string
+  default     = ""
+}
+
+variable "failure_topic_arn" {
+  description = "SNS topic for failure notifications, instead of the notification topic of the module"
+  type        = string
+  default     = ""
+}
+
+variable "notification_topic_fifo" {
+  description = "Create the notification topic as an SNS FIFO topic, for subscribers that need ordered, de-duplicated events"
+  type        = bool
+  default     = false
+}
+
+variable "notify_min_severity" {
+  description = "Minimum severity of the notifications to send: info, warn or critical. Failures are always sent"
+  type        = string
+  default     = "info"
+}
+
+variable "notify_dedup_window" {
+  description = "Window in seconds within which identical notifications of a cluster are collapsed into one. 0 disables deduplication"
+  type        = number
+  default     = 0
+}
+
+variable "notify_quiet_hours" {
+  description = "Daily window (HH:MM-HH:MM) during which scale-in notifications are batched into a digest, e.g. 22:00-07:00"
+  type        = string
+  default     = ""
+}
+
+variable "notify_quiet_timezone" {
+  description = "Timezone of notify_quiet_hours, e.g. Europe/Berlin. UTC when empty"
+  type        = string
+  default     = ""
+}
+
+variable "holidays" {
+  description = "Dates (YYYY-MM-DD) scheduled scale-outs are skipped on, e.g. public holidays"
+  type        = list(string)
+  default     = []
+}
+
+variable "holiday_calendar_s3_uri" {
+  description = "Optional S3 URI (s3://bucket/key) of an iCalendar (.ics) file of more holidays"
+  type        = string
+  default     = ""
+}
+
+variable "holiday_timezone" {
+  description = "Timezone of the holidays, e.g. Asia/Singapore. UTC when empty"
+  type        = string
+  default     = ""
+}
+
+variable "pagerduty_routing_key" {
+  description = "Integration key of a PagerDuty service (Events API v2) to open incidents when scaling actions fail after all retries"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "ticket_system" {
+  description = "Ticket system to open a ticket in when scaling fails on consecutive invocations: jira or servicenow. Empty disables tickets"
+  type        = string
+  default     = ""
+}
+
+variable "ticket_url" {
+  description = "Base URL of the Jira site or ServiceNow instance"
+  type        = string
+  default     = ""
+}
+
+variable "ticket_user" {
+  description = "User of the ticket system"
+  type        = string
+  default     = ""
+}
+
+variable "ticket_token" {
+  description = "Jira API token or ServiceNow password of ticket_user"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "ticket_project" {
+  description = "Key of the Jira project tickets are created in"
+  type        = string
+  default     = ""
+}
+
+variable "ticket_threshold" {
+  description = "Consecutive failed invocations of a cluster before a ticket is opened"
+  type        = number
+  default     = 3
+}
+
+variable "circuit_threshold" {
+  description = "Consecutive failed invocations of a cluster before its scaling attempts are paused for the circuit backoff, 0 disables the circuit breaker"
+  type        = number
+  default     = 0
+}
+
+variable "circuit_backoff" {
+  description = "Seconds the scaling attempts of a cluster stay paused once its circuit opens, 1800 when 0"
+  type        = number
+  default     = 0
+}
+
+variable "churn_budget_hourly" {
+  description = "Instances created and deleted in a cluster within an hour before its scaling pauses until the hour passed, 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "churn_budget_daily" {
+  description = "Instances created and deleted in a cluster within a day before its scaling pauses until the day passed, 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "event_bus_name" {
+  description = "Name of an EventBridge bus to publish scaling lifecycle events to (ScalingDecisionMade, ReplicaCreated, ReplicaDeleted, ScalingFailed)"
+  type        = string
+  default     = ""
+}
+
+variable "audit_s3_uri" {
+  description = "Optional S3 URI (s3://bucket/prefix) to record every scaling action in, for the scaling digest"
+  type        = string
+  default     = ""
+}
+
+variable "plan_s3_uri" {
+  description = "Optional S3 URI (s3://bucket/prefix) to archive the full plan of every dry-run invocation in. Must differ from audit_s3_uri"
+  type        = string
+  default     = ""
+}
+
+variable "report_s3_uri" {
+  description = "Optional S3 URI (s3://bucket/prefix) to archive the savings reports in, for FinOps review. Must differ from audit_s3_uri"
+  type        = string
+  default     = ""
+}
+
+variable "cost_estimates" {
+  description = "Estimate the hourly and monthly cost delta of every scaling action with the AWS Price List API, in dry-run plans, notifications and results"
+  type        = bool
+  default     = false
+}
+
+variable "emf_metrics" {
+  description = "Log metrics of every invocation (decision, replicas added/removed, capacity, decision latency, AWS call errors) in the CloudWatch embedded metric format"
+  type        = bool
+  default     = false
+}
+
+variable "capacity_metrics" {
+  description = "Publish CurrentCapacity, DesiredCapacity and AutoscalerManagedReplicas as custom CloudWatch metrics after every invocation"
+  type        = bool
+  default     = false
+}
+
+variable "heartbeat_metric" {
+  description = "Publish a Heartbeat custom CloudWatch metric on every evaluation of a cluster"
+  type        = bool
+  default     = false
+}
+
+variable "heartbeat_alarm_minutes" {
+  description = "With heartbeat_metric, alarm to the notification topic when a cluster has not been evaluated for this many minutes. 0 disables the alarm"
+  type        = number
+  default     = 0
+}
+
+variable "datadog_api_key" {
+  description = "Optional Datadog API key to submit scaling events and capacity metrics to Datadog"
+  type        = string
+  default     = ""
+  sensitive   = true
+}
+
+variable "datadog_site" {
+  description = "Datadog site of datadog_api_key, e.g. datadoghq.eu. Defaults to datadoghq.com"
+  type        = string
+  default     = ""
+}
+
+variable "metrics_namespace" {
+  description = "CloudWatch namespace of the autoscaler metrics, DocDBAutoscaler when empty"
+  type        = string
+  default     = ""
+}
+
+variable "digest_schedule" {
+  description = "Cron expression for sending the scaling digest compiled from the audit log (e.g., 'cron(0 8 ? * MON *)' for 8 AM UTC on Mondays)."
+  type        = string
+  default     = null
+}
+
+variable "digest_period" {
+  description = "Period summarized by the scaling digest, daily or weekly"
+  type        = string
+  default     = "weekly"
+}
+
+variable "savings_schedule" {
+  description = "Cron expression for sending the savings report compiled from the audit log (e.g., 'cron(0 8 ? * MON *)' for 8 AM UTC on Mondays)."
+  type        = string
+  default     = null
+}
+
+variable "savings_period" {
+  description = "Period covered by the savings report, daily or weekly"
+  type        = string
+  default     = "weekly"
+}
+
+variable "recommendation_schedule" {
+  description = "Cron expression for sending rightsizing recommendations from the utilization of the readers (e.g., 'cron(0 8 1 * ? *)' for 8 AM UTC on the first of the month). Recommendations are never acted on"
+  type        = string
+  default     = null
+}
+
+variable "recommendation_days" {
+  description = "Days of reader utilization analyzed by the rightsizing recommendations, at most 60"
+  type        = number
+  default     = 14
+}
+
 variable "docdb_scale_out_cooldown_period" {
   description = "Cooldown period in seconds before allowing scale-out actions"
   type        = number
@@ -102,6 +503,96 @@ variable "docdb_scale_in_cooldown_period" {
   default     = 1200 #20 minutes
 }
 
+variable "cooldown_tags" {
+  description = "Enforce the scale-in and scale-out cooldowns in the function, keeping the time of the last actions in tags of the cluster"
+  type        = bool
+  default     = false
+}
+
+variable "scale_in_stabilization" {
+  description = "Seconds metric-based scale-ins are held after any scale-out, regardless of the cooldowns. 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "track_desired_capacity" {
+  description = "Keep the desired reader capacity in a tag of the cluster, and restore missing readers on every invocation"
+  type        = bool
+  default     = false
+}
+
+variable "orphan_cleanup" {
+  description = "Remove the replicas of the autoscaler left in a failed state or beyond the maximum capacity on every invocation"
+  type        = bool
+  default     = false
+}
+
+variable "replace_failed_replicas" {
+  description = "Remove and recreate the replicas of the autoscaler that enter a failed state, within the scale-out cooldown and maximum capacity"
+  type        = bool
+  default     = false
+}
+
+variable "instance_quota_check" {
+  description = "Cap scale-outs to the room left in the DB instances quota of the account, with a warning notification, instead of failing mid-batch"
+  type        = bool
+  default     = false
+}
+
+variable "bulk_scale_in_limit" {
+  description = "Replicas a scale-in may remove without approval; larger scale-ins remove only that many, with a warning notification, unless approved. 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "allow_bulk_scale_in" {
+  description = "Approve every scale-in beyond bulk_scale_in_limit"
+  type        = bool
+  default     = false
+}
+
+variable "max_hourly_cost" {
+  description = "Budget of the estimated on-demand cost of the readers of a cluster, in USD per hour; scale-outs beyond it add only the replicas with room, with a budget-exceeded warning notification. 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "canary_scale_out" {
+  description = "Add a single canary replica first on metric-based scale-outs, and the rest only once it is available, has served for canary_window, and the metric still calls for them"
+  type        = bool
+  default     = false
+}
+
+variable "canary_window" {
+  description = "Seconds the canary replica serves before the metric is evaluated again. 0 uses the default of 300"
+  type        = number
+  default     = 0
+}
+
+variable "drain_connections" {
+  description = "DatabaseConnections of a replica below which scale-ins delete it; replicas above it are left for a later invocation. 0 disables"
+  type        = number
+  default     = 0
+}
+
+variable "drain_timeout" {
+  description = "Seconds a scale-in waits for a replica to drain below drain_connections, bounded by the Lambda timeout. 0 checks without waiting"
+  type        = number
+  default     = 0
+}
+
+variable "rds_event_subscription" {
+  description = "Subscribe the trigger topic to the failure and maintenance events of the DocumentDB instances and the failover events of the cluster, replacing failed replicas and pausing during maintenance. Requires metric-based scaling"
+  type        = bool
+  default     = false
+}
+
+variable "maintenance_pause" {
+  description = "Seconds a maintenance pauses autoscaling at most, should the event of its completion be lost. 0 uses the default of 7200"
+  type        = number
+  default     = 0
+}
+
 variable "max_retries" {
   description = "Maximum number of retry attempts for scaling actions"
   type        = number
@@ -114,6 +605,18 @@ variable "initial_backoff" {
   default     = 1
 }
 
+variable "deadline_margin" {
+  description = "Seconds left before the Lambda timeout below which a scaling action stops adding or removing replicas, 10 when 0"
+  type        = number
+  default     = 0
+}
+
+variable "api_rate_limit" {
+  description = "AWS API calls per second the DocumentDB, RDS and CloudWatch clients may make across all clusters, 0 disables the limit"
+  type        = number
+  default     = 0
+}
+
 variable "retry_delay_seconds" {
   description = "Initial delay in seconds before retrying scaling actions"
   type        = number
@@ -162,3 +665,21 @@ variable "scale_in_schedule" {
   type        = string
   default     = null #"cron(0 17 * * ? *)" # Example: 5 PM UTC daily
 }
+
+variable "scaling_lock" {
+  description = "Create a DynamoDB table of per-cluster locks, so that only one invocation scales a cluster at a time"
+  type        = bool
+  default     = false
+}
+
+variable "pause_switch" {
+  description = "Create an SSM parameter that pauses autoscaling of all the clusters while set to \"true\", as an emergency switch"
+  type        = bool
+  default     = false
+}
+
+variable "sns_idempotency" {
+  description = "Create a DynamoDB table of the processed SNS messages, so that messages delivered twice are processed once"
+  type        = bool
+  default     = false
+}
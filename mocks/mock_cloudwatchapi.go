@@ -35,6 +35,26 @@ func (m *MockCloudWatchAPI) EXPECT() *MockCloudWatchAPIMockRecorder {
 	return m.recorder
 }
 
+// DescribeAlarms mocks base method.
+func (m *MockCloudWatchAPI) DescribeAlarms(arg0 context.Context, arg1 *cloudwatch.DescribeAlarmsInput, arg2 ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeAlarms", varargs...)
+	ret0, _ := ret[0].(*cloudwatch.DescribeAlarmsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeAlarms indicates an expected call of DescribeAlarms.
+func (mr *MockCloudWatchAPIMockRecorder) DescribeAlarms(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAlarms", reflect.TypeOf((*MockCloudWatchAPI)(nil).DescribeAlarms), varargs...)
+}
+
 // GetMetricStatistics mocks base method.
 func (m *MockCloudWatchAPI) GetMetricStatistics(arg0 context.Context, arg1 *cloudwatch.GetMetricStatisticsInput, arg2 ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
 	m.ctrl.T.Helper()
@@ -35,6 +35,26 @@ func (m *MockDocDBAPI) EXPECT() *MockDocDBAPIMockRecorder {
 	return m.recorder
 }
 
+// AddTagsToResource mocks base method.
+func (m *MockDocDBAPI) AddTagsToResource(arg0 context.Context, arg1 *docdb.AddTagsToResourceInput, arg2 ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddTagsToResource", varargs...)
+	ret0, _ := ret[0].(*docdb.AddTagsToResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTagsToResource indicates an expected call of AddTagsToResource.
+func (mr *MockDocDBAPIMockRecorder) AddTagsToResource(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTagsToResource", reflect.TypeOf((*MockDocDBAPI)(nil).AddTagsToResource), varargs...)
+}
+
 // CreateDBInstance mocks base method.
 func (m *MockDocDBAPI) CreateDBInstance(arg0 context.Context, arg1 *docdb.CreateDBInstanceInput, arg2 ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +115,26 @@ func (mr *MockDocDBAPIMockRecorder) DescribeDBInstances(arg0, arg1 interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDBInstances", reflect.TypeOf((*MockDocDBAPI)(nil).DescribeDBInstances), varargs...)
 }
 
+// DescribeGlobalClusters mocks base method.
+func (m *MockDocDBAPI) DescribeGlobalClusters(arg0 context.Context, arg1 *docdb.DescribeGlobalClustersInput, arg2 ...func(*docdb.Options)) (*docdb.DescribeGlobalClustersOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeGlobalClusters", varargs...)
+	ret0, _ := ret[0].(*docdb.DescribeGlobalClustersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeGlobalClusters indicates an expected call of DescribeGlobalClusters.
+func (mr *MockDocDBAPIMockRecorder) DescribeGlobalClusters(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeGlobalClusters", reflect.TypeOf((*MockDocDBAPI)(nil).DescribeGlobalClusters), varargs...)
+}
+
 // ListTagsForResource mocks base method.
 func (m *MockDocDBAPI) ListTagsForResource(arg0 context.Context, arg1 *docdb.ListTagsForResourceInput, arg2 ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
 	m.ctrl.T.Helper()
@@ -114,3 +154,23 @@ func (mr *MockDocDBAPIMockRecorder) ListTagsForResource(arg0, arg1 interface{},
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsForResource", reflect.TypeOf((*MockDocDBAPI)(nil).ListTagsForResource), varargs...)
 }
+
+// RemoveTagsFromResource mocks base method.
+func (m *MockDocDBAPI) RemoveTagsFromResource(arg0 context.Context, arg1 *docdb.RemoveTagsFromResourceInput, arg2 ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveTagsFromResource", varargs...)
+	ret0, _ := ret[0].(*docdb.RemoveTagsFromResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTagsFromResource indicates an expected call of RemoveTagsFromResource.
+func (mr *MockDocDBAPIMockRecorder) RemoveTagsFromResource(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTagsFromResource", reflect.TypeOf((*MockDocDBAPI)(nil).RemoveTagsFromResource), varargs...)
+}
@@ -0,0 +1,91 @@
+// Package adapters parses scaling triggers from the webhook payloads of external alerting systems,
+// so that monitoring stacks other than CloudWatch can drive the autoscaler.
+//
+// Alerts select the cluster and the direction with labels (tags for Datadog):
+// "cluster" names the cluster, "direction" is "out" or "in" and the optional "replicas"
+// is the number of readers to add or remove, 1 by default.
+package adapters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Scaling directions of a trigger.
+const (
+	DirectionOut = "out"
+	DirectionIn  = "in"
+)
+
+// Labels read from alerts.
+const (
+	LabelCluster   = "cluster"
+	LabelDirection = "direction"
+	LabelReplicas  = "replicas"
+)
+
+// Trigger is a request to scale a cluster, parsed from a firing alert.
+type Trigger struct {
+	Source    string // Name of the adapter
+	AlertName string
+	ClusterID string // Empty when the alert does not name a cluster
+	Direction string // DirectionOut or DirectionIn
+	Replicas  int    // Number of readers to add or remove
+}
+
+// Adapter parses the payloads of one alerting system.
+type Adapter interface {
+	// Name identifies the alerting system in logs and notifications.
+	Name() string
+	// Detect reports whether the payload was sent by the alerting system.
+	Detect(payload []byte) bool
+	// Parse returns the triggers of the firing alerts of the payload. Resolved alerts are ignored.
+	Parse(payload []byte) ([]Trigger, error)
+}
+
+// registered holds the adapters in detection order.
+var registered = []Adapter{Grafana{}, Alertmanager{}, Datadog{}}
+
+// Register adds an adapter, detected before the built-in ones.
+func Register(adapter Adapter) {
+	registered = append([]Adapter{adapter}, registered...)
+}
+
+// Detect returns the adapter of the payload, or nil when no adapter recognizes it.
+func Detect(payload []byte) Adapter {
+	for _, adapter := range registered {
+		if adapter.Detect(payload) {
+			return adapter
+		}
+	}
+	return nil
+}
+
+// newTrigger builds the trigger of an alert from its labels.
+func newTrigger(source, alertName string, labels map[string]string) (Trigger, error) {
+	trigger := Trigger{
+		Source:    source,
+		AlertName: alertName,
+		ClusterID: labels[LabelCluster],
+		Replicas:  1,
+	}
+
+	switch strings.ToLower(labels[LabelDirection]) {
+	case "out", "scale_out", "scaleout", "up":
+		trigger.Direction = DirectionOut
+	case "in", "scale_in", "scalein", "down":
+		trigger.Direction = DirectionIn
+	default:
+		return trigger, fmt.Errorf("alert %s: %s label must be one of out, in, got %q", alertName, LabelDirection, labels[LabelDirection])
+	}
+
+	if replicas, found := labels[LabelReplicas]; found {
+		number, err := strconv.Atoi(replicas)
+		if err != nil || number < 1 {
+			return trigger, fmt.Errorf("alert %s: %s label must be a positive number, got %q", alertName, LabelReplicas, replicas)
+		}
+		trigger.Replicas = number
+	}
+	return trigger, nil
+}
@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDetect tests that each payload is attributed to its alerting system.
+func TestDetect(t *testing.T) {
+	alertmanager := `{"version":"4","groupKey":"{}:{}","status":"firing","alerts":[{"status":"firing","labels":{"alertname":"HighCPU","cluster":"orders","direction":"out"}}]}`
+	grafana := `{"receiver":"autoscaler","groupKey":"{}:{}","status":"firing","orgId":1,"alerts":[{"status":"firing","labels":{"alertname":"HighCPU"}}]}`
+	datadog := `{"alert_transition":"Triggered","title":"HighCPU","tags":"cluster:orders,direction:out"}`
+
+	assert.Equal(t, "alertmanager", Detect([]byte(alertmanager)).Name())
+	assert.Equal(t, "grafana", Detect([]byte(grafana)).Name())
+	assert.Equal(t, "datadog", Detect([]byte(datadog)).Name())
+	assert.Nil(t, Detect([]byte(`{"ScalingType":"ScaleOut","NumberReplicas":1}`)))
+}
+
+// TestAlertmanagerParse tests that firing alerts are mapped to triggers and resolved alerts are ignored.
+func TestAlertmanagerParse(t *testing.T) {
+	payload := `{"version":"4","groupKey":"{}:{}","status":"firing","alerts":[
+		{"status":"firing","labels":{"alertname":"HighCPU","cluster":"orders","direction":"scale_out","replicas":"2"}},
+		{"status":"firing","labels":{"alertname":"LowCPU","direction":"down"}},
+		{"status":"resolved","labels":{"alertname":"HighConnections","cluster":"orders","direction":"out"}}
+	]}`
+
+	triggers, err := Alertmanager{}.Parse([]byte(payload))
+	assert.NoError(t, err)
+	assert.Equal(t, []Trigger{
+		{Source: "alertmanager", AlertName: "HighCPU", ClusterID: "orders", Direction: DirectionOut, Replicas: 2},
+		{Source: "alertmanager", AlertName: "LowCPU", Direction: DirectionIn, Replicas: 1},
+	}, triggers)
+
+	_, err = Alertmanager{}.Parse([]byte(`{"groupKey":"{}:{}","alerts":[{"status":"firing","labels":{"alertname":"HighCPU","direction":"sideways"}}]}`))
+	assert.ErrorContains(t, err, `direction label must be one of out, in, got "sideways"`)
+}
+
+// TestDatadogParse tests that monitor tags are mapped to a trigger and recoveries are ignored.
+func TestDatadogParse(t *testing.T) {
+	triggers, err := Datadog{}.Parse([]byte(`{"alert_transition":"Triggered","title":"HighCPU","tags":"env:prod, cluster:orders,direction:in"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Trigger{{Source: "datadog", AlertName: "HighCPU", ClusterID: "orders", Direction: DirectionIn, Replicas: 1}}, triggers)
+
+	triggers, err = Datadog{}.Parse([]byte(`{"alert_transition":"Recovered","title":"HighCPU","tags":"cluster:orders,direction:in"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, triggers)
+}
@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"encoding/json"
+)
+
+// webhookMessage is the webhook payload of Prometheus Alertmanager, which Grafana alerting extends.
+type webhookMessage struct {
+	Version  string         `json:"version"`
+	GroupKey string         `json:"groupKey"`
+	Status   string         `json:"status"`
+	OrgID    int64          `json:"orgId"` // Grafana only
+	Alerts   []webhookAlert `json:"alerts"`
+}
+
+type webhookAlert struct {
+	Status string            `json:"status"` // "firing" or "resolved"
+	Labels map[string]string `json:"labels"`
+}
+
+// decodeWebhookMessage decodes an Alertmanager-style payload, ok is false for any other payload.
+func decodeWebhookMessage(payload []byte) (message webhookMessage, ok bool) {
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return message, false
+	}
+	return message, message.GroupKey != "" && len(message.Alerts) > 0
+}
+
+// parseWebhookAlerts returns the triggers of the firing alerts, named by their alertname label.
+func parseWebhookAlerts(source string, message webhookMessage) ([]Trigger, error) {
+	var triggers []Trigger
+	for _, alert := range message.Alerts {
+		if alert.Status != "firing" {
+			continue
+		}
+		trigger, err := newTrigger(source, alert.Labels["alertname"], alert.Labels)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
+// Alertmanager parses the webhook payloads of Prometheus Alertmanager.
+type Alertmanager struct{}
+
+// Name returns "alertmanager".
+func (Alertmanager) Name() string {
+	return "alertmanager"
+}
+
+// Detect reports whether the payload is an Alertmanager webhook message.
+func (Alertmanager) Detect(payload []byte) bool {
+	_, ok := decodeWebhookMessage(payload)
+	return ok
+}
+
+// Parse returns the triggers of the firing alerts.
+func (a Alertmanager) Parse(payload []byte) ([]Trigger, error) {
+	var message webhookMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return nil, err
+	}
+	return parseWebhookAlerts(a.Name(), message)
+}
+
+// Grafana parses the webhook payloads of Grafana alerting, which carry the organization of the alerts.
+type Grafana struct{}
+
+// Name returns "grafana".
+func (Grafana) Name() string {
+	return "grafana"
+}
+
+// Detect reports whether the payload is a Grafana webhook message.
+func (Grafana) Detect(payload []byte) bool {
+	message, ok := decodeWebhookMessage(payload)
+	return ok && message.OrgID != 0
+}
+
+// Parse returns the triggers of the firing alerts.
+func (g Grafana) Parse(payload []byte) ([]Trigger, error) {
+	var message webhookMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return nil, err
+	}
+	return parseWebhookAlerts(g.Name(), message)
+}
@@ -0,0 +1,52 @@
+package adapters
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// datadogMessage is the payload of a Datadog webhook using the template
+//
+//	{"alert_transition": "$ALERT_TRANSITION", "title": "$EVENT_TITLE", "tags": "$TAGS"}
+type datadogMessage struct {
+	AlertTransition string `json:"alert_transition"` // "Triggered", "Re-Triggered", "Recovered", ...
+	Title           string `json:"title"`
+	Tags            string `json:"tags"` // Comma-separated key:value tags
+}
+
+// Datadog parses the webhook payloads of Datadog monitors. Labels are read from the monitor tags.
+type Datadog struct{}
+
+// Name returns "datadog".
+func (Datadog) Name() string {
+	return "datadog"
+}
+
+// Detect reports whether the payload is a Datadog webhook message.
+func (Datadog) Detect(payload []byte) bool {
+	var message datadogMessage
+	return json.Unmarshal(payload, &message) == nil && message.AlertTransition != ""
+}
+
+// Parse returns the trigger of a triggered monitor, none for any other transition.
+func (d Datadog) Parse(payload []byte) ([]Trigger, error) {
+	var message datadogMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return nil, err
+	}
+	if message.AlertTransition != "Triggered" && message.AlertTransition != "Re-Triggered" {
+		return nil, nil
+	}
+
+	labels := map[string]string{}
+	for _, tag := range strings.Split(message.Tags, ",") {
+		if key, value, found := strings.Cut(strings.TrimSpace(tag), ":"); found {
+			labels[key] = value
+		}
+	}
+	trigger, err := newTrigger(d.Name(), message.Title, labels)
+	if err != nil {
+		return nil, err
+	}
+	return []Trigger{trigger}, nil
+}
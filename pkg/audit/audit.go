@@ -0,0 +1,192 @@
+// Package audit keeps a log of the scaling actions of the autoscaler, for reports such as the weekly digest,
+// and archives the plans of dry-run invocations.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Record is the outcome of a scaling action of a cluster.
+type Record struct {
+	Time            time.Time `json:"time"`
+	ClusterID       string    `json:"clusterId"`
+	Decision        string    `json:"decision"` // ScaleOut, ScaleIn, NoAction, Verify or Paused
+	ReplicasAdded   int       `json:"replicasAdded"`
+	ReplicasRemoved int       `json:"replicasRemoved"`
+	Capacity        *int      `json:"capacity,omitempty"` // Readers after the action, when known
+	Error           string    `json:"error,omitempty"`
+	DryRun          bool      `json:"dryRun"`
+	Shadow          bool      `json:"shadow,omitempty"` // Decided in shadow mode, also a dry run
+	CorrelationID   string    `json:"correlationId,omitempty"`
+
+	// What the decision was based on, when known
+	MetricName      string   `json:"metricName,omitempty"`
+	MetricValue     *float64 `json:"metricValue,omitempty"`
+	DesiredCapacity *int     `json:"desiredCapacity,omitempty"`
+	Explanation     string   `json:"explanation,omitempty"` // e.g. "CPUUtilization=82.3 > target=70 → +2"
+}
+
+// Store persists audit records.
+type Store interface {
+	Append(ctx context.Context, record Record) error
+	// List returns the records of a cluster from since (inclusive) to until (exclusive), oldest first.
+	List(ctx context.Context, clusterID string, since, until time.Time) ([]Record, error)
+	// Clusters returns the clusters with records.
+	Clusters(ctx context.Context) ([]string, error)
+}
+
+// S3API defines the interface for Amazon S3 client methods used.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3Store keeps one object per record, at <prefix><cluster>/<YYYY-MM-DD>/<unix nanoseconds>.json,
+// so that the records of a day are listed without reading the others.
+type S3Store struct {
+	Client S3API
+	Bucket string
+	Prefix string // Empty, or ending in "/"
+}
+
+// NewS3Store creates a new S3Store for an s3://bucket/prefix URI. The prefix is optional.
+func NewS3Store(client S3API, uri string) (*S3Store, error) {
+	location, found := strings.CutPrefix(uri, "s3://")
+	if !found {
+		return nil, fmt.Errorf("invalid S3 URI %q, expected s3://bucket/prefix", uri)
+	}
+	bucket, prefix, _ := strings.Cut(location, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid S3 URI %q, expected s3://bucket/prefix", uri)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &S3Store{Client: client, Bucket: bucket, Prefix: prefix}, nil
+}
+
+// Ensure S3Store implements Store
+var _ Store = (*S3Store)(nil)
+
+// Append writes the record.
+func (s *S3Store) Append(ctx context.Context, record Record) error {
+	key := fmt.Sprintf("%s%d.json", s.dayPrefix(record.ClusterID, record.Time), record.Time.UnixNano())
+	if err := s.put(ctx, key, record); err != nil {
+		return fmt.Errorf("failed to write audit record %s: %w", key, err)
+	}
+	return nil
+}
+
+// List returns the records of a cluster from since (inclusive) to until (exclusive), oldest first.
+func (s *S3Store) List(ctx context.Context, clusterID string, since, until time.Time) ([]Record, error) {
+	var records []Record
+	for day := since.UTC().Truncate(24 * time.Hour); day.Before(until); day = day.Add(24 * time.Hour) {
+		keys, _, err := s.list(ctx, s.dayPrefix(clusterID, day), "")
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			record, err := s.read(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			if !record.Time.Before(since) && record.Time.Before(until) {
+				records = append(records, record)
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// Clusters returns the clusters with records.
+func (s *S3Store) Clusters(ctx context.Context) ([]string, error) {
+	_, prefixes, err := s.list(ctx, s.Prefix, "/")
+	if err != nil {
+		return nil, err
+	}
+	clusterIDs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		clusterIDs = append(clusterIDs, strings.TrimSuffix(strings.TrimPrefix(prefix, s.Prefix), "/"))
+	}
+	return clusterIDs, nil
+}
+
+// dayPrefix returns the prefix of the records of a cluster on the day of t, in UTC.
+func (s *S3Store) dayPrefix(clusterID string, t time.Time) string {
+	return fmt.Sprintf("%s%s/%s/", s.Prefix, clusterID, t.UTC().Format(time.DateOnly))
+}
+
+// list returns all the keys, and common prefixes when delimiter is set, under prefix.
+func (s *S3Store) list(ctx context.Context, prefix, delimiter string) ([]string, []string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+	var keys, prefixes []string
+	paginator := s3.NewListObjectsV2Paginator(s.Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list audit records under %s: %w", prefix, err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(commonPrefix.Prefix))
+		}
+	}
+	return keys, prefixes, nil
+}
+
+// put writes v as JSON at key.
+func (s *S3Store) put(ctx context.Context, key string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// read reads the record of a key.
+func (s *S3Store) read(ctx context.Context, key string) (Record, error) {
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to get audit record %s: %w", key, err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to read audit record %s: %w", key, err)
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Record{}, fmt.Errorf("invalid audit record %s: %w", key, err)
+	}
+	return record, nil
+}
@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryS3 is an in-memory bucket.
+type memoryS3 map[string][]byte
+
+func (m memoryS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m[aws.ToString(params.Key)]))}, nil
+}
+
+func (m memoryS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	m[aws.ToString(params.Key)] = data
+	return &s3.PutObjectOutput{}, err
+}
+
+func (m memoryS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	output := &s3.ListObjectsV2Output{}
+	prefixes := map[string]bool{}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		rest, found := strings.CutPrefix(key, aws.ToString(params.Prefix))
+		if !found {
+			continue
+		}
+		if delimiter := aws.ToString(params.Delimiter); delimiter != "" {
+			if before, _, found := strings.Cut(rest, delimiter); found {
+				prefixes[aws.ToString(params.Prefix)+before+delimiter] = true
+				continue
+			}
+		}
+		output.Contents = append(output.Contents, s3Types.Object{Key: aws.String(key)})
+	}
+	for prefix := range prefixes {
+		output.CommonPrefixes = append(output.CommonPrefixes, s3Types.CommonPrefix{Prefix: aws.String(prefix)})
+	}
+	return output, nil
+}
+
+// TestS3Store tests that records are listed per cluster within a time range.
+func TestS3Store(t *testing.T) {
+	bucket := memoryS3{}
+	store, err := NewS3Store(bucket, "s3://audit-bucket/docdb")
+	assert.NoError(t, err)
+	assert.Equal(t, "docdb/", store.Prefix)
+
+	monday := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for _, record := range []Record{
+		{Time: monday, ClusterID: "orders", Decision: "ScaleOut", ReplicasAdded: 2},
+		{Time: monday.Add(26 * time.Hour), ClusterID: "orders", Decision: "ScaleIn", ReplicasRemoved: 1},
+		{Time: monday.Add(10 * 24 * time.Hour), ClusterID: "orders", Decision: "NoAction"},
+		{Time: monday, ClusterID: "users", Decision: "NoAction"},
+	} {
+		assert.NoError(t, store.Append(context.Background(), record))
+	}
+	assert.Contains(t, bucket, "docdb/orders/2024-01-15/1705312800000000000.json")
+
+	records, err := store.List(context.Background(), "orders", monday, monday.Add(7*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "ScaleOut", records[0].Decision)
+	assert.Equal(t, "ScaleIn", records[1].Decision)
+
+	clusterIDs, err := store.Clusters(context.Background())
+	assert.NoError(t, err)
+	sort.Strings(clusterIDs)
+	assert.Equal(t, []string{"orders", "users"}, clusterIDs)
+
+	_, err = NewS3Store(bucket, "audit-bucket")
+	assert.Error(t, err)
+}
+
+// TestSummarize tests the digest of the records of a week.
+func TestSummarize(t *testing.T) {
+	capacity := func(readers int) *int { return &readers }
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	summary := Summarize("orders", since, since.Add(7*24*time.Hour), []Record{
+		{Decision: "ScaleOut", ReplicasAdded: 2, Capacity: capacity(4)},
+		{Decision: "ScaleIn", ReplicasRemoved: 1, Capacity: capacity(3)},
+		{Decision: "ScaleOut", ReplicasAdded: 3, DryRun: true, Capacity: capacity(3)},
+		{Decision: "ScaleOut", Error: "InsufficientDBInstanceCapacity", Capacity: capacity(2)},
+	})
+	assert.Equal(t, 1, summary.ScaleOuts)
+	assert.Equal(t, 1, summary.ScaleIns)
+	assert.Equal(t, 2, summary.ReplicasAdded)
+	assert.Equal(t, 1, summary.Failures)
+	assert.Equal(t, 4, *summary.PeakCapacity)
+	assert.Equal(t, 2, *summary.TroughCapacity)
+	assert.Equal(t, "Scaling digest for cluster orders, 2024-01-15 to 2024-01-22:\n"+
+		"- Scale-outs: 1 (2 replicas added)\n"+
+		"- Scale-ins: 1 (1 replicas removed)\n"+
+		"- Capacity: peak 4, trough 2 readers\n"+
+		"- Failures: 1\n"+
+		"- Last error: InsufficientDBInstanceCapacity\n", summary.Message())
+}
+
+// TestSavings tests the replica-hours of a day, from the capacity after each action.
+func TestSavings(t *testing.T) {
+	capacity := func(readers int) *int { return &readers }
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	report := Savings("orders", since, since.Add(24*time.Hour), 1, 5, []Record{
+		{Time: since.Add(-time.Hour), Capacity: capacity(5)}, // Before the period
+		{Time: since.Add(4 * time.Hour), Capacity: capacity(1)},
+		{Time: since.Add(8 * time.Hour), Decision: "NoAction"}, // Capacity unknown
+		{Time: since.Add(12 * time.Hour), Decision: "ScaleOut", Capacity: capacity(3)},
+		{Time: since.Add(18 * time.Hour), Decision: "ScaleIn", Capacity: capacity(2)},
+	})
+	assert.Equal(t, float64(20), report.CoveredHours, "the hours before the first record are not covered")
+	assert.Equal(t, float64(8+18+12), report.ReplicaHours)
+	assert.Equal(t, float64(100), report.StaticMaxReplicaHours)
+	assert.Equal(t, float64(62), report.SavedReplicaHours)
+	assert.Equal(t, float64(62), report.SavedPercent)
+	assert.Equal(t, float64(12), report.MinShortfallHours)
+	assert.Equal(t, float64(12+6), report.MinShortfallReplicaHours)
+	assert.Equal(t, 3, *report.PeakCapacity)
+	assert.Equal(t, "Savings report for cluster orders, 2024-01-15 to 2024-01-16:\n"+
+		"- Replica-hours: 38.0 over 20.0 hours\n"+
+		"- Saved versus always running MaxCapacity (5): 62.0 replica-hours (62%)\n"+
+		"- Above MinCapacity (1): 12.0 hours, 18.0 replica-hours a static MinCapacity would have lacked\n", report.Message())
+}
+
+// TestHistory tests that the most recent matching records are returned, newest first.
+func TestHistory(t *testing.T) {
+	store, err := NewS3Store(memoryS3{}, "s3://audit-bucket")
+	assert.NoError(t, err)
+	night := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+	for i, decision := range []string{"ScaleOut", "NoAction", "ScaleIn", "ScaleOut", "ScaleIn"} {
+		record := Record{Time: night.Add(time.Duration(i) * time.Hour), ClusterID: "orders", Decision: decision}
+		assert.NoError(t, store.Append(context.Background(), record))
+	}
+
+	history, err := History(context.Background(), store, Query{
+		ClusterID: "orders",
+		Decisions: ParseDecisions("ScaleOut, ScaleIn"),
+		Since:     night,
+		Until:     night.Add(12 * time.Hour),
+		Limit:     3,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, night.Add(4*time.Hour), history[0].Time)
+	assert.Equal(t, night.Add(3*time.Hour), history[1].Time)
+	assert.Equal(t, "ScaleIn", history[2].Decision)
+
+	_, err = History(context.Background(), store, Query{ClusterID: "orders", Since: night, Until: night})
+	assert.Error(t, err)
+
+	since, err := ParseHistoryTime("12h", night)
+	assert.NoError(t, err)
+	assert.Equal(t, night.Add(-12*time.Hour), since)
+	since, err = ParseHistoryTime("2024-01-15T10:00:00Z", night)
+	assert.NoError(t, err)
+	assert.Equal(t, night.Add(-12*time.Hour), since)
+	_, err = ParseHistoryTime("last night", night)
+	assert.Error(t, err)
+}
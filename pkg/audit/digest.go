@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary summarizes the audit records of a cluster over a period.
+type Summary struct {
+	ClusterID       string
+	Since           time.Time
+	Until           time.Time
+	ScaleOuts       int
+	ScaleIns        int
+	ReplicasAdded   int
+	ReplicasRemoved int
+	Failures        int
+	LastError       string
+	PeakCapacity    *int // Most readers after an action in the period, when known
+	TroughCapacity  *int // Fewest readers after an action in the period, when known
+}
+
+// Summarize summarizes the records of a cluster from since to until. Dry-run and failed actions are
+// not counted as scaling actions, as they did not change the cluster.
+func Summarize(clusterID string, since, until time.Time, records []Record) Summary {
+	summary := Summary{ClusterID: clusterID, Since: since, Until: until}
+	for _, record := range records {
+		if record.Error != "" {
+			summary.Failures++
+			summary.LastError = record.Error
+		}
+		if record.Capacity != nil {
+			capacity := *record.Capacity
+			if summary.PeakCapacity == nil || capacity > *summary.PeakCapacity {
+				summary.PeakCapacity = &capacity
+			}
+			if summary.TroughCapacity == nil || capacity < *summary.TroughCapacity {
+				summary.TroughCapacity = &capacity
+			}
+		}
+		if record.DryRun || record.Error != "" {
+			continue
+		}
+		switch record.Decision {
+		case "ScaleOut":
+			summary.ScaleOuts++
+		case "ScaleIn":
+			summary.ScaleIns++
+		}
+		summary.ReplicasAdded += record.ReplicasAdded
+		summary.ReplicasRemoved += record.ReplicasRemoved
+	}
+	return summary
+}
+
+// Message returns the digest message of the summary.
+func (s Summary) Message() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Scaling digest for cluster %s, %s to %s:\n", s.ClusterID, s.Since.UTC().Format(time.DateOnly), s.Until.UTC().Format(time.DateOnly))
+	fmt.Fprintf(&message, "- Scale-outs: %d (%d replicas added)\n", s.ScaleOuts, s.ReplicasAdded)
+	fmt.Fprintf(&message, "- Scale-ins: %d (%d replicas removed)\n", s.ScaleIns, s.ReplicasRemoved)
+	if s.PeakCapacity != nil {
+		fmt.Fprintf(&message, "- Capacity: peak %d, trough %d readers\n", *s.PeakCapacity, *s.TroughCapacity)
+	}
+	fmt.Fprintf(&message, "- Failures: %d\n", s.Failures)
+	if s.LastError != "" {
+		fmt.Fprintf(&message, "- Last error: %s\n", s.LastError)
+	}
+	return message.String()
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Defaults of history queries.
+const (
+	DefaultHistoryLimit  = 20
+	DefaultHistoryPeriod = 7 * 24 * time.Hour
+)
+
+// Query selects the scaling actions of a cluster in the history.
+type Query struct {
+	ClusterID string
+	Decisions []string  // e.g. ScaleOut, ScaleIn; all decisions when empty
+	Since     time.Time // DefaultHistoryPeriod before Until when zero
+	Until     time.Time // Now when zero
+	Limit     int       // DefaultHistoryLimit when zero
+}
+
+// History returns the most recent records of the query, newest first.
+func History(ctx context.Context, store Store, query Query) ([]Record, error) {
+	until := query.Until
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+	since := query.Since
+	if since.IsZero() {
+		since = until.Add(-DefaultHistoryPeriod)
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("history start %s must be before its end %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	records, err := store.List(ctx, query.ClusterID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]Record, 0, min(len(records), limit))
+	for i := len(records) - 1; i >= 0 && len(history) < limit; i-- {
+		if len(query.Decisions) > 0 && !slices.Contains(query.Decisions, records[i].Decision) {
+			continue
+		}
+		history = append(history, records[i])
+	}
+	return history, nil
+}
+
+// ParseHistoryTime parses a time of a history query, either RFC 3339 or a duration before now,
+// e.g. "12h" for 12 hours ago. Empty values parse to the zero time.
+func ParseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, must be RFC 3339 or a duration before now such as 12h", value)
+	}
+	return t, nil
+}
+
+// ParseDecisions parses a comma-separated list of decisions, e.g. "ScaleOut,ScaleIn".
+func ParseDecisions(value string) []string {
+	var decisions []string
+	for _, decision := range strings.Split(value, ",") {
+		if decision = strings.TrimSpace(decision); decision != "" {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
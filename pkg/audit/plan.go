@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+)
+
+// Plan is the full plan of a dry-run invocation: the inputs and metric the decision was based on, and the
+// exact instances that would have been created or removed, to review hypothetical behavior before enabling
+// real scaling.
+type Plan struct {
+	Time              time.Time  `json:"time"`
+	ClusterID         string     `json:"clusterId"`
+	CorrelationID     string     `json:"correlationId,omitempty"`
+	Inputs            PlanInputs `json:"inputs"`
+	MetricName        string     `json:"metricName,omitempty"` // Metric the decision was based on, when metric based
+	MetricValue       *float64   `json:"metricValue,omitempty"`
+	TargetValue       *float64   `json:"targetValue,omitempty"`
+	CurrentCapacity   *int       `json:"currentCapacity,omitempty"` // Readers before the action, when known
+	DesiredCapacity   *int       `json:"desiredCapacity,omitempty"`
+	Decision          string     `json:"decision"`
+	InstancesToCreate []string   `json:"instancesToCreate"`
+	InstancesToRemove []string   `json:"instancesToRemove"`
+	ReasonCodes       []string   `json:"reasonCodes"`
+	Constraints       []string   `json:"constraints"` // Constraints that changed the outcome, e.g. MaxCapacity
+	Error             string     `json:"error,omitempty"`
+
+	// Estimated on-demand cost delta of the instances to create and remove, when known
+	CostDelta *pricing.Cost `json:"costDelta,omitempty"`
+}
+
+// PlanInputs are the settings and trigger of a dry-run invocation.
+type PlanInputs struct {
+	MinCapacity            int                `json:"minCapacity"`
+	MaxCapacity            int                `json:"maxCapacity"`
+	MetricName             string             `json:"metricName"`
+	TargetValue            float64            `json:"targetValue"`
+	MetricTargets          map[string]float64 `json:"metricTargets,omitempty"`
+	ScaleInCooldown        int                `json:"scaleInCooldown"`
+	ScaleOutCooldown       int                `json:"scaleOutCooldown"`
+	ScaleInStabilization   int                `json:"scaleInStabilization,omitempty"`
+	InstanceType           string             `json:"instanceType,omitempty"`
+	ScheduledScaling       bool               `json:"scheduledScaling"`
+	ScheduleNumberReplicas int                `json:"scheduleNumberReplicas,omitempty"`
+	SchedulePhase          string             `json:"schedulePhase,omitempty"`
+	TriggerAlarm           string             `json:"triggerAlarm,omitempty"` // Alarm that triggered the invocation, if any
+}
+
+// PlanStore archives the plans of dry-run invocations.
+type PlanStore interface {
+	SavePlan(ctx context.Context, plan Plan) error
+}
+
+// Ensure S3Store implements PlanStore
+var _ PlanStore = (*S3Store)(nil)
+
+// SavePlan writes the plan, with the same layout as audit records. Plans must be kept under another
+// prefix than the audit log, as they are not audit records.
+func (s *S3Store) SavePlan(ctx context.Context, plan Plan) error {
+	key := fmt.Sprintf("%s%d.json", s.dayPrefix(plan.ClusterID, plan.Time), plan.Time.UnixNano())
+	if err := s.put(ctx, key, plan); err != nil {
+		return fmt.Errorf("failed to write dry-run plan %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SavingsReport compares the readers a cluster ran over a period with static provisioning: the replica-hours
+// saved versus always running MaxCapacity, and those a static MinCapacity would have lacked, the hours at
+// risk of missing the SLO without autoscaling. The capacity is only known from the first audit record with
+// one, so the hours before it are not covered.
+type SavingsReport struct {
+	ClusterID    string    `json:"clusterId"`
+	Since        time.Time `json:"since"`
+	Until        time.Time `json:"until"`
+	MinCapacity  int       `json:"minCapacity"`
+	MaxCapacity  int       `json:"maxCapacity"`
+	CoveredHours float64   `json:"coveredHours"` // Hours of the period whose capacity is known
+
+	ReplicaHours             float64 `json:"replicaHours"`             // Reader-hours the cluster ran
+	StaticMaxReplicaHours    float64 `json:"staticMaxReplicaHours"`    // Reader-hours of always running MaxCapacity
+	SavedReplicaHours        float64 `json:"savedReplicaHours"`        // StaticMaxReplicaHours minus ReplicaHours
+	SavedPercent             float64 `json:"savedPercent"`             // SavedReplicaHours in percent of StaticMaxReplicaHours
+	MinShortfallHours        float64 `json:"minShortfallHours"`        // Hours a static MinCapacity would have run short of readers
+	MinShortfallReplicaHours float64 `json:"minShortfallReplicaHours"` // Reader-hours a static MinCapacity would have lacked
+	PeakCapacity             *int    `json:"peakCapacity,omitempty"`   // Most readers over the period, when known
+}
+
+// Savings computes the savings report of a cluster from since to until, from its records oldest first. The
+// capacity after each action holds until the next one, so that dry-run actions, which leave the readers
+// as they are, count too.
+func Savings(clusterID string, since, until time.Time, minCapacity, maxCapacity int, records []Record) SavingsReport {
+	report := SavingsReport{ClusterID: clusterID, Since: since, Until: until, MinCapacity: minCapacity, MaxCapacity: maxCapacity}
+	var capacity *int
+	var from time.Time
+	addSegment := func(to time.Time) {
+		if capacity == nil || !to.After(from) {
+			return
+		}
+		hours := to.Sub(from).Hours()
+		report.CoveredHours += hours
+		report.ReplicaHours += hours * float64(*capacity)
+		if *capacity > minCapacity {
+			report.MinShortfallHours += hours
+			report.MinShortfallReplicaHours += hours * float64(*capacity-minCapacity)
+		}
+	}
+	for _, record := range records {
+		if record.Capacity == nil || record.Time.Before(since) || !record.Time.Before(until) {
+			continue
+		}
+		addSegment(record.Time)
+		capacity, from = record.Capacity, record.Time
+		if report.PeakCapacity == nil || *capacity > *report.PeakCapacity {
+			peak := *capacity
+			report.PeakCapacity = &peak
+		}
+	}
+	addSegment(until)
+
+	report.StaticMaxReplicaHours = report.CoveredHours * float64(maxCapacity)
+	report.SavedReplicaHours = report.StaticMaxReplicaHours - report.ReplicaHours
+	if report.StaticMaxReplicaHours > 0 {
+		report.SavedPercent = 100 * report.SavedReplicaHours / report.StaticMaxReplicaHours
+	}
+	return report
+}
+
+// Message returns the notification message of the report.
+func (r SavingsReport) Message() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Savings report for cluster %s, %s to %s:\n", r.ClusterID, r.Since.UTC().Format(time.DateOnly), r.Until.UTC().Format(time.DateOnly))
+	if r.CoveredHours == 0 {
+		message.WriteString("- No capacity recorded in the period\n")
+		return message.String()
+	}
+	fmt.Fprintf(&message, "- Replica-hours: %.1f over %.1f hours\n", r.ReplicaHours, r.CoveredHours)
+	fmt.Fprintf(&message, "- Saved versus always running MaxCapacity (%d): %.1f replica-hours (%.0f%%)\n", r.MaxCapacity, r.SavedReplicaHours, r.SavedPercent)
+	fmt.Fprintf(&message, "- Above MinCapacity (%d): %.1f hours, %.1f replica-hours a static MinCapacity would have lacked\n", r.MinCapacity, r.MinShortfallHours, r.MinShortfallReplicaHours)
+	return message.String()
+}
+
+// ReportStore archives savings reports.
+type ReportStore interface {
+	SaveSavingsReport(ctx context.Context, report SavingsReport) error
+}
+
+// Ensure S3Store implements ReportStore
+var _ ReportStore = (*S3Store)(nil)
+
+// SaveSavingsReport writes the report at <prefix><cluster>/<YYYY-MM-DD>/savings-<unix nanoseconds>.json, by
+// the end of its period. Reports must be kept under another prefix than the audit log, as they are not
+// audit records.
+func (s *S3Store) SaveSavingsReport(ctx context.Context, report SavingsReport) error {
+	key := fmt.Sprintf("%ssavings-%d.json", s.dayPrefix(report.ClusterID, report.Until), report.Until.UnixNano())
+	if err := s.put(ctx, key, report); err != nil {
+		return fmt.Errorf("failed to write savings report %s: %w", key, err)
+	}
+	return nil
+}
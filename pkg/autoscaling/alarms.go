@@ -0,0 +1,191 @@
+package autoscaling
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// maxCompositeAlarmDepth bounds how deep nested composite alarms are resolved.
+const maxCompositeAlarmDepth = 3
+
+// alarmRuleChildPattern matches alarm references such as ALARM("name") or OK(arn:...) in a composite alarm rule.
+var alarmRuleChildPattern = regexp.MustCompile(`(?:ALARM|OK|INSUFFICIENT_DATA)\(\s*"?([^"()]+?)"?\s*\)`)
+
+// AlarmNotification is the subset of a CloudWatch alarm SNS notification used by the autoscaler.
+type AlarmNotification struct {
+	AlarmName     string       `json:"AlarmName"`
+	NewStateValue string       `json:"NewStateValue"`
+	AlarmRule     string       `json:"AlarmRule"` // Only present for composite alarms
+	Trigger       AlarmTrigger `json:"Trigger"`   // Only present for metric alarms
+}
+
+// AlarmTrigger describes the metric watched by a metric alarm.
+type AlarmTrigger struct {
+	MetricName string           `json:"MetricName"`
+	Dimensions []AlarmDimension `json:"Dimensions"`
+}
+
+// AlarmDimension is a metric dimension of an alarm notification.
+type AlarmDimension struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ClusterID returns the DBClusterIdentifier dimension of the alarm metric, if any.
+func (a *AlarmNotification) ClusterID() string {
+	if a == nil {
+		return ""
+	}
+	for _, dimension := range a.Trigger.Dimensions {
+		if dimension.Name == "DBClusterIdentifier" {
+			return dimension.Value
+		}
+	}
+	return ""
+}
+
+// IsComposite reports whether the notification was sent by a composite alarm.
+func (a *AlarmNotification) IsComposite() bool {
+	return a != nil && a.AlarmRule != ""
+}
+
+// parseAlarmRuleChildren extracts the child alarm names referenced by a composite alarm rule.
+func parseAlarmRuleChildren(alarmRule string) []string {
+	seen := map[string]bool{}
+	var children []string
+	for _, match := range alarmRuleChildPattern.FindAllStringSubmatch(alarmRule, -1) {
+		name := strings.TrimSpace(match[1])
+		// Children may be referenced by ARN, e.g. arn:aws:cloudwatch:region:account:alarm:name
+		if strings.HasPrefix(name, "arn:") {
+			if i := strings.Index(name, ":alarm:"); i >= 0 {
+				name = name[i+len(":alarm:"):]
+			}
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			children = append(children, name)
+		}
+	}
+	return children
+}
+
+// GetBreachedChildMetrics returns the metric names of the composite alarm's children that are in ALARM state.
+// Nested composite alarms are resolved up to maxCompositeAlarmDepth levels.
+func (d *DocumentDB) GetBreachedChildMetrics(ctx context.Context, alarmRule string) ([]string, error) {
+	return d.breachedChildMetrics(ctx, alarmRule, 0)
+}
+
+func (d *DocumentDB) breachedChildMetrics(ctx context.Context, alarmRule string, depth int) ([]string, error) {
+	childNames := parseAlarmRuleChildren(alarmRule)
+	if len(childNames) == 0 {
+		return nil, nil
+	}
+
+	output, err := d.CloudWatchClient.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{
+		AlarmNames: childNames,
+		AlarmTypes: []cwTypes.AlarmType{cwTypes.AlarmTypeMetricAlarm, cwTypes.AlarmTypeCompositeAlarm},
+	})
+	if err != nil {
+		d.Logger.Error("Failed to describe child alarms", "Error", err, "ChildAlarms", childNames)
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var metricNames []string
+	addMetric := func(metricName string) {
+		if !seen[metricName] {
+			seen[metricName] = true
+			metricNames = append(metricNames, metricName)
+		}
+	}
+
+	for _, alarm := range output.MetricAlarms {
+		if alarm.StateValue != cwTypes.StateValueAlarm {
+			continue
+		}
+		metricName := aws.ToString(alarm.MetricName)
+		if metricName == "" {
+			// Metric math alarms have no single metric to scale on
+			d.Logger.Warn("Skipping breached child alarm without a metric name", "AlarmName", aws.ToString(alarm.AlarmName))
+			continue
+		}
+		d.Logger.Info("Child alarm breached", "AlarmName", aws.ToString(alarm.AlarmName), "MetricName", metricName)
+		addMetric(metricName)
+	}
+
+	for _, alarm := range output.CompositeAlarms {
+		if alarm.StateValue != cwTypes.StateValueAlarm {
+			continue
+		}
+		if depth+1 >= maxCompositeAlarmDepth {
+			d.Logger.Warn("Skipping nested composite alarm beyond maximum depth", "AlarmName", aws.ToString(alarm.AlarmName))
+			continue
+		}
+		nested, err := d.breachedChildMetrics(ctx, aws.ToString(alarm.AlarmRule), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, metricName := range nested {
+			addMetric(metricName)
+		}
+	}
+
+	return metricNames, nil
+}
+
+// ExecuteCompositeAlarmScalingAction scales on the child metrics of the triggering composite alarm that breached.
+// When several children breached, the metric requiring the most capacity wins.
+func (d *DocumentDB) ExecuteCompositeAlarmScalingAction(ctx context.Context) error {
+	metricNames, err := d.GetBreachedChildMetrics(ctx, d.TriggerAlarm.AlarmRule)
+	if err != nil {
+		d.Logger.Error("Failed to resolve composite alarm children", "Error", err, "AlarmName", d.TriggerAlarm.AlarmName)
+		return err
+	}
+	if len(metricNames) == 0 {
+		d.Logger.Warn("No breached child alarms found, falling back to configured metric", "AlarmName", d.TriggerAlarm.AlarmName, "MetricName", d.MetricName)
+		metricNames = []string{d.MetricName}
+	}
+
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return err
+	}
+
+	desiredCapacity := 0
+	var (
+		drivingMetric string
+		drivingValue  float64
+	)
+	for _, metricName := range metricNames {
+		metricValue, err := d.getMetricValue(ctx, metricName)
+		if err != nil {
+			d.Logger.Error("Failed to retrieve metric value", "Error", err, "MetricName", metricName)
+			return err
+		}
+		targetValue := d.targetValueFor(metricName)
+		desired := d.calculateDesiredCapacity(metricValue, currentCapacity, targetValue)
+		d.Logger.Info("Evaluated child metric", "MetricName", metricName, "MetricValue", metricValue, "TargetValue", targetValue, "DesiredCapacity", desired)
+
+		if drivingMetric == "" || desired > desiredCapacity {
+			desiredCapacity = desired
+			drivingMetric = metricName
+			drivingValue = metricValue
+		}
+	}
+	d.Logger.Info("Calculated desired capacity from composite alarm", "AlarmName", d.TriggerAlarm.AlarmName, "DrivingMetric", drivingMetric, "DesiredCapacity", desiredCapacity)
+	d.recordReason(ReasonCompositeAlarm)
+
+	return d.applyObservation(ctx, Observation{
+		CurrentCapacity: currentCapacity,
+		DesiredCapacity: desiredCapacity,
+		MetricName:      drivingMetric,
+		MetricValue:     drivingValue,
+		TargetValue:     d.targetValueFor(drivingMetric),
+	})
+}
@@ -0,0 +1,43 @@
+package autoscaling
+
+import (
+	"context"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// recordAudit appends the outcome of the last scaling action to the audit log, when configured,
+// with the current capacity of the cluster. Failures are logged only.
+func (d *DocumentDB) recordAudit(ctx context.Context, actionErr error) {
+	if d.Audit == nil {
+		return
+	}
+	result := d.LastResult()
+	record := audit.Record{
+		Time:            time.Now().UTC(),
+		ClusterID:       d.ClusterID,
+		Decision:        result.Decision,
+		ReplicasAdded:   result.ReplicasAdded,
+		ReplicasRemoved: result.ReplicasRemoved,
+		DryRun:          d.DryRun,
+		Shadow:          d.Shadow,
+		CorrelationID:   correlation.FromContext(ctx),
+		MetricName:      result.metricName,
+		MetricValue:     result.metricValue,
+		DesiredCapacity: result.desiredCapacity,
+		Explanation:     result.Explanation,
+	}
+	if actionErr != nil {
+		record.Error = actionErr.Error()
+	}
+	if capacity, err := d.GetCurrentCapacity(ctx); err == nil {
+		record.Capacity = &capacity
+	} else {
+		d.Logger.Warn("Failed to retrieve current capacity for audit record", "Error", err)
+	}
+	if err := d.Audit.Append(ctx, record); err != nil {
+		d.Logger.Error("Failed to append audit record", "Error", err)
+	}
+}
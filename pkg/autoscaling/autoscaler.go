@@ -0,0 +1,27 @@
+package autoscaling
+
+import "context"
+
+// Autoscaler is the API of the autoscaler of a cluster, for programs embedding it as a library rather
+// than invoking the Lambda function, which is one of its consumers. Make one with New.
+type Autoscaler interface {
+	// Evaluate evaluates the cluster and takes the scaling action it calls for, as an invocation does.
+	Evaluate(ctx context.Context) (*ScalingResult, error)
+	// Plan plans the metric-based action of the cluster without changing it.
+	Plan(ctx context.Context) (*ScalingPlan, error)
+	// Apply carries out a plan, unless the readers of the cluster changed since it was made.
+	Apply(ctx context.Context, plan *ScalingPlan) (*ScalingResult, error)
+	// Status describes the readers of the cluster and the state of the autoscaler.
+	Status(ctx context.Context) (*ClusterStatus, error)
+}
+
+var _ Autoscaler = (*DocumentDB)(nil)
+
+// Evaluate evaluates the cluster and takes the scaling action it calls for: on the metric, the schedule
+// or the triggering alarm, after reconciling and removing orphaned replicas when configured. The result
+// is returned even when the action failed, with what was done. Failures are reported by ReportOutcome,
+// once any retries are done.
+func (d *DocumentDB) Evaluate(ctx context.Context) (*ScalingResult, error) {
+	err := d.ExecuteScalingAction(ctx)
+	return d.LastResult(), err
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -15,35 +16,96 @@ import (
 	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/docdb"
 	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
-	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/lock"
+	"github.com/cheelim1/docdb-autoscaler/pkg/metrics"
 	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
+	"golang.org/x/sync/errgroup"
 )
 
+// ErrTopologyChanged is returned when the cluster writer changes mid-invocation, e.g. after a failover.
+var ErrTopologyChanged = errors.New("cluster topology changed during scaling action")
+
 // DocumentDB represents the DocumentDB cluster configuration and state.
 type DocumentDB struct {
 	ClusterID              string
+	Engine                 Engine // Engine of the cluster, DocDBEngine when unset
 	MinCapacity            int
 	MaxCapacity            int
+	ManagedCapacity        bool // MinCapacity and MaxCapacity bound the replicas of the autoscaler, on top of the baseline readers it did not create
+	BootstrapCapacity      int  // Readers a metric-based scale-out brings a cluster without readers to, MinCapacity (at least 1) when 0
 	MetricName             string
 	TargetValue            float64
+	MetricTargets          map[string]float64 // Per-metric targets, e.g. for composite alarm children; falls back to TargetValue
+	MetricConcurrency      int                // Readers whose metric is fetched at the same time, DefaultMetricConcurrency when zero
+	CountableStatuses      []string           // Statuses of the readers counted in the capacity and the metric average, every status when empty
 	ScaleInCooldown        int
 	ScaleOutCooldown       int
 	InstanceType           string // Combined instance type and size, e.g., "db.r6g.large"
 	DryRun                 bool
+	Shadow                 bool // Record decisions as shadow decisions, and take cooldowns from them; implies DryRun
 	ScheduledScaling       bool
 	ScheduleNumberReplicas int
-
-	DocDBClient      DocDBAPI
-	CloudWatchClient CloudWatchAPI
-	RDSClient        RDSAPI
-	Notifier         notifications.NotifierInterface
-	Logger           *slog.Logger
-
-	// lastScaleInTime  time.Time
-	// lastScaleOutTime time.Time
+	SchedulePhase          string             // SchedulePhaseScaleOut or SchedulePhaseScaleIn, inferred from the scheduled replicas when empty
+	Holidays               *schedule.Calendar // Days scheduled scale-outs are skipped on, e.g. public holidays; scale-ins still happen
+	AllowZeroReaders       bool               // Permit removals that would leave the cluster with no readers
+	WriterOnlyBootstrap    bool               // Scale a cluster without readers on the metric of its writer, creating its first readers
+	TriggerAlarm           *AlarmNotification // Alarm that triggered this invocation, if any
+	ElasticScaleDimension  string             // ElasticShardCount (default) or ElasticShardCapacity, for elastic clusters
+	CountManagedReplicas   bool               // Report the replicas created by the autoscaler in Metrics, at the cost of a tag lookup per reader
+	EnforceCooldowns       bool               // Skip metric-based actions during the cooldowns, keeping the time of the last actions in cluster tags
+	TrackDesiredCapacity   bool               // Keep the desired capacity in a cluster tag, and restore missing readers on every invocation
+	RemoveOrphans          bool               // Remove the replicas of the autoscaler left failed or beyond MaxCapacity on every invocation
+	ReplaceFailedReplicas  bool               // Remove and recreate the failed replicas of the autoscaler on every invocation
+	CheckInstanceQuota     bool               // Cap scale-outs to the room left in the DB instances quota of the account
+	ManagedReplicaCap      int                // Hard cap on the replicas of the cluster created by the autoscaler or scheduled scaling, 0 disables
+	AccountReplicaCap      int                // Hard cap on the replicas of the engine in the account created by the autoscaler or scheduled scaling, 0 disables
+	BulkScaleInLimit       int                // Replicas a scale-in may remove without approval, 0 disables
+	AllowBulkScaleIn       bool               // Approve scale-ins beyond BulkScaleInLimit
+	MaxHourlyCost          float64            // Budget of the estimated hourly cost of the readers, in USD, capping scale-outs when Prices is set; 0 disables
+	CanaryScaleOut         bool               // Add a single replica first on metric-based scale-outs, and the rest once it is verified
+	CanaryWindow           time.Duration      // How long the canary replica serves before the metric is evaluated again, DefaultCanaryWindow when zero
+	DrainConnections       float64            // DatabaseConnections of a replica below which scale-ins delete it, 0 disables
+	DrainTimeout           time.Duration      // How long a scale-in waits for a replica to drain below DrainConnections
+	MaintenancePause       time.Duration      // How long a maintenance pauses autoscaling at most, DefaultMaintenancePause when zero
+	ScaleInStabilization   time.Duration      // How long metric-based scale-ins are held after any scale-out, whatever the cooldowns; 0 disables
+
+	DocDBClient       DocDBAPI
+	CloudWatchClient  CloudWatchAPI
+	RDSClient         RDSAPI
+	ElasticClient     DocDBElasticAPI // Optional; enables scaling of elastic clusters
+	SSMClient         SSMAPI          // Optional; reads PauseParameter
+	Notifier          notifications.NotifierInterface
+	Incidents         notifications.IncidentNotifier  // Optional; pages on-call when scaling actions keep failing
+	Events            notifications.LifecycleNotifier // Optional; publishes lifecycle events to an event bus
+	Tickets           notifications.TicketNotifier    // Optional; opens a ticket when scaling keeps failing
+	Audit             audit.Store                     // Optional; records the outcome of every action
+	Plans             audit.PlanStore                 // Optional; archives the plan of every dry-run action
+	Lock              lock.Locker                     // Optional; lets only one invocation scale the cluster at a time
+	Metrics           metrics.Recorder                // Optional; records metrics of every action
+	AWSErrors         *metrics.ErrorCounter           // Optional; counts the failed AWS calls reported in Metrics
+	TicketThreshold   int                             // Consecutive failed invocations before a ticket is opened
+	CircuitThreshold  int                             // Consecutive failed invocations before the circuit opens, 0 disables the circuit breaker
+	CircuitBackoff    time.Duration                   // How long the circuit stays open, DefaultCircuitBackoff when zero
+	ChurnBudgetHourly int                             // Instances created and deleted in an hour before scaling pauses, 0 disables
+	ChurnBudgetDaily  int                             // Instances created and deleted in a day before scaling pauses, 0 disables
+	DeadlineMargin    time.Duration                   // Time left before the deadline of ctx below which no replica is added or removed, DefaultDeadlineMargin when zero
+	PauseParameter    string                          // SSM parameter that, set to "true", pauses autoscaling, see pauseSwitch
+	Prices            pricing.Estimator               // Optional; estimates the cost delta of every action
+	Region            string                          // Region of the cluster, for the prices of its instances
+	Logger            *slog.Logger
+
+	lastResult            *ScalingResult
+	snapshot              *topologySnapshot
+	validatedInstanceType string // InstanceType once found orderable
+	baselineReaders       int    // Readers the autoscaler did not create, with ManagedCapacity, see loadBaselineReaders
 }
 
 // NewDocumentDB initializes a new DocumentDB instance.
+//
+// Deprecated: Use New with options, e.g. WithCapacity and WithMetric.
 func NewDocumentDB(
 	clusterID string,
 	minCapacity, maxCapacity int,
@@ -60,29 +122,50 @@ func NewDocumentDB(
 	logger *slog.Logger,
 	rdsClient RDSAPI,
 ) *DocumentDB {
-	return &DocumentDB{
-		ClusterID:              clusterID,
-		MinCapacity:            minCapacity,
-		MaxCapacity:            maxCapacity,
-		MetricName:             metricName,
-		TargetValue:            targetValue,
-		ScaleInCooldown:        scaleInCooldown,
-		ScaleOutCooldown:       scaleOutCooldown,
-		InstanceType:           instanceType,
-		DryRun:                 dryRun,
-		ScheduledScaling:       scheduledScaling,
-		ScheduleNumberReplicas: scheduleNumberReplicas,
-		DocDBClient:            docdbClient,
-		CloudWatchClient:       cloudwatchClient,
-		RDSClient:              rdsClient,
-		Notifier:               notifier,
-		Logger:                 logger,
-	}
+	d := newDocumentDB(clusterID,
+		WithClients(docdbClient, cloudwatchClient, rdsClient),
+		WithCapacity(minCapacity, maxCapacity),
+		WithMetric(metricName, targetValue),
+		WithCooldowns(scaleInCooldown, scaleOutCooldown),
+		WithInstanceType(instanceType),
+		WithDryRun(dryRun),
+		WithNotifier(notifier),
+		WithLogger(logger),
+	)
+	d.ScheduledScaling = scheduledScaling
+	d.ScheduleNumberReplicas = scheduleNumberReplicas
+	return d
 }
 
 // CalculateDesiredCapacity calculates the desired number of read replicas.
 func (d *DocumentDB) CalculateDesiredCapacity(currentMetricValue float64, currentCapacity int) int {
-	proportionalCapacity := (currentMetricValue / d.TargetValue) * float64(currentCapacity)
+	return d.calculateDesiredCapacity(currentMetricValue, currentCapacity, d.TargetValue)
+}
+
+// calculateDesiredCapacity calculates the desired number of read replicas for the given target value.
+func (d *DocumentDB) calculateDesiredCapacity(currentMetricValue float64, currentCapacity int, targetValue float64) int {
+	// Enforce minimum and maximum bounds
+	return d.clampCapacity(d.requestedCapacity(currentMetricValue, currentCapacity, targetValue))
+}
+
+// requestedCapacity calculates the number of read replicas the metric calls for, before the minimum and
+// maximum bounds. Any multiple of no readers is none, so a cluster without readers is scaled out to an
+// absolute capacity instead when the metric is above its target: BootstrapCapacity, or MinCapacity and at
+// least one reader when it is not set.
+func (d *DocumentDB) requestedCapacity(currentMetricValue float64, currentCapacity int, targetValue float64) int {
+	if currentCapacity > 0 || currentMetricValue <= targetValue {
+		return proportionalCapacity(currentMetricValue, currentCapacity, targetValue)
+	}
+	if d.BootstrapCapacity > 0 {
+		return d.BootstrapCapacity
+	}
+	return max(d.MinCapacity, 1)
+}
+
+// proportionalCapacity calculates the number of read replicas bringing the metric to the target value,
+// before the minimum and maximum bounds.
+func proportionalCapacity(currentMetricValue float64, currentCapacity int, targetValue float64) int {
+	proportionalCapacity := (currentMetricValue / targetValue) * float64(currentCapacity)
 	var desiredCapacity float64
 
 	if proportionalCapacity > float64(currentCapacity) {
@@ -92,66 +175,79 @@ func (d *DocumentDB) CalculateDesiredCapacity(currentMetricValue float64, curren
 		// Scaling In: Round down to reduce replicas conservatively
 		desiredCapacity = math.Floor(proportionalCapacity)
 	}
+	return int(desiredCapacity)
+}
 
-	// Enforce minimum and maximum bounds
-	if desiredCapacity < float64(d.MinCapacity) {
-		desiredCapacity = float64(d.MinCapacity)
-	} else if desiredCapacity > float64(d.MaxCapacity) {
-		desiredCapacity = float64(d.MaxCapacity)
+// clampCapacity bounds a reader count to MinCapacity and MaxCapacity, on top of the baseline readers with
+// ManagedCapacity.
+func (d *DocumentDB) clampCapacity(capacity int) int {
+	if capacity < d.minReaders() {
+		return d.minReaders()
+	} else if capacity > d.maxReaders() {
+		return d.maxReaders()
 	}
+	return capacity
+}
 
-	return int(desiredCapacity)
+// targetValueFor returns the target value configured for metricName, falling back to TargetValue.
+func (d *DocumentDB) targetValueFor(metricName string) float64 {
+	if target, ok := d.MetricTargets[metricName]; ok {
+		return target
+	}
+	return d.TargetValue
 }
 
+// DefaultMetricConcurrency is how many reader metrics are fetched at the same time by default.
+const DefaultMetricConcurrency = 10
+
 // GetCurrentMetricValue retrieves the current value of the specified CloudWatch metric, considering only reader instances.
 func (d *DocumentDB) GetCurrentMetricValue(ctx context.Context) (float64, error) {
-	// Step 1: Get all reader instances
-	readerInstances, err := d.GetReaderInstances(ctx)
+	return d.getMetricValue(ctx, d.MetricName)
+}
+
+// getMetricValue retrieves the average of metricName across reader instances.
+func (d *DocumentDB) getMetricValue(ctx context.Context, metricName string) (float64, error) {
+	// Step 1: Get all reader instances in a countable status
+	readerInstances, err := d.countableReaders(ctx)
 	if err != nil {
 		return 0, err
 	}
 
 	if len(readerInstances) == 0 {
-		return 0, errors.New("no reader instances found")
-	}
-
-	var totalMetric float64
-	for _, instance := range readerInstances {
-		// Step 2: Fetch metric for each reader instance
-		input := &cloudwatch.GetMetricStatisticsInput{
-			Namespace:  aws.String("AWS/DocDB"),
-			MetricName: aws.String(d.MetricName),
-			Dimensions: []cwTypes.Dimension{
-				{
-					Name:  aws.String("DBInstanceIdentifier"),
-					Value: instance.DBInstanceIdentifier,
-				},
-			},
-			StartTime:  aws.Time(time.Now().Add(-5 * time.Minute)),
-			EndTime:    aws.Time(time.Now()),
-			Period:     aws.Int32(300), // 5 minutes
-			Statistics: []cwTypes.Statistic{cwTypes.StatisticAverage},
-		}
-
-		resp, err := d.CloudWatchClient.GetMetricStatistics(ctx, input)
-		if err != nil {
-			d.Logger.Error("Failed to get metric statistics", "Error", err, "InstanceID", aws.ToString(instance.DBInstanceIdentifier))
-			return 0, err
+		if d.WriterOnlyBootstrap {
+			noReaders, err := d.hasNoReaders(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if noReaders {
+				return d.getWriterMetricValue(ctx, metricName)
+			}
 		}
-
-		if len(resp.Datapoints) == 0 {
-			d.Logger.Error("No datapoints found for instance", "InstanceID", aws.ToString(instance.DBInstanceIdentifier))
-			return 0, fmt.Errorf("no datapoints found for instance %s", aws.ToString(instance.DBInstanceIdentifier))
+		if len(d.CountableStatuses) > 0 {
+			return 0, fmt.Errorf("no reader instances found in the countable statuses %s", strings.Join(d.CountableStatuses, ", "))
 		}
+		return 0, errors.New("no reader instances found")
+	}
 
-		// Sort datapoints by timestamp
-		sort.Slice(resp.Datapoints, func(i, j int) bool {
-			return resp.Datapoints[i].Timestamp.Before(*resp.Datapoints[j].Timestamp)
+	// Step 2: Fetch metric for each reader instance, a few at a time
+	values := make([]float64, len(readerInstances))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(d.metricConcurrency())
+	for i, instance := range readerInstances {
+		i, instanceID := i, aws.ToString(instance.DBInstanceIdentifier)
+		group.Go(func() error {
+			value, err := d.getInstanceMetricValue(groupCtx, metricName, instanceID)
+			values[i] = value
+			return err
 		})
+	}
+	if err := group.Wait(); err != nil {
+		return 0, err
+	}
 
-		// Use the latest datapoint
-		latestDatapoint := resp.Datapoints[len(resp.Datapoints)-1]
-		totalMetric += aws.ToFloat64(latestDatapoint.Average)
+	var totalMetric float64
+	for _, value := range values {
+		totalMetric += value
 	}
 
 	// Step 3: Calculate average across readers
@@ -159,9 +255,79 @@ func (d *DocumentDB) GetCurrentMetricValue(ctx context.Context) (float64, error)
 	return averageMetric, nil
 }
 
-// GetReaderInstances retrieves all reader instances in the cluster.
-func (d *DocumentDB) GetReaderInstances(ctx context.Context) ([]docdbTypes.DBInstance, error) {
-	// Get all instances in the cluster
+// hasNoReaders reports whether the cluster runs its writer only, with no reader in any status: readers
+// being created do not make room for more.
+func (d *DocumentDB) hasNoReaders(ctx context.Context) (bool, error) {
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(readerInstances) == 0, nil
+}
+
+// getWriterMetricValue retrieves the latest average of metricName for the writer of a cluster without
+// readers, which serves the reads until its first readers are created.
+func (d *DocumentDB) getWriterMetricValue(ctx context.Context, metricName string) (float64, error) {
+	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		return 0, err
+	}
+	d.Logger.Info("No reader instances found, scaling on the metric of the writer", "WriterInstanceID", writerInstanceIdentifier, "MetricName", metricName, "ClusterID", d.ClusterID)
+	return d.getInstanceMetricValue(ctx, metricName, writerInstanceIdentifier)
+}
+
+// getInstanceMetricValue retrieves the latest average of metricName for a single instance.
+func (d *DocumentDB) getInstanceMetricValue(ctx context.Context, metricName, instanceID string) (float64, error) {
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(d.engine().MetricNamespace),
+		MetricName: aws.String(metricName),
+		Dimensions: []cwTypes.Dimension{
+			{
+				Name:  aws.String("DBInstanceIdentifier"),
+				Value: aws.String(instanceID),
+			},
+		},
+		StartTime:  aws.Time(time.Now().Add(-5 * time.Minute)),
+		EndTime:    aws.Time(time.Now()),
+		Period:     aws.Int32(300), // 5 minutes
+		Statistics: []cwTypes.Statistic{cwTypes.StatisticAverage},
+	}
+
+	resp, err := d.CloudWatchClient.GetMetricStatistics(ctx, input)
+	if err != nil {
+		d.Logger.Error("Failed to get metric statistics", "Error", err, "InstanceID", instanceID)
+		return 0, err
+	}
+
+	if len(resp.Datapoints) == 0 {
+		d.Logger.Error("No datapoints found for instance", "InstanceID", instanceID)
+		return 0, fmt.Errorf("no datapoints found for instance %s", instanceID)
+	}
+
+	// Sort datapoints by timestamp
+	sort.Slice(resp.Datapoints, func(i, j int) bool {
+		return resp.Datapoints[i].Timestamp.Before(*resp.Datapoints[j].Timestamp)
+	})
+
+	// Use the latest datapoint
+	latestDatapoint := resp.Datapoints[len(resp.Datapoints)-1]
+	return aws.ToFloat64(latestDatapoint.Average), nil
+}
+
+// metricConcurrency returns how many reader metrics are fetched at the same time.
+func (d *DocumentDB) metricConcurrency() int {
+	if d.MetricConcurrency <= 0 {
+		return DefaultMetricConcurrency
+	}
+	return d.MetricConcurrency
+}
+
+// describeInstances retrieves all instances in the cluster, following the pages of large fleets. During
+// an action, the instances are described once and read from the topology snapshot afterwards.
+func (d *DocumentDB) describeInstances(ctx context.Context) ([]docdbTypes.DBInstance, error) {
+	if d.snapshot != nil && d.snapshot.instancesLoaded {
+		return d.snapshot.instances, nil
+	}
 	describeInstancesInput := &docdb.DescribeDBInstancesInput{
 		Filters: []docdbTypes.Filter{
 			{
@@ -170,12 +336,30 @@ func (d *DocumentDB) GetReaderInstances(ctx context.Context) ([]docdbTypes.DBIns
 			},
 		},
 	}
-	dbInstancesOutput, err := d.DocDBClient.DescribeDBInstances(ctx, describeInstancesInput)
+	var dbInstances []docdbTypes.DBInstance
+	paginator := docdb.NewDescribeDBInstancesPaginator(d.DocDBClient, describeInstancesInput)
+	for paginator.HasMorePages() {
+		dbInstancesOutput, err := paginator.NextPage(ctx)
+		if err != nil {
+			d.Logger.Error("Failed to describe DB instances", "Error", err)
+			return nil, err
+		}
+		dbInstances = append(dbInstances, dbInstancesOutput.DBInstances...)
+	}
+	if d.snapshot != nil {
+		d.snapshot.instances = dbInstances
+		d.snapshot.instancesLoaded = true
+	}
+	return dbInstances, nil
+}
+
+// GetReaderInstances retrieves all reader instances in the cluster.
+func (d *DocumentDB) GetReaderInstances(ctx context.Context) ([]docdbTypes.DBInstance, error) {
+	// Get all instances in the cluster
+	dbInstances, err := d.describeInstances(ctx)
 	if err != nil {
-		d.Logger.Error("Failed to describe DB instances", "Error", err)
 		return nil, err
 	}
-	dbInstances := dbInstancesOutput.DBInstances
 
 	// Get the writer instance identifier
 	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
@@ -195,14 +379,48 @@ func (d *DocumentDB) GetReaderInstances(ctx context.Context) ([]docdbTypes.DBIns
 	return readerInstances, nil
 }
 
-// GetCurrentCapacity calculates the current number of reader instances in the cluster.
-func (d *DocumentDB) GetCurrentCapacity(ctx context.Context) (int, error) {
+// countableReaders retrieves the reader instances counted in the capacity and the metric average: those in
+// one of CountableStatuses, or all of them when it is empty. Readers being created or rebooting have no
+// datapoints yet, or skew the average.
+func (d *DocumentDB) countableReaders(ctx context.Context) ([]docdbTypes.DBInstance, error) {
 	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil || len(d.CountableStatuses) == 0 {
+		return readerInstances, err
+	}
+	var countable []docdbTypes.DBInstance
+	for _, instance := range readerInstances {
+		if d.isCountable(instance) {
+			countable = append(countable, instance)
+			continue
+		}
+		d.Logger.Info("Not counting reader in a status outside the countable statuses", "InstanceID", aws.ToString(instance.DBInstanceIdentifier), "Status", aws.ToString(instance.DBInstanceStatus), "CountableStatuses", d.CountableStatuses)
+	}
+	return countable, nil
+}
+
+// isCountable reports whether a reader is counted in the capacity: when it is in one of CountableStatuses,
+// or always when it is empty.
+func (d *DocumentDB) isCountable(instance docdbTypes.DBInstance) bool {
+	status := aws.ToString(instance.DBInstanceStatus)
+	return len(d.CountableStatuses) == 0 || slices.ContainsFunc(d.CountableStatuses, func(countableStatus string) bool { return strings.EqualFold(countableStatus, status) })
+}
+
+// GetCurrentCapacity calculates the current number of reader instances in the cluster, in a countable status.
+func (d *DocumentDB) GetCurrentCapacity(ctx context.Context) (int, error) {
+	readerInstances, err := d.countableReaders(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return 0, err
+	}
+
 	capacity := len(readerInstances)
+	if d.ManagedCapacity {
+		d.Logger.Info("Retrieved current capacity", "CurrentCapacity", capacity, "BaselineReaders", d.baselineReaders)
+		return capacity, nil
+	}
 	d.Logger.Info("Retrieved current capacity", "CurrentCapacity", capacity)
 	return capacity, nil
 }
@@ -210,18 +428,10 @@ func (d *DocumentDB) GetCurrentCapacity(ctx context.Context) (int, error) {
 // GetWriterInstanceIdentifier retrieves the identifier of the writer (primary) instance.
 func (d *DocumentDB) GetWriterInstanceIdentifier(ctx context.Context) (string, error) {
 	// Get cluster details
-	describeClustersInput := &rds.DescribeDBClustersInput{
-		DBClusterIdentifier: aws.String(d.ClusterID),
-	}
-	dbClustersOutput, err := d.RDSClient.DescribeDBClusters(ctx, describeClustersInput)
+	dbCluster, err := d.describeCluster(ctx)
 	if err != nil {
-		d.Logger.Error("Failed to describe DB clusters", "Error", err)
 		return "", err
 	}
-	if len(dbClustersOutput.DBClusters) == 0 {
-		return "", fmt.Errorf("no clusters found with identifier %s", d.ClusterID)
-	}
-	dbCluster := dbClustersOutput.DBClusters[0]
 
 	// Find the writer instance identifier
 	for _, member := range dbCluster.DBClusterMembers {
@@ -230,26 +440,25 @@ func (d *DocumentDB) GetWriterInstanceIdentifier(ctx context.Context) (string, e
 		}
 	}
 
+	// Secondary members of a global cluster have no writer, they use a reference reader instead
+	role, err := d.GetGlobalClusterRole(ctx, dbCluster)
+	if err != nil {
+		return "", err
+	}
+	if role == GlobalClusterRoleSecondary {
+		return d.getReferenceInstanceIdentifier(ctx)
+	}
+
 	return "", fmt.Errorf("writer instance not found in cluster %s", d.ClusterID)
 }
 
 // GetWriterInstance retrieves the writer (primary) DB instance.
 func (d *DocumentDB) GetWriterInstance(ctx context.Context) (*docdbTypes.DBInstance, error) {
 	// Get all instances in the cluster
-	describeInstancesInput := &docdb.DescribeDBInstancesInput{
-		Filters: []docdbTypes.Filter{
-			{
-				Name:   aws.String("db-cluster-id"),
-				Values: []string{d.ClusterID},
-			},
-		},
-	}
-	dbInstancesOutput, err := d.DocDBClient.DescribeDBInstances(ctx, describeInstancesInput)
+	dbInstances, err := d.describeInstances(ctx)
 	if err != nil {
-		d.Logger.Error("Failed to describe DB instances", "Error", err)
 		return nil, err
 	}
-	dbInstances := dbInstancesOutput.DBInstances
 
 	// Get the writer instance identifier
 	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
@@ -266,22 +475,105 @@ func (d *DocumentDB) GetWriterInstance(ctx context.Context) (*docdbTypes.DBInsta
 	return nil, fmt.Errorf("writer instance not found")
 }
 
+// verifyWriterUnchanged re-reads the writer identity right before a destructive operation.
+// It fails if a failover moved the writer away from expectedWriter or onto the target instance.
+func (d *DocumentDB) verifyWriterUnchanged(ctx context.Context, expectedWriter, targetInstanceID string) error {
+	// The writer is re-read from AWS, not from the topology snapshot
+	d.invalidateSnapshot()
+	currentWriter, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to re-verify writer instance identifier", "Error", err)
+		return err
+	}
+	if currentWriter != expectedWriter || currentWriter == targetInstanceID {
+		d.Logger.Error("Cluster topology changed, aborting destructive operation", "ExpectedWriter", expectedWriter, "CurrentWriter", currentWriter, "InstanceID", targetInstanceID)
+		return fmt.Errorf("%w: writer changed from %s to %s", ErrTopologyChanged, expectedWriter, currentWriter)
+	}
+	return nil
+}
+
+// verifyBeforeDelete re-verifies a replica right before it is deleted, with fresh describe and tag calls
+// rather than the topology the removal was planned on: the writer must be unchanged and not the replica,
+// and the replica must still belong to the cluster and carry the autoscaler or scheduler tag.
+func (d *DocumentDB) verifyBeforeDelete(ctx context.Context, expectedWriter string, instance docdbTypes.DBInstance) error {
+	instanceID := aws.ToString(instance.DBInstanceIdentifier)
+	if err := d.verifyWriterUnchanged(ctx, expectedWriter, instanceID); err != nil {
+		return err
+	}
+
+	output, err := d.DocDBClient.DescribeDBInstances(ctx, &docdb.DescribeDBInstancesInput{
+		Filters: []docdbTypes.Filter{
+			{Name: aws.String("db-cluster-id"), Values: []string{d.ClusterID}},
+			{Name: aws.String("db-instance-id"), Values: []string{instanceID}},
+		},
+	})
+	if err != nil {
+		d.Logger.Error("Failed to re-verify replica before deletion", "Error", err, "InstanceID", instanceID)
+		return err
+	}
+	index := slices.IndexFunc(output.DBInstances, func(current docdbTypes.DBInstance) bool {
+		return aws.ToString(current.DBInstanceIdentifier) == instanceID
+	})
+	if index < 0 {
+		d.Logger.Error("Replica no longer belongs to the cluster, aborting deletion", "InstanceID", instanceID, "ClusterID", d.ClusterID)
+		return fmt.Errorf("%w: %s is no longer a member of %s", ErrTopologyChanged, instanceID, d.ClusterID)
+	}
+
+	tags, err := d.DocDBClient.ListTagsForResource(ctx, &docdb.ListTagsForResourceInput{ResourceName: output.DBInstances[index].DBInstanceArn})
+	if err != nil {
+		d.Logger.Error("Failed to re-verify replica tags before deletion", "Error", err, "InstanceID", instanceID)
+		return err
+	}
+	if !slices.ContainsFunc(tags.TagList, func(tag docdbTypes.Tag) bool {
+		return slices.Contains(managedTagKeys, aws.ToString(tag.Key)) && aws.ToString(tag.Value) == "true"
+	}) {
+		d.Logger.Error("Replica is no longer tagged as managed by the autoscaler, aborting deletion", "InstanceID", instanceID, "ClusterID", d.ClusterID)
+		return fmt.Errorf("%w: %s is no longer tagged as managed by the autoscaler", ErrTopologyChanged, instanceID)
+	}
+	return nil
+}
+
+// canRemoveReader reports whether removing one reader keeps the cluster at or above its reader floor.
+// The floor is MinCapacity, on top of the baseline readers with ManagedCapacity, and never zero readers
+// unless AllowZeroReaders is set.
+func (d *DocumentDB) canRemoveReader(currentReaders int) bool {
+	remainingReaders := currentReaders - 1
+	if remainingReaders < d.minReaders() {
+		return false
+	}
+	if remainingReaders <= 0 && !d.AllowZeroReaders {
+		return false
+	}
+	return true
+}
+
 // HasAutoscalerTag checks if the instance has the autoscaler-created tag.
 func (d *DocumentDB) HasAutoscalerTag(ctx context.Context, instance docdbTypes.DBInstance) (bool, error) {
+	tags, err := d.instanceTags(ctx, instance)
+	if err != nil {
+		return false, err
+	}
+	return hasCreatedTag(tags), nil
+}
+
+// instanceTags returns the tags of an instance.
+func (d *DocumentDB) instanceTags(ctx context.Context, instance docdbTypes.DBInstance) ([]docdbTypes.Tag, error) {
 	input := &docdb.ListTagsForResourceInput{
 		ResourceName: instance.DBInstanceArn,
 	}
 	output, err := d.DocDBClient.ListTagsForResource(ctx, input)
 	if err != nil {
 		d.Logger.Error("Failed to list tags for resource", "Error", err, "ResourceName", aws.ToString(instance.DBInstanceArn))
-		return false, err
-	}
-	for _, tag := range output.TagList {
-		if aws.ToString(tag.Key) == "docdb-autoscaler-created" && aws.ToString(tag.Value) == "true" {
-			return true, nil
-		}
+		return nil, err
 	}
-	return false, nil
+	return output.TagList, nil
+}
+
+// hasCreatedTag reports whether tags hold the autoscaler-created tag.
+func hasCreatedTag(tags []docdbTypes.Tag) bool {
+	return slices.ContainsFunc(tags, func(tag docdbTypes.Tag) bool {
+		return aws.ToString(tag.Key) == "docdb-autoscaler-created" && aws.ToString(tag.Value) == "true"
+	})
 }
 
 // AddReplicas adds the specified number of read replicas.
@@ -291,22 +583,27 @@ func (d *DocumentDB) AddReplicas(ctx context.Context, replicasToAdd int) error {
 		d.Logger.Error("Failed to get writer instance", "Error", err)
 		return err
 	}
+	if err := d.validateInstanceType(ctx); err != nil {
+		return err
+	}
+	// The replicas with room are added before a cap error fails the action
+	replicasToAdd, capErr := d.capToManagedReplicas(ctx, replicasToAdd)
+	if capErr != nil && !errors.Is(capErr, ErrManagedReplicaCap) {
+		return capErr
+	}
+	replicasToAdd = d.capToInstanceQuota(ctx, replicasToAdd)
+	replicaClass := d.InstanceType
+	if replicaClass == "" {
+		replicaClass = aws.ToString(writerInstance.DBInstanceClass)
+	}
+	replicasToAdd = d.capToBudget(ctx, replicasToAdd, replicaClass)
 
 	for i := 0; i < replicasToAdd; i++ {
-		// Generate a shorter unique identifier
-		timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
-		uniqueID := timestamp[len(timestamp)-9:] // Use last 9 digits to ensure uniqueness and keep length short
-
-		baseIdentifier := fmt.Sprintf("%s-reader-%s", d.ClusterID, uniqueID)
-		// Ensure the identifier is no more than 63 characters
-		if len(baseIdentifier) > 63 {
-			baseIdentifier = baseIdentifier[:63]
-			// Ensure it doesn't end with a hyphen
-			baseIdentifier = strings.TrimRight(baseIdentifier, "-")
+		if d.stopBeforeDeadline(ctx, replicasToAdd-i) {
+			break
 		}
 
-		// Ensure identifier starts with a letter and contains only allowed characters
-		baseIdentifier = sanitizeDBInstanceIdentifier(baseIdentifier)
+		baseIdentifier := d.replicaIdentifier("reader", timestampSuffix())
 
 		// Determine the DBInstanceClass based on INSTANCE_TYPE environment variable
 		var instanceClass *string
@@ -320,12 +617,13 @@ func (d *DocumentDB) AddReplicas(ctx context.Context, replicasToAdd int) error {
 			DBClusterIdentifier:  aws.String(d.ClusterID),
 			DBInstanceClass:      instanceClass,
 			DBInstanceIdentifier: aws.String(baseIdentifier),
-			Engine:               aws.String("docdb"), // Required field
-			PromotionTier:        aws.Int32(15),       // Set PromotionTier to 15
+			Engine:               aws.String(d.engine().Name), // Required field
+			PromotionTier:        aws.Int32(15),               // Set PromotionTier to 15
 		}
 
 		if !d.DryRun {
-			result, err := d.DocDBClient.CreateDBInstance(ctx, input)
+			result, err := d.createReplica(ctx, input, "reader")
+			baseIdentifier = aws.ToString(input.DBInstanceIdentifier)
 			if err != nil {
 				d.Logger.Error("Failed to add replicas", "Error", fmt.Sprintf("failed to create DB instance %s: %v", baseIdentifier, err), "ReplicasToAdd", replicasToAdd-i)
 				return err
@@ -355,13 +653,16 @@ func (d *DocumentDB) AddReplicas(ctx context.Context, replicasToAdd int) error {
 				d.Logger.Error("Failed to tag new read replica", "Error", err, "InstanceID", baseIdentifier)
 				// Optionally handle this error
 			}
+			d.invalidateSnapshot()
 			d.Logger.Info("Added read replica", "ClusterID", d.ClusterID, "InstanceID", baseIdentifier)
+			d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaCreated, InstanceID: baseIdentifier, InstanceClass: aws.ToString(instanceClass)})
 		} else {
 			d.Logger.Info("[Dry Run] Would add read replica", "ClusterID", d.ClusterID, "InstanceID", baseIdentifier)
 		}
+		d.recordAdded(baseIdentifier, aws.ToString(instanceClass))
 	}
 
-	return nil
+	return capErr
 }
 
 // sanitizeDBInstanceIdentifier ensures the DBInstanceIdentifier complies with AWS constraints.
@@ -399,21 +700,16 @@ func isValidDBInstanceIdentifierChar(ch rune) bool {
 
 // RemoveReplica removes a single read replica added by the autoscaler.
 func (d *DocumentDB) RemoveReplica(ctx context.Context) error {
+	return d.RemoveReplicas(ctx, 1)
+}
+
+// RemoveReplicas removes up to replicasToRemove read replicas added by the autoscaler.
+func (d *DocumentDB) RemoveReplicas(ctx context.Context, replicasToRemove int) error {
 	// Get all instances in the cluster
-	describeInstancesInput := &docdb.DescribeDBInstancesInput{
-		Filters: []docdbTypes.Filter{
-			{
-				Name:   aws.String("db-cluster-id"),
-				Values: []string{d.ClusterID},
-			},
-		},
-	}
-	dbInstancesOutput, err := d.DocDBClient.DescribeDBInstances(ctx, describeInstancesInput)
+	dbInstances, err := d.describeInstances(ctx)
 	if err != nil {
-		d.Logger.Error("Failed to describe DB instances", "Error", err)
 		return err
 	}
-	dbInstances := dbInstancesOutput.DBInstances
 
 	// Get the writer instance identifier
 	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
@@ -422,59 +718,246 @@ func (d *DocumentDB) RemoveReplica(ctx context.Context) error {
 		return err
 	}
 
+	// Count readers to enforce the reader floor regardless of what the capacity calculation decided, as it
+	// counts them, so that the readers not serving yet do not make up for those removed
+	readers, err := d.countableReaders(ctx)
+	if err != nil {
+		return err
+	}
+	readerCount := len(readers)
+
 	// Find instances to remove
-	var instanceToRemove *docdbTypes.DBInstance
+	removed := 0
 	for _, instance := range dbInstances {
+		if removed >= replicasToRemove {
+			break
+		}
+
 		instanceID := aws.ToString(instance.DBInstanceIdentifier)
 		if instanceID == writerInstanceIdentifier {
 			continue // Skip the writer instance
 		}
 
 		// Check if the instance has the autoscaler tag
-		hasTag, err := d.HasAutoscalerTag(ctx, instance)
+		tags, err := d.instanceTags(ctx, instance)
 		if err != nil {
 			d.Logger.Error("Failed to check autoscaler tag", "Error", err, "InstanceID", instanceID)
 			continue
 		}
+		hasTag := hasCreatedTag(tags)
 
-		// Check if the instance is in 'available' state
-		if aws.ToString(instance.DBInstanceStatus) != "available" {
-			d.Logger.Info("Instance is not in 'available' state, skipping", "InstanceID", instanceID, "Status", aws.ToString(instance.DBInstanceStatus))
+		// Skip the instances that cannot be deleted now, another candidate is removed instead
+		if blocker := removalBlocker(instance); blocker != "" {
+			d.Logger.Info("Instance cannot be removed now, skipping", "InstanceID", instanceID, "Reason", blocker)
 			continue
 		}
 
-		if hasTag {
-			// Found an instance to remove
-			instanceToRemove = &instance
-			break // Remove only one instance per invocation
+		if !hasTag {
+			continue
 		}
+
+		// Prescaled replicas serve the peak they were added for until they expire
+		if expiresAt, prescaled := prescaleExpiry(tags); prescaled && expiresAt.After(time.Now()) {
+			d.Logger.Info("Prescaled replica has not expired, skipping", "InstanceID", instanceID, "ExpiresAt", expiresAt)
+			d.recordConstraint(ConstraintPrescale)
+			continue
+		}
+
+		if !d.canRemoveReader(readerCount) {
+			d.Logger.Warn("Refusing to remove reader below the reader floor", "CurrentReaders", readerCount, "MinCapacity", d.MinCapacity, "AllowZeroReaders", d.AllowZeroReaders)
+			d.recordConstraint(ConstraintReaderFloor)
+			break
+		}
+
+		if d.stopBeforeDeadline(ctx, replicasToRemove-removed) {
+			break
+		}
+
+		// Remove the instance
+		if !d.DryRun {
+			// Skip the replicas still serving many clients, another candidate is removed instead
+			drained, err := d.waitForDrain(ctx, instanceID)
+			if err != nil {
+				return err
+			}
+			if !drained {
+				d.recordConstraint(ConstraintConnectionDrain)
+				continue
+			}
+
+			// Guard against a failover, or a replica changed, since the topology was read
+			if err := d.verifyBeforeDelete(ctx, writerInstanceIdentifier, instance); err != nil {
+				return err
+			}
+
+			deleteInput := &docdb.DeleteDBInstanceInput{
+				DBInstanceIdentifier: instance.DBInstanceIdentifier,
+			}
+			_, err = d.DocDBClient.DeleteDBInstance(ctx, deleteInput)
+			if isDeletionRefused(err) {
+				d.Logger.Warn("Deletion of read replica was refused, trying another candidate", "Error", err, "InstanceID", instanceID)
+				continue
+			}
+			if err != nil {
+				d.Logger.Error("Failed to delete read replica", "Error", err, "InstanceID", instanceID)
+				return err
+			}
+			d.invalidateSnapshot()
+			d.Logger.Info("Removed read replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+			d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaDeleted, InstanceID: instanceID, InstanceClass: aws.ToString(instance.DBInstanceClass)})
+		} else {
+			d.Logger.Info("[Dry Run] Would remove read replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+		}
+		d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+		if d.isCountable(instance) {
+			readerCount--
+		}
+		removed++
 	}
 
-	if instanceToRemove == nil {
+	if removed == 0 {
 		d.Logger.Info("No autoscaler-created instances found to remove")
-		return nil // Nothing to remove
 	}
 
-	// Remove the instance
-	if !d.DryRun {
-		deleteInput := &docdb.DeleteDBInstanceInput{
-			DBInstanceIdentifier: instanceToRemove.DBInstanceIdentifier,
-		}
-		_, err := d.DocDBClient.DeleteDBInstance(ctx, deleteInput)
+	return nil
+}
+
+// ScaleToCapacity adds or removes autoscaler-created replicas until the cluster has desiredCapacity readers.
+// The target is bounded by MinCapacity and MaxCapacity. The outcome is available afterwards via LastResult.
+func (d *DocumentDB) ScaleToCapacity(ctx context.Context, desiredCapacity int) error {
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.beginSnapshot()
+	defer d.endSnapshot()
+
+	release, locked, err := d.acquireLock(ctx)
+	if err != nil || locked {
+		return err
+	}
+	defer release()
+
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
 		if err != nil {
-			d.Logger.Error("Failed to delete read replica", "Error", err, "InstanceID", aws.ToString(instanceToRemove.DBInstanceIdentifier))
 			return err
 		}
-		d.Logger.Info("Removed read replica", "ClusterID", d.ClusterID, "InstanceID", aws.ToString(instanceToRemove.DBInstanceIdentifier))
+		return d.scaleElasticToCapacity(ctx, cluster, desiredCapacity)
+	}
+
+	if paused, err := d.skipIfPaused(ctx); err != nil || paused {
+		return err
+	}
+	if open, err := d.skipIfCircuitOpen(ctx); err != nil || open {
+		return err
+	}
+	if exhausted, err := d.skipIfChurnExceeded(ctx); err != nil || exhausted {
+		return err
+	}
+
+	d.recordReason(ReasonRequestedCapacity)
+	// The current capacity loads the baseline readers the bounds sit on top of with ManagedCapacity
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return err
+	}
+	boundedCapacity := d.clampCapacity(desiredCapacity)
+	d.recordBounds(desiredCapacity, boundedCapacity)
+	if boundedCapacity != desiredCapacity {
+		d.Logger.Warn("Desired capacity adjusted to MIN_CAPACITY/MAX_CAPACITY bounds", "RequestedCapacity", desiredCapacity, "DesiredCapacity", boundedCapacity)
+	}
+	if boundedCapacity != currentCapacity {
+		d.saveDesiredCapacity(ctx, boundedCapacity)
+	}
+	return d.convergeCapacity(ctx, currentCapacity, boundedCapacity)
+}
+
+// convergeCapacity adds or removes autoscaler-created replicas until the cluster has boundedCapacity readers.
+func (d *DocumentDB) convergeCapacity(ctx context.Context, currentCapacity, boundedCapacity int) error {
+	d.recordCapacity(currentCapacity, boundedCapacity)
+
+	if boundedCapacity > currentCapacity {
+		replicasToAdd := boundedCapacity - currentCapacity
+		d.Logger.Info("Scaling Out to desired capacity", "ReplicasToAdd", replicasToAdd, "DesiredCapacity", boundedCapacity, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleOut)
+		before := d.captureTopology(ctx)
+
+		if err := d.AddReplicas(ctx, replicasToAdd); err != nil {
+			d.Logger.Error("Failed to add replicas", "Error", err, "ReplicasToAdd", replicasToAdd)
+			return err
+		}
+		d.recordLastAction(ctx, DecisionScaleOut)
+		// Send scale-out notification with the number actually added
+		if err := d.notifierWithTopology(ctx, before).SendScaleOutNotification(ctx, d.ClusterID, d.replicasDone(replicasToAdd)); err != nil {
+			d.Logger.Error("Failed to send scale-out notification", "Error", err)
+		}
+	} else if boundedCapacity < currentCapacity {
+		replicasToRemove := d.capToBulkScaleIn(ctx, currentCapacity-boundedCapacity)
+		d.Logger.Info("Scaling In to desired capacity", "ReplicasToRemove", replicasToRemove, "DesiredCapacity", boundedCapacity, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleIn)
+		before := d.captureTopology(ctx)
+
+		if err := d.RemoveReplicas(ctx, replicasToRemove); err != nil {
+			d.Logger.Error("Failed to remove replicas", "Error", err, "ReplicasToRemove", replicasToRemove)
+			return err
+		}
+		// Send scale-in notification with the number actually removed
+		if removed := d.lastResult.ReplicasRemoved; removed > 0 {
+			if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, removed); err != nil {
+				d.Logger.Error("Failed to send scale-in notification", "Error", err)
+			}
+		}
 	} else {
-		d.Logger.Info("[Dry Run] Would remove read replica", "ClusterID", d.ClusterID, "InstanceID", aws.ToString(instanceToRemove.DBInstanceIdentifier))
+		d.Logger.Info("No scaling action needed", "DesiredCapacity", boundedCapacity, "CurrentCapacity", currentCapacity, "ClusterID", d.ClusterID)
 	}
 
 	return nil
 }
 
 // ExecuteScalingAction performs the scaling logic.
+// The outcome is available afterwards via LastResult.
 func (d *DocumentDB) ExecuteScalingAction(ctx context.Context) error {
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.beginSnapshot()
+	defer d.endSnapshot()
+
+	release, locked, err := d.acquireLock(ctx)
+	if err != nil || locked {
+		return err
+	}
+	defer release()
+
+	// Elastic clusters scale their shards instead of adding instances
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
+		if err != nil {
+			return err
+		}
+		return d.ExecuteElasticScalingAction(ctx, cluster)
+	}
+
+	if paused, err := d.skipIfPaused(ctx); err != nil || paused {
+		return err
+	}
+	if open, err := d.skipIfCircuitOpen(ctx); err != nil || open {
+		return err
+	}
+	if exhausted, err := d.skipIfChurnExceeded(ctx); err != nil || exhausted {
+		return err
+	}
+
+	// Replicas left failed or beyond MaxCapacity by earlier invocations are removed, or replaced, before the trigger is evaluated
+	if removed, err := d.removeOrphans(ctx); err != nil || removed {
+		return err
+	}
+
+	// Prescaled replicas are removed once they expire, before the trigger is evaluated
+	if removed, err := d.removeExpiredPrescales(ctx); err != nil || removed {
+		return err
+	}
+
+	// Interrupted scale-outs and readers removed out of band are restored before the trigger is evaluated
+	if reconciled, err := d.reconcile(ctx); err != nil || reconciled {
+		return err
+	}
+
 	if d.ScheduledScaling {
 		// Use scheduled scaling logic
 		return d.ExecuteScheduledScalingAction(ctx)
@@ -487,6 +970,7 @@ func (d *DocumentDB) ExecuteScalingAction(ctx context.Context) error {
 // ExecuteScheduledScalingAction handles the scheduled scaling logic.
 func (d *DocumentDB) ExecuteScheduledScalingAction(ctx context.Context) error {
 	d.Logger.Info("Executing scheduled scaling action", "ClusterID", d.ClusterID)
+	d.recordReason(ReasonSchedule)
 
 	// Get current reader instances
 	readerInstances, err := d.GetReaderInstances(ctx)
@@ -494,6 +978,9 @@ func (d *DocumentDB) ExecuteScheduledScalingAction(ctx context.Context) error {
 		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
 		return err
 	}
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return err
+	}
 
 	// Count instances with the scheduler tag
 	scheduledInstances := []docdbTypes.DBInstance{}
@@ -508,57 +995,59 @@ func (d *DocumentDB) ExecuteScheduledScalingAction(ctx context.Context) error {
 		}
 	}
 
-	currentScheduledReplicas := len(scheduledInstances)
-	d.Logger.Info("Current scheduled replicas", "Count", currentScheduledReplicas)
+	d.Logger.Info("Current scheduled replicas", "Count", len(scheduledInstances), "Phase", d.SchedulePhase)
+	plan := d.PlanScheduledScaling(len(readerInstances), len(scheduledInstances))
+	if holiday, found := d.Holidays.Holiday(time.Now()); found && plan.Decision == DecisionScaleOut {
+		// The traffic scheduled scale-outs are for does not come on holidays
+		d.Logger.Info("Skipping the scheduled scale-out on a holiday", "Holiday", holiday, "ReplicasToAdd", plan.ReplicasToAdd, "ClusterID", d.ClusterID)
+		plan.Decision, plan.DesiredCapacity, plan.ReplicasToAdd = DecisionNoAction, plan.CurrentCapacity, 0
+		plan.Constraints = append(plan.Constraints, ConstraintHoliday)
+		plan.Explanation = plan.explain()
+	}
+	d.recordCapacity(plan.CurrentCapacity, plan.DesiredCapacity)
+	for _, constraint := range plan.Constraints {
+		d.recordConstraint(constraint)
+	}
 
-	// Determine action based on the presence of scheduled instances
-	if currentScheduledReplicas > 0 {
-		// Scale In: Remove all scheduled instances
-		d.Logger.Info("Scaling In: Removing scheduled replicas", "ReplicasToRemove", currentScheduledReplicas)
+	switch plan.Decision {
+	case DecisionScaleIn:
+		// Remove the scheduled instances, or as many as a scale-in may remove without approval
+		scheduledInstances = scheduledInstances[:d.capToBulkScaleIn(ctx, plan.ReplicasToRemove)]
+		replicasToRemove := len(scheduledInstances)
+		d.Logger.Info("Scaling In: Removing scheduled replicas", "ReplicasToRemove", replicasToRemove)
+		d.recordDecision(DecisionScaleIn)
+		before := d.captureTopology(ctx)
 		err := d.RemoveScheduledReplicas(ctx, scheduledInstances)
 		if err != nil {
 			d.Logger.Error("Failed to remove scheduled replicas", "Error", err)
 			return err
 		}
-		// Send scale-in notification
-		err = d.Notifier.SendScaleInNotification(d.ClusterID, currentScheduledReplicas)
-		if err != nil {
-			d.Logger.Error("Failed to send scale-in notification", "Error", err)
-		}
-	} else {
-		// Scale Out: Add scheduled replicas
-		replicasToAdd := d.ScheduleNumberReplicas
-		desiredCapacity := len(readerInstances) + replicasToAdd
-
-		// Enforce MAX_CAPACITY
-		if desiredCapacity > d.MaxCapacity {
-			replicasToAdd = d.MaxCapacity - len(readerInstances)
-			if replicasToAdd <= 0 {
-				d.Logger.Info("Desired capacity exceeds MAX_CAPACITY. No replicas to add.")
-				return nil
+		// Send scale-in notification with the number actually removed, as replicas may have been kept, e.g. undrained
+		if removed := d.lastResult.ReplicasRemoved; removed > 0 {
+			if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, removed); err != nil {
+				d.Logger.Error("Failed to send scale-in notification", "Error", err)
 			}
-			d.Logger.Warn("Adjusting replicas to add due to MAX_CAPACITY constraint", "AdjustedReplicasToAdd", replicasToAdd)
-		}
-
-		// Enforce MIN_CAPACITY
-		if desiredCapacity < d.MinCapacity {
-			d.Logger.Info("Desired capacity is below MIN_CAPACITY. Adjusting to MIN_CAPACITY.", "MinCapacity", d.MinCapacity)
-			replicasToAdd = d.MinCapacity - len(readerInstances)
 		}
 
-		d.Logger.Info("Scaling Out: Adding scheduled replicas", "ReplicasToAdd", replicasToAdd)
-		err := d.AddScheduledReplicas(ctx, replicasToAdd)
+	case DecisionScaleOut:
+		d.Logger.Info("Scaling Out: Adding scheduled replicas", "ReplicasToAdd", plan.ReplicasToAdd)
+		d.recordDecision(DecisionScaleOut)
+		before := d.captureTopology(ctx)
+		err := d.AddScheduledReplicas(ctx, plan.ReplicasToAdd)
 		if err != nil {
 			d.Logger.Error("Failed to add scheduled replicas", "Error", err)
 			return err
 		}
-		// Send scale-out notification
-		err = d.Notifier.SendScaleOutNotification(d.ClusterID, replicasToAdd)
+		d.recordLastAction(ctx, DecisionScaleOut)
+		// Send scale-out notification with the number actually added
+		err = d.notifierWithTopology(ctx, before).SendScaleOutNotification(ctx, d.ClusterID, d.replicasDone(plan.ReplicasToAdd))
 		if err != nil {
 			d.Logger.Error("Failed to send scale-out notification", "Error", err)
 		}
-	}
 
+	default:
+		d.Logger.Info("No scheduled replicas to add or remove within MIN_CAPACITY and MAX_CAPACITY", "CurrentReaders", plan.CurrentCapacity, "ScheduledReplicas", len(scheduledInstances), "MinCapacity", d.MinCapacity, "MaxCapacity", d.MaxCapacity, "ClusterID", d.ClusterID)
+	}
 	return nil
 }
 
@@ -594,33 +1083,35 @@ func (d *DocumentDB) AddScheduledReplicas(ctx context.Context, replicasToAdd int
 		}
 		instanceClass = writerInstance.DBInstanceClass
 	}
+	if err := d.validateInstanceType(ctx); err != nil {
+		return err
+	}
+	// The replicas with room are added before a cap error fails the action
+	replicasToAdd, capErr := d.capToManagedReplicas(ctx, replicasToAdd)
+	if capErr != nil && !errors.Is(capErr, ErrManagedReplicaCap) {
+		return capErr
+	}
+	replicasToAdd = d.capToInstanceQuota(ctx, replicasToAdd)
+	replicasToAdd = d.capToBudget(ctx, replicasToAdd, aws.ToString(instanceClass))
 
 	for i := 0; i < replicasToAdd; i++ {
-		// Generate a shorter unique identifier
-		timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
-		uniqueID := timestamp[len(timestamp)-9:] // Use last 9 digits to ensure uniqueness and keep length short
-
-		baseIdentifier := fmt.Sprintf("%s-scheduler-%s", d.ClusterID, uniqueID)
-		// Ensure the identifier is no more than 63 characters
-		if len(baseIdentifier) > 63 {
-			baseIdentifier = baseIdentifier[:63]
-			// Ensure it doesn't end with a hyphen
-			baseIdentifier = strings.TrimRight(baseIdentifier, "-")
+		if d.stopBeforeDeadline(ctx, replicasToAdd-i) {
+			break
 		}
 
-		// Ensure identifier starts with a letter and contains only allowed characters
-		baseIdentifier = sanitizeDBInstanceIdentifier(baseIdentifier)
+		baseIdentifier := d.replicaIdentifier("scheduler", timestampSuffix())
 
 		input := &docdb.CreateDBInstanceInput{
 			DBClusterIdentifier:  aws.String(d.ClusterID),
 			DBInstanceClass:      instanceClass,
 			DBInstanceIdentifier: aws.String(baseIdentifier),
-			Engine:               aws.String("docdb"), // Required field
-			PromotionTier:        aws.Int32(15),       // Set PromotionTier to 15
+			Engine:               aws.String(d.engine().Name), // Required field
+			PromotionTier:        aws.Int32(15),               // Set PromotionTier to 15
 		}
 
 		if !d.DryRun {
-			result, err := d.DocDBClient.CreateDBInstance(ctx, input)
+			result, err := d.createReplica(ctx, input, "scheduler")
+			baseIdentifier = aws.ToString(input.DBInstanceIdentifier)
 			if err != nil {
 				d.Logger.Error("Failed to create scheduled replica", "Error", fmt.Sprintf("failed to create DB instance %s: %v", baseIdentifier, err), "ReplicasToAdd", replicasToAdd-i)
 				return err
@@ -650,108 +1141,210 @@ func (d *DocumentDB) AddScheduledReplicas(ctx context.Context, replicasToAdd int
 				d.Logger.Error("Failed to tag new scheduled replica", "Error", err, "InstanceID", baseIdentifier)
 				// Optionally handle this error
 			}
+			d.invalidateSnapshot()
 			d.Logger.Info("Added scheduled read replica", "ClusterID", d.ClusterID, "InstanceID", baseIdentifier)
+			d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaCreated, InstanceID: baseIdentifier, InstanceClass: aws.ToString(instanceClass)})
 		} else {
 			d.Logger.Info("[Dry Run] Would add scheduled read replica", "ClusterID", d.ClusterID, "InstanceID", baseIdentifier)
 		}
+		d.recordAdded(baseIdentifier, aws.ToString(instanceClass))
 	}
 
-	return nil
+	return capErr
 }
 
 // RemoveScheduledReplicas removes scheduled read replicas.
 func (d *DocumentDB) RemoveScheduledReplicas(ctx context.Context, instances []docdbTypes.DBInstance) error {
+	// Count current readers to enforce the reader floor, as the capacity does
+	readerInstances, err := d.countableReaders(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
+		return err
+	}
+	readerCount := len(readerInstances)
+
+	// Record the writer so a failover during removal can be detected
+	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to get writer instance identifier", "Error", err)
+		return err
+	}
+
+	removed := 0
 	for _, instance := range instances {
 		instanceID := aws.ToString(instance.DBInstanceIdentifier)
 
-		// Check if the instance is in 'available' state
-		if aws.ToString(instance.DBInstanceStatus) != "available" {
-			d.Logger.Info("Instance is not in 'available' state, skipping", "InstanceID", instanceID, "Status", aws.ToString(instance.DBInstanceStatus))
+		// Skip the instances that cannot be deleted now
+		if blocker := removalBlocker(instance); blocker != "" {
+			d.Logger.Info("Instance cannot be removed now, skipping", "InstanceID", instanceID, "Reason", blocker)
 			continue
 		}
 
+		// Enforce the reader floor regardless of the scheduled plan
+		if !d.canRemoveReader(readerCount) {
+			d.Logger.Warn("Refusing to remove scheduled reader below the reader floor", "InstanceID", instanceID, "CurrentReaders", readerCount, "MinCapacity", d.MinCapacity, "AllowZeroReaders", d.AllowZeroReaders)
+			d.recordConstraint(ConstraintReaderFloor)
+			break
+		}
+
+		if d.stopBeforeDeadline(ctx, len(instances)-removed) {
+			break
+		}
+
 		// Remove the instance
 		if !d.DryRun {
+			// Skip the replicas still serving many clients, until the next invocation
+			drained, err := d.waitForDrain(ctx, instanceID)
+			if err != nil {
+				return err
+			}
+			if !drained {
+				d.recordConstraint(ConstraintConnectionDrain)
+				continue
+			}
+
+			// Guard against a failover, or a replica changed, since the topology was read
+			if err := d.verifyBeforeDelete(ctx, writerInstanceIdentifier, instance); err != nil {
+				return err
+			}
+
 			deleteInput := &docdb.DeleteDBInstanceInput{
 				DBInstanceIdentifier: instance.DBInstanceIdentifier,
 			}
-			_, err := d.DocDBClient.DeleteDBInstance(ctx, deleteInput)
+			_, err = d.DocDBClient.DeleteDBInstance(ctx, deleteInput)
+			if isDeletionRefused(err) {
+				d.Logger.Warn("Deletion of scheduled read replica was refused, skipping", "Error", err, "InstanceID", instanceID)
+				continue
+			}
 			if err != nil {
 				d.Logger.Error("Failed to delete scheduled read replica", "Error", err, "InstanceID", instanceID)
 				return err
 			}
+			d.invalidateSnapshot()
 			d.Logger.Info("Removed scheduled read replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+			d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaDeleted, InstanceID: instanceID, InstanceClass: aws.ToString(instance.DBInstanceClass)})
 		} else {
 			d.Logger.Info("[Dry Run] Would remove scheduled read replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
 		}
+		d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+		if d.isCountable(instance) {
+			readerCount--
+		}
+		removed++
 	}
 	return nil
 }
 
 // ExecuteMetricBasedScalingAction handles the existing metric-based scaling logic.
 func (d *DocumentDB) ExecuteMetricBasedScalingAction(ctx context.Context) error {
-	// For now, skipping the cooldown logic, currently implemented at EventBridge.
+	// Composite alarms scale on whichever underlying condition breached
+	if d.TriggerAlarm.IsComposite() {
+		return d.ExecuteCompositeAlarmScalingAction(ctx)
+	}
 
-	// Step 1: Retrieve current metric value
-	currentMetricValue, err := d.GetCurrentMetricValue(ctx)
+	observation, err := d.observeMetric(ctx)
 	if err != nil {
-		d.Logger.Error("Failed to retrieve current metric value", "Error", err)
 		return err
 	}
-	d.Logger.Info("Retrieved current metric value", "MetricValue", currentMetricValue)
 
-	// Step 2: Retrieve current capacity
-	currentCapacity, err := d.GetCurrentCapacity(ctx)
-	if err != nil {
-		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+	// Step 4: Determine scaling action
+	return d.executePlan(ctx, d.PlanScaling(observation))
+}
+
+// Cleanup removes the replicas created by the autoscaler and by scheduled scaling, e.g. after an incident
+// or before decommissioning the autoscaler. The reader floor still applies. The outcome is available
+// afterwards via LastResult.
+func (d *DocumentDB) Cleanup(ctx context.Context) error {
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.beginSnapshot()
+	defer d.endSnapshot()
+	d.recordReason(ReasonCleanup)
+
+	release, locked, err := d.acquireLock(ctx)
+	if err != nil || locked {
 		return err
 	}
-	d.Logger.Info("Retrieved current capacity", "CurrentCapacity", currentCapacity)
+	defer release()
 
-	// Step 3: Calculate desired capacity
-	desiredCapacity := d.CalculateDesiredCapacity(currentMetricValue, currentCapacity)
-	d.Logger.Info("Calculated desired capacity", "DesiredCapacity", desiredCapacity)
-
-	// Step 4: Determine scaling action
-	if desiredCapacity > currentCapacity {
-		// Scale Out
-		replicasToAdd := desiredCapacity - currentCapacity
-		d.Logger.Info("Scaling Out", "ReplicasToAdd", replicasToAdd, "ClusterID", d.ClusterID)
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
+		return err
+	}
+	before := d.captureTopology(ctx)
 
-		err := d.AddReplicas(ctx, replicasToAdd)
+	scheduledInstances := []docdbTypes.DBInstance{}
+	for _, instance := range readerInstances {
+		hasTag, err := d.HasSchedulerTag(ctx, instance)
 		if err != nil {
-			d.Logger.Error("Failed to add replicas", "Error", err, "ReplicasToAdd", replicasToAdd)
+			d.Logger.Error("Failed to check scheduler tag", "Error", err, "InstanceID", aws.ToString(instance.DBInstanceIdentifier))
 			return err
 		}
-		// Send scale-out notification
-		err = d.Notifier.SendScaleOutNotification(d.ClusterID, replicasToAdd)
-		if err != nil {
-			d.Logger.Error("Failed to send scale-out notification", "Error", err)
+		if hasTag {
+			scheduledInstances = append(scheduledInstances, instance)
+		}
+	}
+	if len(scheduledInstances) > 0 {
+		if err := d.RemoveScheduledReplicas(ctx, scheduledInstances); err != nil {
+			d.Logger.Error("Failed to remove scheduled replicas", "Error", err)
+			return err
 		}
+	}
 
-	} else if desiredCapacity < currentCapacity {
-		// Scale In
-		replicasToRemove := 1 // Only remove one replica at a time
-		d.Logger.Info("Scaling In", "ReplicasToRemove", replicasToRemove, "ClusterID", d.ClusterID)
+	if err := d.RemoveReplicas(ctx, len(readerInstances)); err != nil {
+		d.Logger.Error("Failed to remove replicas", "Error", err)
+		return err
+	}
 
-		// Remove the required number of replicas (only 1)
-		for i := 0; i < replicasToRemove; i++ {
-			err := d.RemoveReplica(ctx)
-			if err != nil {
-				d.Logger.Error("Failed to remove replica", "Error", err, "Attempt", i+1)
-				return err
-			}
-		}
-		// Send scale-in notification
-		err := d.Notifier.SendScaleInNotification(d.ClusterID, replicasToRemove)
-		if err != nil {
+	if removed := d.lastResult.ReplicasRemoved; removed > 0 {
+		d.recordDecision(DecisionScaleIn)
+		d.saveDesiredCapacity(ctx, len(readerInstances)-removed)
+		if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, removed); err != nil {
 			d.Logger.Error("Failed to send scale-in notification", "Error", err)
 		}
-
-	} else {
-		// No action needed
-		d.Logger.Info("No scaling action needed", "DesiredCapacity", desiredCapacity, "CurrentCapacity", currentCapacity, "ClusterID", d.ClusterID)
 	}
-
 	return nil
 }
+
+// ReportOutcome opens an incident when a scaling action failed after all retries, and resolves it
+// once an action of the cluster succeeds. Routine scaling events are only sent to the Notifier.
+// The decision or failure is also published as a lifecycle event, and failures of consecutive
+// invocations are counted towards a ticket and the circuit breaker. A pending quiet-hours digest
+// is sent once the quiet hours are over, and the outcome is appended to the audit log and recorded
+// in the metrics. Every outcome is first logged as a single decision record, and in dry-run the
+// plan is archived.
+func (d *DocumentDB) ReportOutcome(ctx context.Context, actionErr error) {
+	if d.lastResult != nil {
+		d.lastResult.CostDelta = d.costDelta(ctx)
+	}
+	d.logDecision(ctx, actionErr)
+	d.emitOutcome(ctx, actionErr)
+	d.recordAudit(ctx, actionErr)
+	d.archivePlan(ctx, actionErr)
+	d.recordMetrics(ctx, actionErr)
+	if d.Shadow {
+		// Shadow decisions are only recorded: failures are not tracked, paged or ticketed
+		return
+	}
+	d.flushQuietDigest(ctx)
+	if d.Tickets != nil || d.circuitEnabled() {
+		if err := d.trackFailures(ctx, actionErr); err != nil {
+			d.Logger.Error("Failed to track consecutive failures", "Error", err)
+		}
+	}
+	if err := d.trackChurn(ctx); err != nil {
+		d.Logger.Error("Failed to track the churn budget", "Error", err)
+	}
+	if d.Incidents == nil {
+		return
+	}
+	if actionErr != nil {
+		if err := d.Incidents.TriggerIncident(ctx, d.ClusterID, fmt.Sprintf("Scaling action failed on cluster %s: %v", d.ClusterID, actionErr)); err != nil {
+			d.Logger.Error("Failed to trigger incident", "Error", err)
+		}
+		return
+	}
+	if err := d.Incidents.ResolveIncident(ctx, d.ClusterID); err != nil {
+		d.Logger.Error("Failed to resolve incident", "Error", err)
+	}
+}
@@ -1,23 +1,47 @@
 package autoscaling
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/docdb"
 	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/aws-sdk-go-v2/service/docdbelastic"
+	elasticTypes "github.com/aws/aws-sdk-go-v2/service/docdbelastic/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/lock"
+	"github.com/cheelim1/docdb-autoscaler/pkg/metrics"
 	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	// Import the mocks from their respective packages
+	mockCloudWatch "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/cloudwatch"
 	mockDocDB "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/docdb"
 	mockRDS "github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/mocks/rds"
 )
@@ -40,21 +64,63 @@ func getTestLogger() *slog.Logger {
 // NoOpNotifier is a dummy notifier that does nothing.
 type NoOpNotifier struct{}
 
-func (n *NoOpNotifier) SendScaleOutNotification(clusterID string, replicasAdded int) error {
+func (n *NoOpNotifier) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
 	return nil
 }
 
-func (n *NoOpNotifier) SendScaleInNotification(clusterID string, replicasRemoved int) error {
+func (n *NoOpNotifier) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
 	return nil
 }
 
-func (n *NoOpNotifier) SendFailureNotification(clusterID, errorMessage, action string) error {
+func (n *NoOpNotifier) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
 	return nil
 }
 
 // Ensure NoOpNotifier implements NotifierInterface
 var _ notifications.NotifierInterface = (*NoOpNotifier)(nil)
 
+// TestNew tests the defaults and the validation of the options constructor.
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clients := WithClients(mockDocDB.NewMockDocDBAPI(ctrl), mockCloudWatch.NewMockCloudWatchAPI(ctrl), mockRDS.NewMockRDSAPI(ctrl))
+
+	docdbAutoScaler, err := New("test-cluster", clients, WithMetric("CPUUtilization", 50), WithInstanceType("db.r6g.large"), WithDryRun(true))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultMinCapacity, docdbAutoScaler.MinCapacity)
+	assert.Equal(t, DefaultMaxCapacity, docdbAutoScaler.MaxCapacity)
+	assert.Equal(t, "db.r6g.large", docdbAutoScaler.InstanceType)
+	assert.True(t, docdbAutoScaler.DryRun)
+	assert.NotNil(t, docdbAutoScaler.Notifier)
+	assert.NotNil(t, docdbAutoScaler.Logger)
+
+	_, err = New("test-cluster", clients, WithCapacity(3, 2))
+	assert.ErrorContains(t, err, "must not exceed max capacity")
+
+	_, err = New("test-cluster", WithMetric("CPUUtilization", 0))
+	assert.ErrorContains(t, err, "clients are required")
+	assert.ErrorContains(t, err, "target value must be positive")
+}
+
+// TestNewFromConfig tests that the settings of a configuration are validated, including those set
+// without an option.
+func TestNewFromConfig(t *testing.T) {
+	cfg := aws.Config{Region: "us-east-1"}
+	settings := &config.Config{ClusterID: "test-cluster", MinCapacity: 1, MaxCapacity: 5, MetricName: "CPUUtilization", TargetValue: 50, BulkScaleInLimit: 2, CircuitBackoff: 600}
+
+	docdbAutoScaler, err := NewFromConfig(cfg, settings, getTestLogger())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, docdbAutoScaler.BulkScaleInLimit)
+	assert.Equal(t, 10*time.Minute, docdbAutoScaler.CircuitBackoff)
+
+	settings.BulkScaleInLimit, settings.CircuitBackoff, settings.ElasticScaleDimension = -1, -60, "shards"
+	_, err = NewFromConfig(cfg, settings, getTestLogger())
+	assert.ErrorContains(t, err, "bulk scale-in limit must not be negative, got -1")
+	assert.ErrorContains(t, err, "circuit backoff must not be negative, got -1m0s")
+	assert.ErrorContains(t, err, "elastic scale dimension must be shardCount or shardCapacity, got shards")
+}
+
 // TestCalculateDesiredCapacity tests the CalculateDesiredCapacity method.
 func TestCalculateDesiredCapacity(t *testing.T) {
 	docdbAutoScaler := &DocumentDB{
@@ -104,6 +170,13 @@ func TestCalculateDesiredCapacity(t *testing.T) {
 			targetValue:      50,
 			expectedCapacity: 5, // ceil(300/50 * 2) = ceil(12) = 5 (maxCapacity)
 		},
+		{
+			name:             "Empty Cluster Above Target",
+			currentMetric:    80,
+			currentCapacity:  0,
+			targetValue:      50,
+			expectedCapacity: 1, // 80/50 * 0 = 0, so MinCapacity
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,6 +188,257 @@ func TestCalculateDesiredCapacity(t *testing.T) {
 	}
 }
 
+// TestCalculateDesiredCapacity_EmptyCluster tests the absolute capacity a cluster without readers is scaled
+// out to.
+func TestCalculateDesiredCapacity_EmptyCluster(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{MinCapacity: 0, MaxCapacity: 5, AllowZeroReaders: true, TargetValue: 50}
+	assert.Equal(t, 1, docdbAutoScaler.CalculateDesiredCapacity(80, 0), "at least one reader without MinCapacity")
+	assert.Equal(t, 0, docdbAutoScaler.CalculateDesiredCapacity(50, 0), "no scale-out at the target")
+
+	docdbAutoScaler.BootstrapCapacity = 3
+	assert.Equal(t, 3, docdbAutoScaler.CalculateDesiredCapacity(80, 0))
+	assert.Equal(t, 4, docdbAutoScaler.CalculateDesiredCapacity(80, 2), "proportional once the cluster has readers")
+
+	docdbAutoScaler.BootstrapCapacity = 8
+	assert.Equal(t, 5, docdbAutoScaler.CalculateDesiredCapacity(80, 0), "within MaxCapacity")
+
+	// The plan does not report a MinCapacity constraint for the bootstrap capacity
+	docdbAutoScaler.BootstrapCapacity = 2
+	plan := docdbAutoScaler.PlanScaling(Observation{CurrentCapacity: 0, DesiredCapacity: 2, MetricName: "CPUUtilization", MetricValue: 80, TargetValue: 50})
+	assert.Equal(t, DecisionScaleOut, plan.Decision)
+	assert.Equal(t, 2, plan.ReplicasToAdd)
+	assert.Empty(t, plan.Constraints)
+}
+
+// TestPlanScaling tests the metric-based planning of observations, without AWS calls.
+func TestPlanScaling(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{
+		ClusterID:      "test-cluster",
+		MinCapacity:    1,
+		MaxCapacity:    5,
+		CanaryScaleOut: true,
+	}
+
+	tests := []struct {
+		name                string
+		observation         Observation
+		expectedDecision    string
+		expectedToAdd       int
+		expectedToRemove    int
+		expectedDeferred    int
+		expectedReasons     []string
+		expectedConstraints []string
+		expectedExplanation string
+	}{
+		{
+			name:                "Canary Scale Out",
+			observation:         Observation{CurrentCapacity: 2, DesiredCapacity: 4, MetricName: "CPUUtilization", MetricValue: 80, TargetValue: 50},
+			expectedDecision:    DecisionScaleOut,
+			expectedToAdd:       1,
+			expectedDeferred:    1,
+			expectedReasons:     []string{ReasonMetricAboveTarget},
+			expectedConstraints: []string{ConstraintCanary},
+			expectedExplanation: "CPUUtilization=80 > target=50 → +1; held by Canary",
+		},
+		{
+			name:                "Rest Of Scale Out After Verified Canary",
+			observation:         Observation{CurrentCapacity: 3, DesiredCapacity: 5, CanaryVerified: true},
+			expectedDecision:    DecisionScaleOut,
+			expectedToAdd:       2,
+			expectedReasons:     []string{},
+			expectedConstraints: []string{},
+			expectedExplanation: "+2",
+		},
+		{
+			name:                "Held By Pending Canary",
+			observation:         Observation{CurrentCapacity: 3, DesiredCapacity: 5, CanaryPending: true},
+			expectedDecision:    DecisionNoAction,
+			expectedReasons:     []string{},
+			expectedConstraints: []string{ConstraintCanary},
+			expectedExplanation: "no change; held by Canary",
+		},
+		{
+			name:                "Scale Out Cooldown",
+			observation:         Observation{CurrentCapacity: 2, DesiredCapacity: 3, ScaleOutCooldown: true},
+			expectedDecision:    DecisionNoAction,
+			expectedReasons:     []string{},
+			expectedConstraints: []string{ConstraintCooldown},
+			expectedExplanation: "no change; suppressed by Cooldown",
+		},
+		{
+			name:                "Single Scale In Within Min Capacity",
+			observation:         Observation{CurrentCapacity: 3, DesiredCapacity: 1, MetricName: "CPUUtilization", MetricValue: 5, TargetValue: 50},
+			expectedDecision:    DecisionScaleIn,
+			expectedToRemove:    1,
+			expectedReasons:     []string{ReasonMetricBelowTarget},
+			expectedConstraints: []string{ConstraintMinCapacity, ConstraintSingleScaleIn},
+			expectedExplanation: "CPUUtilization=5 < target=50 → -1; clamped by MinCapacity; clamped by SingleScaleIn",
+		},
+		{
+			name:                "Scale In Cooldown",
+			observation:         Observation{CurrentCapacity: 3, DesiredCapacity: 2, ScaleInCooldown: true},
+			expectedDecision:    DecisionNoAction,
+			expectedReasons:     []string{},
+			expectedConstraints: []string{ConstraintCooldown},
+			expectedExplanation: "no change; suppressed by Cooldown",
+		},
+		{
+			name:                "Scale In Stabilization",
+			observation:         Observation{CurrentCapacity: 3, DesiredCapacity: 2, Stabilizing: true},
+			expectedDecision:    DecisionNoAction,
+			expectedReasons:     []string{},
+			expectedConstraints: []string{ConstraintStabilization},
+			expectedExplanation: "no change; suppressed by Stabilization",
+		},
+		{
+			name:                "No Scaling Needed",
+			observation:         Observation{CurrentCapacity: 2, DesiredCapacity: 2, MetricName: "CPUUtilization", MetricValue: 50, TargetValue: 50},
+			expectedDecision:    DecisionNoAction,
+			expectedReasons:     []string{ReasonMetricAtTarget},
+			expectedConstraints: []string{},
+			expectedExplanation: "CPUUtilization=50 = target=50 → no change",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := docdbAutoScaler.PlanScaling(tt.observation)
+			assert.Equal(t, "test-cluster", plan.ClusterID)
+			assert.Equal(t, tt.expectedDecision, plan.Decision)
+			assert.Equal(t, tt.expectedToAdd, plan.ReplicasToAdd)
+			assert.Equal(t, tt.expectedToRemove, plan.ReplicasToRemove)
+			assert.Equal(t, tt.expectedDeferred, plan.ReplicasDeferred)
+			assert.Equal(t, tt.expectedDeferred > 0, plan.Canary)
+			assert.Equal(t, tt.expectedReasons, plan.ReasonCodes)
+			assert.Equal(t, tt.expectedConstraints, plan.Constraints)
+			assert.Equal(t, tt.expectedExplanation, plan.Explanation)
+		})
+	}
+}
+
+// TestPlanScheduledScaling tests the scheduled scaling plans of readers and scheduled replicas, with both
+// bounds applied.
+func TestPlanScheduledScaling(t *testing.T) {
+	tests := []struct {
+		name                string
+		minCapacity         int
+		maxCapacity         int
+		scheduleReplicas    int
+		phase               string
+		allowZeroReaders    bool
+		baselineReaders     int
+		readers             int
+		scheduledReplicas   int
+		expectedDecision    string
+		expectedDesired     int
+		expectedToAdd       int
+		expectedToRemove    int
+		expectedConstraints []string
+	}{
+		{
+			name: "Scale Out", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, readers: 2,
+			expectedDecision: DecisionScaleOut, expectedDesired: 4, expectedToAdd: 2, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale Out Held To MaxCapacity", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 4, readers: 3,
+			expectedDecision: DecisionScaleOut, expectedDesired: 5, expectedToAdd: 2, expectedConstraints: []string{ConstraintMaxCapacity},
+		},
+		{
+			name: "No Scale Out At MaxCapacity", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, readers: 5,
+			expectedDecision: DecisionNoAction, expectedDesired: 5, expectedConstraints: []string{ConstraintMaxCapacity},
+		},
+		{
+			name: "No Scale Out Above MaxCapacity", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, readers: 7,
+			expectedDecision: DecisionNoAction, expectedDesired: 7, expectedConstraints: []string{ConstraintMaxCapacity},
+		},
+		{
+			name: "Scale Out Raised To MinCapacity", minCapacity: 4, maxCapacity: 6, scheduleReplicas: 1, readers: 1,
+			expectedDecision: DecisionScaleOut, expectedDesired: 4, expectedToAdd: 3, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "Negative Schedule Adds Nothing", minCapacity: 1, maxCapacity: 5, scheduleReplicas: -3, readers: 3,
+			expectedDecision: DecisionNoAction, expectedDesired: 3, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "Negative Schedule Below MinCapacity Adds Nothing", minCapacity: 3, maxCapacity: 5, scheduleReplicas: -1, readers: 4,
+			expectedDecision: DecisionNoAction, expectedDesired: 4, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale Out On Top Of Baseline Readers", minCapacity: 0, maxCapacity: 2, scheduleReplicas: 3, baselineReaders: 3, readers: 3,
+			expectedDecision: DecisionScaleOut, expectedDesired: 5, expectedToAdd: 2, expectedConstraints: []string{ConstraintMaxCapacity},
+		},
+		{
+			name: "Scale In", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, readers: 4, scheduledReplicas: 2,
+			expectedDecision: DecisionScaleIn, expectedDesired: 2, expectedToRemove: 2, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale In Keeps MinCapacity", minCapacity: 3, maxCapacity: 5, scheduleReplicas: 2, readers: 4, scheduledReplicas: 2,
+			expectedDecision: DecisionScaleIn, expectedDesired: 3, expectedToRemove: 1, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "No Scale In At MinCapacity", minCapacity: 2, maxCapacity: 5, scheduleReplicas: 1, readers: 2, scheduledReplicas: 1,
+			expectedDecision: DecisionNoAction, expectedDesired: 2, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "Scale In Keeps The Last Reader", minCapacity: 0, maxCapacity: 5, scheduleReplicas: 2, readers: 2, scheduledReplicas: 2,
+			expectedDecision: DecisionScaleIn, expectedDesired: 1, expectedToRemove: 1, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "Scale In To Zero Readers", minCapacity: 0, maxCapacity: 5, scheduleReplicas: 2, allowZeroReaders: true, readers: 2, scheduledReplicas: 2,
+			expectedDecision: DecisionScaleIn, expectedDesired: 0, expectedToRemove: 2, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale In Keeps MinCapacity On Top Of Baseline Readers", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, baselineReaders: 2, readers: 4, scheduledReplicas: 2,
+			expectedDecision: DecisionScaleIn, expectedDesired: 3, expectedToRemove: 1, expectedConstraints: []string{ConstraintMinCapacity},
+		},
+		{
+			name: "Scale Out Phase Adds The Missing Scheduled Replicas", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 3, phase: SchedulePhaseScaleOut, readers: 3, scheduledReplicas: 1,
+			expectedDecision: DecisionScaleOut, expectedDesired: 5, expectedToAdd: 2, expectedConstraints: []string{},
+		},
+		{
+			name: "No Scale Out Once The Scale Out Phase Completed", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, phase: SchedulePhaseScaleOut, readers: 3, scheduledReplicas: 2,
+			expectedDecision: DecisionNoAction, expectedDesired: 3, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale Out Phase Removes The Scheduled Replicas Beyond The Schedule", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 1, phase: SchedulePhaseScaleOut, readers: 4, scheduledReplicas: 3,
+			expectedDecision: DecisionScaleIn, expectedDesired: 2, expectedToRemove: 2, expectedConstraints: []string{},
+		},
+		{
+			name: "Scale In Phase Removes The Scheduled Replicas Left", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 3, phase: SchedulePhaseScaleIn, readers: 2, scheduledReplicas: 1,
+			expectedDecision: DecisionScaleIn, expectedDesired: 1, expectedToRemove: 1, expectedConstraints: []string{},
+		},
+		{
+			name: "No Scale Out In The Scale In Phase", minCapacity: 1, maxCapacity: 5, scheduleReplicas: 2, phase: SchedulePhaseScaleIn, readers: 2,
+			expectedDecision: DecisionNoAction, expectedDesired: 2, expectedConstraints: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docdbAutoScaler := &DocumentDB{
+				ClusterID:              "test-cluster",
+				MinCapacity:            tt.minCapacity,
+				MaxCapacity:            tt.maxCapacity,
+				ScheduledScaling:       true,
+				ScheduleNumberReplicas: tt.scheduleReplicas,
+				SchedulePhase:          tt.phase,
+				AllowZeroReaders:       tt.allowZeroReaders,
+				ManagedCapacity:        tt.baselineReaders > 0,
+				baselineReaders:        tt.baselineReaders,
+			}
+			plan := docdbAutoScaler.PlanScheduledScaling(tt.readers, tt.scheduledReplicas)
+			assert.Equal(t, tt.expectedDecision, plan.Decision)
+			assert.Equal(t, tt.readers, plan.CurrentCapacity)
+			assert.Equal(t, tt.expectedDesired, plan.DesiredCapacity)
+			assert.Equal(t, tt.expectedToAdd, plan.ReplicasToAdd)
+			assert.Equal(t, tt.expectedToRemove, plan.ReplicasToRemove)
+			assert.Equal(t, tt.baselineReaders, plan.BaselineReaders)
+			assert.Equal(t, []string{ReasonSchedule}, plan.ReasonCodes)
+			assert.Equal(t, tt.expectedConstraints, plan.Constraints)
+		})
+	}
+}
+
 // TestExecuteScheduledScalingAction tests the scheduled scaling logic.
 func TestExecuteScheduledScalingAction(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -209,6 +533,13 @@ func TestExecuteScheduledScalingAction(t *testing.T) {
 
 	err := docdbAutoScaler.ExecuteScalingAction(context.Background())
 	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleOut, result.Decision)
+	assert.Equal(t, 2, result.ReplicasAdded)
+	assert.Len(t, result.PendingInstanceIDs, 2)
+	assert.Equal(t, result.PendingInstanceIDs, result.AddedInstanceIDs)
+	assert.Contains(t, result.ReasonCodes, ReasonSchedule)
 }
 
 // TestExecuteScheduledScalingAction_ScaleIn tests the scheduled scaling logic for scaling in.
@@ -242,6 +573,11 @@ func TestExecuteScheduledScalingAction_ScaleIn(t *testing.T) {
 					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:scheduled-replica-1"),
 					DBInstanceStatus:     awsString("available"),
 				},
+				{
+					DBInstanceIdentifier: awsString("static-replica-1"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:static-replica-1"),
+					DBInstanceStatus:     awsString("available"),
+				},
 				{
 					DBInstanceIdentifier: awsString("writer-instance"),
 					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:writer-instance"),
@@ -301,4 +637,2959 @@ func TestExecuteScheduledScalingAction_ScaleIn(t *testing.T) {
 
 	err := docdbAutoScaler.ExecuteScalingAction(context.Background())
 	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleIn, result.Decision)
+	assert.Equal(t, 1, result.ReplicasRemoved)
+}
+
+// TestRemoveScheduledReplicas_AbortsOnFailover tests that no replica is deleted when the writer changes mid-invocation.
+func TestRemoveScheduledReplicas_AbortsOnFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		Notifier:    &NoOpNotifier{},
+	}
+
+	clusterWithWriter := func(writerID string) *rds.DescribeDBClustersOutput {
+		return &rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString(writerID),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance")},
+				{DBInstanceIdentifier: awsString("scheduled-replica-1")},
+				{DBInstanceIdentifier: awsString("static-replica-1")},
+			},
+		}, nil).AnyTimes()
+
+	// Initial lookups see the original writer, the re-verification sees the failed-over writer
+	gomock.InOrder(
+		mockRDSClient.
+			EXPECT().
+			DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(clusterWithWriter("writer-instance"), nil).Times(2),
+		mockRDSClient.
+			EXPECT().
+			DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(clusterWithWriter("scheduled-replica-1"), nil).Times(1),
+	)
+
+	// DeleteDBInstance must never be called
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := docdbAutoScaler.RemoveScheduledReplicas(context.Background(), []docdbTypes.DBInstance{
+		{
+			DBInstanceIdentifier: awsString("scheduled-replica-1"),
+			DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:scheduled-replica-1"),
+			DBInstanceStatus:     awsString("available"),
+		},
+	})
+	assert.ErrorIs(t, err, ErrTopologyChanged)
+}
+
+// TestRemoveScheduledReplicas_AbortsOnStaleReplica tests that no replica is deleted when a fresh describe no longer
+// finds it in the cluster, or finds it without the autoscaler tags.
+func TestRemoveScheduledReplicas_AbortsOnStaleReplica(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []docdbTypes.DBInstance
+		tags      []docdbTypes.Tag
+	}{
+		{
+			name: "no longer in the cluster",
+			instances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance")},
+				{DBInstanceIdentifier: awsString("static-replica-1"), DBInstanceArn: awsString("arn:static-replica-1")},
+			},
+		},
+		{
+			name: "no longer tagged",
+			instances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance")},
+				{DBInstanceIdentifier: awsString("scheduled-replica-1"), DBInstanceArn: awsString("arn:scheduled-replica-1")},
+			},
+			tags: []docdbTypes.Tag{{Key: awsString("team"), Value: awsString("orders")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+			mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+			docdbAutoScaler := &DocumentDB{
+				DocDBClient:      mockDocDBClient,
+				RDSClient:        mockRDSClient,
+				Logger:           getTestLogger(),
+				ClusterID:        "test-cluster",
+				AllowZeroReaders: true,
+				Notifier:         &NoOpNotifier{},
+			}
+
+			mockRDSClient.
+				EXPECT().
+				DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&rds.DescribeDBClustersOutput{
+					DBClusters: []rdsTypes.DBCluster{
+						{
+							DBClusterIdentifier: awsString("test-cluster"),
+							DBClusterMembers: []rdsTypes.DBClusterMember{
+								{
+									DBInstanceIdentifier: awsString("writer-instance"),
+									IsClusterWriter:      awsBool(true),
+								},
+							},
+						},
+					},
+				}, nil).AnyTimes()
+			mockDocDBClient.
+				EXPECT().
+				DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&docdb.DescribeDBInstancesOutput{DBInstances: tt.instances}, nil).AnyTimes()
+			mockDocDBClient.
+				EXPECT().
+				ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&docdb.ListTagsForResourceOutput{TagList: tt.tags}, nil).AnyTimes()
+
+			// DeleteDBInstance must never be called
+			mockDocDBClient.
+				EXPECT().
+				DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+
+			err := docdbAutoScaler.RemoveScheduledReplicas(context.Background(), []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("scheduled-replica-1"),
+					DBInstanceArn:        awsString("arn:scheduled-replica-1"),
+					DBInstanceStatus:     awsString("available"),
+				},
+			})
+			assert.ErrorIs(t, err, ErrTopologyChanged)
+		})
+	}
+}
+
+// TestCanRemoveReader tests the reader floor invariant.
+func TestCanRemoveReader(t *testing.T) {
+	tests := []struct {
+		name             string
+		minCapacity      int
+		allowZeroReaders bool
+		currentReaders   int
+		expected         bool
+	}{
+		{
+			name:           "Above Min Capacity",
+			minCapacity:    1,
+			currentReaders: 3,
+			expected:       true,
+		},
+		{
+			name:           "At Min Capacity",
+			minCapacity:    2,
+			currentReaders: 2,
+			expected:       false,
+		},
+		{
+			name:           "Last Reader",
+			minCapacity:    0,
+			currentReaders: 1,
+			expected:       false,
+		},
+		{
+			name:             "Last Reader With Zero Readers Allowed",
+			minCapacity:      0,
+			allowZeroReaders: true,
+			currentReaders:   1,
+			expected:         true,
+		},
+		{
+			name:             "No Readers",
+			minCapacity:      0,
+			allowZeroReaders: true,
+			currentReaders:   0,
+			expected:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docdbAutoScaler := &DocumentDB{
+				MinCapacity:      tt.minCapacity,
+				AllowZeroReaders: tt.allowZeroReaders,
+			}
+			assert.Equal(t, tt.expected, docdbAutoScaler.canRemoveReader(tt.currentReaders))
+		})
+	}
+}
+
+// TestScaleToCapacity_ScaleIn tests that ScaleToCapacity removes autoscaler-created replicas down to the requested count.
+func TestScaleToCapacity_ScaleIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		MinCapacity: 1,
+		MaxCapacity: 5,
+		Notifier:    &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-2"), DBInstanceArn: awsString("arn:replica-2"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-3"), DBInstanceArn: awsString("arn:replica-3"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	// All readers were created by the autoscaler
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.ListTagsForResourceOutput{
+			TagList: []docdbTypes.Tag{
+				{
+					Key:   awsString("docdb-autoscaler-created"),
+					Value: awsString("true"),
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DeleteDBInstanceOutput{}, nil).Times(2)
+
+	err := docdbAutoScaler.ScaleToCapacity(context.Background(), 1)
+	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleIn, result.Decision)
+	assert.Equal(t, 2, result.ReplicasRemoved)
+}
+
+// TestParseAlarmRuleChildren tests extracting child alarm names from composite alarm rules.
+func TestParseAlarmRuleChildren(t *testing.T) {
+	tests := []struct {
+		name      string
+		alarmRule string
+		expected  []string
+	}{
+		{
+			name:      "Quoted Names",
+			alarmRule: `ALARM("docdb-cpu-high") OR ALARM("docdb-connections-high")`,
+			expected:  []string{"docdb-cpu-high", "docdb-connections-high"},
+		},
+		{
+			name:      "ARN Reference",
+			alarmRule: `ALARM(arn:aws:cloudwatch:us-east-1:123456789012:alarm:docdb-cpu-high) AND NOT OK("docdb-cpu-high")`,
+			expected:  []string{"docdb-cpu-high"},
+		},
+		{
+			name:      "No Children",
+			alarmRule: `TRUE`,
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseAlarmRuleChildren(tt.alarmRule))
+		})
+	}
+}
+
+// TestGetBreachedChildMetrics tests that only child alarms in ALARM state contribute metrics.
+func TestGetBreachedChildMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCloudWatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		CloudWatchClient: mockCloudWatchClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+	}
+
+	mockCloudWatchClient.
+		EXPECT().
+		DescribeAlarms(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+			assert.Equal(t, []string{"docdb-cpu-high", "docdb-connections-high"}, input.AlarmNames)
+			return &cloudwatch.DescribeAlarmsOutput{
+				MetricAlarms: []cwTypes.MetricAlarm{
+					{
+						AlarmName:  awsString("docdb-cpu-high"),
+						MetricName: awsString("CPUUtilization"),
+						StateValue: cwTypes.StateValueOk,
+					},
+					{
+						AlarmName:  awsString("docdb-connections-high"),
+						MetricName: awsString("DatabaseConnections"),
+						StateValue: cwTypes.StateValueAlarm,
+					},
+				},
+			}, nil
+		}).Times(1)
+
+	metricNames, err := docdbAutoScaler.GetBreachedChildMetrics(context.Background(), `ALARM("docdb-cpu-high") OR ALARM("docdb-connections-high")`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DatabaseConnections"}, metricNames)
+}
+
+// TestExecuteScalingAction_Paused tests that no scaling happens while the cluster carries the paused tag.
+func TestExecuteScalingAction_Paused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:            mockDocDBClient,
+		RDSClient:              mockRDSClient,
+		Logger:                 getTestLogger(),
+		ClusterID:              "test-cluster",
+		ScheduledScaling:       true,
+		ScheduleNumberReplicas: 2,
+		MinCapacity:            1,
+		MaxCapacity:            5,
+		Notifier:               &NoOpNotifier{},
+	}
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					TagList: []rdsTypes.Tag{
+						{
+							Key:   awsString("docdb-autoscaler-paused"),
+							Value: awsString("true"),
+						},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	// No instance lookups or mutations while paused
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := docdbAutoScaler.ExecuteScalingAction(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, DecisionPaused, docdbAutoScaler.LastResult().Decision)
+}
+
+// TestListClusterIdentifiers tests that all pages of DocumentDB clusters are listed.
+func TestListClusterIdentifiers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	gomock.InOrder(
+		mockRDSClient.
+			EXPECT().
+			DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&rds.DescribeDBClustersOutput{
+				DBClusters: []rdsTypes.DBCluster{
+					{DBClusterIdentifier: awsString("prod-orders-blue")},
+				},
+				Marker: awsString("page-2"),
+			}, nil),
+		mockRDSClient.
+			EXPECT().
+			DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, params *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+				assert.Equal(t, "page-2", aws.ToString(params.Marker))
+				return &rds.DescribeDBClustersOutput{
+					DBClusters: []rdsTypes.DBCluster{
+						{DBClusterIdentifier: awsString("prod-orders-green")},
+					},
+				}, nil
+			}),
+	)
+
+	clusterIDs, err := ListClusterIdentifiers(context.Background(), mockRDSClient, DocDBEngine)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod-orders-blue", "prod-orders-green"}, clusterIDs)
+}
+
+// TestGetWriterInstanceIdentifier_GlobalSecondary tests that the oldest instance stands in for the writer
+// in a secondary member of a global cluster.
+func TestGetWriterInstanceIdentifier_GlobalSecondary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "secondary-cluster",
+	}
+
+	clusterArn := "arn:aws:rds:eu-west-1:123456789012:cluster:secondary-cluster"
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("secondary-cluster"),
+					DBClusterArn:        awsString(clusterArn),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{DBInstanceIdentifier: awsString("secondary-reader-2"), IsClusterWriter: awsBool(false)},
+						{DBInstanceIdentifier: awsString("secondary-reader-1"), IsClusterWriter: awsBool(false)},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeGlobalClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeGlobalClustersOutput{
+			GlobalClusters: []docdbTypes.GlobalCluster{
+				{
+					GlobalClusterIdentifier: awsString("global-cluster"),
+					GlobalClusterMembers: []docdbTypes.GlobalClusterMember{
+						{DBClusterArn: awsString("arn:aws:rds:us-east-1:123456789012:cluster:primary-cluster"), IsWriter: awsBool(true)},
+						{DBClusterArn: awsString(clusterArn), IsWriter: awsBool(false)},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("secondary-reader-2"),
+					InstanceCreateTime:   aws.Time(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)),
+				},
+				{
+					DBInstanceIdentifier: awsString("secondary-reader-1"),
+					InstanceCreateTime:   aws.Time(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+			},
+		}, nil).Times(1)
+
+	writerInstanceIdentifier, err := docdbAutoScaler.GetWriterInstanceIdentifier(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "secondary-reader-1", writerInstanceIdentifier)
+}
+
+// TestExecuteScalingAction_ElasticCluster tests that an elastic cluster is scaled by its shard count.
+func TestExecuteScalingAction_ElasticCluster(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	mockCloudWatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+	mockElasticClient := mockDocDB.NewMockDocDBElasticAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		RDSClient:        mockRDSClient,
+		CloudWatchClient: mockCloudWatchClient,
+		ElasticClient:    mockElasticClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "elastic-cluster",
+		MetricName:       "PrimaryInstanceCPUUtilization",
+		TargetValue:      60,
+		MinCapacity:      2,
+		MaxCapacity:      8,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	clusterArn := "arn:aws:docdb-elastic:us-east-1:123456789012:cluster/0123abcd"
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, &rdsTypes.DBClusterNotFoundFault{}).Times(1)
+
+	mockElasticClient.
+		EXPECT().
+		ListClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdbelastic.ListClustersOutput{
+			Clusters: []elasticTypes.ClusterInList{
+				{ClusterName: awsString("other-cluster"), ClusterArn: awsString("arn:aws:docdb-elastic:us-east-1:123456789012:cluster/4567efgh")},
+				{ClusterName: awsString("elastic-cluster"), ClusterArn: awsString(clusterArn)},
+			},
+		}, nil).Times(1)
+
+	mockElasticClient.
+		EXPECT().
+		GetCluster(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdbelastic.GetClusterOutput{
+			Cluster: &elasticTypes.Cluster{
+				ClusterName:   awsString("elastic-cluster"),
+				ClusterArn:    awsString(clusterArn),
+				ShardCount:    aws.Int32(2),
+				ShardCapacity: aws.Int32(4),
+				Status:        elasticTypes.StatusActive,
+			},
+		}, nil).Times(1)
+
+	mockElasticClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdbelastic.ListTagsForResourceOutput{Tags: map[string]string{}}, nil).Times(1)
+
+	mockCloudWatchClient.
+		EXPECT().
+		GetMetricStatistics(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+			assert.Equal(t, "AWS/DocDB-Elastic", aws.ToString(params.Namespace))
+			assert.Equal(t, "0123abcd", aws.ToString(params.Dimensions[0].Value))
+			return &cloudwatch.GetMetricStatisticsOutput{
+				Datapoints: []cwTypes.Datapoint{
+					{Average: aws.Float64(90), Timestamp: aws.Time(time.Now())},
+				},
+			}, nil
+		}).Times(1)
+
+	// 90% against a 60% target on 2 shards calls for 3 shards
+	mockElasticClient.
+		EXPECT().
+		UpdateCluster(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *docdbelastic.UpdateClusterInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.UpdateClusterOutput, error) {
+			assert.Equal(t, int32(3), aws.ToInt32(params.ShardCount))
+			assert.Nil(t, params.ShardCapacity)
+			return &docdbelastic.UpdateClusterOutput{}, nil
+		}).Times(1)
+
+	err := docdbAutoScaler.ExecuteScalingAction(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, DecisionScaleOut, docdbAutoScaler.LastResult().Decision)
+}
+
+// TestGetElasticCluster_Snapshot tests that the steps of an action share one lookup of the cluster,
+// whether it is elastic or instance-based.
+func TestGetElasticCluster_Snapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	mockElasticClient := mockDocDB.NewMockDocDBElasticAPI(ctrl)
+	docdbAutoScaler := &DocumentDB{
+		RDSClient:     mockRDSClient,
+		ElasticClient: mockElasticClient,
+		Logger:        getTestLogger(),
+		ClusterID:     "elastic-cluster",
+	}
+
+	clusterArn := "arn:aws:docdb-elastic:us-east-1:123456789012:cluster/0123abcd"
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, &rdsTypes.DBClusterNotFoundFault{}).Times(1)
+	mockElasticClient.
+		EXPECT().
+		ListClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdbelastic.ListClustersOutput{
+			Clusters: []elasticTypes.ClusterInList{{ClusterName: awsString("elastic-cluster"), ClusterArn: awsString(clusterArn)}},
+		}, nil).Times(1)
+	mockElasticClient.
+		EXPECT().
+		GetCluster(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdbelastic.GetClusterOutput{
+			Cluster: &elasticTypes.Cluster{ClusterName: awsString("elastic-cluster"), ClusterArn: awsString(clusterArn), ShardCount: aws.Int32(2)},
+		}, nil).Times(1)
+
+	docdbAutoScaler.beginSnapshot()
+	for i := 0; i < 2; i++ {
+		cluster, err := docdbAutoScaler.getElasticCluster(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, clusterArn, aws.ToString(cluster.ClusterArn))
+	}
+	docdbAutoScaler.endSnapshot()
+
+	// The description of an instance-based cluster is reused by the next steps
+	docdbAutoScaler.ClusterID = "cluster-a"
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{DBClusters: []rdsTypes.DBCluster{{DBClusterIdentifier: awsString("cluster-a")}}}, nil).Times(1)
+
+	docdbAutoScaler.beginSnapshot()
+	defer docdbAutoScaler.endSnapshot()
+	cluster, err := docdbAutoScaler.getElasticCluster(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, cluster)
+	dbCluster, err := docdbAutoScaler.describeCluster(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster-a", aws.ToString(dbCluster.DBClusterIdentifier))
+}
+
+// TestStepElasticCapacity tests stepping through the supported shard capacities within the bounds.
+func TestStepElasticCapacity(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{
+		ElasticScaleDimension: ElasticShardCapacity,
+		TargetValue:           60,
+		MinCapacity:           4,
+		MaxCapacity:           32,
+	}
+
+	assert.Equal(t, 16, docdbAutoScaler.stepElasticCapacity(4, 2))
+	assert.Equal(t, 32, docdbAutoScaler.stepElasticCapacity(16, 3))
+	assert.Equal(t, 4, docdbAutoScaler.stepElasticCapacity(8, -2))
+	assert.Equal(t, 8, docdbAutoScaler.desiredElasticCapacity(70, 4))
+	assert.Equal(t, 8, docdbAutoScaler.desiredElasticCapacity(25, 16))
+	assert.Equal(t, 16, docdbAutoScaler.desiredElasticCapacity(55, 16))
+}
+
+// TestLookupEngine tests the engine lookup and its DocumentDB default.
+func TestLookupEngine(t *testing.T) {
+	engine, err := LookupEngine("")
+	assert.NoError(t, err)
+	assert.Equal(t, DocDBEngine, engine)
+
+	engine, err = LookupEngine("neptune")
+	assert.NoError(t, err)
+	assert.Equal(t, "AWS/Neptune", engine.MetricNamespace)
+
+	_, err = LookupEngine("mongodb")
+	assert.Error(t, err)
+}
+
+// TestRDSInstanceClient tests that DocumentDB shapes are converted to and from the RDS API.
+func TestRDSInstanceClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRDSInstanceClient := mockRDS.NewMockRDSInstanceAPI(ctrl)
+	client := NewRDSInstanceClient(mockRDSInstanceClient)
+
+	createTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockRDSInstanceClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+			assert.Equal(t, "db-cluster-id", aws.ToString(params.Filters[0].Name))
+			assert.Equal(t, []string{"aurora-cluster"}, params.Filters[0].Values)
+			return &rds.DescribeDBInstancesOutput{
+				DBInstances: []rdsTypes.DBInstance{
+					{
+						DBInstanceIdentifier: awsString("aurora-reader-1"),
+						DBInstanceClass:      awsString("db.r6g.large"),
+						InstanceCreateTime:   aws.Time(createTime),
+						PromotionTier:        aws.Int32(15),
+					},
+				},
+			}, nil
+		}).Times(1)
+
+	mockRDSInstanceClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *rds.CreateDBInstanceInput, optFns ...func(*rds.Options)) (*rds.CreateDBInstanceOutput, error) {
+			assert.Equal(t, "aurora-postgresql", aws.ToString(params.Engine))
+			assert.Equal(t, "aurora-cluster", aws.ToString(params.DBClusterIdentifier))
+			return &rds.CreateDBInstanceOutput{
+				DBInstance: &rdsTypes.DBInstance{DBInstanceArn: awsString("arn:aws:rds:us-east-1:123456789012:db:aurora-reader-2")},
+			}, nil
+		}).Times(1)
+
+	describeOutput, err := client.DescribeDBInstances(context.Background(), &docdb.DescribeDBInstancesInput{
+		Filters: []docdbTypes.Filter{{Name: awsString("db-cluster-id"), Values: []string{"aurora-cluster"}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "aurora-reader-1", aws.ToString(describeOutput.DBInstances[0].DBInstanceIdentifier))
+	assert.Equal(t, createTime, aws.ToTime(describeOutput.DBInstances[0].InstanceCreateTime))
+	assert.Equal(t, int32(15), aws.ToInt32(describeOutput.DBInstances[0].PromotionTier))
+
+	createOutput, err := client.CreateDBInstance(context.Background(), &docdb.CreateDBInstanceInput{
+		DBClusterIdentifier:  awsString("aurora-cluster"),
+		DBInstanceClass:      awsString("db.r6g.large"),
+		DBInstanceIdentifier: awsString("aurora-reader-2"),
+		Engine:               awsString("aurora-postgresql"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:rds:us-east-1:123456789012:db:aurora-reader-2", aws.ToString(createOutput.DBInstance.DBInstanceArn))
+}
+
+// recordingTickets records the tickets opened.
+type recordingTickets struct {
+	descriptions []string
+}
+
+func (r *recordingTickets) OpenTicket(ctx context.Context, clusterID, summary, description string) error {
+	r.descriptions = append(r.descriptions, description)
+	return nil
+}
+
+// TestReportOutcome_OpensTicket tests that a ticket with the error history is opened once the
+// failures of consecutive invocations reach the threshold.
+func TestReportOutcome_OpensTicket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	tickets := &recordingTickets{}
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:     mockDocDBClient,
+		RDSClient:       mockRDSClient,
+		Logger:          getTestLogger(),
+		ClusterID:       "test-cluster",
+		Notifier:        &NoOpNotifier{},
+		Tickets:         tickets,
+		TicketThreshold: 3,
+	}
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterArn:        awsString("arn:aws:rds:region:account-id:cluster:test-cluster"),
+					TagList: []rdsTypes.Tag{
+						{Key: awsString("docdb-autoscaler-failures"), Value: awsString("2")},
+						{Key: awsString("docdb-autoscaler-failure-1"), Value: awsString("1700000000 throttled")},
+						{Key: awsString("docdb-autoscaler-failure-2"), Value: awsString("1700000300 throttled")},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	var tags []docdbTypes.Tag
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+			tags = params.Tags
+			return &docdb.AddTagsToResourceOutput{}, nil
+		}).Times(1)
+
+	docdbAutoScaler.ReportOutcome(context.Background(), fmt.Errorf("InsufficientDBInstanceCapacity: \"db.r6g.large\""))
+
+	assert.Len(t, tickets.descriptions, 1)
+	assert.Contains(t, tickets.descriptions[0], "has failed on 3 consecutive invocations")
+	assert.Contains(t, tickets.descriptions[0], "- 2023-11-14T22:13:20Z: throttled\n")
+	assert.Contains(t, tickets.descriptions[0], "InsufficientDBInstanceCapacity:  db.r6g.large \n")
+
+	values := map[string]string{}
+	for _, tag := range tags {
+		values[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	assert.Equal(t, "3", values["docdb-autoscaler-failures"])
+	assert.Contains(t, values["docdb-autoscaler-failure-3"], "InsufficientDBInstanceCapacity")
+	assert.NotEmpty(t, values["docdb-autoscaler-ticket"])
+}
+
+// recordingMetrics records the invocations.
+type recordingMetrics struct {
+	invocations []metrics.Invocation
+}
+
+func (r *recordingMetrics) RecordInvocation(ctx context.Context, invocation metrics.Invocation) error {
+	r.invocations = append(r.invocations, invocation)
+	return nil
+}
+
+// TestReportOutcome_RecordsMetrics tests that the metrics of an action include the capacity it decided on.
+func TestReportOutcome_RecordsMetrics(t *testing.T) {
+	recorder := &recordingMetrics{}
+	docdbAutoScaler := &DocumentDB{
+		Logger:    getTestLogger(),
+		ClusterID: "test-cluster",
+		Notifier:  &NoOpNotifier{},
+		Metrics:   recorder,
+		AWSErrors: &metrics.ErrorCounter{},
+	}
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(context.Background(), 3, 3))
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+
+	assert.Len(t, recorder.invocations, 1)
+	invocation := recorder.invocations[0]
+	assert.Equal(t, "test-cluster", invocation.ClusterID)
+	assert.Equal(t, DecisionNoAction, invocation.Decision)
+	assert.Equal(t, 3, *invocation.CurrentCapacity)
+	assert.Equal(t, 3, *invocation.DesiredCapacity)
+	assert.False(t, invocation.Failed)
+}
+
+// TestReportOutcome_LogsDecision tests the single decision record of an action.
+func TestReportOutcome_LogsDecision(t *testing.T) {
+	var output bytes.Buffer
+	docdbAutoScaler := &DocumentDB{
+		Logger:      slog.New(slog.NewJSONHandler(&output, nil)),
+		ClusterID:   "test-cluster",
+		MinCapacity: 1,
+		MaxCapacity: 5,
+		Notifier:    &NoOpNotifier{},
+	}
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	docdbAutoScaler.recordMetric("CPUUtilization", 90, 60)
+	docdbAutoScaler.recordBounds(8, 5)
+
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(context.Background(), 5, 5))
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+
+	var record map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "Scaling decision" {
+			record = entry
+		}
+	}
+	assert.NotNil(t, record)
+	assert.Equal(t, DecisionNoAction, record["Decision"])
+	assert.Equal(t, "CPUUtilization", record["MetricName"])
+	assert.Equal(t, float64(90), record["MetricValue"])
+	assert.Equal(t, float64(5), record["DesiredCapacity"])
+	assert.Equal(t, []any{ReasonMetricAboveTarget}, record["ReasonCodes"])
+	constraints := record["Constraints"].(map[string]any)
+	assert.Equal(t, float64(5), constraints["MaxCapacity"])
+	assert.Equal(t, []any{ConstraintMaxCapacity}, constraints["Applied"])
+	assert.Equal(t, "CPUUtilization=90 > target=60 → no change; clamped by MaxCapacity", record["Explanation"])
+}
+
+// TestScalingResult_Explanation tests the explanations of results, partial and merged ones.
+func TestScalingResult_Explanation(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{Logger: getTestLogger(), ClusterID: "test-cluster"}
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	docdbAutoScaler.recordMetric("CPUUtilization", 82.345, 70)
+	docdbAutoScaler.recordDecision(DecisionScaleOut)
+	docdbAutoScaler.recordAdded("test-cluster-replica-1", "db.r6g.large")
+	docdbAutoScaler.recordPartial(1)
+	assert.Equal(t, "CPUUtilization=82.35 > target=70 → +1; 1 replicas remaining", docdbAutoScaler.LastResult().Explanation)
+
+	merged := NewScalingResult(false)
+	merged.Merge(docdbAutoScaler.LastResult())
+	merged.Merge(&ScalingResult{Decision: DecisionPaused, ReasonCodes: []string{ReasonPaused}, Explanation: "Paused → paused"})
+	assert.Equal(t, "CPUUtilization=82.35 > target=70 → +1; 1 replicas remaining | Paused → paused", merged.Explanation)
+}
+
+// recordingPlans records the archived dry-run plans.
+type recordingPlans struct {
+	plans []audit.Plan
+}
+
+func (r *recordingPlans) SavePlan(ctx context.Context, plan audit.Plan) error {
+	r.plans = append(r.plans, plan)
+	return nil
+}
+
+// TestReportOutcome_ArchivesPlan tests that the plan of a dry-run action lists the instances it would create.
+func TestReportOutcome_ArchivesPlan(t *testing.T) {
+	plans := &recordingPlans{}
+	docdbAutoScaler := &DocumentDB{
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		MaxCapacity: 5,
+		DryRun:      true,
+		Notifier:    &NoOpNotifier{},
+		Plans:       plans,
+	}
+	docdbAutoScaler.lastResult = NewScalingResult(true)
+	docdbAutoScaler.recordMetric("CPUUtilization", 90, 60)
+	docdbAutoScaler.recordCapacity(2, 3)
+	docdbAutoScaler.recordAdded("test-cluster-replica-1", "db.r6g.large")
+	docdbAutoScaler.recordDecision(DecisionScaleOut)
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+
+	assert.Len(t, plans.plans, 1)
+	plan := plans.plans[0]
+	assert.Equal(t, DecisionScaleOut, plan.Decision)
+	assert.Equal(t, 5, plan.Inputs.MaxCapacity)
+	assert.Equal(t, float64(90), *plan.MetricValue)
+	assert.Equal(t, 3, *plan.DesiredCapacity)
+	assert.Equal(t, []string{"test-cluster-replica-1"}, plan.InstancesToCreate)
+	assert.Equal(t, []string{}, plan.InstancesToRemove)
+
+	// Plans are only archived in dry-run
+	docdbAutoScaler.DryRun = false
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+	assert.Len(t, plans.plans, 1)
+}
+
+// fixedPrices prices instances by class, regardless of the engine and region.
+type fixedPrices map[string]float64
+
+func (f fixedPrices) HourlyPrice(ctx context.Context, engine, region, instanceClass string) (float64, error) {
+	price, found := f[instanceClass]
+	if !found {
+		return 0, errors.New("no price")
+	}
+	return price, nil
+}
+
+// TestReportOutcome_EstimatesCostDelta tests that the cost delta of the instances added and removed is
+// reported in the result and the dry-run plan, and omitted when a price is unknown.
+func TestReportOutcome_EstimatesCostDelta(t *testing.T) {
+	plans := &recordingPlans{}
+	docdbAutoScaler := &DocumentDB{
+		Logger:    getTestLogger(),
+		ClusterID: "test-cluster",
+		Region:    "us-east-1",
+		DryRun:    true,
+		Notifier:  &NoOpNotifier{},
+		Plans:     plans,
+		Prices:    fixedPrices{"db.r6g.large": 0.277, "db.r6g.xlarge": 0.554},
+	}
+	docdbAutoScaler.lastResult = NewScalingResult(true)
+	docdbAutoScaler.recordAdded("test-cluster-replica-1", "db.r6g.xlarge")
+	docdbAutoScaler.recordAdded("test-cluster-replica-2", "db.r6g.xlarge")
+	docdbAutoScaler.recordRemoved("test-cluster-replica-0", "db.r6g.large")
+	docdbAutoScaler.recordDecision(DecisionScaleOut)
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+
+	expected := &pricing.Cost{Hourly: 0.831, Monthly: 606.63}
+	assert.Equal(t, expected, docdbAutoScaler.LastResult().CostDelta)
+	assert.Len(t, plans.plans, 1)
+	assert.Equal(t, expected, plans.plans[0].CostDelta)
+
+	docdbAutoScaler.lastResult = NewScalingResult(true)
+	docdbAutoScaler.recordAdded("test-cluster-replica-3", "db.t4g.medium")
+	docdbAutoScaler.ReportOutcome(context.Background(), nil)
+	assert.Nil(t, docdbAutoScaler.LastResult().CostDelta, "unknown prices are not estimated")
+}
+
+// simulatingIAM allows the actions it lists.
+type simulatingIAM struct {
+	allowed []string
+}
+
+func (s *simulatingIAM) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	decision := iamTypes.PolicyEvaluationDecisionTypeImplicitDeny
+	if slices.Contains(s.allowed, params.ActionNames[0]) {
+		decision = iamTypes.PolicyEvaluationDecisionTypeAllowed
+	}
+	return &iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []iamTypes.EvaluationResult{{EvalActionName: aws.String(params.ActionNames[0]), EvalDecision: decision}},
+	}, nil
+}
+
+// TestCheckPermissions tests that a denied permission makes the report unhealthy.
+func TestCheckPermissions(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{ClusterID: "test-cluster"}
+	permissions := docdbAutoScaler.ClusterPermissions("arn:aws:rds:us-east-1:123456789012:cluster:test-cluster")
+	assert.Contains(t, permissions, Permission{Action: "rds:CreateDBInstance", Resource: "arn:aws:rds:us-east-1:123456789012:db:test-cluster-healthcheck"})
+
+	report := NewHealthReport("test-cluster")
+	CheckPermissions(context.Background(), &simulatingIAM{allowed: []string{"rds:DescribeDBClusters"}}, "arn:aws:iam::123456789012:role/autoscaler", permissions[:2], report)
+	assert.False(t, report.Healthy)
+	assert.Equal(t, HealthPass, report.Checks[0].Status)
+	assert.Equal(t, HealthFail, report.Checks[1].Status)
+	assert.Contains(t, report.Checks[1].Error, "implicitDeny")
+
+	assert.Equal(t, "arn:aws:iam::123456789012:role/autoscaler", PrincipalARN("arn:aws:sts::123456789012:assumed-role/autoscaler/session"))
+	assert.Equal(t, "arn:aws:iam::123456789012:user/operator", PrincipalARN("arn:aws:iam::123456789012:user/operator"))
+}
+
+// heldLock is a lock held by another invocation.
+type heldLock struct{}
+
+func (heldLock) Acquire(ctx context.Context, key, owner string) error { return lock.ErrLocked }
+
+func (heldLock) Release(ctx context.Context, key, owner string) error { return nil }
+
+// TestExecuteScalingAction_Locked tests that the action is skipped while another invocation holds the
+// lock of the cluster, without calling the cluster APIs.
+func TestExecuteScalingAction_Locked(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{
+		Logger:    getTestLogger(),
+		ClusterID: "test-cluster",
+		Notifier:  &NoOpNotifier{},
+		Lock:      heldLock{},
+	}
+
+	assert.NoError(t, docdbAutoScaler.ExecuteScalingAction(context.Background()))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionNoAction, result.Decision)
+	assert.Equal(t, []string{ReasonLocked}, result.ReasonCodes)
+}
+
+// TestApplyDesiredCapacity_Cooldown tests that a scale-out is skipped until the cooldown of the last
+// scale-out, read from the cluster tags, has elapsed.
+func TestApplyDesiredCapacity_Cooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		RDSClient:        mockRDSClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		ScaleOutCooldown: 600,
+		EnforceCooldowns: true,
+		Notifier:         &NoOpNotifier{},
+	}
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					TagList: []rdsTypes.Tag{
+						{
+							Key:   awsString("docdb-autoscaler:last-scale-out"),
+							Value: awsString(time.Now().Add(-5 * time.Minute).UTC().Format(time.RFC3339)),
+						},
+					},
+				},
+			},
+		}, nil)
+
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(context.Background(), 2, 3))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionNoAction, result.Decision)
+	assert.Equal(t, []string{ConstraintCooldown}, result.Constraints)
+}
+
+// memoryAudit keeps audit records in memory.
+type memoryAudit struct {
+	records []audit.Record
+}
+
+func (m *memoryAudit) Append(ctx context.Context, record audit.Record) error {
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *memoryAudit) List(ctx context.Context, clusterID string, since, until time.Time) ([]audit.Record, error) {
+	var records []audit.Record
+	for _, record := range m.records {
+		if record.ClusterID == clusterID && !record.Time.Before(since) && record.Time.Before(until) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (m *memoryAudit) Clusters(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// TestApplyDesiredCapacity_ShadowCooldown tests that in shadow mode, a scale-out is planned without
+// touching the cluster and recorded as a shadow decision, and that the next one waits for the cooldown of
+// that shadow decision, read from the audit log rather than the cluster tags.
+func TestApplyDesiredCapacity_ShadowCooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	store := &memoryAudit{}
+	recorder := &recordingMetrics{}
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		ScaleOutCooldown: 600,
+		EnforceCooldowns: true,
+		DryRun:           true,
+		Shadow:           true,
+		Audit:            store,
+		Metrics:          recorder,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	// Only reads: no instance is created and no tag written
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("writer-instance"),
+					DBInstanceClass:      awsString("db.r6g.large"),
+					DBInstanceStatus:     awsString("available"),
+				},
+				{
+					DBInstanceIdentifier: awsString("replica-1"),
+					DBInstanceClass:      awsString("db.r6g.large"),
+					DBInstanceStatus:     awsString("available"),
+				},
+			},
+		}, nil).AnyTimes()
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{DBInstanceIdentifier: awsString("writer-instance"), IsClusterWriter: awsBool(true)},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	ctx := context.Background()
+	docdbAutoScaler.lastResult = NewScalingResult(true)
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(ctx, 1, 2))
+	docdbAutoScaler.ReportOutcome(ctx, nil)
+	assert.Equal(t, DecisionScaleOut, docdbAutoScaler.LastResult().Decision)
+	assert.Equal(t, 1, docdbAutoScaler.LastResult().ReplicasAdded)
+	if assert.Len(t, store.records, 1) {
+		assert.True(t, store.records[0].Shadow)
+		assert.True(t, store.records[0].DryRun)
+		assert.Equal(t, 2, *store.records[0].DesiredCapacity)
+	}
+	if assert.Len(t, recorder.invocations, 1) {
+		assert.True(t, recorder.invocations[0].Shadow)
+	}
+
+	docdbAutoScaler.lastResult = NewScalingResult(true)
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(ctx, 1, 2))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionNoAction, result.Decision)
+	assert.Equal(t, []string{ConstraintCooldown}, result.Constraints)
+}
+
+// TestSimulate tests that recorded metrics are spread over the simulated readers, and that the simulated
+// scale-in waits for the cooldown of the scale-out.
+func TestSimulate(t *testing.T) {
+	docdbAutoScaler := &DocumentDB{
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MetricName:       "CPUUtilization",
+		TargetValue:      50,
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		ScaleInCooldown:  900,
+		EnforceCooldowns: true,
+	}
+	since := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return since.Add(time.Duration(minutes) * time.Minute) }
+	points := []metricPoint{
+		{at(0), 50},
+		{at(5), 100}, // Scale out to 4
+		{at(10), 100},
+		{at(15), 20}, // In the scale-in cooldown
+		{at(25), 20}, // Scale in, one reader at a time
+	}
+
+	simulation := docdbAutoScaler.simulate(since, at(30), 5*time.Minute, points, func(time.Time) int { return 2 })
+	var capacities []int
+	for _, step := range simulation.Steps {
+		capacities = append(capacities, step.Capacity)
+	}
+	assert.Equal(t, []int{2, 4, 4, 4, 3}, capacities)
+	assert.Equal(t, 50.0, simulation.Steps[2].SimulatedMetric, "the load of 2 readers is spread over 4")
+	assert.Equal(t, ConstraintCooldown, simulation.Steps[3].Constraint)
+	assert.Equal(t, 1, simulation.ScaleOuts)
+	assert.Equal(t, 1, simulation.ScaleIns)
+	assert.Equal(t, 4, simulation.PeakCapacity)
+	assert.Equal(t, 1, simulation.MissingDatapoints)
+	assert.Equal(t, 1, simulation.AboveTargetSteps)
+	assert.InDelta(t, 17.0/12, simulation.ReplicaHours, 1e-9)
+
+	_, err := (&DocumentDB{ScheduledScaling: true}).Simulate(context.Background(), since, at(30), 0)
+	assert.ErrorIs(t, err, ErrNoSimulationTarget)
+}
+
+// TestExecuteScalingAction_Reconciles tests that a reader missing from the desired capacity kept in the
+// cluster tags is restored before the metric is evaluated.
+func TestExecuteScalingAction_Reconciles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:          mockDocDBClient,
+		RDSClient:            mockRDSClient,
+		Logger:               getTestLogger(),
+		ClusterID:            "test-cluster",
+		MetricName:           "CPUUtilization",
+		TargetValue:          60,
+		MinCapacity:          1,
+		MaxCapacity:          5,
+		TrackDesiredCapacity: true,
+		Notifier:             &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("replica-1"),
+					DBInstanceStatus:     awsString("available"),
+				},
+				{
+					DBInstanceIdentifier: awsString("writer-instance"),
+					DBInstanceStatus:     awsString("available"),
+				},
+			},
+		}, nil).AnyTimes()
+
+	// The last scale-out aimed for 2 readers, but one of them is gone
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterArn:        awsString("arn:aws:rds:region:account-id:cluster:test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+						{
+							DBInstanceIdentifier: awsString("replica-1"),
+							IsClusterWriter:      awsBool(false),
+						},
+					},
+					TagList: []rdsTypes.Tag{
+						{
+							Key:   awsString("docdb-autoscaler:desired-capacity"),
+							Value: awsString("2"),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+			return &docdb.CreateDBInstanceOutput{
+				DBInstance: &docdbTypes.DBInstance{
+					DBInstanceIdentifier: input.DBInstanceIdentifier,
+					DBInstanceArn:        aws.String("arn:aws:docdb:region:account-id:db:" + *input.DBInstanceIdentifier),
+				},
+			}, nil
+		}).Times(1)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).AnyTimes()
+
+	assert.NoError(t, docdbAutoScaler.ExecuteScalingAction(context.Background()))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleOut, result.Decision)
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Equal(t, []string{ReasonReconcile}, result.ReasonCodes)
+}
+
+// TestExecuteScalingAction_RemovesOrphans tests that a failed replica of the autoscaler is removed, while
+// a failed reader created by hand is left alone.
+func TestExecuteScalingAction_RemovesOrphans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:   mockDocDBClient,
+		RDSClient:     mockRDSClient,
+		Logger:        getTestLogger(),
+		ClusterID:     "test-cluster",
+		MetricName:    "CPUUtilization",
+		TargetValue:   60,
+		MinCapacity:   1,
+		MaxCapacity:   5,
+		RemoveOrphans: true,
+		Notifier:      &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("writer-instance"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:writer-instance"),
+					DBInstanceStatus:     awsString("available"),
+				},
+				{
+					DBInstanceIdentifier: awsString("manual-reader"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:manual-reader"),
+					DBInstanceStatus:     awsString("failed"),
+				},
+				{
+					DBInstanceIdentifier: awsString("test-cluster-reader-1"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:test-cluster-reader-1"),
+					DBInstanceStatus:     awsString("failed"),
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
+			if aws.ToString(input.ResourceName) == "arn:aws:docdb:region:account-id:db:test-cluster-reader-1" {
+				return &docdb.ListTagsForResourceOutput{
+					TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+				}, nil
+			}
+			return &docdb.ListTagsForResourceOutput{}, nil
+		}).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+			assert.Equal(t, "test-cluster-reader-1", aws.ToString(input.DBInstanceIdentifier))
+			return &docdb.DeleteDBInstanceOutput{}, nil
+		}).Times(1)
+
+	assert.NoError(t, docdbAutoScaler.ExecuteScalingAction(context.Background()))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleIn, result.Decision)
+	assert.Equal(t, []string{"test-cluster-reader-1"}, result.RemovedInstanceIDs)
+	assert.Equal(t, []string{ReasonOrphanCleanup}, result.ReasonCodes)
+}
+
+// TestExecuteScalingAction_ReplacesFailedReplicas tests that a failed replica of the autoscaler is
+// removed and recreated.
+func TestExecuteScalingAction_ReplacesFailedReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:           mockDocDBClient,
+		RDSClient:             mockRDSClient,
+		Logger:                getTestLogger(),
+		ClusterID:             "test-cluster",
+		MinCapacity:           1,
+		MaxCapacity:           5,
+		ReplaceFailedReplicas: true,
+		Notifier:              &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{
+					DBInstanceIdentifier: awsString("writer-instance"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:writer-instance"),
+					DBInstanceClass:      awsString("db.r6g.large"),
+					DBInstanceStatus:     awsString("available"),
+				},
+				{
+					DBInstanceIdentifier: awsString("test-cluster-reader-1"),
+					DBInstanceArn:        awsString("arn:aws:docdb:region:account-id:db:test-cluster-reader-1"),
+					DBInstanceStatus:     awsString("incompatible-parameters"),
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.ListTagsForResourceOutput{
+			TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DeleteDBInstanceOutput{}, nil).Times(1)
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+			return &docdb.CreateDBInstanceOutput{
+				DBInstance: &docdbTypes.DBInstance{
+					DBInstanceIdentifier: input.DBInstanceIdentifier,
+					DBInstanceArn:        aws.String("arn:aws:docdb:region:account-id:db:" + *input.DBInstanceIdentifier),
+				},
+			}, nil
+		}).Times(1)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).AnyTimes()
+
+	assert.NoError(t, docdbAutoScaler.ExecuteScalingAction(context.Background()))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionReplace, result.Decision)
+	assert.Equal(t, []string{"test-cluster-reader-1"}, result.RemovedInstanceIDs)
+	assert.Equal(t, 1, result.ReplicasAdded)
+}
+
+// TestExecuteScalingAction_CircuitOpen tests that no scaling happens until the circuit opened by repeated failures closes.
+func TestExecuteScalingAction_CircuitOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		CircuitThreshold: 3,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	openUntil := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+	// The paused and circuit checks share one description of the cluster
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					TagList: []rdsTypes.Tag{
+						{
+							Key:   awsString("docdb-autoscaler-circuit-open-until"),
+							Value: awsString(openUntil),
+						},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	// No instance lookups or mutations while the circuit is open
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := docdbAutoScaler.ExecuteScalingAction(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, DecisionPaused, docdbAutoScaler.LastResult().Decision)
+	assert.Contains(t, docdbAutoScaler.LastResult().ReasonCodes, ReasonCircuitOpen)
+}
+
+// TestExecuteScalingAction_PauseSwitch tests that the pause parameter, a pause parameter that cannot be
+// read and the paused tag skip the action.
+func TestExecuteScalingAction_PauseSwitch(t *testing.T) {
+	tests := []struct {
+		name         string
+		parameter    *ssm.GetParameterOutput
+		parameterErr error
+		tags         []rdsTypes.Tag
+	}{
+		{
+			name:      "parameter",
+			parameter: &ssm.GetParameterOutput{Parameter: &ssmTypes.Parameter{Value: awsString("true")}},
+		},
+		{
+			name:         "unreadable parameter",
+			parameterErr: errors.New("AccessDeniedException: not authorized to perform ssm:GetParameter"),
+		},
+		{
+			name: "tag",
+			tags: []rdsTypes.Tag{{Key: awsString("docdb-autoscaler-paused"), Value: awsString("true")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+			mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+			mockSSMClient := mockDocDB.NewMockSSMAPI(ctrl)
+
+			docdbAutoScaler := &DocumentDB{
+				DocDBClient:    mockDocDBClient,
+				RDSClient:      mockRDSClient,
+				SSMClient:      mockSSMClient,
+				PauseParameter: "/test-cluster/docdb-autoscaler/paused",
+				Logger:         getTestLogger(),
+				ClusterID:      "test-cluster",
+				MinCapacity:    1,
+				MaxCapacity:    5,
+				Notifier:       &NoOpNotifier{},
+			}
+
+			mockRDSClient.
+				EXPECT().
+				DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&rds.DescribeDBClustersOutput{
+					DBClusters: []rdsTypes.DBCluster{{DBClusterIdentifier: awsString("test-cluster"), TagList: tt.tags}},
+				}, nil).Times(1)
+
+			// A missing parameter pauses nothing
+			parameterErr := tt.parameterErr
+			if tt.parameter == nil && parameterErr == nil {
+				parameterErr = &ssmTypes.ParameterNotFound{}
+			}
+			mockSSMClient.
+				EXPECT().
+				GetParameter(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(tt.parameter, parameterErr).
+				Times(1)
+
+			// No instance lookups or mutations while paused
+			mockDocDBClient.
+				EXPECT().
+				DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+
+			err := docdbAutoScaler.ExecuteScalingAction(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, DecisionPaused, docdbAutoScaler.LastResult().Decision)
+			assert.Contains(t, docdbAutoScaler.LastResult().ReasonCodes, ReasonPaused)
+		})
+	}
+}
+
+// TestScaleToCapacity_StopsBeforeDeadline tests that no replica is added once the deadline of the invocation is too close.
+func TestScaleToCapacity_StopsBeforeDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:    mockDocDBClient,
+		RDSClient:      mockRDSClient,
+		Logger:         getTestLogger(),
+		ClusterID:      "test-cluster",
+		MinCapacity:    1,
+		MaxCapacity:    5,
+		DeadlineMargin: time.Minute,
+		Notifier:       &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	// The invocation is about to time out, so no replica is created
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := docdbAutoScaler.ScaleToCapacity(ctx, 3)
+	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleOut, result.Decision)
+	assert.True(t, result.Partial)
+	assert.Equal(t, 0, result.ReplicasAdded)
+	assert.Equal(t, 2, result.ReplicasRemaining)
+	assert.Contains(t, result.ReasonCodes, ReasonDeadline)
+}
+
+// TestRemoveScheduledReplicas_StopsBeforeDeadline tests that no scheduled replica is deleted once the deadline of the invocation is too close.
+func TestRemoveScheduledReplicas_StopsBeforeDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:    mockDocDBClient,
+		RDSClient:      mockRDSClient,
+		Logger:         getTestLogger(),
+		ClusterID:      "test-cluster",
+		MinCapacity:    1,
+		MaxCapacity:    5,
+		DeadlineMargin: time.Minute,
+		Notifier:       &NoOpNotifier{},
+		lastResult:     NewScalingResult(false),
+	}
+
+	scheduledInstances := []docdbTypes.DBInstance{
+		{DBInstanceIdentifier: awsString("scheduled-1"), DBInstanceArn: awsString("arn:scheduled-1"), DBInstanceStatus: awsString("available")},
+		{DBInstanceIdentifier: awsString("scheduled-2"), DBInstanceArn: awsString("arn:scheduled-2"), DBInstanceStatus: awsString("available")},
+	}
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: append([]docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+			}, scheduledInstances...),
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	// The invocation is about to time out, so no replica is deleted
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := docdbAutoScaler.RemoveScheduledReplicas(ctx, scheduledInstances)
+	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.True(t, result.Partial)
+	assert.Equal(t, 0, result.ReplicasRemoved)
+	assert.Equal(t, 2, result.ReplicasRemaining)
+	assert.Contains(t, result.ReasonCodes, ReasonDeadline)
+}
+
+// TestScaleToCapacity_CappedByInstanceQuota tests that a scale-out stops at the room left in the DB instances quota.
+func TestScaleToCapacity_CappedByInstanceQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:        mockDocDBClient,
+		RDSClient:          mockRDSClient,
+		Logger:             getTestLogger(),
+		ClusterID:          "test-cluster",
+		MinCapacity:        1,
+		MaxCapacity:        5,
+		CheckInstanceQuota: true,
+		Notifier:           &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeAccountAttributes(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeAccountAttributesOutput{
+			AccountQuotas: []rdsTypes.AccountQuota{
+				{AccountQuotaName: awsString("DBClusters"), Used: aws.Int64(3), Max: aws.Int64(40)},
+				{AccountQuotaName: awsString("DBInstances"), Used: aws.Int64(39), Max: aws.Int64(40)},
+			},
+		}, nil).Times(1)
+
+	// The quota has room for one of the two replicas to add
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceArn: awsString("arn:new-replica")}}, nil).
+		Times(1)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).
+		Times(1)
+
+	err := docdbAutoScaler.ScaleToCapacity(context.Background(), 3)
+	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleOut, result.Decision)
+	assert.True(t, result.Partial)
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Equal(t, 1, result.ReplicasRemaining)
+	assert.Contains(t, result.Constraints, ConstraintInstanceQuota)
+}
+
+// TestScaleToCapacity_CappedByBudget tests that a scale-out stops at the replicas MaxHourlyCost has room for.
+func TestScaleToCapacity_CappedByBudget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:   mockDocDBClient,
+		RDSClient:     mockRDSClient,
+		Logger:        getTestLogger(),
+		ClusterID:     "test-cluster",
+		MinCapacity:   1,
+		MaxCapacity:   5,
+		MaxHourlyCost: 0.6,
+		Prices:        fixedPrices{"db.r6g.large": 0.277},
+		Notifier:      &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceClass: awsString("db.r6g.large"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceClass: awsString("db.r6g.large"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	// The reader costs $0.277/hour, so the budget has room for one of the three replicas to add
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceArn: awsString("arn:new-replica")}}, nil).
+		Times(1)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).
+		Times(1)
+
+	err := docdbAutoScaler.ScaleToCapacity(context.Background(), 4)
+	assert.NoError(t, err)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, DecisionScaleOut, result.Decision)
+	assert.True(t, result.Partial)
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Equal(t, 2, result.ReplicasRemaining)
+	assert.Contains(t, result.Constraints, ConstraintBudget)
+}
+
+// TestApply tests that a plan is carried out, and that it is not applied once the readers changed.
+func TestApply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler, err := New("test-cluster",
+		WithClients(mockDocDBClient, mockCloudWatch.NewMockCloudWatchAPI(ctrl), mockRDSClient),
+		WithCapacity(1, 5),
+		WithLogger(getTestLogger()),
+	)
+	assert.NoError(t, err)
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{{
+				DBClusterIdentifier: awsString("test-cluster"),
+				DBClusterMembers: []rdsTypes.DBClusterMember{
+					{DBInstanceIdentifier: awsString("writer-instance"), IsClusterWriter: awsBool(true)},
+					{DBInstanceIdentifier: awsString("replica-1"), IsClusterWriter: awsBool(false)},
+				},
+			}},
+		}, nil).AnyTimes()
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	// The plan was made with two readers, the cluster has one now
+	stale := docdbAutoScaler.PlanScaling(Observation{CurrentCapacity: 2, DesiredCapacity: 1})
+	_, err = docdbAutoScaler.Apply(context.Background(), stale)
+	assert.ErrorIs(t, err, ErrStalePlan)
+
+	plan := docdbAutoScaler.PlanScaling(Observation{CurrentCapacity: 1, DesiredCapacity: 1})
+	result, err := docdbAutoScaler.Apply(context.Background(), plan)
+	assert.NoError(t, err)
+	assert.Equal(t, DecisionNoAction, result.Decision)
+	assert.Equal(t, 1, *result.currentCapacity)
+}
+
+// TestApplyDesiredCapacity_Canary tests that a scale-out adds a canary replica first, waits for it while
+// it is created, and adds the rest once it served for the canary window, regardless of the scale-out
+// cooldown the canary started.
+func TestApplyDesiredCapacity_Canary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		ScaleOutCooldown: 600,
+		EnforceCooldowns: true,
+		CanaryScaleOut:   true,
+		CanaryWindow:     5 * time.Minute,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	instances := []docdbTypes.DBInstance{
+		{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceClass: awsString("db.r6g.large"), DBInstanceStatus: awsString("available")},
+	}
+	clusterTags := map[string]string{}
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.DescribeDBInstancesInput, optFns ...func(*docdb.Options)) (*docdb.DescribeDBInstancesOutput, error) {
+			return &docdb.DescribeDBInstancesOutput{DBInstances: instances}, nil
+		}).AnyTimes()
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+			var tagList []rdsTypes.Tag
+			for key, value := range clusterTags {
+				tagList = append(tagList, rdsTypes.Tag{Key: awsString(key), Value: awsString(value)})
+			}
+			return &rds.DescribeDBClustersOutput{
+				DBClusters: []rdsTypes.DBCluster{
+					{
+						DBClusterIdentifier: awsString("test-cluster"),
+						DBClusterArn:        awsString("arn:test-cluster"),
+						DBClusterMembers:    []rdsTypes.DBClusterMember{{DBInstanceIdentifier: awsString("writer-instance"), IsClusterWriter: awsBool(true)}},
+						TagList:             tagList,
+					},
+				},
+			}, nil
+		}).AnyTimes()
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+			if aws.ToString(input.ResourceName) == "arn:test-cluster" {
+				for _, tag := range input.Tags {
+					clusterTags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+				}
+			}
+			return &docdb.AddTagsToResourceOutput{}, nil
+		}).AnyTimes()
+	mockDocDBClient.
+		EXPECT().
+		RemoveTagsFromResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+			for _, key := range input.TagKeys {
+				delete(clusterTags, key)
+			}
+			return &docdb.RemoveTagsFromResourceOutput{}, nil
+		}).AnyTimes()
+	var created []string
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+			instanceID := aws.ToString(input.DBInstanceIdentifier)
+			created = append(created, instanceID)
+			instances = append(instances, docdbTypes.DBInstance{DBInstanceIdentifier: input.DBInstanceIdentifier, DBInstanceClass: input.DBInstanceClass, DBInstanceStatus: awsString("creating")})
+			return &docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceIdentifier: input.DBInstanceIdentifier, DBInstanceArn: awsString("arn:" + instanceID)}}, nil
+		}).Times(3)
+	ctx := context.Background()
+
+	// A single canary replica of the three to add
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(ctx, 1, 4))
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Equal(t, 2, result.ReplicasRemaining)
+	assert.Contains(t, result.Constraints, ConstraintCanary)
+	assert.Len(t, created, 1)
+	assert.Equal(t, created[0], clusterTags[canaryTagKey])
+
+	// The canary is still being created
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(ctx, 2, 4))
+	assert.Equal(t, 0, docdbAutoScaler.LastResult().ReplicasAdded)
+	assert.Equal(t, []string{ConstraintCanary}, docdbAutoScaler.LastResult().Constraints)
+
+	// Available for longer than the canary window: the rest is added despite the cooldown
+	instances[1].DBInstanceStatus = awsString("available")
+	clusterTags[canaryTagKey] = created[0] + "@" + time.Now().Add(-10*time.Minute).UTC().Format(time.RFC3339)
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	assert.NoError(t, docdbAutoScaler.applyDesiredCapacity(ctx, 2, 4))
+	assert.Equal(t, 2, docdbAutoScaler.LastResult().ReplicasAdded)
+	assert.NotContains(t, clusterTags, canaryTagKey)
+}
+
+// TestHandleRDSEvent_Maintenance tests that a maintenance event of an instance of the cluster pauses
+// autoscaling, until MaintenancePause elapses should its completion be lost.
+func TestHandleRDSEvent_Maintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		MaintenancePause: time.Hour,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("maintenance")},
+			},
+		}, nil).AnyTimes()
+	clusterTags := map[string]string{}
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+			var tagList []rdsTypes.Tag
+			for key, value := range clusterTags {
+				tagList = append(tagList, rdsTypes.Tag{Key: awsString(key), Value: awsString(value)})
+			}
+			return &rds.DescribeDBClustersOutput{
+				DBClusters: []rdsTypes.DBCluster{
+					{
+						DBClusterIdentifier: awsString("test-cluster"),
+						DBClusterArn:        awsString("arn:test-cluster"),
+						DBClusterMembers:    []rdsTypes.DBClusterMember{{DBInstanceIdentifier: awsString("writer-instance"), IsClusterWriter: awsBool(true)}},
+						TagList:             tagList,
+					},
+				},
+			}, nil
+		}).AnyTimes()
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+			for _, tag := range input.Tags {
+				clusterTags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			return &docdb.AddTagsToResourceOutput{}, nil
+		}).Times(1)
+
+	message := `{"Event Source":"db-instance","Event Time":"2026-10-14 02:00:00.000","Source ID":"replica-1","Source ARN":"arn:aws:rds:us-east-1:123456789012:db:replica-1",` +
+		`"Event ID":"http://docs.amazonwebservices.com/AmazonRDS/latest/UserGuide/USER_Events.html#RDS-EVENT-0026","Event Message":"Offline maintenance of the DB instance is taking place"}`
+	event, ok := ParseRDSEvent([]byte(message))
+	assert.True(t, ok)
+	assert.Equal(t, "RDS-EVENT-0026", event.Code())
+	assert.Equal(t, RDSEventMaintenanceStarted, event.Kind())
+	_, ok = ParseRDSEvent([]byte(`{"AlarmName":"high-cpu","NewStateValue":"ALARM"}`))
+	assert.False(t, ok)
+
+	ctx := context.Background()
+	relates, err := docdbAutoScaler.RelatesTo(ctx, event)
+	assert.NoError(t, err)
+	assert.True(t, relates)
+	relates, err = docdbAutoScaler.RelatesTo(ctx, RDSEvent{EventSource: "db-instance", SourceID: "other-instance"})
+	assert.NoError(t, err)
+	assert.False(t, relates)
+
+	assert.NoError(t, docdbAutoScaler.HandleRDSEvent(ctx, event))
+	assert.Equal(t, DecisionPaused, docdbAutoScaler.LastResult().Decision)
+	assert.True(t, strings.HasPrefix(clusterTags[maintenanceTagKey], "replica-1@"))
+
+	// Actions are skipped during the maintenance
+	assert.NoError(t, docdbAutoScaler.ExecuteScalingAction(ctx))
+	assert.Equal(t, DecisionPaused, docdbAutoScaler.LastResult().Decision)
+	assert.Equal(t, []string{ReasonMaintenance}, docdbAutoScaler.LastResult().ReasonCodes)
+
+	// A maintenance whose completion was lost pauses nothing after MaintenancePause
+	clusterTags[maintenanceTagKey] = "replica-1@" + time.Now().Add(-2*time.Hour).UTC().Format(time.RFC3339)
+	paused, err := docdbAutoScaler.skipIfPaused(ctx)
+	assert.NoError(t, err)
+	assert.False(t, paused)
+}
+
+// TestAddReplicas_IdentifierAlreadyExists tests that a taken identifier is replaced by a fresh one.
+func TestAddReplicas_IdentifierAlreadyExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		MinCapacity: 1,
+		MaxCapacity: 5,
+		Notifier:    &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	var identifiers []string
+	gomock.InOrder(
+		mockDocDBClient.
+			EXPECT().
+			CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+				identifiers = append(identifiers, aws.ToString(input.DBInstanceIdentifier))
+				return nil, &docdbTypes.DBInstanceAlreadyExistsFault{Message: awsString("DB instance already exists")}
+			}),
+		mockDocDBClient.
+			EXPECT().
+			CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+				identifiers = append(identifiers, aws.ToString(input.DBInstanceIdentifier))
+				return &docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceArn: awsString("arn:new-replica")}}, nil
+			}),
+	)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).
+		Times(1)
+
+	docdbAutoScaler.lastResult = &ScalingResult{}
+	err := docdbAutoScaler.AddReplicas(context.Background(), 1)
+	assert.NoError(t, err)
+
+	assert.Len(t, identifiers, 2)
+	assert.NotEqual(t, identifiers[0], identifiers[1])
+	assert.Regexp(t, `^test-cluster-reader-\d{9}$`, identifiers[1])
+	assert.Equal(t, identifiers[1:], docdbAutoScaler.LastResult().AddedInstanceIDs)
+}
+
+// TestScaleToCapacity_InstanceTypeNotOrderable tests that an instance type not offered for the cluster fails before any replica is created.
+func TestScaleToCapacity_InstanceTypeNotOrderable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:  mockDocDBClient,
+		RDSClient:    mockRDSClient,
+		Logger:       getTestLogger(),
+		ClusterID:    "test-cluster",
+		MinCapacity:  1,
+		MaxCapacity:  5,
+		InstanceType: "db.r9z.large",
+		Notifier:     &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					EngineVersion:       awsString("5.0.0"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeOrderableDBInstanceOptions(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *rds.DescribeOrderableDBInstanceOptionsInput, optFns ...func(*rds.Options)) (*rds.DescribeOrderableDBInstanceOptionsOutput, error) {
+			assert.Equal(t, "docdb", aws.ToString(input.Engine))
+			assert.Equal(t, "5.0.0", aws.ToString(input.EngineVersion))
+			assert.Equal(t, "db.r9z.large", aws.ToString(input.DBInstanceClass))
+			return &rds.DescribeOrderableDBInstanceOptionsOutput{}, nil
+		}).
+		Times(1)
+
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := docdbAutoScaler.ScaleToCapacity(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrInstanceTypeNotOrderable)
+}
+
+// TestGetReaderInstances_Paginated tests that the readers of all pages of DB instances are returned.
+func TestGetReaderInstances_Paginated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		Notifier:    &NoOpNotifier{},
+	}
+
+	gomock.InOrder(
+		mockDocDBClient.
+			EXPECT().
+			DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&docdb.DescribeDBInstancesOutput{
+				DBInstances: []docdbTypes.DBInstance{
+					{DBInstanceIdentifier: awsString("writer-instance")},
+					{DBInstanceIdentifier: awsString("replica-1")},
+				},
+				Marker: awsString("page-2"),
+			}, nil),
+		mockDocDBClient.
+			EXPECT().
+			DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, params *docdb.DescribeDBInstancesInput, optFns ...func(*docdb.Options)) (*docdb.DescribeDBInstancesOutput, error) {
+				assert.Equal(t, "page-2", aws.ToString(params.Marker))
+				return &docdb.DescribeDBInstancesOutput{
+					DBInstances: []docdbTypes.DBInstance{
+						{DBInstanceIdentifier: awsString("replica-2")},
+					},
+				}, nil
+			}),
+	)
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	readerInstances, err := docdbAutoScaler.GetReaderInstances(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, readerInstances, 2)
+	assert.Equal(t, "replica-2", aws.ToString(readerInstances[1].DBInstanceIdentifier))
+}
+
+// TestGetCurrentMetricValue_Concurrent tests that the metric is averaged across readers fetched at the same time.
+func TestGetCurrentMetricValue_Concurrent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	mockCloudWatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:       mockDocDBClient,
+		RDSClient:         mockRDSClient,
+		CloudWatchClient:  mockCloudWatchClient,
+		Logger:            getTestLogger(),
+		ClusterID:         "test-cluster",
+		MetricName:        "CPUUtilization",
+		MetricConcurrency: 2,
+		Notifier:          &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance")},
+				{DBInstanceIdentifier: awsString("replica-1")},
+				{DBInstanceIdentifier: awsString("replica-2")},
+				{DBInstanceIdentifier: awsString("replica-3")},
+			},
+		}, nil).Times(1)
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).Times(1)
+
+	values := map[string]float64{"replica-1": 30, "replica-2": 60, "replica-3": 90}
+	mockCloudWatchClient.
+		EXPECT().
+		GetMetricStatistics(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+			return &cloudwatch.GetMetricStatisticsOutput{
+				Datapoints: []cwTypes.Datapoint{
+					{Average: aws.Float64(values[aws.ToString(params.Dimensions[0].Value)]), Timestamp: aws.Time(time.Now())},
+				},
+			}, nil
+		}).Times(3)
+
+	metricValue, err := docdbAutoScaler.GetCurrentMetricValue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(60), metricValue)
+}
+
+// TestScaleToCapacity_ManagedReplicaCap tests that a scale-out stops at the account cap on the replicas managed
+// by the autoscaler, and fails once the replicas with room are added.
+func TestScaleToCapacity_ManagedReplicaCap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:       mockDocDBClient,
+		RDSClient:         mockRDSClient,
+		Logger:            getTestLogger(),
+		ClusterID:         "test-cluster",
+		MinCapacity:       1,
+		MaxCapacity:       5,
+		AccountReplicaCap: 3,
+		Notifier:          &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	// Two replicas of the account are managed by the autoscaler, one was created by hand
+	mockRDSClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBInstancesOutput{
+			DBInstances: []rdsTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("replica-1"), TagList: []rdsTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}}},
+				{DBInstanceIdentifier: awsString("other-replica"), TagList: []rdsTypes.Tag{{Key: awsString("docdb-autoscaler-scheduler"), Value: awsString("true")}}},
+				{DBInstanceIdentifier: awsString("manual-replica")},
+			},
+		}, nil).Times(1)
+
+	// The cap has room for one of the three replicas to add
+	mockDocDBClient.
+		EXPECT().
+		CreateDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceArn: awsString("arn:new-replica")}}, nil).
+		Times(1)
+	mockDocDBClient.
+		EXPECT().
+		AddTagsToResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.AddTagsToResourceOutput{}, nil).
+		Times(1)
+
+	err := docdbAutoScaler.ScaleToCapacity(context.Background(), 4)
+	assert.ErrorIs(t, err, ErrManagedReplicaCap)
+
+	result := docdbAutoScaler.LastResult()
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Equal(t, 2, result.ReplicasRemaining)
+	assert.True(t, result.Partial)
+	assert.Contains(t, result.Constraints, ConstraintManagedReplicaCap)
+}
+
+// TestScaleToCapacity_BulkScaleInLimit tests that a scale-in beyond BulkScaleInLimit removes only that many
+// replicas, unless it is approved.
+func TestScaleToCapacity_BulkScaleInLimit(t *testing.T) {
+	tests := []struct {
+		name            string
+		approved        bool
+		expectedRemoved int
+	}{
+		{name: "not approved", approved: false, expectedRemoved: 1},
+		{name: "approved", approved: true, expectedRemoved: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+			mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+			docdbAutoScaler := &DocumentDB{
+				DocDBClient:      mockDocDBClient,
+				RDSClient:        mockRDSClient,
+				Logger:           getTestLogger(),
+				ClusterID:        "test-cluster",
+				MinCapacity:      0,
+				MaxCapacity:      5,
+				AllowZeroReaders: true,
+				BulkScaleInLimit: 1,
+				AllowBulkScaleIn: tt.approved,
+				Notifier:         &NoOpNotifier{},
+			}
+
+			mockDocDBClient.
+				EXPECT().
+				DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&docdb.DescribeDBInstancesOutput{
+					DBInstances: []docdbTypes.DBInstance{
+						{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+						{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+						{DBInstanceIdentifier: awsString("replica-2"), DBInstanceArn: awsString("arn:replica-2"), DBInstanceStatus: awsString("available")},
+						{DBInstanceIdentifier: awsString("replica-3"), DBInstanceArn: awsString("arn:replica-3"), DBInstanceStatus: awsString("available")},
+					},
+				}, nil).AnyTimes()
+
+			mockRDSClient.
+				EXPECT().
+				DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&rds.DescribeDBClustersOutput{
+					DBClusters: []rdsTypes.DBCluster{
+						{
+							DBClusterIdentifier: awsString("test-cluster"),
+							DBClusterMembers: []rdsTypes.DBClusterMember{
+								{
+									DBInstanceIdentifier: awsString("writer-instance"),
+									IsClusterWriter:      awsBool(true),
+								},
+							},
+						},
+					},
+				}, nil).AnyTimes()
+
+			mockDocDBClient.
+				EXPECT().
+				ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&docdb.ListTagsForResourceOutput{
+					TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+				}, nil).AnyTimes()
+
+			mockDocDBClient.
+				EXPECT().
+				DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&docdb.DeleteDBInstanceOutput{}, nil).Times(tt.expectedRemoved)
+
+			err := docdbAutoScaler.ScaleToCapacity(context.Background(), 0)
+			assert.NoError(t, err)
+
+			result := docdbAutoScaler.LastResult()
+			assert.Equal(t, tt.expectedRemoved, result.ReplicasRemoved)
+			assert.Equal(t, !tt.approved, result.Partial)
+			assert.Equal(t, !tt.approved, slices.Contains(result.Constraints, ConstraintBulkScaleIn))
+		})
+	}
+}
+
+// TestRemoveReplicas_SkipsInstancesThatCannotBeDeleted tests that a scale-in removes another candidate
+// instead of the replicas that are backing up, have pending modifications or whose deletion is refused.
+func TestRemoveReplicas_SkipsInstancesThatCannotBeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient: mockDocDBClient,
+		RDSClient:   mockRDSClient,
+		Logger:      getTestLogger(),
+		ClusterID:   "test-cluster",
+		MinCapacity: 1,
+		MaxCapacity: 5,
+		Notifier:    &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("backing-up")},
+				{DBInstanceIdentifier: awsString("replica-2"), DBInstanceArn: awsString("arn:replica-2"), DBInstanceStatus: awsString("available"), PendingModifiedValues: &docdbTypes.PendingModifiedValues{DBInstanceClass: awsString("db.r6g.xlarge")}},
+				{DBInstanceIdentifier: awsString("replica-3"), DBInstanceArn: awsString("arn:replica-3"), DBInstanceStatus: awsString("available"), PendingModifiedValues: &docdbTypes.PendingModifiedValues{}},
+				{DBInstanceIdentifier: awsString("replica-4"), DBInstanceArn: awsString("arn:replica-4"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.ListTagsForResourceOutput{
+			TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+		}, nil).AnyTimes()
+
+	// The deletion of replica-3 is refused, replica-4 is removed instead
+	var deleted []string
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+			deleted = append(deleted, aws.ToString(input.DBInstanceIdentifier))
+			if aws.ToString(input.DBInstanceIdentifier) == "replica-3" {
+				return nil, &docdbTypes.InvalidDBInstanceStateFault{Message: awsString("DB instance is not in an available state")}
+			}
+			return &docdb.DeleteDBInstanceOutput{}, nil
+		}).Times(2)
+
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	err := docdbAutoScaler.RemoveReplicas(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replica-3", "replica-4"}, deleted)
+	assert.Equal(t, []string{"replica-4"}, docdbAutoScaler.LastResult().RemovedInstanceIDs)
+}
+
+// TestRemoveReplicas_ReaderFloorCountsCountableReaders tests that the readers outside CountableStatuses,
+// e.g. still being created, do not count towards the reader floor of a scale-in.
+func TestRemoveReplicas_ReaderFloorCountsCountableReaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:       mockDocDBClient,
+		RDSClient:         mockRDSClient,
+		Logger:            getTestLogger(),
+		ClusterID:         "test-cluster",
+		MinCapacity:       1,
+		MaxCapacity:       5,
+		CountableStatuses: []string{"available"},
+		Notifier:          &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-2"), DBInstanceArn: awsString("arn:replica-2"), DBInstanceStatus: awsString("creating")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.ListTagsForResourceOutput{
+			TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+		}, nil).AnyTimes()
+
+	// replica-1 is the only reader serving, the one being created does not replace it yet
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(0)
+
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	err := docdbAutoScaler.RemoveReplicas(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Empty(t, docdbAutoScaler.LastResult().RemovedInstanceIDs)
+	assert.Contains(t, docdbAutoScaler.LastResult().Constraints, ConstraintReaderFloor)
+}
+
+// TestRemoveReplicas_SkipsReplicasNotDrained tests that a replica whose connections are at or above
+// DrainConnections is not deleted, another candidate being removed instead.
+func TestRemoveReplicas_SkipsReplicasNotDrained(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	mockCloudWatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		CloudWatchClient: mockCloudWatchClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		DrainConnections: 10,
+		Notifier:         &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceArn: awsString("arn:writer-instance"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceArn: awsString("arn:replica-1"), DBInstanceStatus: awsString("available")},
+				{DBInstanceIdentifier: awsString("replica-2"), DBInstanceArn: awsString("arn:replica-2"), DBInstanceStatus: awsString("available")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	mockDocDBClient.
+		EXPECT().
+		ListTagsForResource(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.ListTagsForResourceOutput{
+			TagList: []docdbTypes.Tag{{Key: awsString("docdb-autoscaler-created"), Value: awsString("true")}},
+		}, nil).AnyTimes()
+
+	// replica-1 still serves 25 clients, replica-2 has drained to 3
+	connections := map[string]float64{"replica-1": 25, "replica-2": 3}
+	mockCloudWatchClient.
+		EXPECT().
+		GetMetricStatistics(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+			assert.Equal(t, "DatabaseConnections", aws.ToString(input.MetricName))
+			instanceID := aws.ToString(input.Dimensions[0].Value)
+			return &cloudwatch.GetMetricStatisticsOutput{
+				Datapoints: []cwTypes.Datapoint{
+					{Timestamp: aws.Time(time.Now().Add(-2 * time.Minute)), Maximum: aws.Float64(0)},
+					{Timestamp: aws.Time(time.Now().Add(-time.Minute)), Maximum: aws.Float64(connections[instanceID])},
+				},
+			}, nil
+		}).Times(2)
+
+	var deleted []string
+	mockDocDBClient.
+		EXPECT().
+		DeleteDBInstance(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, input *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+			deleted = append(deleted, aws.ToString(input.DBInstanceIdentifier))
+			return &docdb.DeleteDBInstanceOutput{}, nil
+		}).Times(1)
+
+	docdbAutoScaler.lastResult = NewScalingResult(false)
+	err := docdbAutoScaler.RemoveReplicas(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replica-2"}, deleted)
+	assert.Contains(t, docdbAutoScaler.LastResult().Constraints, ConstraintConnectionDrain)
+}
+
+// TestRecommend tests that an underused cluster is recommended to remove a reader and to downsize its class,
+// with the estimated savings, and that readers without datapoints are not counted.
+func TestRecommend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDocDBClient := mockDocDB.NewMockDocDBAPI(ctrl)
+	mockRDSClient := mockRDS.NewMockRDSAPI(ctrl)
+	mockCloudWatchClient := mockCloudWatch.NewMockCloudWatchAPI(ctrl)
+
+	docdbAutoScaler := &DocumentDB{
+		DocDBClient:      mockDocDBClient,
+		RDSClient:        mockRDSClient,
+		CloudWatchClient: mockCloudWatchClient,
+		Logger:           getTestLogger(),
+		ClusterID:        "test-cluster",
+		MinCapacity:      1,
+		MaxCapacity:      5,
+		MetricName:       "CPUUtilization",
+		TargetValue:      60,
+		Prices:           fixedPrices{"db.r6g.large": 0.277, "db.r6g.xlarge": 0.554},
+		Notifier:         &NoOpNotifier{},
+	}
+
+	mockDocDBClient.
+		EXPECT().
+		DescribeDBInstances(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&docdb.DescribeDBInstancesOutput{
+			DBInstances: []docdbTypes.DBInstance{
+				{DBInstanceIdentifier: awsString("writer-instance"), DBInstanceClass: awsString("db.r6g.xlarge")},
+				{DBInstanceIdentifier: awsString("replica-1"), DBInstanceClass: awsString("db.r6g.xlarge")},
+				{DBInstanceIdentifier: awsString("replica-2"), DBInstanceClass: awsString("db.r6g.xlarge")},
+				{DBInstanceIdentifier: awsString("replica-3"), DBInstanceClass: awsString("db.r6g.xlarge")},
+				{DBInstanceIdentifier: awsString("replica-new"), DBInstanceClass: awsString("db.r6g.xlarge")},
+			},
+		}, nil).AnyTimes()
+
+	mockRDSClient.
+		EXPECT().
+		DescribeDBClusters(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&rds.DescribeDBClustersOutput{
+			DBClusters: []rdsTypes.DBCluster{
+				{
+					DBClusterIdentifier: awsString("test-cluster"),
+					DBClusterMembers: []rdsTypes.DBClusterMember{
+						{
+							DBInstanceIdentifier: awsString("writer-instance"),
+							IsClusterWriter:      awsBool(true),
+						},
+					},
+				},
+			},
+		}, nil).AnyTimes()
+
+	hourlyAverages := map[string][]float64{
+		"replica-1": {10, 20},
+		"replica-2": {15, 25},
+		"replica-3": {12, 22},
+	}
+	mockCloudWatchClient.
+		EXPECT().
+		GetMetricStatistics(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+			assert.Equal(t, int32(3600), aws.ToInt32(params.Period))
+			output := &cloudwatch.GetMetricStatisticsOutput{}
+			for _, average := range hourlyAverages[aws.ToString(params.Dimensions[0].Value)] {
+				output.Datapoints = append(output.Datapoints, cwTypes.Datapoint{Average: aws.Float64(average), Timestamp: aws.Time(time.Now())})
+			}
+			return output, nil
+		}).Times(4)
+
+	recommendations, err := docdbAutoScaler.Recommend(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultRecommendationDays, recommendations.Days)
+	assert.Equal(t, 3, recommendations.Readers, "the reader without datapoints is not counted")
+	assert.Equal(t, float64(25), recommendations.PeakUtilization)
+	assert.Equal(t, []Recommendation{
+		{Action: RecommendRemoveReplica, Reason: "with 2 readers instead of 3, the peak hourly average would be about 37.5, below the target", Savings: &pricing.Cost{Hourly: 0.554, Monthly: 404.42}},
+		{Action: RecommendDownsizeClass, InstanceClass: "db.r6g.large", Reason: "with db.r6g.large readers instead of db.r6g.xlarge, the peak hourly average would be about 50.0, below the target", Savings: &pricing.Cost{Hourly: 0.831, Monthly: 606.63}},
+	}, recommendations.Recommendations)
+	assert.Contains(t, recommendations.Message(), "- RemoveReplica: with 2 readers instead of 3")
+
+	// Scheduled scaling has no target to recommend against
+	docdbAutoScaler.MetricName = ""
+	_, err = docdbAutoScaler.Recommend(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrNoRecommendationTarget)
+}
+
+// TestEndpointOverrides tests that the endpoints overridden in the environment are reported, that the
+// clients of a cluster call them, and that the S3 client addresses their buckets in the path.
+func TestEndpointOverrides(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_MAX_ATTEMPTS", "1")
+	assert.Empty(t, EndpointOverrides())
+
+	t.Setenv("AWS_ENDPOINT_URL_DOCDB", server.URL)
+	t.Setenv("AWS_ENDPOINT_URL_S3", "http://localhost:4566")
+	assert.Equal(t, map[string]string{"DOCDB": server.URL, "S3": "http://localhost:4566"}, EndpointOverrides())
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	assert.NoError(t, err)
+	_, err = docdb.NewFromConfig(cfg).DescribeDBInstances(context.Background(), &docdb.DescribeDBInstancesInput{})
+	assert.Error(t, err)
+	assert.Len(t, paths, 1, "the DocumentDB client calls the overridden endpoint")
+	assert.True(t, NewS3Client(cfg).Options().UsePathStyle)
+
+	// Ignored as the SDK ignores them
+	t.Setenv("AWS_IGNORE_CONFIGURED_ENDPOINT_URLS", "true")
+	assert.Empty(t, EndpointOverrides())
+	t.Setenv("AWS_IGNORE_CONFIGURED_ENDPOINT_URLS", "")
+	t.Setenv("AWS_ENDPOINT_URL_S3", "")
+	cfg, err = awsconfig.LoadDefaultConfig(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, NewS3Client(cfg).Options().UsePathStyle)
 }
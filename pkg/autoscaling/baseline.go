@@ -0,0 +1,37 @@
+package autoscaling
+
+import "context"
+
+// loadBaselineReaders counts the baseline readers of the cluster when ManagedCapacity is set: the readers
+// created by hand or by other tools, which the autoscaler never removes. MinCapacity and MaxCapacity then
+// bound the replicas of the autoscaler and of scheduled scaling on top of them, see minReaders and
+// maxReaders. The count is kept until the next load, as the actions of the autoscaler do not change it.
+func (d *DocumentDB) loadBaselineReaders(ctx context.Context) error {
+	if !d.ManagedCapacity {
+		d.baselineReaders = 0
+		return nil
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		return err
+	}
+	managed, err := d.countManagedReplicas(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to count the managed replicas of the cluster", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	d.baselineReaders = max(len(readerInstances)-managed, 0)
+	return nil
+}
+
+// minReaders returns the fewest readers the cluster scales to: MinCapacity, on top of the baseline readers
+// with ManagedCapacity.
+func (d *DocumentDB) minReaders() int {
+	return d.MinCapacity + d.baselineReaders
+}
+
+// maxReaders returns the most readers the cluster scales to: MaxCapacity, on top of the baseline readers
+// with ManagedCapacity.
+func (d *DocumentDB) maxReaders() int {
+	return d.MaxCapacity + d.baselineReaders
+}
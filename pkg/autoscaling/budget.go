@@ -0,0 +1,51 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// capToBudget returns how many of the replicas of instanceClass to add fit in MaxHourlyCost, the budget of
+// the estimated on-demand cost of the readers of the cluster, so that a runaway scale-out does not spend
+// silently. The replicas left out are recorded as remaining, and reported in a budget-exceeded warning
+// notification. Failing to price the readers is logged only, and leaves the scale-out uncapped.
+func (d *DocumentDB) capToBudget(ctx context.Context, replicasToAdd int, instanceClass string) int {
+	if d.MaxHourlyCost <= 0 || d.Prices == nil || replicasToAdd <= 0 {
+		return replicasToAdd
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to read the readers for the budget, scaling out uncapped", "Error", err, "ClusterID", d.ClusterID)
+		return replicasToAdd
+	}
+	readerClasses := make([]string, 0, len(readerInstances))
+	for _, instance := range readerInstances {
+		readerClasses = append(readerClasses, aws.ToString(instance.DBInstanceClass))
+	}
+	readersCost, err := d.hourlyPrice(ctx, readerClasses)
+	if err != nil {
+		d.Logger.Warn("Failed to price the readers for the budget, scaling out uncapped", "Error", err, "ClusterID", d.ClusterID)
+		return replicasToAdd
+	}
+	replicaCost, err := d.hourlyPrice(ctx, []string{instanceClass})
+	if err != nil || replicaCost <= 0 {
+		d.Logger.Warn("Failed to price the replicas to add for the budget, scaling out uncapped", "Error", err, "InstanceClass", instanceClass, "ClusterID", d.ClusterID)
+		return replicasToAdd
+	}
+
+	room := max(int((d.MaxHourlyCost-readersCost)/replicaCost), 0)
+	if room >= replicasToAdd {
+		return replicasToAdd
+	}
+	d.Logger.Warn("Budget exceeded, capping scale-out", "ReplicasToAdd", replicasToAdd, "BudgetRoom", room, "ReadersHourlyCost", readersCost, "ReplicaHourlyCost", replicaCost, "MaxHourlyCost", d.MaxHourlyCost, "ClusterID", d.ClusterID)
+	d.recordConstraint(ConstraintBudget)
+	d.recordPartial(replicasToAdd - room)
+	message := fmt.Sprintf("Budget exceeded: the readers cost an estimated $%.4g/hour and each %s replica $%.4g/hour, so MAX_HOURLY_COST ($%.4g/hour) has room for %d of the %d replicas to add", readersCost, instanceClass, replicaCost, d.MaxHourlyCost, room, replicasToAdd)
+	if err := notifications.SendWarning(ctx, d.Notifier, d.ClusterID, message, "scale out"); err != nil {
+		d.Logger.Error("Failed to send budget warning notification", "Error", err)
+	}
+	return room
+}
@@ -0,0 +1,25 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// capToBulkScaleIn returns how many of the replicas to remove a scale-in may remove without approval: at
+// most BulkScaleInLimit, unless AllowBulkScaleIn is set, e.g. by ALLOW_BULK_SCALE_IN or the approval of the
+// triggering event. The replicas left out are recorded as remaining, and reported in a warning notification.
+func (d *DocumentDB) capToBulkScaleIn(ctx context.Context, replicasToRemove int) int {
+	if d.BulkScaleInLimit <= 0 || d.AllowBulkScaleIn || replicasToRemove <= d.BulkScaleInLimit {
+		return replicasToRemove
+	}
+	d.Logger.Warn("Bulk scale-in is not approved, capping scale-in", "ReplicasToRemove", replicasToRemove, "BulkScaleInLimit", d.BulkScaleInLimit, "ClusterID", d.ClusterID)
+	d.recordConstraint(ConstraintBulkScaleIn)
+	d.recordPartial(replicasToRemove - d.BulkScaleInLimit)
+	message := fmt.Sprintf("Scale-in of %d replicas exceeds BULK_SCALE_IN_LIMIT, removing only %d; set ALLOW_BULK_SCALE_IN or approve the event with ApproveBulkScaleIn to remove them all", replicasToRemove, d.BulkScaleInLimit)
+	if err := notifications.SendWarning(ctx, d.Notifier, d.ClusterID, message, "scale in"); err != nil {
+		d.Logger.Error("Failed to send bulk scale-in warning notification", "Error", err)
+	}
+	return d.BulkScaleInLimit
+}
@@ -0,0 +1,170 @@
+package autoscaling
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+// canaryTagKey is the cluster tag holding the canary replica of a scale-out, as <instance ID>, then
+// <instance ID>@<RFC 3339 time> once it was seen available, so that the verification spans invocations
+// without a state table.
+const canaryTagKey = "docdb-autoscaler:canary"
+
+// DefaultCanaryWindow is how long a canary replica serves before the metric is evaluated again, unless
+// configured otherwise.
+const DefaultCanaryWindow = 5 * time.Minute
+
+// canaryReplica is the canary replica of a scale-out in progress.
+type canaryReplica struct {
+	instanceID  string
+	availableAt time.Time // Zero until the replica was seen available
+}
+
+// canaryState is how far the verification of the canary replica of the last scale-out got.
+type canaryState int
+
+const (
+	canaryNone      canaryState = iota // No canary, or CanaryScaleOut is not set
+	canaryMissing                      // The canary was deleted before its verification
+	canaryCreating                     // The canary is not available yet
+	canaryAvailable                    // The canary was just seen available
+	canaryServing                      // The canary serves, within CanaryWindow
+	canaryVerified                     // The canary served for CanaryWindow
+)
+
+// holds reports whether scale-outs must wait for the canary.
+func (s canaryState) holds() bool {
+	return s == canaryCreating || s == canaryAvailable || s == canaryServing
+}
+
+// readCanary reads the canary replica of the last scale-out, when CanaryScaleOut is set, and how far its
+// verification got: scale-outs wait until it is available, and then for CanaryWindow, so that the metric is
+// evaluated again with the canary serving. Nothing is changed, see settleCanary.
+func (d *DocumentDB) readCanary(ctx context.Context) (canaryReplica, canaryState, error) {
+	if !d.CanaryScaleOut || d.DryRun {
+		return canaryReplica{}, canaryNone, nil
+	}
+	canary, found, err := d.loadCanary(ctx)
+	if err != nil || !found {
+		return canaryReplica{}, canaryNone, err
+	}
+	instances, err := d.describeInstances(ctx)
+	if err != nil {
+		return canaryReplica{}, canaryNone, err
+	}
+	index := slices.IndexFunc(instances, func(instance docdbTypes.DBInstance) bool {
+		return aws.ToString(instance.DBInstanceIdentifier) == canary.instanceID
+	})
+
+	switch {
+	case index < 0:
+		return canary, canaryMissing, nil
+	case aws.ToString(instances[index].DBInstanceStatus) != "available":
+		return canary, canaryCreating, nil
+	case canary.availableAt.IsZero():
+		return canary, canaryAvailable, nil
+	case time.Since(canary.availableAt) < d.canaryWindow():
+		return canary, canaryServing, nil
+	}
+	return canary, canaryVerified, nil
+}
+
+// settleCanary keeps the cluster tags up to date with the verification of the canary: the time it was first
+// seen available is saved, and a verified canary is cleared, so that the next scale-out is taken in full,
+// regardless of the scale-out cooldown the canary started. A canary deleted in the meantime is cleared
+// too, without verification.
+func (d *DocumentDB) settleCanary(ctx context.Context, canary canaryReplica, state canaryState) {
+	switch state {
+	case canaryMissing:
+		d.Logger.Warn("Canary replica no longer exists, clearing it", "InstanceID", canary.instanceID, "ClusterID", d.ClusterID)
+		d.clearCanary(ctx)
+	case canaryCreating:
+		d.Logger.Info("Waiting for the canary replica to become available", "InstanceID", canary.instanceID, "ClusterID", d.ClusterID)
+	case canaryAvailable:
+		canary.availableAt = time.Now().UTC()
+		d.saveCanary(ctx, canary)
+		d.Logger.Info("Canary replica is available, verifying it", "InstanceID", canary.instanceID, "CanaryWindow", d.canaryWindow(), "ClusterID", d.ClusterID)
+	case canaryServing:
+		d.Logger.Info("Verifying the canary replica", "InstanceID", canary.instanceID, "AvailableAt", canary.availableAt, "CanaryWindow", d.canaryWindow(), "ClusterID", d.ClusterID)
+	case canaryVerified:
+		d.Logger.Info("Canary replica verified, evaluating the scale-out again", "InstanceID", canary.instanceID, "ClusterID", d.ClusterID)
+		d.clearCanary(ctx)
+	}
+}
+
+// canaryWindow returns how long a canary replica serves before the metric is evaluated again.
+func (d *DocumentDB) canaryWindow() time.Duration {
+	if d.CanaryWindow <= 0 {
+		return DefaultCanaryWindow
+	}
+	return d.CanaryWindow
+}
+
+// loadCanary reads the canary replica from the cluster tags. Tags edited by hand into invalid times are
+// read as not yet available.
+func (d *DocumentDB) loadCanary(ctx context.Context) (canaryReplica, bool, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return canaryReplica{}, false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != canaryTagKey {
+			continue
+		}
+		instanceID, availableAt, _ := strings.Cut(aws.ToString(tag.Value), "@")
+		if instanceID == "" {
+			return canaryReplica{}, false, nil
+		}
+		canary := canaryReplica{instanceID: instanceID}
+		if t, err := time.Parse(time.RFC3339, availableAt); err == nil {
+			canary.availableAt = t
+		}
+		return canary, true, nil
+	}
+	return canaryReplica{}, false, nil
+}
+
+// saveCanary writes the canary replica to the cluster tags. Failures are logged only: the scale-out then
+// goes ahead in full on the next invocation.
+func (d *DocumentDB) saveCanary(ctx context.Context, canary canaryReplica) {
+	value := canary.instanceID
+	if !canary.availableAt.IsZero() {
+		value += "@" + canary.availableAt.Format(time.RFC3339)
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to record the canary replica", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(canaryTagKey), Value: aws.String(value)}},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to record the canary replica", "Error", err, "InstanceID", canary.instanceID)
+	}
+}
+
+// clearCanary removes the canary replica from the cluster tags. Failures are logged only.
+func (d *DocumentDB) clearCanary(ctx context.Context) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to clear the canary replica", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.RemoveTagsFromResource(ctx, &docdb.RemoveTagsFromResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		TagKeys:      []string{canaryTagKey},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to clear the canary replica", "Error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+// churnWindow is a window of the churn budget: the instances created and deleted in the cluster are counted
+// in its tags from the first one, and the count starts over once the window passed.
+type churnWindow struct {
+	name        string
+	length      time.Duration
+	limit       int
+	startTagKey string // RFC 3339 time the window started
+	countTagKey string // Instances created and deleted since
+}
+
+// churnWindows returns the windows of the churn budget with a limit.
+func (d *DocumentDB) churnWindows() []churnWindow {
+	var windows []churnWindow
+	if d.ChurnBudgetHourly > 0 {
+		windows = append(windows, churnWindow{name: "hourly", length: time.Hour, limit: d.ChurnBudgetHourly, startTagKey: "docdb-autoscaler:churn-hourly-start", countTagKey: "docdb-autoscaler:churn-hourly-count"})
+	}
+	if d.ChurnBudgetDaily > 0 {
+		windows = append(windows, churnWindow{name: "daily", length: 24 * time.Hour, limit: d.ChurnBudgetDaily, startTagKey: "docdb-autoscaler:churn-daily-start", countTagKey: "docdb-autoscaler:churn-daily-count"})
+	}
+	return windows
+}
+
+// churn returns the start of the window and the instances counted in it, from the cluster tags. A window
+// that passed, or tags edited by hand into invalid values, count nothing.
+func (w churnWindow) churn(tags map[string]string, now time.Time) (time.Time, int) {
+	start, err := time.Parse(time.RFC3339, tags[w.startTagKey])
+	if err != nil || !now.Before(start.Add(w.length)) {
+		return now, 0
+	}
+	count, err := strconv.Atoi(tags[w.countTagKey])
+	if err != nil {
+		return now, 0
+	}
+	return start, count
+}
+
+// clusterTags returns the tags of the cluster by key.
+func (d *DocumentDB) clusterTags(ctx context.Context) (*string, map[string]string, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags := map[string]string{}
+	for _, tag := range dbCluster.TagList {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return dbCluster.DBClusterArn, tags, nil
+}
+
+// skipIfChurnExceeded skips the scaling action while the instances created and deleted in a window of the
+// churn budget reached its limit, e.g. with alarms that keep scaling the cluster out and in. Scaling resumes
+// once the window passed.
+func (d *DocumentDB) skipIfChurnExceeded(ctx context.Context) (bool, error) {
+	windows := d.churnWindows()
+	if len(windows) == 0 {
+		return false, nil
+	}
+	_, tags, err := d.clusterTags(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	for _, window := range windows {
+		start, count := window.churn(tags, now)
+		if count >= window.limit {
+			d.Logger.Warn("Churn budget is exhausted, skipping scaling action", "ClusterID", d.ClusterID, "Window", window.name, "Churn", count, "Limit", window.limit, "ResumesAt", start.Add(window.length).UTC())
+			d.recordDecision(DecisionPaused)
+			d.recordReason(ReasonChurnBudget)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// trackChurn counts the instances the last action created and deleted in the windows of the churn budget,
+// and sends a critical notification when it exhausts one. Dry-run actions change nothing, so they are not
+// counted.
+func (d *DocumentDB) trackChurn(ctx context.Context) error {
+	windows := d.churnWindows()
+	result := d.LastResult()
+	churn := result.ReplicasAdded + result.ReplicasRemoved
+	if len(windows) == 0 || d.DryRun || churn == 0 {
+		return nil
+	}
+	clusterARN, tags, err := d.clusterTags(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var newTags []docdbTypes.Tag
+	var exhausted []string
+	for _, window := range windows {
+		start, count := window.churn(tags, now)
+		newTags = append(newTags,
+			docdbTypes.Tag{Key: aws.String(window.startTagKey), Value: aws.String(start.UTC().Format(time.RFC3339))},
+			docdbTypes.Tag{Key: aws.String(window.countTagKey), Value: aws.String(strconv.Itoa(count + churn))},
+		)
+		if count < window.limit && count+churn >= window.limit {
+			exhausted = append(exhausted, fmt.Sprintf("%d instances created and deleted in the %s window, scaling is paused until %s", count+churn, window.name, start.Add(window.length).UTC().Format(time.RFC3339)))
+		}
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: clusterARN,
+		Tags:         newTags,
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		return err
+	}
+
+	for _, message := range exhausted {
+		d.Logger.Error("Churn budget exhausted", "ClusterID", d.ClusterID, "Message", message)
+		if err := d.Notifier.SendFailureNotification(ctx, d.ClusterID, "Churn budget exhausted: "+message, "churn budget"); err != nil {
+			d.Logger.Error("Failed to send churn budget notification", "Error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+// circuitTagKey holds the unix time until which the circuit of the cluster is open, after CircuitThreshold
+// consecutive failed invocations.
+const circuitTagKey = "docdb-autoscaler-circuit-open-until"
+
+// DefaultCircuitBackoff is how long scaling attempts are paused once the circuit opens.
+const DefaultCircuitBackoff = 30 * time.Minute
+
+// circuitEnabled reports whether consecutive failures open the circuit of the cluster.
+func (d *DocumentDB) circuitEnabled() bool {
+	return d.CircuitThreshold > 0
+}
+
+// skipIfCircuitOpen skips the scaling action while the circuit of the cluster is open, rather than
+// hammering a broken dependency on every invocation. Once the backoff elapsed, the next action is
+// attempted: it closes the circuit when it succeeds, and reopens it when it fails.
+func (d *DocumentDB) skipIfCircuitOpen(ctx context.Context) (bool, error) {
+	if !d.circuitEnabled() {
+		return false, nil
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != circuitTagKey {
+			continue
+		}
+		unix, err := strconv.ParseInt(aws.ToString(tag.Value), 10, 64)
+		if err != nil {
+			return false, nil
+		}
+		if openUntil := time.Unix(unix, 0); time.Now().Before(openUntil) {
+			d.Logger.Warn("Circuit is open after repeated failures, skipping scaling action", "ClusterID", d.ClusterID, "OpenUntil", openUntil.UTC())
+			d.recordDecision(DecisionPaused)
+			d.recordReason(ReasonCircuitOpen)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// openCircuit returns the tag opening the circuit of the cluster for the backoff, and sends a critical
+// notification. Failing to notify is logged only.
+func (d *DocumentDB) openCircuit(ctx context.Context, failures int, now time.Time) docdbTypes.Tag {
+	backoff := d.CircuitBackoff
+	if backoff <= 0 {
+		backoff = DefaultCircuitBackoff
+	}
+	openUntil := now.Add(backoff)
+	d.Logger.Error("Opening circuit after repeated failures", "ClusterID", d.ClusterID, "ConsecutiveFailures", failures, "OpenUntil", openUntil.UTC())
+	message := fmt.Sprintf("Scaling failed on %d consecutive invocations, scaling attempts are paused until %s", failures, openUntil.UTC().Format(time.RFC3339))
+	if err := d.Notifier.SendFailureNotification(ctx, d.ClusterID, message, "circuit breaker"); err != nil {
+		d.Logger.Error("Failed to send circuit breaker notification", "Error", err)
+	}
+	return docdbTypes.Tag{Key: aws.String(circuitTagKey), Value: aws.String(strconv.FormatInt(openUntil.Unix(), 10))}
+}
@@ -0,0 +1,111 @@
+package autoscaling
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+// Cluster tags holding the time of the last scale-out and scale-in, in RFC 3339, so that cooldowns
+// are enforced without a state table.
+const (
+	lastScaleOutTagKey = "docdb-autoscaler:last-scale-out"
+	lastScaleInTagKey  = "docdb-autoscaler:last-scale-in"
+)
+
+// inCooldown reports whether the metric-based decision must wait for a cooldown, reading the times of the
+// last actions from the cluster tags: a scale-out waits ScaleOutCooldown after the last scale-out, and a
+// scale-in waits ScaleInCooldown after the last scale-out or scale-in, so that the cluster does not scale
+// in right after scaling out. Tags edited by hand into invalid times are ignored.
+func (d *DocumentDB) inCooldown(ctx context.Context, decision string) (bool, error) {
+	if !d.EnforceCooldowns {
+		return false, nil
+	}
+	cooldown := time.Duration(d.ScaleOutCooldown) * time.Second
+	tagKeys := []string{lastScaleOutTagKey}
+	if decision == DecisionScaleIn {
+		cooldown = time.Duration(d.ScaleInCooldown) * time.Second
+		tagKeys = append(tagKeys, lastScaleInTagKey)
+	}
+	if cooldown <= 0 {
+		return false, nil
+	}
+	if d.Shadow {
+		return d.inShadowCooldown(ctx, decision, cooldown)
+	}
+
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		for _, tagKey := range tagKeys {
+			if aws.ToString(tag.Key) != tagKey {
+				continue
+			}
+			lastAction, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+			if err == nil && time.Since(lastAction) < cooldown {
+				d.Logger.Info("Scaling action is in cooldown, skipping", "Decision", decision, "LastAction", tagKey, "LastActionTime", lastAction, "Cooldown", cooldown, "ClusterID", d.ClusterID)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// inStabilization reports whether a metric-based scale-in must wait for the stabilization window of the last
+// scale-out, ScaleInStabilization after it, whatever the cooldowns: the metric of readers that just absorbed
+// the load dips, and would otherwise remove the replicas just added.
+func (d *DocumentDB) inStabilization(ctx context.Context) (bool, error) {
+	if d.ScaleInStabilization <= 0 {
+		return false, nil
+	}
+	if d.Shadow {
+		return d.inShadowCooldown(ctx, DecisionScaleOut, d.ScaleInStabilization)
+	}
+
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != lastScaleOutTagKey {
+			continue
+		}
+		lastScaleOut, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+		if err == nil && time.Since(lastScaleOut) < d.ScaleInStabilization {
+			d.Logger.Info("Scale-in is within the stabilization window of the last scale-out, skipping", "LastScaleOutTime", lastScaleOut, "ScaleInStabilization", d.ScaleInStabilization, "ClusterID", d.ClusterID)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordLastAction writes the time of a scale-out or scale-in to the cluster tags, for the cooldowns and the
+// scale-in stabilization window of the next invocations. Dry-run actions change nothing, so they are not
+// recorded. Failures are logged only.
+func (d *DocumentDB) recordLastAction(ctx context.Context, decision string) {
+	if (!d.EnforceCooldowns && d.ScaleInStabilization <= 0) || d.DryRun {
+		return
+	}
+	tagKey := lastScaleOutTagKey
+	if decision == DecisionScaleIn {
+		tagKey = lastScaleInTagKey
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to record the last scaling action", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(tagKey), Value: aws.String(time.Now().UTC().Format(time.RFC3339))}},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to record the last scaling action", "Error", err, "Tag", tagKey)
+	}
+}
@@ -0,0 +1,41 @@
+package autoscaling
+
+import (
+	"context"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+)
+
+// costDelta estimates the on-demand cost delta of the instances the current scaling action added and
+// removed, or planned to in dry-run, when Prices is set. It returns nil when nothing changed, or when a
+// price is unknown, e.g. of an instance class missing from the price list. Failures are logged only.
+func (d *DocumentDB) costDelta(ctx context.Context) *pricing.Cost {
+	if d.Prices == nil || d.lastResult == nil || len(d.lastResult.addedClasses)+len(d.lastResult.removedClasses) == 0 {
+		return nil
+	}
+	added, err := d.hourlyPrice(ctx, d.lastResult.addedClasses)
+	if err != nil {
+		d.Logger.Warn("Failed to estimate the cost of the scaling action", "Error", err, "ClusterID", d.ClusterID)
+		return nil
+	}
+	removed, err := d.hourlyPrice(ctx, d.lastResult.removedClasses)
+	if err != nil {
+		d.Logger.Warn("Failed to estimate the cost of the scaling action", "Error", err, "ClusterID", d.ClusterID)
+		return nil
+	}
+	cost := pricing.NewCost(added - removed)
+	return &cost
+}
+
+// hourlyPrice returns the total hourly price of instances of the classes in the region of the cluster.
+func (d *DocumentDB) hourlyPrice(ctx context.Context, instanceClasses []string) (float64, error) {
+	var total float64
+	for _, instanceClass := range instanceClasses {
+		price, err := d.Prices.HourlyPrice(ctx, d.engine().Name, d.Region, instanceClass)
+		if err != nil {
+			return 0, err
+		}
+		total += price
+	}
+	return total, nil
+}
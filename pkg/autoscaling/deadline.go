@@ -0,0 +1,52 @@
+package autoscaling
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDeadlineMargin is the time left before the deadline of the invocation below which no AWS
+// operation is started.
+const DefaultDeadlineMargin = 10 * time.Second
+
+// nearDeadline reports whether the deadline of ctx, e.g. the timeout of the Lambda invocation, is too
+// close to start another AWS operation.
+func (d *DocumentDB) nearDeadline(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return false
+	}
+	return time.Until(deadline) < d.deadlineMargin()
+}
+
+// deadlineMargin returns the time left before the deadline of the invocation below which no AWS operation
+// is started.
+func (d *DocumentDB) deadlineMargin() time.Duration {
+	if d.DeadlineMargin <= 0 {
+		return DefaultDeadlineMargin
+	}
+	return d.DeadlineMargin
+}
+
+// stopBeforeDeadline reports whether the current action must stop before its remaining replicas, rather
+// than be killed between two AWS operations, and records it as partial. The desired capacity was saved
+// before the action, so that the next invocation resumes it when TrackDesiredCapacity is set.
+func (d *DocumentDB) stopBeforeDeadline(ctx context.Context, remaining int) bool {
+	if !d.nearDeadline(ctx) {
+		return false
+	}
+	deadline, _ := ctx.Deadline()
+	d.Logger.Warn("Invocation is about to time out, stopping the scaling action", "ReplicasRemaining", remaining, "Deadline", deadline, "ClusterID", d.ClusterID)
+	d.recordReason(ReasonDeadline)
+	d.recordPartial(remaining)
+	return true
+}
+
+// replicasDone returns how many of the planned replicas the current action added or removed, once it
+// stopped before the deadline.
+func (d *DocumentDB) replicasDone(planned int) int {
+	if d.lastResult == nil {
+		return planned
+	}
+	return planned - d.lastResult.ReplicasRemaining
+}
@@ -0,0 +1,161 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Reason codes of a scaling decision, reported in its decision record.
+const (
+	ReasonMetricAboveTarget = "MetricAboveTarget" // The metric was above its target value
+	ReasonMetricBelowTarget = "MetricBelowTarget" // The metric was below its target value
+	ReasonMetricAtTarget    = "MetricAtTarget"    // The metric was at its target value
+	ReasonCompositeAlarm    = "CompositeAlarm"    // The metric drove a composite alarm
+	ReasonRequestedCapacity = "RequestedCapacity" // A capacity was requested, e.g. by a direct invocation
+	ReasonSchedule          = "Schedule"          // Scheduled scaling
+	ReasonPaused            = "Paused"            // Autoscaling of the cluster is paused
+	ReasonCleanup           = "Cleanup"           // Removal of the replicas created by the autoscaler
+	ReasonLocked            = "Locked"            // Another invocation held the lock of the cluster
+	ReasonReconcile         = "Reconcile"         // Readers were missing from the desired capacity, e.g. after an interrupted scale-out
+	ReasonOrphanCleanup     = "OrphanCleanup"     // Replicas of the autoscaler were failed or beyond MaxCapacity
+	ReasonCircuitOpen       = "CircuitOpen"       // Scaling is paused after repeated failures
+	ReasonDeadline          = "Deadline"          // The action stopped before the deadline of the invocation
+	ReasonRDSEvent          = "RDSEvent"          // An RDS event of the cluster, e.g. an instance failure, triggered the action
+	ReasonMaintenance       = "Maintenance"       // Autoscaling is paused during the maintenance of the cluster
+	ReasonPrescale          = "Prescale"          // Replicas were added ahead of a known peak, e.g. a marketing event
+	ReasonPrescaleExpired   = "PrescaleExpired"   // Prescaled replicas were removed once their expiry passed
+	ReasonChurnBudget       = "ChurnBudget"       // Scaling is paused after too many instances were created and deleted
+	ReasonWriterBootstrap   = "WriterBootstrap"   // The cluster had no readers, so the metric of its writer decided the scale-out
+)
+
+// Constraints that changed the outcome of a scaling decision, reported in its decision record.
+const (
+	ConstraintMinCapacity       = "MinCapacity"       // The desired capacity was raised to MinCapacity
+	ConstraintMaxCapacity       = "MaxCapacity"       // The desired capacity was lowered to MaxCapacity
+	ConstraintReaderFloor       = "ReaderFloor"       // A removal was refused to keep the reader floor
+	ConstraintSingleScaleIn     = "SingleScaleIn"     // Only one replica was removed, although more were above the desired capacity
+	ConstraintCooldown          = "Cooldown"          // The action was skipped during the cooldown of the last action
+	ConstraintInstanceQuota     = "InstanceQuota"     // The scale-out was capped to the room left in the DB instances quota
+	ConstraintManagedReplicaCap = "ManagedReplicaCap" // The scale-out was capped by ManagedReplicaCap or AccountReplicaCap
+	ConstraintBulkScaleIn       = "BulkScaleIn"       // The scale-in was capped to BulkScaleInLimit without approval
+	ConstraintBudget            = "Budget"            // The scale-out was capped to the replicas MaxHourlyCost has room for
+	ConstraintCanary            = "Canary"            // The scale-out was held to a canary replica, or until the canary was verified
+	ConstraintConnectionDrain   = "ConnectionDrain"   // A replica was not removed as its connections did not drain below DrainConnections
+	ConstraintHoliday           = "Holiday"           // The scheduled scale-out was skipped on a holiday of the Holidays calendar
+	ConstraintPrescale          = "Prescale"          // A prescaled replica was kept until its expiry
+	ConstraintStabilization     = "Stabilization"     // The scale-in was held within ScaleInStabilization of the last scale-out
+)
+
+// logDecision logs the decision record of the last scaling action: a single structured record with the
+// metric, capacity, decision, constraints and reason codes, for log-based analytics.
+// Cooldowns are enforced by the alarms and schedules triggering the autoscaler, or with cluster tags when
+// EnforceCooldowns is set, so they are reported as configured.
+func (d *DocumentDB) logDecision(ctx context.Context, actionErr error) {
+	result := d.LastResult()
+	attrs := []slog.Attr{
+		slog.String("ClusterID", d.ClusterID),
+		slog.String("Decision", result.Decision),
+		slog.Int("ReplicasAdded", result.ReplicasAdded),
+		slog.Int("ReplicasRemoved", result.ReplicasRemoved),
+		slog.Bool("DryRun", d.DryRun),
+	}
+	if result.metricValue != nil {
+		attrs = append(attrs,
+			slog.String("MetricName", result.metricName),
+			slog.Float64("MetricValue", *result.metricValue),
+			slog.Float64("TargetValue", *result.targetValue),
+		)
+	}
+	if result.currentCapacity != nil {
+		attrs = append(attrs, slog.Int("CurrentCapacity", *result.currentCapacity), slog.Int("DesiredCapacity", *result.desiredCapacity))
+	}
+	attrs = append(attrs,
+		slog.Group("Constraints",
+			slog.Int("MinCapacity", d.MinCapacity),
+			slog.Int("MaxCapacity", d.MaxCapacity),
+			slog.Int("ScaleInCooldown", d.ScaleInCooldown),
+			slog.Int("ScaleOutCooldown", d.ScaleOutCooldown),
+			slog.Any("Applied", result.Constraints),
+		),
+		slog.Any("ReasonCodes", result.ReasonCodes),
+		slog.String("Explanation", result.Explanation),
+	)
+	level := slog.LevelInfo
+	if actionErr != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("Error", actionErr.Error()))
+	}
+	d.Logger.LogAttrs(ctx, level, "Scaling decision", attrs...)
+}
+
+// explain returns the human-readable explanation of a decision, e.g.
+// "CPUUtilization=82.3 > target=70 → +2; clamped by MaxCapacity": what triggered it, with the metric
+// compared to its target, the replicas it added or removed, and the constraints that changed it.
+func explain(metricName string, metricValue, targetValue *float64, reasonCodes []string, decision string, added, removed int, constraints []string) string {
+	var triggers []string
+	for _, reason := range reasonCodes {
+		switch reason {
+		case ReasonMetricAboveTarget, ReasonMetricBelowTarget, ReasonMetricAtTarget:
+			if metricValue != nil && targetValue != nil {
+				triggers = append(triggers, fmt.Sprintf("%s=%s %s target=%s", metricName, formatMetric(*metricValue), comparison(reason), formatMetric(*targetValue)))
+				continue
+			}
+		}
+		triggers = append(triggers, reason)
+	}
+
+	var changes []string
+	if added > 0 {
+		changes = append(changes, "+"+strconv.Itoa(added))
+	}
+	if removed > 0 {
+		changes = append(changes, "-"+strconv.Itoa(removed))
+	}
+	change := strings.Join(changes, " ")
+	if change == "" {
+		change = "no change"
+		if decision == DecisionPaused {
+			change = "paused"
+		}
+	}
+
+	explanation := change
+	if len(triggers) > 0 {
+		explanation = strings.Join(triggers, ", ") + " → " + change
+	}
+	for _, constraint := range constraints {
+		explanation += "; " + constraintVerb(constraint) + " " + constraint
+	}
+	return explanation
+}
+
+// comparison returns the operator of a metric reason code.
+func comparison(reason string) string {
+	switch reason {
+	case ReasonMetricAboveTarget:
+		return ">"
+	case ReasonMetricBelowTarget:
+		return "<"
+	}
+	return "="
+}
+
+// formatMetric formats a metric value to at most two decimals.
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// constraintVerb returns how a constraint changed the outcome of a decision in its explanation.
+func constraintVerb(constraint string) string {
+	switch constraint {
+	case ConstraintCooldown, ConstraintStabilization, ConstraintHoliday:
+		return "suppressed by"
+	case ConstraintCanary, ConstraintReaderFloor, ConstraintConnectionDrain, ConstraintPrescale:
+		return "held by"
+	}
+	return "clamped by"
+}
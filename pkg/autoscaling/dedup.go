@@ -0,0 +1,90 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// notifiedTagPrefix prefixes the cluster tags holding the dedup state of notification events,
+// e.g. docdb-autoscaler-notified-ScaleOut = "<fingerprint>:<unix time sent>:<suppressed>".
+const notifiedTagPrefix = "docdb-autoscaler-notified-"
+
+// clusterTagDedupStore keeps the dedup state of notifications in tags of the cluster itself,
+// so no extra infrastructure is needed. The autoscaler only notifies of its own cluster.
+type clusterTagDedupStore struct {
+	d *DocumentDB
+}
+
+// Ensure clusterTagDedupStore implements notifications.DedupStore
+var _ notifications.DedupStore = clusterTagDedupStore{}
+
+// LoadDedupState reads the dedup state of the event from the cluster tags.
+func (s clusterTagDedupStore) LoadDedupState(ctx context.Context, clusterID, event string) (notifications.DedupState, bool, error) {
+	dbCluster, err := s.d.describeCluster(ctx)
+	if err != nil {
+		return notifications.DedupState{}, false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != notifiedTagPrefix+event {
+			continue
+		}
+		state, err := parseDedupState(aws.ToString(tag.Value))
+		if err != nil {
+			// A tag edited by hand restarts deduplication
+			return notifications.DedupState{}, false, nil
+		}
+		return state, true, nil
+	}
+	return notifications.DedupState{}, false, nil
+}
+
+// SaveDedupState writes the dedup state of the event to the cluster tags.
+func (s clusterTagDedupStore) SaveDedupState(ctx context.Context, clusterID, event string, state notifications.DedupState) error {
+	dbCluster, err := s.d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	value := fmt.Sprintf("%s:%d:%d", state.Fingerprint, state.Sent.Unix(), state.Suppressed)
+	_, err = s.d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(notifiedTagPrefix + event), Value: aws.String(value)}},
+	})
+	s.d.invalidateSnapshot()
+	return err
+}
+
+// parseDedupState parses the value of a notified tag.
+func parseDedupState(value string) (notifications.DedupState, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return notifications.DedupState{}, fmt.Errorf("invalid dedup state %q", value)
+	}
+	sent, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return notifications.DedupState{}, fmt.Errorf("invalid dedup state %q: %w", value, err)
+	}
+	suppressed, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return notifications.DedupState{}, fmt.Errorf("invalid dedup state %q: %w", value, err)
+	}
+	return notifications.DedupState{Fingerprint: parts[0], Sent: time.Unix(sent, 0), Suppressed: suppressed}, nil
+}
+
+// DedupNotifications collapses identical notifications of an event of the cluster within window
+// into one, keeping the dedup state in tags of the cluster.
+func (d *DocumentDB) DedupNotifications(window time.Duration) {
+	d.Notifier = &notifications.Deduplicator{
+		Notifier: d.Notifier,
+		Store:    clusterTagDedupStore{d: d},
+		Window:   window,
+		Logger:   d.Logger,
+	}
+}
@@ -0,0 +1,77 @@
+package autoscaling
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// drainPollInterval is how often the connections of a draining replica are read, as DatabaseConnections
+// is reported every minute.
+const drainPollInterval = 30 * time.Second
+
+// waitForDrain reports whether a replica may be deleted without cutting off many clients, when
+// DrainConnections is set: once its DatabaseConnections are below the threshold, waiting up to
+// DrainTimeout for them to drop as the reader endpoint rebalances. The wait also ends before the deadline
+// of ctx. Replicas without datapoints are taken as drained.
+func (d *DocumentDB) waitForDrain(ctx context.Context, instanceID string) (bool, error) {
+	if d.DrainConnections <= 0 {
+		return true, nil
+	}
+	waitUntil := time.Now().Add(d.DrainTimeout)
+	for {
+		connections, found, err := d.instanceConnections(ctx, instanceID)
+		if err != nil {
+			return false, err
+		}
+		if !found || connections < d.DrainConnections {
+			d.Logger.Info("Replica is drained", "InstanceID", instanceID, "DatabaseConnections", connections, "Threshold", d.DrainConnections)
+			return true, nil
+		}
+
+		wait := min(drainPollInterval, time.Until(waitUntil))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline)-wait < d.deadlineMargin() {
+			wait = 0
+		}
+		if wait <= 0 {
+			d.Logger.Warn("Replica still has connections, not removing it", "InstanceID", instanceID, "DatabaseConnections", connections, "Threshold", d.DrainConnections, "DrainTimeout", d.DrainTimeout)
+			return false, nil
+		}
+		d.Logger.Info("Waiting for replica connections to drain", "InstanceID", instanceID, "DatabaseConnections", connections, "Threshold", d.DrainConnections)
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// instanceConnections returns the latest DatabaseConnections of an instance, the highest within the
+// minute, and false when it has no datapoints.
+func (d *DocumentDB) instanceConnections(ctx context.Context, instanceID string) (float64, bool, error) {
+	now := time.Now()
+	output, err := d.CloudWatchClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(d.engine().MetricNamespace),
+		MetricName: aws.String("DatabaseConnections"),
+		Dimensions: []cwTypes.Dimension{{Name: aws.String("DBInstanceIdentifier"), Value: aws.String(instanceID)}},
+		StartTime:  aws.Time(now.Add(-5 * time.Minute)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int32(60), // 1 minute
+		Statistics: []cwTypes.Statistic{cwTypes.StatisticMaximum},
+	})
+	if err != nil {
+		d.Logger.Error("Failed to get database connections", "Error", err, "InstanceID", instanceID)
+		return 0, false, err
+	}
+	if len(output.Datapoints) == 0 {
+		return 0, false, nil
+	}
+	sort.Slice(output.Datapoints, func(i, j int) bool {
+		return output.Datapoints[i].Timestamp.Before(*output.Datapoints[j].Timestamp)
+	})
+	return aws.ToFloat64(output.Datapoints[len(output.Datapoints)-1].Maximum), true, nil
+}
@@ -0,0 +1,308 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/docdbelastic"
+	elasticTypes "github.com/aws/aws-sdk-go-v2/service/docdbelastic/types"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// Dimensions along which an elastic cluster is scaled.
+const (
+	ElasticShardCount    = "shardCount"    // Number of shards
+	ElasticShardCapacity = "shardCapacity" // vCPUs of each shard
+)
+
+// elasticShardCapacities are the vCPU capacities supported for the shards of an elastic cluster.
+var elasticShardCapacities = []int{2, 4, 8, 16, 32, 64}
+
+// getElasticCluster returns the elastic cluster named ClusterID, or nil when ClusterID is an
+// instance-based cluster or no ElasticClient is configured. Only DocumentDB has elastic clusters.
+// The cluster is looked up through the topology snapshot, so that an action describes it once.
+func (d *DocumentDB) getElasticCluster(ctx context.Context) (*elasticTypes.Cluster, error) {
+	if d.ElasticClient == nil || d.engine() != DocDBEngine {
+		return nil, nil
+	}
+	if d.snapshot != nil && d.snapshot.elasticCluster != nil {
+		return d.snapshot.elasticCluster, nil
+	}
+
+	_, err := d.describeCluster(ctx)
+	var notFound *rdsTypes.DBClusterNotFoundFault
+	if err == nil {
+		return nil, nil
+	} else if !errors.As(err, &notFound) {
+		return nil, err
+	}
+
+	listClustersInput := &docdbelastic.ListClustersInput{}
+	for {
+		listClustersOutput, err := d.ElasticClient.ListClusters(ctx, listClustersInput)
+		if err != nil {
+			d.Logger.Error("Failed to list elastic clusters", "Error", err)
+			return nil, err
+		}
+		for _, cluster := range listClustersOutput.Clusters {
+			if aws.ToString(cluster.ClusterName) != d.ClusterID {
+				continue
+			}
+			getClusterOutput, err := d.ElasticClient.GetCluster(ctx, &docdbelastic.GetClusterInput{ClusterArn: cluster.ClusterArn})
+			if err != nil {
+				d.Logger.Error("Failed to get elastic cluster", "Error", err, "ClusterID", d.ClusterID)
+				return nil, err
+			}
+			if d.snapshot != nil {
+				d.snapshot.elasticCluster = getClusterOutput.Cluster
+			}
+			return getClusterOutput.Cluster, nil
+		}
+		if listClustersOutput.NextToken == nil {
+			break
+		}
+		listClustersInput.NextToken = listClustersOutput.NextToken
+	}
+	return nil, fmt.Errorf("no clusters found with identifier %s", d.ClusterID)
+}
+
+// elasticScaleDimension returns the configured dimension, defaulting to the shard count.
+func (d *DocumentDB) elasticScaleDimension() string {
+	if d.ElasticScaleDimension == ElasticShardCapacity {
+		return ElasticShardCapacity
+	}
+	return ElasticShardCount
+}
+
+// elasticCapacity returns the current capacity of the elastic cluster along the scaled dimension.
+func (d *DocumentDB) elasticCapacity(cluster *elasticTypes.Cluster) int {
+	if d.elasticScaleDimension() == ElasticShardCapacity {
+		return int(aws.ToInt32(cluster.ShardCapacity))
+	}
+	return int(aws.ToInt32(cluster.ShardCount))
+}
+
+// boundElasticCapacity bounds capacity to MinCapacity and MaxCapacity. Shard capacities are rounded up
+// to a supported capacity, and an elastic cluster keeps at least one shard.
+func (d *DocumentDB) boundElasticCapacity(capacity int) int {
+	capacity = d.clampCapacity(capacity)
+	if d.elasticScaleDimension() == ElasticShardCount {
+		return max(capacity, 1)
+	}
+
+	for _, shardCapacity := range elasticShardCapacities {
+		if shardCapacity >= capacity && shardCapacity <= d.MaxCapacity {
+			return shardCapacity
+		}
+	}
+	// No supported capacity between the bounds, use the largest one within MaxCapacity
+	for i := len(elasticShardCapacities) - 1; i > 0; i-- {
+		if elasticShardCapacities[i] <= d.MaxCapacity {
+			return elasticShardCapacities[i]
+		}
+	}
+	return elasticShardCapacities[0]
+}
+
+// stepElasticCapacity moves the capacity by the given number of shards, or of supported shard capacities.
+func (d *DocumentDB) stepElasticCapacity(currentCapacity, steps int) int {
+	if d.elasticScaleDimension() == ElasticShardCount {
+		return d.boundElasticCapacity(currentCapacity + steps)
+	}
+
+	index := sort.SearchInts(elasticShardCapacities, currentCapacity) + steps
+	index = max(0, min(index, len(elasticShardCapacities)-1))
+	return d.boundElasticCapacity(elasticShardCapacities[index])
+}
+
+// desiredElasticCapacity calculates the desired capacity of an elastic cluster from the metric value.
+func (d *DocumentDB) desiredElasticCapacity(currentMetricValue float64, currentCapacity int) int {
+	proportionalCapacity := (currentMetricValue / d.targetValueFor(d.MetricName)) * float64(currentCapacity)
+
+	if proportionalCapacity > float64(currentCapacity) {
+		// Scaling Out: to the capacity that covers the load
+		return d.boundElasticCapacity(int(math.Ceil(proportionalCapacity)))
+	}
+
+	// Scaling In: a single step at a time, and only while the lower capacity still covers the load
+	lowerCapacity := d.stepElasticCapacity(currentCapacity, -1)
+	if lowerCapacity < currentCapacity && float64(lowerCapacity) >= proportionalCapacity {
+		return lowerCapacity
+	}
+	return d.boundElasticCapacity(currentCapacity)
+}
+
+// getElasticMetricValue retrieves the latest average of MetricName for the elastic cluster.
+func (d *DocumentDB) getElasticMetricValue(ctx context.Context, cluster *elasticTypes.Cluster) (float64, error) {
+	// The ClusterId dimension is the last segment of the cluster ARN
+	clusterArn := aws.ToString(cluster.ClusterArn)
+	clusterID := clusterArn[strings.LastIndex(clusterArn, "/")+1:]
+
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/DocDB-Elastic"),
+		MetricName: aws.String(d.MetricName),
+		Dimensions: []cwTypes.Dimension{
+			{
+				Name:  aws.String("ClusterId"),
+				Value: aws.String(clusterID),
+			},
+		},
+		StartTime:  aws.Time(time.Now().Add(-5 * time.Minute)),
+		EndTime:    aws.Time(time.Now()),
+		Period:     aws.Int32(300), // 5 minutes
+		Statistics: []cwTypes.Statistic{cwTypes.StatisticAverage},
+	}
+
+	resp, err := d.CloudWatchClient.GetMetricStatistics(ctx, input)
+	if err != nil {
+		d.Logger.Error("Failed to get metric statistics", "Error", err, "ClusterID", d.ClusterID)
+		return 0, err
+	}
+	if len(resp.Datapoints) == 0 {
+		d.Logger.Error("No datapoints found for elastic cluster", "ClusterID", d.ClusterID)
+		return 0, fmt.Errorf("no datapoints found for elastic cluster %s", d.ClusterID)
+	}
+
+	// Use the latest datapoint
+	sort.Slice(resp.Datapoints, func(i, j int) bool {
+		return resp.Datapoints[i].Timestamp.Before(*resp.Datapoints[j].Timestamp)
+	})
+	return aws.ToFloat64(resp.Datapoints[len(resp.Datapoints)-1].Average), nil
+}
+
+// isElasticClusterPaused checks if the elastic cluster carries the paused tag.
+func (d *DocumentDB) isElasticClusterPaused(ctx context.Context, cluster *elasticTypes.Cluster) (bool, error) {
+	tagsOutput, err := d.ElasticClient.ListTagsForResource(ctx, &docdbelastic.ListTagsForResourceInput{ResourceArn: cluster.ClusterArn})
+	if err != nil {
+		d.Logger.Error("Failed to list tags for elastic cluster", "Error", err, "ClusterID", d.ClusterID)
+		return false, err
+	}
+	return tagsOutput.Tags[pausedTagKey] == "true", nil
+}
+
+// setElasticClusterPaused adds or removes the paused tag of the elastic cluster.
+func (d *DocumentDB) setElasticClusterPaused(ctx context.Context, cluster *elasticTypes.Cluster, paused bool) error {
+	var err error
+	if paused {
+		_, err = d.ElasticClient.TagResource(ctx, &docdbelastic.TagResourceInput{
+			ResourceArn: cluster.ClusterArn,
+			Tags:        map[string]string{pausedTagKey: "true"},
+		})
+	} else {
+		_, err = d.ElasticClient.UntagResource(ctx, &docdbelastic.UntagResourceInput{
+			ResourceArn: cluster.ClusterArn,
+			TagKeys:     []string{pausedTagKey},
+		})
+	}
+	if err != nil {
+		d.Logger.Error("Failed to update paused tag of elastic cluster", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	return nil
+}
+
+// executeElasticScalingAction scales the elastic cluster to the capacity returned by desiredCapacity.
+func (d *DocumentDB) executeElasticScalingAction(ctx context.Context, cluster *elasticTypes.Cluster, desiredCapacity func(currentCapacity int) (int, error)) error {
+	paused, err := d.isElasticClusterPaused(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if pauseSwitch := d.pauseSwitch(ctx, paused); pauseSwitch != "" {
+		d.skipForPauseSwitch(ctx, pauseSwitch)
+		return nil
+	}
+
+	// Elastic clusters only accept updates while active
+	if cluster.Status != elasticTypes.StatusActive {
+		d.Logger.Warn("Elastic cluster is not active, skipping scaling action", "ClusterID", d.ClusterID, "Status", string(cluster.Status))
+		return nil
+	}
+
+	dimension := d.elasticScaleDimension()
+	currentCapacity := d.elasticCapacity(cluster)
+	d.Logger.Info("Retrieved current elastic cluster capacity", "Dimension", dimension, "CurrentCapacity", currentCapacity)
+
+	targetCapacity, err := desiredCapacity(currentCapacity)
+	if err != nil {
+		return err
+	}
+	if targetCapacity == currentCapacity {
+		d.Logger.Info("No scaling action needed", "DesiredCapacity", targetCapacity, "CurrentCapacity", currentCapacity, "ClusterID", d.ClusterID)
+		return nil
+	}
+
+	updateClusterInput := &docdbelastic.UpdateClusterInput{
+		ClusterArn: cluster.ClusterArn,
+	}
+	if dimension == ElasticShardCapacity {
+		updateClusterInput.ShardCapacity = aws.Int32(int32(targetCapacity))
+	} else {
+		updateClusterInput.ShardCount = aws.Int32(int32(targetCapacity))
+	}
+
+	scaleOut := targetCapacity > currentCapacity
+	if scaleOut {
+		d.Logger.Info("Scaling Out elastic cluster", "Dimension", dimension, "DesiredCapacity", targetCapacity, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleOut)
+	} else {
+		d.Logger.Info("Scaling In elastic cluster", "Dimension", dimension, "DesiredCapacity", targetCapacity, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleIn)
+	}
+
+	if !d.DryRun {
+		if _, err := d.ElasticClient.UpdateCluster(ctx, updateClusterInput); err != nil {
+			d.Logger.Error("Failed to update elastic cluster", "Error", err, "ClusterID", d.ClusterID)
+			return err
+		}
+	} else {
+		d.Logger.Info("[Dry Run] Would update elastic cluster", "Dimension", dimension, "DesiredCapacity", targetCapacity, "ClusterID", d.ClusterID)
+	}
+
+	if scaleOut {
+		if err := d.Notifier.SendScaleOutNotification(ctx, d.ClusterID, targetCapacity-currentCapacity); err != nil {
+			d.Logger.Error("Failed to send scale-out notification", "Error", err)
+		}
+	} else {
+		if err := d.Notifier.SendScaleInNotification(ctx, d.ClusterID, currentCapacity-targetCapacity); err != nil {
+			d.Logger.Error("Failed to send scale-in notification", "Error", err)
+		}
+	}
+	return nil
+}
+
+// ExecuteElasticScalingAction performs the scheduled or metric-based scaling logic on an elastic cluster.
+// Scheduled scaling moves the capacity by ScheduleNumberReplicas shards, or supported shard capacities.
+func (d *DocumentDB) ExecuteElasticScalingAction(ctx context.Context, cluster *elasticTypes.Cluster) error {
+	return d.executeElasticScalingAction(ctx, cluster, func(currentCapacity int) (int, error) {
+		if d.ScheduledScaling {
+			return d.stepElasticCapacity(currentCapacity, d.ScheduleNumberReplicas), nil
+		}
+
+		currentMetricValue, err := d.getElasticMetricValue(ctx, cluster)
+		if err != nil {
+			d.Logger.Error("Failed to retrieve current metric value", "Error", err)
+			return 0, err
+		}
+		d.Logger.Info("Retrieved current metric value", "MetricValue", currentMetricValue)
+		return d.desiredElasticCapacity(currentMetricValue, currentCapacity), nil
+	})
+}
+
+// scaleElasticToCapacity scales the elastic cluster to desiredCapacity, within the bounds.
+func (d *DocumentDB) scaleElasticToCapacity(ctx context.Context, cluster *elasticTypes.Cluster, desiredCapacity int) error {
+	return d.executeElasticScalingAction(ctx, cluster, func(int) (int, error) {
+		boundedCapacity := d.boundElasticCapacity(desiredCapacity)
+		if boundedCapacity != desiredCapacity {
+			d.Logger.Warn("Desired capacity adjusted to MIN_CAPACITY/MAX_CAPACITY bounds", "RequestedCapacity", desiredCapacity, "DesiredCapacity", boundedCapacity)
+		}
+		return boundedCapacity, nil
+	})
+}
@@ -0,0 +1,50 @@
+package autoscaling
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// endpointURLEnv is the environment variable overriding the endpoint of every AWS API, read by the SDK. The
+// endpoint of a single API is overridden with endpointURLEnv_<service ID>, e.g. AWS_ENDPOINT_URL_DOCDB,
+// and the shared config of a profile may set them with endpoint_url, e.g. for LocalStack.
+const endpointURLEnv = "AWS_ENDPOINT_URL"
+
+// ignoreEndpointURLsEnv makes the SDK ignore the configured endpoints.
+const ignoreEndpointURLsEnv = "AWS_IGNORE_CONFIGURED_ENDPOINT_URLS"
+
+// endpointServices are the service IDs of the AWS APIs the autoscaler calls, as in AWS_ENDPOINT_URL_<ID>.
+var endpointServices = []string{"CLOUDWATCH", "DOCDB", "DOCDB_ELASTIC", "DYNAMODB", "EVENTBRIDGE", "IAM", "PRICING", "RDS", "S3", "SNS", "SSM", "STS"}
+
+// EndpointOverrides returns the endpoints of the AWS APIs overridden in the environment, by service ID, with
+// the endpoint of every API under "*", e.g. to log that the autoscaler runs against LocalStack.
+func EndpointOverrides() map[string]string {
+	overrides := map[string]string{}
+	if strings.EqualFold(os.Getenv(ignoreEndpointURLsEnv), "true") {
+		return overrides
+	}
+	if endpoint := os.Getenv(endpointURLEnv); endpoint != "" {
+		overrides["*"] = endpoint
+	}
+	for _, service := range endpointServices {
+		if endpoint := os.Getenv(endpointURLEnv + "_" + service); endpoint != "" {
+			overrides[service] = endpoint
+		}
+	}
+	return overrides
+}
+
+// NewS3Client returns the S3 client of cfg. Buckets of overridden S3 endpoints are addressed in the path of
+// the URLs, as LocalStack and other S3-compatible stores serve them at http://host:port/bucket rather than
+// at a host name of their own.
+func NewS3Client(cfg aws.Config) *s3.Client {
+	overrides := EndpointOverrides()
+	_, overridden := overrides["S3"]
+	pathStyle := overridden || aws.ToString(cfg.BaseEndpoint) != ""
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = o.UsePathStyle || pathStyle
+	})
+}
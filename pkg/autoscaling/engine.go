@@ -0,0 +1,47 @@
+package autoscaling
+
+import "fmt"
+
+// Engine describes a database engine sharing the DocumentDB cluster and instance model:
+// a writer and read replicas managed through the RDS query API, with per-instance CloudWatch metrics.
+type Engine struct {
+	Name            string // Engine of the clusters, passed to CreateDBInstance and used to list clusters
+	MetricNamespace string // CloudWatch namespace of the instance metrics
+	RDSAPI          bool   // Instances are managed with an RDS client, see NewRDSInstanceClient
+}
+
+// Supported engines. Neptune shares the RDS query API with DocumentDB, so the DocumentDB client manages its instances.
+var (
+	DocDBEngine            = Engine{Name: "docdb", MetricNamespace: "AWS/DocDB"}
+	NeptuneEngine          = Engine{Name: "neptune", MetricNamespace: "AWS/Neptune"}
+	AuroraMySQLEngine      = Engine{Name: "aurora-mysql", MetricNamespace: "AWS/RDS", RDSAPI: true}
+	AuroraPostgreSQLEngine = Engine{Name: "aurora-postgresql", MetricNamespace: "AWS/RDS", RDSAPI: true}
+)
+
+// engines are the supported engines by name.
+var engines = map[string]Engine{
+	DocDBEngine.Name:            DocDBEngine,
+	NeptuneEngine.Name:          NeptuneEngine,
+	AuroraMySQLEngine.Name:      AuroraMySQLEngine,
+	AuroraPostgreSQLEngine.Name: AuroraPostgreSQLEngine,
+}
+
+// LookupEngine returns the engine with the given name, defaulting to DocumentDB when name is empty.
+func LookupEngine(name string) (Engine, error) {
+	if name == "" {
+		return DocDBEngine, nil
+	}
+	engine, found := engines[name]
+	if !found {
+		return Engine{}, fmt.Errorf("unsupported engine %s", name)
+	}
+	return engine, nil
+}
+
+// engine returns the engine of the cluster, defaulting to DocumentDB.
+func (d *DocumentDB) engine() Engine {
+	if d.Engine.Name == "" {
+		return DocDBEngine
+	}
+	return d.Engine
+}
@@ -0,0 +1,38 @@
+package autoscaling
+
+import (
+	"context"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// emitEvent publishes a lifecycle event of the cluster, when an event bus is configured.
+// Failures are logged only, so that scaling does not depend on the bus.
+func (d *DocumentDB) emitEvent(ctx context.Context, event notifications.LifecycleEvent) {
+	if d.Events == nil {
+		return
+	}
+	event.ClusterID = d.ClusterID
+	event.DryRun = d.DryRun
+	event.CorrelationID = correlation.FromContext(ctx)
+	if err := d.Events.PublishLifecycleEvent(ctx, event); err != nil {
+		d.Logger.Error("Failed to publish lifecycle event", "Error", err, "DetailType", event.DetailType)
+	}
+}
+
+// emitOutcome publishes the decision of the last scaling action, or its failure.
+func (d *DocumentDB) emitOutcome(ctx context.Context, actionErr error) {
+	if actionErr != nil {
+		d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventScalingFailed, Error: actionErr.Error()})
+		return
+	}
+	result := d.LastResult()
+	d.emitEvent(ctx, notifications.LifecycleEvent{
+		DetailType:      notifications.EventScalingDecisionMade,
+		Decision:        result.Decision,
+		ReplicasAdded:   result.ReplicasAdded,
+		ReplicasRemoved: result.ReplicasRemoved,
+		Explanation:     result.Explanation,
+	})
+}
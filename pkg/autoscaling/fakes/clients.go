@@ -0,0 +1,385 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// DocDB serves the DocumentDB API of a cluster.
+type DocDB struct {
+	cluster *Cluster
+}
+
+// DocDB returns the DocumentDB client of the cluster.
+func (c *Cluster) DocDB() *DocDB {
+	return &DocDB{cluster: c}
+}
+
+// DescribeDBInstances describes the instances of the cluster, filtered by db-cluster-id and db-instance-id.
+func (f *DocDB) DescribeDBInstances(ctx context.Context, params *docdb.DescribeDBInstancesInput, optFns ...func(*docdb.Options)) (*docdb.DescribeDBInstancesOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeDBInstances"); err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	if params.DBInstanceIdentifier != nil {
+		ids = append(ids, aws.ToString(params.DBInstanceIdentifier))
+	}
+	for _, filter := range params.Filters {
+		switch aws.ToString(filter.Name) {
+		case "db-cluster-id":
+			if !slices.Contains(filter.Values, c.ID) {
+				return &docdb.DescribeDBInstancesOutput{}, nil
+			}
+		case "db-instance-id":
+			ids = append(ids, filter.Values...)
+		}
+	}
+	output := &docdb.DescribeDBInstancesOutput{}
+	for _, instance := range c.instances {
+		if len(ids) > 0 && !slices.Contains(ids, instance.ID) {
+			continue
+		}
+		output.DBInstances = append(output.DBInstances, docdbTypes.DBInstance{
+			DBInstanceIdentifier: aws.String(instance.ID),
+			DBInstanceArn:        aws.String(instance.arn()),
+			DBInstanceClass:      aws.String(instance.Class),
+			DBInstanceStatus:     aws.String(instance.Status),
+			DBClusterIdentifier:  aws.String(c.ID),
+			AvailabilityZone:     aws.String(instance.AvailabilityZone),
+			Engine:               aws.String("docdb"),
+		})
+	}
+	return output, nil
+}
+
+// CreateDBInstance adds an instance to the cluster, with the status CreateStatus.
+func (f *DocDB) CreateDBInstance(ctx context.Context, params *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("CreateDBInstance"); err != nil {
+		return nil, err
+	}
+	id := aws.ToString(params.DBInstanceIdentifier)
+	if c.instance(id) != nil {
+		return nil, apiError("DBInstanceAlreadyExists", "DB instance %s already exists", id)
+	}
+	quota := c.InstanceQuota
+	if quota <= 0 {
+		quota = DefaultInstanceQuota
+	}
+	if len(c.instances) >= quota {
+		return nil, apiError("InstanceQuotaExceeded", "DB instances quota of %d reached", quota)
+	}
+	status := c.CreateStatus
+	if status == "" {
+		status = StatusAvailable
+	}
+	instance := &Instance{
+		ID:               id,
+		Class:            aws.ToString(params.DBInstanceClass),
+		AvailabilityZone: aws.ToString(params.AvailabilityZone),
+		Status:           status,
+		Tags:             map[string]string{},
+	}
+	if instance.AvailabilityZone == "" {
+		instance.AvailabilityZone = "us-east-1a"
+	}
+	for _, tag := range params.Tags {
+		instance.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	c.instances = append(c.instances, instance)
+	return &docdb.CreateDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{
+		DBInstanceIdentifier: aws.String(id),
+		DBInstanceArn:        aws.String(instance.arn()),
+		DBInstanceClass:      aws.String(instance.Class),
+		DBInstanceStatus:     aws.String(status),
+	}}, nil
+}
+
+// DeleteDBInstance removes an instance from the cluster. The writer cannot be deleted.
+func (f *DocDB) DeleteDBInstance(ctx context.Context, params *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DeleteDBInstance"); err != nil {
+		return nil, err
+	}
+	id := aws.ToString(params.DBInstanceIdentifier)
+	index := slices.IndexFunc(c.instances, func(instance *Instance) bool { return instance.ID == id })
+	if index < 0 {
+		return nil, apiError("DBInstanceNotFound", "DB instance %s not found", id)
+	}
+	if c.instances[index].Writer {
+		return nil, apiError("InvalidDBClusterStateFault", "DB instance %s is the writer of cluster %s", id, c.ID)
+	}
+	c.instances = slices.Delete(c.instances, index, index+1)
+	return &docdb.DeleteDBInstanceOutput{DBInstance: &docdbTypes.DBInstance{DBInstanceIdentifier: aws.String(id), DBInstanceStatus: aws.String("deleting")}}, nil
+}
+
+// ListTagsForResource lists the tags of the cluster or of an instance, by ARN.
+func (f *DocDB) ListTagsForResource(ctx context.Context, params *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("ListTagsForResource"); err != nil {
+		return nil, err
+	}
+	tags, err := c.resourceTags(aws.ToString(params.ResourceName))
+	if err != nil {
+		return nil, err
+	}
+	output := &docdb.ListTagsForResourceOutput{TagList: []docdbTypes.Tag{}}
+	for _, key := range sortedKeys(tags) {
+		output.TagList = append(output.TagList, docdbTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return output, nil
+}
+
+// AddTagsToResource sets tags of the cluster or of an instance, by ARN.
+func (f *DocDB) AddTagsToResource(ctx context.Context, params *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("AddTagsToResource"); err != nil {
+		return nil, err
+	}
+	tags, err := c.resourceTags(aws.ToString(params.ResourceName))
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range params.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return &docdb.AddTagsToResourceOutput{}, nil
+}
+
+// RemoveTagsFromResource removes tags of the cluster or of an instance, by ARN.
+func (f *DocDB) RemoveTagsFromResource(ctx context.Context, params *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("RemoveTagsFromResource"); err != nil {
+		return nil, err
+	}
+	tags, err := c.resourceTags(aws.ToString(params.ResourceName))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range params.TagKeys {
+		delete(tags, key)
+	}
+	return &docdb.RemoveTagsFromResourceOutput{}, nil
+}
+
+// DescribeGlobalClusters describes no global clusters, as fake clusters are never members of one.
+func (f *DocDB) DescribeGlobalClusters(ctx context.Context, params *docdb.DescribeGlobalClustersInput, optFns ...func(*docdb.Options)) (*docdb.DescribeGlobalClustersOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeGlobalClusters"); err != nil {
+		return nil, err
+	}
+	return &docdb.DescribeGlobalClustersOutput{}, nil
+}
+
+// RDS serves the RDS API of a cluster.
+type RDS struct {
+	cluster *Cluster
+}
+
+// RDS returns the RDS client of the cluster.
+func (c *Cluster) RDS() *RDS {
+	return &RDS{cluster: c}
+}
+
+// DescribeDBClusters describes the cluster, with its members and tags.
+func (f *RDS) DescribeDBClusters(ctx context.Context, params *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeDBClusters"); err != nil {
+		return nil, err
+	}
+	if id := aws.ToString(params.DBClusterIdentifier); id != "" && id != c.ID {
+		return nil, apiError("DBClusterNotFoundFault", "DB cluster %s not found", id)
+	}
+	cluster := rdsTypes.DBCluster{
+		DBClusterIdentifier: aws.String(c.ID),
+		DBClusterArn:        aws.String(c.arn()),
+		Engine:              aws.String("docdb"),
+		EngineVersion:       aws.String(c.EngineVersion),
+		Status:              aws.String(StatusAvailable),
+	}
+	for _, instance := range c.instances {
+		cluster.DBClusterMembers = append(cluster.DBClusterMembers, rdsTypes.DBClusterMember{
+			DBInstanceIdentifier: aws.String(instance.ID),
+			IsClusterWriter:      aws.Bool(instance.Writer),
+		})
+	}
+	for _, key := range sortedKeys(c.Tags) {
+		cluster.TagList = append(cluster.TagList, rdsTypes.Tag{Key: aws.String(key), Value: aws.String(c.Tags[key])})
+	}
+	return &rds.DescribeDBClustersOutput{DBClusters: []rdsTypes.DBCluster{cluster}}, nil
+}
+
+// DescribeDBInstances describes the instances of the cluster.
+func (f *RDS) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeDBInstances"); err != nil {
+		return nil, err
+	}
+	output := &rds.DescribeDBInstancesOutput{}
+	for _, instance := range c.instances {
+		output.DBInstances = append(output.DBInstances, rdsTypes.DBInstance{
+			DBInstanceIdentifier: aws.String(instance.ID),
+			DBInstanceArn:        aws.String(instance.arn()),
+			DBInstanceClass:      aws.String(instance.Class),
+			DBInstanceStatus:     aws.String(instance.Status),
+			DBClusterIdentifier:  aws.String(c.ID),
+			AvailabilityZone:     aws.String(instance.AvailabilityZone),
+			Engine:               aws.String("docdb"),
+		})
+	}
+	return output, nil
+}
+
+// DescribeAccountAttributes describes the DB instances quota of the account, used by the instances of the
+// cluster.
+func (f *RDS) DescribeAccountAttributes(ctx context.Context, params *rds.DescribeAccountAttributesInput, optFns ...func(*rds.Options)) (*rds.DescribeAccountAttributesOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeAccountAttributes"); err != nil {
+		return nil, err
+	}
+	quota := c.InstanceQuota
+	if quota <= 0 {
+		quota = DefaultInstanceQuota
+	}
+	return &rds.DescribeAccountAttributesOutput{AccountQuotas: []rdsTypes.AccountQuota{
+		{AccountQuotaName: aws.String("DBInstances"), Used: aws.Int64(int64(len(c.instances))), Max: aws.Int64(int64(quota))},
+	}}, nil
+}
+
+// DescribeOrderableDBInstanceOptions offers every instance class, but those of NotOrderable.
+func (f *RDS) DescribeOrderableDBInstanceOptions(ctx context.Context, params *rds.DescribeOrderableDBInstanceOptionsInput, optFns ...func(*rds.Options)) (*rds.DescribeOrderableDBInstanceOptionsOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeOrderableDBInstanceOptions"); err != nil {
+		return nil, err
+	}
+	output := &rds.DescribeOrderableDBInstanceOptionsOutput{}
+	if class := aws.ToString(params.DBInstanceClass); !slices.Contains(c.NotOrderable, class) {
+		output.OrderableDBInstanceOptions = []rdsTypes.OrderableDBInstanceOption{
+			{DBInstanceClass: params.DBInstanceClass, Engine: params.Engine, EngineVersion: params.EngineVersion},
+		}
+	}
+	return output, nil
+}
+
+// CloudWatch serves the CloudWatch API of a cluster, from its metric series.
+type CloudWatch struct {
+	cluster *Cluster
+}
+
+// CloudWatch returns the CloudWatch client of the cluster.
+func (c *Cluster) CloudWatch() *CloudWatch {
+	return &CloudWatch{cluster: c}
+}
+
+// GetMetricStatistics returns a single datapoint of the metric of the instance of the DBInstanceIdentifier
+// dimension at the current step, with every statistic set to its value, or no datapoints without a series.
+func (f *CloudWatch) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("GetMetricStatistics"); err != nil {
+		return nil, err
+	}
+	var instanceID string
+	for _, dimension := range params.Dimensions {
+		if aws.ToString(dimension.Name) == "DBInstanceIdentifier" {
+			instanceID = aws.ToString(dimension.Value)
+		}
+	}
+	output := &cloudwatch.GetMetricStatisticsOutput{Label: params.MetricName}
+	value, found := c.metricValue(instanceID, aws.ToString(params.MetricName))
+	if !found {
+		return output, nil
+	}
+	timestamp := time.Now()
+	if params.EndTime != nil {
+		timestamp = *params.EndTime
+	}
+	output.Datapoints = []cwTypes.Datapoint{{
+		Timestamp:   aws.Time(timestamp),
+		Average:     aws.Float64(value),
+		Maximum:     aws.Float64(value),
+		Minimum:     aws.Float64(value),
+		Sum:         aws.Float64(value),
+		SampleCount: aws.Float64(1),
+	}}
+	return output, nil
+}
+
+// DescribeAlarms describes the alarms of the cluster, filtered by name.
+func (f *CloudWatch) DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	c := f.cluster
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.call("DescribeAlarms"); err != nil {
+		return nil, err
+	}
+	output := &cloudwatch.DescribeAlarmsOutput{}
+	for _, alarm := range c.MetricAlarms {
+		if len(params.AlarmNames) == 0 || slices.Contains(params.AlarmNames, aws.ToString(alarm.AlarmName)) {
+			output.MetricAlarms = append(output.MetricAlarms, alarm)
+		}
+	}
+	for _, alarm := range c.CompositeAlarms {
+		if len(params.AlarmNames) == 0 || slices.Contains(params.AlarmNames, aws.ToString(alarm.AlarmName)) {
+			output.CompositeAlarms = append(output.CompositeAlarms, alarm)
+		}
+	}
+	return output, nil
+}
+
+// SNS records the messages published to it.
+type SNS struct {
+	mu       sync.Mutex
+	messages []sns.PublishInput
+}
+
+// Publish records a message.
+func (f *SNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, *params)
+	return &sns.PublishOutput{MessageId: aws.String(fmt.Sprintf("message-%d", len(f.messages)))}, nil
+}
+
+// Messages returns the messages published so far.
+func (f *SNS) Messages() []sns.PublishInput {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.messages)
+}
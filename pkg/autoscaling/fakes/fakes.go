@@ -0,0 +1,283 @@
+// Package fakes provides in-memory implementations of the AWS APIs the autoscaler calls, for tests of
+// the autoscaler and of programs embedding it, without mocking every call.
+//
+// A Cluster holds the topology, tags and metric series of a DocumentDB cluster. Its DocDB, RDS and
+// CloudWatch clients serve the DocDBAPI, RDSAPI and CloudWatchAPI of package autoscaling from it, and
+// change it as the autoscaler creates, deletes and tags instances:
+//
+//	cluster := fakes.NewCluster("orders")
+//	cluster.AddReader("orders-reader-1", nil)
+//	cluster.SetMetric("CPUUtilization", 80, 60, 40)
+//	autoscaler, err := autoscaling.New("orders", autoscaling.WithClients(cluster.DocDB(), cluster.CloudWatch(), cluster.RDS()))
+package fakes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/smithy-go"
+)
+
+// Instance statuses.
+const (
+	StatusAvailable = "available"
+	StatusCreating  = "creating"
+)
+
+// DefaultInstanceClass is the class of the instances added without one.
+const DefaultInstanceClass = "db.r6g.large"
+
+// DefaultInstanceQuota is the DB instances quota of the account, unless configured otherwise.
+const DefaultInstanceQuota = 40
+
+// Instance is an instance of a fake cluster.
+type Instance struct {
+	ID               string
+	Class            string
+	AvailabilityZone string
+	Status           string
+	Writer           bool
+	Tags             map[string]string
+}
+
+// arn returns the ARN of the instance.
+func (i *Instance) arn() string {
+	return "arn:aws:rds:us-east-1:123456789012:db:" + i.ID
+}
+
+// Cluster is an in-memory DocumentDB cluster. Its fields may be set before the clients are used; once
+// they are, the cluster is changed through its methods, which are safe for concurrent use.
+type Cluster struct {
+	ID            string
+	EngineVersion string
+	CreateStatus  string   // Status of the instances created through the DocDB client, StatusAvailable when empty
+	InstanceQuota int      // DB instances quota of the account, DefaultInstanceQuota when zero
+	NotOrderable  []string // Instance classes not offered
+	Tags          map[string]string
+
+	// Alarms described by the CloudWatch client
+	MetricAlarms    []cwTypes.MetricAlarm
+	CompositeAlarms []cwTypes.CompositeAlarm
+
+	mu        sync.Mutex
+	instances []*Instance
+	metrics   map[string][]float64 // By metric name, and by instance ID and metric name when instance-specific
+	step      int
+	errs      map[string]error
+	calls     map[string]int
+}
+
+// NewCluster returns a cluster with a writer named <id>-writer of DefaultInstanceClass, and no readers.
+func NewCluster(id string) *Cluster {
+	c := &Cluster{ID: id, EngineVersion: "5.0.0", Tags: map[string]string{}}
+	c.AddInstance(Instance{ID: id + "-writer", Writer: true})
+	return c
+}
+
+// arn returns the ARN of the cluster.
+func (c *Cluster) arn() string {
+	return "arn:aws:rds:us-east-1:123456789012:cluster:" + c.ID
+}
+
+// AddInstance adds an instance, available and of DefaultInstanceClass unless set.
+func (c *Cluster) AddInstance(instance Instance) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if instance.Class == "" {
+		instance.Class = DefaultInstanceClass
+	}
+	if instance.Status == "" {
+		instance.Status = StatusAvailable
+	}
+	if instance.AvailabilityZone == "" {
+		instance.AvailabilityZone = "us-east-1a"
+	}
+	if instance.Tags == nil {
+		instance.Tags = map[string]string{}
+	}
+	c.instances = append(c.instances, &instance)
+}
+
+// AddReader adds an available reader with tags, e.g. the docdb-autoscaler-created tag of a replica of
+// the autoscaler.
+func (c *Cluster) AddReader(id string, tags map[string]string) {
+	c.AddInstance(Instance{ID: id, Tags: tags})
+}
+
+// Instances returns a copy of the instances of the cluster, the writer first.
+func (c *Cluster) Instances() []Instance {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	instances := make([]Instance, 0, len(c.instances))
+	for _, instance := range c.instances {
+		copied := *instance
+		copied.Tags = cloneTags(instance.Tags)
+		instances = append(instances, copied)
+	}
+	return instances
+}
+
+// Readers returns the IDs of the readers of the cluster.
+func (c *Cluster) Readers() []string {
+	var readers []string
+	for _, instance := range c.Instances() {
+		if !instance.Writer {
+			readers = append(readers, instance.ID)
+		}
+	}
+	return readers
+}
+
+// ClusterTags returns a copy of the tags of the cluster.
+func (c *Cluster) ClusterTags() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return cloneTags(c.Tags)
+}
+
+// SetStatus sets the status of an instance, e.g. "failed" or "backing-up".
+func (c *Cluster) SetStatus(instanceID, status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if instance := c.instance(instanceID); instance != nil {
+		instance.Status = status
+	}
+}
+
+// Settle makes the instances being created available, as if their creation completed.
+func (c *Cluster) Settle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, instance := range c.instances {
+		if instance.Status == StatusCreating {
+			instance.Status = StatusAvailable
+		}
+	}
+}
+
+// Failover makes an instance the writer of the cluster.
+func (c *Cluster) Failover(instanceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, instance := range c.instances {
+		instance.Writer = instance.ID == instanceID
+	}
+}
+
+// SetMetric sets the series of a metric of every instance: each step of the cluster reads the next
+// value, and the last value once the series is exhausted.
+func (c *Cluster) SetMetric(metricName string, series ...float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metrics == nil {
+		c.metrics = map[string][]float64{}
+	}
+	c.metrics[metricName] = series
+}
+
+// SetInstanceMetric sets the series of a metric of a single instance, overriding that of SetMetric.
+func (c *Cluster) SetInstanceMetric(instanceID, metricName string, series ...float64) {
+	c.SetMetric(instanceID+"/"+metricName, series...)
+}
+
+// Step moves the metric series on to their next values.
+func (c *Cluster) Step() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.step++
+}
+
+// metricValue returns the value of a metric of an instance at the current step, and false without a
+// series.
+func (c *Cluster) metricValue(instanceID, metricName string) (float64, bool) {
+	series, found := c.metrics[instanceID+"/"+metricName]
+	if !found {
+		series, found = c.metrics[metricName]
+	}
+	if !found || len(series) == 0 {
+		return 0, false
+	}
+	return series[min(c.step, len(series)-1)], true
+}
+
+// Fail makes every call of an operation fail with err, e.g. "CreateDBInstance", until cleared with a nil
+// err.
+func (c *Cluster) Fail(operation string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.errs == nil {
+		c.errs = map[string]error{}
+	}
+	if err == nil {
+		delete(c.errs, operation)
+		return
+	}
+	c.errs[operation] = err
+}
+
+// Calls returns how many times an operation was called, e.g. "DeleteDBInstance".
+func (c *Cluster) Calls(operation string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[operation]
+}
+
+// call counts a call of an operation, with the lock held, and returns the error it fails with, if any.
+func (c *Cluster) call(operation string) error {
+	if c.calls == nil {
+		c.calls = map[string]int{}
+	}
+	c.calls[operation]++
+	return c.errs[operation]
+}
+
+// instance returns the instance with an ID or ARN, with the lock held.
+func (c *Cluster) instance(idOrARN string) *Instance {
+	for _, instance := range c.instances {
+		if instance.ID == idOrARN || instance.arn() == idOrARN {
+			return instance
+		}
+	}
+	return nil
+}
+
+// resourceTags returns the tags of the cluster or an instance by ARN, with the lock held.
+func (c *Cluster) resourceTags(arn string) (map[string]string, error) {
+	if arn == c.arn() {
+		if c.Tags == nil {
+			c.Tags = map[string]string{}
+		}
+		return c.Tags, nil
+	}
+	if instance := c.instance(arn); instance != nil && strings.HasPrefix(arn, "arn:") {
+		return instance.Tags, nil
+	}
+	return nil, apiError("DBInstanceNotFound", "resource %s not found", arn)
+}
+
+// apiError returns an error of the AWS API with a code, as returned by the SDK.
+func apiError(code, format string, args ...any) error {
+	return &smithy.GenericAPIError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// cloneTags returns a copy of tags.
+func cloneTags(tags map[string]string) map[string]string {
+	cloned := make(map[string]string, len(tags))
+	for key, value := range tags {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// sortedKeys returns the keys of tags in order, for stable outputs.
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
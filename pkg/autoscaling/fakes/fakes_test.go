@@ -0,0 +1,89 @@
+package fakes_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/fakes"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ autoscaling.DocDBAPI      = (*fakes.DocDB)(nil)
+	_ autoscaling.RDSAPI        = (*fakes.RDS)(nil)
+	_ autoscaling.CloudWatchAPI = (*fakes.CloudWatch)(nil)
+	_ notifications.SNSAPI      = (*fakes.SNS)(nil)
+)
+
+// newAutoscaler returns an autoscaler of the fake cluster, scaling on CPUUtilization.
+func newAutoscaler(t *testing.T, cluster *fakes.Cluster, options ...autoscaling.Option) *autoscaling.DocumentDB {
+	options = append([]autoscaling.Option{
+		autoscaling.WithClients(cluster.DocDB(), cluster.CloudWatch(), cluster.RDS()),
+		autoscaling.WithCapacity(1, 5),
+		autoscaling.WithMetric("CPUUtilization", 50),
+		autoscaling.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, options...)
+	autoscaler, err := autoscaling.New(cluster.ID, options...)
+	assert.NoError(t, err)
+	return autoscaler
+}
+
+// TestCluster tests that the autoscaler scales a fake cluster out and in with its metric series.
+func TestCluster(t *testing.T) {
+	cluster := fakes.NewCluster("orders")
+	cluster.AddReader("orders-reader-1", nil)
+	cluster.SetMetric("CPUUtilization", 100, 10)
+	autoscaler := newAutoscaler(t, cluster)
+
+	result, err := autoscaler.Evaluate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.DecisionScaleOut, result.Decision)
+	assert.Equal(t, 1, result.ReplicasAdded)
+	assert.Len(t, cluster.Readers(), 2)
+	added := cluster.Instances()[2]
+	assert.Equal(t, "true", added.Tags["docdb-autoscaler-created"])
+	assert.Equal(t, fakes.DefaultInstanceClass, added.Class)
+
+	// Only the replica of the autoscaler is removed
+	cluster.Step()
+	result, err = autoscaler.Evaluate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, autoscaling.DecisionScaleIn, result.Decision)
+	assert.Equal(t, []string{"orders-reader-1"}, cluster.Readers())
+	assert.Equal(t, 1, cluster.Calls("DeleteDBInstance"))
+}
+
+// TestCluster_Fail tests that the injected errors fail the calls of the autoscaler.
+func TestCluster_Fail(t *testing.T) {
+	cluster := fakes.NewCluster("orders")
+	cluster.AddReader("orders-reader-1", nil)
+	cluster.SetMetric("CPUUtilization", 100)
+	autoscaler := newAutoscaler(t, cluster)
+
+	quota := errors.New("quota exceeded")
+	cluster.Fail("CreateDBInstance", quota)
+	_, err := autoscaler.Evaluate(context.Background())
+	assert.ErrorIs(t, err, quota)
+	assert.Len(t, cluster.Readers(), 1)
+
+	cluster.Fail("CreateDBInstance", nil)
+	_, err = autoscaler.Evaluate(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, cluster.Readers(), 2)
+}
+
+// TestSNS tests that the published notifications are recorded.
+func TestSNS(t *testing.T) {
+	topic := &fakes.SNS{}
+	notifier := notifications.NewNotifier(topic, "arn:aws:sns:us-east-1:123456789012:scaling")
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 2))
+
+	messages := topic.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:scaling", *messages[0].TopicArn)
+}
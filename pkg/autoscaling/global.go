@@ -0,0 +1,82 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// Roles of a cluster in a DocumentDB global cluster.
+const (
+	GlobalClusterRolePrimary   = "primary"
+	GlobalClusterRoleSecondary = "secondary"
+)
+
+// GetGlobalClusterRole reports whether the cluster is the primary or a secondary member of a global cluster.
+// It returns an empty role for clusters that are not part of a global cluster.
+func (d *DocumentDB) GetGlobalClusterRole(ctx context.Context, dbCluster *rdsTypes.DBCluster) (string, error) {
+	clusterArn := aws.ToString(dbCluster.DBClusterArn)
+	if clusterArn == "" {
+		return "", nil
+	}
+	describeGlobalClustersInput := &docdb.DescribeGlobalClustersInput{
+		Filters: []docdbTypes.Filter{
+			{
+				Name:   aws.String("db-cluster-id"),
+				Values: []string{clusterArn},
+			},
+		},
+	}
+	globalClustersOutput, err := d.DocDBClient.DescribeGlobalClusters(ctx, describeGlobalClustersInput)
+	if err != nil {
+		d.Logger.Error("Failed to describe global clusters", "Error", err)
+		return "", err
+	}
+
+	for _, globalCluster := range globalClustersOutput.GlobalClusters {
+		for _, member := range globalCluster.GlobalClusterMembers {
+			if aws.ToString(member.DBClusterArn) != clusterArn {
+				continue
+			}
+			if aws.ToBool(member.IsWriter) {
+				return GlobalClusterRolePrimary, nil
+			}
+			d.Logger.Info("Cluster is a secondary member of a global cluster", "ClusterID", d.ClusterID, "GlobalClusterID", aws.ToString(globalCluster.GlobalClusterIdentifier))
+			return GlobalClusterRoleSecondary, nil
+		}
+	}
+	return "", nil
+}
+
+// getReferenceInstanceIdentifier returns the instance standing in for the writer in a secondary cluster,
+// where all members are readers: the oldest instance, which is never scaled in and whose class new replicas follow.
+func (d *DocumentDB) getReferenceInstanceIdentifier(ctx context.Context) (string, error) {
+	dbInstances, err := d.describeInstances(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var reference *docdbTypes.DBInstance
+	for i, instance := range dbInstances {
+		if reference == nil || isOlderInstance(instance, *reference) {
+			reference = &dbInstances[i]
+		}
+	}
+	if reference == nil {
+		return "", fmt.Errorf("no instances found in secondary cluster %s", d.ClusterID)
+	}
+	return aws.ToString(reference.DBInstanceIdentifier), nil
+}
+
+// isOlderInstance orders instances by creation time, then identifier, to pick a stable reference instance.
+func isOlderInstance(a, b docdbTypes.DBInstance) bool {
+	aTime, bTime := aws.ToTime(a.InstanceCreateTime), aws.ToTime(b.InstanceCreateTime)
+	if !aTime.Equal(bTime) {
+		return aTime.Before(bTime)
+	}
+	return aws.ToString(a.DBInstanceIdentifier) < aws.ToString(b.DBInstanceIdentifier)
+}
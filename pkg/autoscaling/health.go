@@ -0,0 +1,149 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// Statuses of a health check.
+const (
+	HealthPass = "pass"
+	HealthFail = "fail"
+	HealthSkip = "skip"
+)
+
+// HealthCheck is the outcome of one check of a health check invocation.
+type HealthCheck struct {
+	Name   string `json:"Name"`
+	Status string `json:"Status"`
+	Error  string `json:"Error,omitempty"` // Why the check failed or was skipped
+}
+
+// HealthReport is the outcome of a health check invocation, e.g. a post-deploy smoke test.
+// It is healthy when no check failed.
+type HealthReport struct {
+	ClusterID string        `json:"ClusterID"`
+	Healthy   bool          `json:"Healthy"`
+	Checks    []HealthCheck `json:"Checks"`
+}
+
+// NewHealthReport returns a healthy report without checks.
+func NewHealthReport(clusterID string) *HealthReport {
+	return &HealthReport{ClusterID: clusterID, Healthy: true, Checks: []HealthCheck{}}
+}
+
+// Check adds a check that passed when err is nil and failed otherwise.
+func (r *HealthReport) Check(name string, err error) {
+	if err == nil {
+		r.Checks = append(r.Checks, HealthCheck{Name: name, Status: HealthPass})
+		return
+	}
+	r.Healthy = false
+	r.Checks = append(r.Checks, HealthCheck{Name: name, Status: HealthFail, Error: err.Error()})
+}
+
+// Skip adds a check that was not run.
+func (r *HealthReport) Skip(name, reason string) {
+	r.Checks = append(r.Checks, HealthCheck{Name: name, Status: HealthSkip, Error: reason})
+}
+
+// IAMAPI defines the interface for AWS IAM client methods used to check permissions.
+type IAMAPI interface {
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// Permission is an IAM action needed on a resource.
+type Permission struct {
+	Action   string
+	Resource string
+}
+
+// CheckCluster adds the checks of the reachability of the cluster, using only read calls: describing the
+// cluster and its readers, listing its tags and reading its metric. It returns the ARN of the cluster,
+// empty when it could not be described.
+func (d *DocumentDB) CheckCluster(ctx context.Context, report *HealthReport) string {
+	dbCluster, err := d.describeCluster(ctx)
+	report.Check("DescribeCluster", err)
+	if err != nil {
+		return ""
+	}
+	clusterARN := aws.ToString(dbCluster.DBClusterArn)
+
+	readers, err := d.GetReaderInstances(ctx)
+	report.Check("DescribeInstances", err)
+
+	_, err = d.DocDBClient.ListTagsForResource(ctx, &docdb.ListTagsForResourceInput{ResourceName: aws.String(clusterARN)})
+	report.Check("ListTags", err)
+
+	switch {
+	case d.MetricName == "":
+		report.Skip("GetMetric", "METRIC_NAME is not set")
+	case len(readers) == 0:
+		report.Skip("GetMetric", "the cluster has no readers")
+	default:
+		_, err := d.GetCurrentMetricValue(ctx)
+		report.Check("GetMetric "+d.MetricName, err)
+	}
+	return clusterARN
+}
+
+// ClusterPermissions returns the IAM permissions needed to scale the cluster of clusterARN: reading and
+// tagging the cluster and its instances, creating and deleting replicas, and reading metrics.
+func (d *DocumentDB) ClusterPermissions(clusterARN string) []Permission {
+	// Replicas are named after the cluster, e.g. arn:aws:rds:<region>:<account>:db:<cluster>-reader-<suffix>
+	instanceARN := strings.Replace(clusterARN, ":cluster:", ":db:", 1) + "-healthcheck"
+	return []Permission{
+		{Action: "rds:DescribeDBClusters", Resource: clusterARN},
+		{Action: "rds:DescribeDBInstances", Resource: instanceARN},
+		{Action: "rds:ListTagsForResource", Resource: clusterARN},
+		{Action: "rds:AddTagsToResource", Resource: clusterARN},
+		{Action: "rds:RemoveTagsFromResource", Resource: clusterARN},
+		{Action: "rds:CreateDBInstance", Resource: instanceARN},
+		{Action: "rds:CreateDBInstance", Resource: clusterARN},
+		{Action: "rds:DeleteDBInstance", Resource: instanceARN},
+		{Action: "cloudwatch:GetMetricStatistics", Resource: "*"},
+		{Action: "cloudwatch:DescribeAlarms", Resource: "*"},
+	}
+}
+
+// CheckPermissions adds a check per permission, simulating the IAM policies of the principal, so that
+// missing permissions are found without changing anything.
+func CheckPermissions(ctx context.Context, client IAMAPI, principalARN string, permissions []Permission, report *HealthReport) {
+	for _, permission := range permissions {
+		name := fmt.Sprintf("Permission %s on %s", permission.Action, permission.Resource)
+		output, err := client.SimulatePrincipalPolicy(ctx, &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(principalARN),
+			ActionNames:     []string{permission.Action},
+			ResourceArns:    []string{permission.Resource},
+		})
+		if err != nil {
+			report.Check(name, fmt.Errorf("failed to simulate the policies of %s: %w", principalARN, err))
+			continue
+		}
+		for _, result := range output.EvaluationResults {
+			if result.EvalDecision != iamTypes.PolicyEvaluationDecisionTypeAllowed {
+				err = fmt.Errorf("%s is %s for %s", permission.Action, result.EvalDecision, principalARN)
+			}
+		}
+		report.Check(name, err)
+	}
+}
+
+// PrincipalARN returns the ARN of the IAM role of an assumed-role caller ARN, e.g. of a Lambda execution
+// role, as IAM simulates the policies of roles rather than of their sessions. Other ARNs are returned as is.
+// Roles with a path are not supported.
+func PrincipalARN(callerARN string) string {
+	// arn:aws:sts::<account>:assumed-role/<role>/<session>
+	parts := strings.SplitN(callerARN, ":", 6)
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return callerARN
+	}
+	role := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], role)
+}
@@ -0,0 +1,63 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/smithy-go"
+)
+
+// maxIdentifierAttempts is how many identifiers the creation of a replica tries before it fails.
+const maxIdentifierAttempts = 3
+
+// replicaIdentifier returns the identifier of a new replica of the cluster, e.g. "<cluster>-reader-<suffix>",
+// complying with the DB instance identifier constraints.
+func (d *DocumentDB) replicaIdentifier(role, suffix string) string {
+	identifier := fmt.Sprintf("%s-%s-%s", d.ClusterID, role, suffix)
+	// Ensure the identifier is no more than 63 characters
+	if len(identifier) > 63 {
+		identifier = identifier[:63]
+		// Ensure it doesn't end with a hyphen
+		identifier = strings.TrimRight(identifier, "-")
+	}
+	// Ensure identifier starts with a letter and contains only allowed characters
+	return sanitizeDBInstanceIdentifier(identifier)
+}
+
+// timestampSuffix returns the last 9 digits of the current time in nanoseconds, unique enough and short.
+func timestampSuffix() string {
+	timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
+	return timestamp[len(timestamp)-9:]
+}
+
+// randomSuffix returns 9 random digits, for an identifier whose timestamp suffix was already taken.
+func randomSuffix() string {
+	return fmt.Sprintf("%09d", rand.Intn(1_000_000_000))
+}
+
+// isAlreadyExists reports whether err is the DBInstanceAlreadyExists error of the DocumentDB or RDS API.
+func isAlreadyExists(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "DBInstanceAlreadyExists"
+}
+
+// createReplica creates the DB instance of input. When its identifier is already taken, e.g. by another
+// invocation in the same nanosecond window or by a retried create, it retries with a fresh random suffix,
+// which it sets in input.
+func (d *DocumentDB) createReplica(ctx context.Context, input *docdb.CreateDBInstanceInput, role string) (*docdb.CreateDBInstanceOutput, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := d.DocDBClient.CreateDBInstance(ctx, input)
+		if err == nil || !isAlreadyExists(err) || attempt == maxIdentifierAttempts {
+			return result, err
+		}
+		taken := aws.ToString(input.DBInstanceIdentifier)
+		input.DBInstanceIdentifier = aws.String(d.replicaIdentifier(role, randomSuffix()))
+		d.Logger.Warn("Replica identifier already exists, retrying with a new one", "InstanceID", taken, "NewInstanceID", aws.ToString(input.DBInstanceIdentifier), "ClusterID", d.ClusterID)
+	}
+}
@@ -0,0 +1,46 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// ErrInstanceTypeNotOrderable is returned when InstanceType is not offered for the engine of the cluster in its region.
+var ErrInstanceTypeNotOrderable = errors.New("instance type is not orderable")
+
+// validateInstanceType checks, on the first replica creation, that InstanceType is offered for the engine and
+// engine version of the cluster in its region, so that a misconfigured class fails the action with a
+// configuration error and a failure notification, rather than every CreateDBInstance after the fact.
+// Failing to read the orderable options, e.g. without the IAM permission, is logged only.
+func (d *DocumentDB) validateInstanceType(ctx context.Context) error {
+	if d.InstanceType == "" || d.validatedInstanceType == d.InstanceType {
+		return nil
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	output, err := d.RDSClient.DescribeOrderableDBInstanceOptions(ctx, &rds.DescribeOrderableDBInstanceOptionsInput{
+		Engine:          aws.String(d.engine().Name),
+		EngineVersion:   dbCluster.EngineVersion,
+		DBInstanceClass: aws.String(d.InstanceType),
+	})
+	if err != nil {
+		d.Logger.Warn("Failed to check that INSTANCE_TYPE is orderable", "Error", err, "InstanceType", d.InstanceType)
+		return nil
+	}
+	if len(output.OrderableDBInstanceOptions) == 0 {
+		err := fmt.Errorf("%w: INSTANCE_TYPE %s is not offered for engine %s version %s in the region of cluster %s", ErrInstanceTypeNotOrderable, d.InstanceType, d.engine().Name, aws.ToString(dbCluster.EngineVersion), d.ClusterID)
+		d.Logger.Error("Invalid INSTANCE_TYPE", "Error", err)
+		if notifyErr := d.Notifier.SendFailureNotification(ctx, d.ClusterID, err.Error(), "validate configuration"); notifyErr != nil {
+			d.Logger.Error("Failed to send failure notification", "Error", notifyErr)
+		}
+		return err
+	}
+	d.validatedInstanceType = d.InstanceType
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/docdbelastic"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 )
 
 // DocDBAPI defines the interface for Amazon DocumentDB interactions.
@@ -15,14 +17,46 @@ type DocDBAPI interface {
 	DeleteDBInstance(ctx context.Context, params *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error)
 	ListTagsForResource(ctx context.Context, params *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error)
 	AddTagsToResource(ctx context.Context, params *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error)
+	RemoveTagsFromResource(ctx context.Context, params *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error)
+	DescribeGlobalClusters(ctx context.Context, params *docdb.DescribeGlobalClustersInput, optFns ...func(*docdb.Options)) (*docdb.DescribeGlobalClustersOutput, error)
 }
 
 // CloudWatchAPI defines the interface for Amazon CloudWatch interactions.
 type CloudWatchAPI interface {
 	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+	DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error)
 }
 
 // RDSAPI defines the interface for Amazon RDS interactions (used for DocumentDB cluster operations).
 type RDSAPI interface {
 	DescribeDBClusters(ctx context.Context, params *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error)
+	DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error)
+	DescribeAccountAttributes(ctx context.Context, params *rds.DescribeAccountAttributesInput, optFns ...func(*rds.Options)) (*rds.DescribeAccountAttributesOutput, error)
+	DescribeOrderableDBInstanceOptions(ctx context.Context, params *rds.DescribeOrderableDBInstanceOptionsInput, optFns ...func(*rds.Options)) (*rds.DescribeOrderableDBInstanceOptionsOutput, error)
+}
+
+// DocDBElasticAPI defines the interface for Amazon DocumentDB elastic cluster interactions.
+type DocDBElasticAPI interface {
+	ListClusters(ctx context.Context, params *docdbelastic.ListClustersInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.ListClustersOutput, error)
+	GetCluster(ctx context.Context, params *docdbelastic.GetClusterInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.GetClusterOutput, error)
+	UpdateCluster(ctx context.Context, params *docdbelastic.UpdateClusterInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.UpdateClusterOutput, error)
+	ListTagsForResource(ctx context.Context, params *docdbelastic.ListTagsForResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.ListTagsForResourceOutput, error)
+	TagResource(ctx context.Context, params *docdbelastic.TagResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.TagResourceOutput, error)
+	UntagResource(ctx context.Context, params *docdbelastic.UntagResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.UntagResourceOutput, error)
+}
+
+// RDSInstanceAPI defines the interface for Amazon RDS instance interactions, used for Aurora clusters.
+type RDSInstanceAPI interface {
+	DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error)
+	CreateDBInstance(ctx context.Context, params *rds.CreateDBInstanceInput, optFns ...func(*rds.Options)) (*rds.CreateDBInstanceOutput, error)
+	DeleteDBInstance(ctx context.Context, params *rds.DeleteDBInstanceInput, optFns ...func(*rds.Options)) (*rds.DeleteDBInstanceOutput, error)
+	ListTagsForResource(ctx context.Context, params *rds.ListTagsForResourceInput, optFns ...func(*rds.Options)) (*rds.ListTagsForResourceOutput, error)
+	AddTagsToResource(ctx context.Context, params *rds.AddTagsToResourceInput, optFns ...func(*rds.Options)) (*rds.AddTagsToResourceOutput, error)
+	RemoveTagsFromResource(ctx context.Context, params *rds.RemoveTagsFromResourceInput, optFns ...func(*rds.Options)) (*rds.RemoveTagsFromResourceOutput, error)
+	DescribeGlobalClusters(ctx context.Context, params *rds.DescribeGlobalClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeGlobalClustersOutput, error)
+}
+
+// SSMAPI defines the interface for AWS Systems Manager Parameter Store interactions.
+type SSMAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
 }
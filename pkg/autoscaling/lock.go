@@ -0,0 +1,40 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+	"github.com/cheelim1/docdb-autoscaler/pkg/lock"
+)
+
+// acquireLock takes the lock of the cluster for the current scaling action, when configured, and reports
+// whether the action must be skipped because another invocation holds it. Dry-run actions change nothing,
+// so they take no lock. The returned function releases the lock; failing to release it is logged only,
+// as the lease expires anyway.
+func (d *DocumentDB) acquireLock(ctx context.Context) (func(), bool, error) {
+	if d.Lock == nil || d.DryRun {
+		return func() {}, false, nil
+	}
+	key := "cluster/" + d.ClusterID
+	owner := correlation.FromContext(ctx)
+	if owner == "" {
+		owner = correlation.NewID()
+	}
+	err := d.Lock.Acquire(ctx, key, owner)
+	if errors.Is(err, lock.ErrLocked) {
+		d.Logger.Warn("Another invocation is scaling the cluster, skipping scaling action", "ClusterID", d.ClusterID)
+		d.recordReason(ReasonLocked)
+		return func() {}, true, nil
+	}
+	if err != nil {
+		d.Logger.Error("Failed to acquire cluster lock", "Error", err)
+		return nil, false, err
+	}
+	return func() {
+		// The action may have been canceled, the lock must be released anyway
+		if err := d.Lock.Release(context.WithoutCancel(ctx), key, owner); err != nil {
+			d.Logger.Warn("Failed to release cluster lock", "Error", err)
+		}
+	}, false, nil
+}
@@ -0,0 +1,87 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// ErrManagedReplicaCap is returned when adding replicas would exceed ManagedReplicaCap or AccountReplicaCap.
+var ErrManagedReplicaCap = errors.New("managed replica cap reached")
+
+// managedTagKeys are the tags of the replicas created by the autoscaler and by scheduled scaling.
+var managedTagKeys = []string{"docdb-autoscaler-created", "docdb-autoscaler-scheduler"}
+
+// countAccountManagedReplicas counts the instances of the engine in the account and region of the cluster
+// created by the autoscaler or by scheduled scaling. The RDS API returns the tags of the instances in their
+// description, so no tag lookup per instance is needed.
+func (d *DocumentDB) countAccountManagedReplicas(ctx context.Context) (int, error) {
+	paginator := rds.NewDescribeDBInstancesPaginator(d.RDSClient, &rds.DescribeDBInstancesInput{
+		Filters: []rdsTypes.Filter{{Name: aws.String("engine"), Values: []string{d.engine().Name}}},
+	})
+	managed := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			d.Logger.Error("Failed to count the managed replicas of the account", "Error", err)
+			return 0, err
+		}
+		for _, instance := range page.DBInstances {
+			if slices.ContainsFunc(instance.TagList, func(tag rdsTypes.Tag) bool {
+				return slices.Contains(managedTagKeys, aws.ToString(tag.Key)) && aws.ToString(tag.Value) == "true"
+			}) {
+				managed++
+			}
+		}
+	}
+	return managed, nil
+}
+
+// capToManagedReplicas returns how many of the replicas to add the hard caps on the replicas managed by the
+// autoscaler leave room for, in the cluster and in the account, whatever MaxCapacity allows. They bound the
+// blast radius of a runaway trigger: when they leave out replicas, it also returns ErrManagedReplicaCap, so
+// that the action fails and pages once the replicas with room are added, and sends a failure notification.
+func (d *DocumentDB) capToManagedReplicas(ctx context.Context, replicasToAdd int) (int, error) {
+	if replicasToAdd <= 0 || (d.ManagedReplicaCap <= 0 && d.AccountReplicaCap <= 0) {
+		return replicasToAdd, nil
+	}
+	caps := []struct {
+		scope string
+		limit int
+		count func(ctx context.Context) (int, error)
+	}{
+		{"cluster " + d.ClusterID, d.ManagedReplicaCap, d.countManagedReplicas},
+		{"account", d.AccountReplicaCap, d.countAccountManagedReplicas},
+	}
+	room := replicasToAdd
+	var capErr error
+	for _, replicaCap := range caps {
+		if replicaCap.limit <= 0 {
+			continue
+		}
+		managed, err := replicaCap.count(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if managed+room > replicaCap.limit {
+			room = max(replicaCap.limit-managed, 0)
+			capErr = fmt.Errorf("%w: the %s has %d replicas managed by the autoscaler, at most %d are allowed", ErrManagedReplicaCap, replicaCap.scope, managed, replicaCap.limit)
+		}
+	}
+	if capErr == nil {
+		return replicasToAdd, nil
+	}
+
+	d.Logger.Error("Managed replica cap reached, refusing to add more replicas", "Error", capErr, "ReplicasToAdd", replicasToAdd, "Room", room, "ClusterID", d.ClusterID)
+	d.recordConstraint(ConstraintManagedReplicaCap)
+	d.recordPartial(replicasToAdd - room)
+	if err := d.Notifier.SendFailureNotification(ctx, d.ClusterID, capErr.Error(), "scale out"); err != nil {
+		d.Logger.Error("Failed to send failure notification", "Error", err)
+	}
+	return room, capErr
+}
@@ -0,0 +1,70 @@
+package autoscaling
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/cheelim1/docdb-autoscaler/pkg/metrics"
+)
+
+// recordMetrics records the metrics of the last scaling action, when configured. Failures are logged only.
+func (d *DocumentDB) recordMetrics(ctx context.Context, actionErr error) {
+	if d.Metrics == nil {
+		return
+	}
+	result := d.LastResult()
+	invocation := metrics.Invocation{
+		ClusterID:       d.ClusterID,
+		Decision:        result.Decision,
+		ReplicasAdded:   result.ReplicasAdded,
+		ReplicasRemoved: result.ReplicasRemoved,
+		CurrentCapacity: result.currentCapacity,
+		DesiredCapacity: result.desiredCapacity,
+		Failed:          actionErr != nil,
+		DryRun:          d.DryRun,
+		Shadow:          d.Shadow,
+	}
+	if actionErr != nil {
+		invocation.Error = actionErr.Error()
+	}
+	if !result.decidedAt.IsZero() {
+		invocation.DecisionLatency = result.decidedAt.Sub(result.startedAt)
+	}
+	if d.CountManagedReplicas {
+		if managed, err := d.countManagedReplicas(ctx); err == nil {
+			invocation.ManagedReplicas = &managed
+		} else {
+			d.Logger.Warn("Failed to count autoscaler-managed replicas for metrics", "Error", err)
+		}
+	}
+	if d.AWSErrors != nil {
+		invocation.AWSCallErrors = d.AWSErrors.Take()
+	}
+	if err := d.Metrics.RecordInvocation(ctx, invocation); err != nil {
+		d.Logger.Error("Failed to record metrics", "Error", err)
+	}
+}
+
+// countManagedReplicas counts the readers created by the autoscaler or by scheduled scaling.
+func (d *DocumentDB) countManagedReplicas(ctx context.Context) (int, error) {
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	managed := 0
+	for _, instance := range readerInstances {
+		output, err := d.DocDBClient.ListTagsForResource(ctx, &docdb.ListTagsForResourceInput{ResourceName: instance.DBInstanceArn})
+		if err != nil {
+			return 0, err
+		}
+		for _, tag := range output.TagList {
+			if slices.Contains(managedTagKeys, aws.ToString(tag.Key)) && aws.ToString(tag.Value) == "true" {
+				managed++
+				break
+			}
+		}
+	}
+	return managed, nil
+}
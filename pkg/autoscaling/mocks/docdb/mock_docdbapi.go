@@ -10,7 +10,9 @@ import (
 
 	cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	docdb "github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbelastic "github.com/aws/aws-sdk-go-v2/service/docdbelastic"
 	rds "github.com/aws/aws-sdk-go-v2/service/rds"
+	ssm "github.com/aws/aws-sdk-go-v2/service/ssm"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -117,6 +119,26 @@ func (mr *MockDocDBAPIMockRecorder) DescribeDBInstances(ctx, params interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDBInstances", reflect.TypeOf((*MockDocDBAPI)(nil).DescribeDBInstances), varargs...)
 }
 
+// DescribeGlobalClusters mocks base method.
+func (m *MockDocDBAPI) DescribeGlobalClusters(ctx context.Context, params *docdb.DescribeGlobalClustersInput, optFns ...func(*docdb.Options)) (*docdb.DescribeGlobalClustersOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeGlobalClusters", varargs...)
+	ret0, _ := ret[0].(*docdb.DescribeGlobalClustersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeGlobalClusters indicates an expected call of DescribeGlobalClusters.
+func (mr *MockDocDBAPIMockRecorder) DescribeGlobalClusters(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeGlobalClusters", reflect.TypeOf((*MockDocDBAPI)(nil).DescribeGlobalClusters), varargs...)
+}
+
 // ListTagsForResource mocks base method.
 func (m *MockDocDBAPI) ListTagsForResource(ctx context.Context, params *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +159,26 @@ func (mr *MockDocDBAPIMockRecorder) ListTagsForResource(ctx, params interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsForResource", reflect.TypeOf((*MockDocDBAPI)(nil).ListTagsForResource), varargs...)
 }
 
+// RemoveTagsFromResource mocks base method.
+func (m *MockDocDBAPI) RemoveTagsFromResource(ctx context.Context, params *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveTagsFromResource", varargs...)
+	ret0, _ := ret[0].(*docdb.RemoveTagsFromResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTagsFromResource indicates an expected call of RemoveTagsFromResource.
+func (mr *MockDocDBAPIMockRecorder) RemoveTagsFromResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTagsFromResource", reflect.TypeOf((*MockDocDBAPI)(nil).RemoveTagsFromResource), varargs...)
+}
+
 // MockCloudWatchAPI is a mock of CloudWatchAPI interface.
 type MockCloudWatchAPI struct {
 	ctrl     *gomock.Controller
@@ -160,6 +202,26 @@ func (m *MockCloudWatchAPI) EXPECT() *MockCloudWatchAPIMockRecorder {
 	return m.recorder
 }
 
+// DescribeAlarms mocks base method.
+func (m *MockCloudWatchAPI) DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeAlarms", varargs...)
+	ret0, _ := ret[0].(*cloudwatch.DescribeAlarmsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeAlarms indicates an expected call of DescribeAlarms.
+func (mr *MockCloudWatchAPIMockRecorder) DescribeAlarms(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAlarms", reflect.TypeOf((*MockCloudWatchAPI)(nil).DescribeAlarms), varargs...)
+}
+
 // GetMetricStatistics mocks base method.
 func (m *MockCloudWatchAPI) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
 	m.ctrl.T.Helper()
@@ -203,6 +265,26 @@ func (m *MockRDSAPI) EXPECT() *MockRDSAPIMockRecorder {
 	return m.recorder
 }
 
+// DescribeAccountAttributes mocks base method.
+func (m *MockRDSAPI) DescribeAccountAttributes(ctx context.Context, params *rds.DescribeAccountAttributesInput, optFns ...func(*rds.Options)) (*rds.DescribeAccountAttributesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeAccountAttributes", varargs...)
+	ret0, _ := ret[0].(*rds.DescribeAccountAttributesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeAccountAttributes indicates an expected call of DescribeAccountAttributes.
+func (mr *MockRDSAPIMockRecorder) DescribeAccountAttributes(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAccountAttributes", reflect.TypeOf((*MockRDSAPI)(nil).DescribeAccountAttributes), varargs...)
+}
+
 // DescribeDBClusters mocks base method.
 func (m *MockRDSAPI) DescribeDBClusters(ctx context.Context, params *rds.DescribeDBClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeDBClustersOutput, error) {
 	m.ctrl.T.Helper()
@@ -222,3 +304,392 @@ func (mr *MockRDSAPIMockRecorder) DescribeDBClusters(ctx, params interface{}, op
 	varargs := append([]interface{}{ctx, params}, optFns...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDBClusters", reflect.TypeOf((*MockRDSAPI)(nil).DescribeDBClusters), varargs...)
 }
+
+// DescribeDBInstances mocks base method.
+func (m *MockRDSAPI) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeDBInstances", varargs...)
+	ret0, _ := ret[0].(*rds.DescribeDBInstancesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDBInstances indicates an expected call of DescribeDBInstances.
+func (mr *MockRDSAPIMockRecorder) DescribeDBInstances(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDBInstances", reflect.TypeOf((*MockRDSAPI)(nil).DescribeDBInstances), varargs...)
+}
+
+// DescribeOrderableDBInstanceOptions mocks base method.
+func (m *MockRDSAPI) DescribeOrderableDBInstanceOptions(ctx context.Context, params *rds.DescribeOrderableDBInstanceOptionsInput, optFns ...func(*rds.Options)) (*rds.DescribeOrderableDBInstanceOptionsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeOrderableDBInstanceOptions", varargs...)
+	ret0, _ := ret[0].(*rds.DescribeOrderableDBInstanceOptionsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeOrderableDBInstanceOptions indicates an expected call of DescribeOrderableDBInstanceOptions.
+func (mr *MockRDSAPIMockRecorder) DescribeOrderableDBInstanceOptions(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeOrderableDBInstanceOptions", reflect.TypeOf((*MockRDSAPI)(nil).DescribeOrderableDBInstanceOptions), varargs...)
+}
+
+// MockDocDBElasticAPI is a mock of DocDBElasticAPI interface.
+type MockDocDBElasticAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocDBElasticAPIMockRecorder
+}
+
+// MockDocDBElasticAPIMockRecorder is the mock recorder for MockDocDBElasticAPI.
+type MockDocDBElasticAPIMockRecorder struct {
+	mock *MockDocDBElasticAPI
+}
+
+// NewMockDocDBElasticAPI creates a new mock instance.
+func NewMockDocDBElasticAPI(ctrl *gomock.Controller) *MockDocDBElasticAPI {
+	mock := &MockDocDBElasticAPI{ctrl: ctrl}
+	mock.recorder = &MockDocDBElasticAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocDBElasticAPI) EXPECT() *MockDocDBElasticAPIMockRecorder {
+	return m.recorder
+}
+
+// GetCluster mocks base method.
+func (m *MockDocDBElasticAPI) GetCluster(ctx context.Context, params *docdbelastic.GetClusterInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.GetClusterOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetCluster", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.GetClusterOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCluster indicates an expected call of GetCluster.
+func (mr *MockDocDBElasticAPIMockRecorder) GetCluster(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockDocDBElasticAPI)(nil).GetCluster), varargs...)
+}
+
+// ListClusters mocks base method.
+func (m *MockDocDBElasticAPI) ListClusters(ctx context.Context, params *docdbelastic.ListClustersInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.ListClustersOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListClusters", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.ListClustersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusters indicates an expected call of ListClusters.
+func (mr *MockDocDBElasticAPIMockRecorder) ListClusters(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusters", reflect.TypeOf((*MockDocDBElasticAPI)(nil).ListClusters), varargs...)
+}
+
+// ListTagsForResource mocks base method.
+func (m *MockDocDBElasticAPI) ListTagsForResource(ctx context.Context, params *docdbelastic.ListTagsForResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.ListTagsForResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListTagsForResource", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.ListTagsForResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsForResource indicates an expected call of ListTagsForResource.
+func (mr *MockDocDBElasticAPIMockRecorder) ListTagsForResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsForResource", reflect.TypeOf((*MockDocDBElasticAPI)(nil).ListTagsForResource), varargs...)
+}
+
+// TagResource mocks base method.
+func (m *MockDocDBElasticAPI) TagResource(ctx context.Context, params *docdbelastic.TagResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.TagResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TagResource", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.TagResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagResource indicates an expected call of TagResource.
+func (mr *MockDocDBElasticAPIMockRecorder) TagResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagResource", reflect.TypeOf((*MockDocDBElasticAPI)(nil).TagResource), varargs...)
+}
+
+// UntagResource mocks base method.
+func (m *MockDocDBElasticAPI) UntagResource(ctx context.Context, params *docdbelastic.UntagResourceInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.UntagResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UntagResource", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.UntagResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagResource indicates an expected call of UntagResource.
+func (mr *MockDocDBElasticAPIMockRecorder) UntagResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagResource", reflect.TypeOf((*MockDocDBElasticAPI)(nil).UntagResource), varargs...)
+}
+
+// UpdateCluster mocks base method.
+func (m *MockDocDBElasticAPI) UpdateCluster(ctx context.Context, params *docdbelastic.UpdateClusterInput, optFns ...func(*docdbelastic.Options)) (*docdbelastic.UpdateClusterOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateCluster", varargs...)
+	ret0, _ := ret[0].(*docdbelastic.UpdateClusterOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCluster indicates an expected call of UpdateCluster.
+func (mr *MockDocDBElasticAPIMockRecorder) UpdateCluster(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCluster", reflect.TypeOf((*MockDocDBElasticAPI)(nil).UpdateCluster), varargs...)
+}
+
+// MockRDSInstanceAPI is a mock of RDSInstanceAPI interface.
+type MockRDSInstanceAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockRDSInstanceAPIMockRecorder
+}
+
+// MockRDSInstanceAPIMockRecorder is the mock recorder for MockRDSInstanceAPI.
+type MockRDSInstanceAPIMockRecorder struct {
+	mock *MockRDSInstanceAPI
+}
+
+// NewMockRDSInstanceAPI creates a new mock instance.
+func NewMockRDSInstanceAPI(ctrl *gomock.Controller) *MockRDSInstanceAPI {
+	mock := &MockRDSInstanceAPI{ctrl: ctrl}
+	mock.recorder = &MockRDSInstanceAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRDSInstanceAPI) EXPECT() *MockRDSInstanceAPIMockRecorder {
+	return m.recorder
+}
+
+// AddTagsToResource mocks base method.
+func (m *MockRDSInstanceAPI) AddTagsToResource(ctx context.Context, params *rds.AddTagsToResourceInput, optFns ...func(*rds.Options)) (*rds.AddTagsToResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddTagsToResource", varargs...)
+	ret0, _ := ret[0].(*rds.AddTagsToResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTagsToResource indicates an expected call of AddTagsToResource.
+func (mr *MockRDSInstanceAPIMockRecorder) AddTagsToResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTagsToResource", reflect.TypeOf((*MockRDSInstanceAPI)(nil).AddTagsToResource), varargs...)
+}
+
+// CreateDBInstance mocks base method.
+func (m *MockRDSInstanceAPI) CreateDBInstance(ctx context.Context, params *rds.CreateDBInstanceInput, optFns ...func(*rds.Options)) (*rds.CreateDBInstanceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateDBInstance", varargs...)
+	ret0, _ := ret[0].(*rds.CreateDBInstanceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDBInstance indicates an expected call of CreateDBInstance.
+func (mr *MockRDSInstanceAPIMockRecorder) CreateDBInstance(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDBInstance", reflect.TypeOf((*MockRDSInstanceAPI)(nil).CreateDBInstance), varargs...)
+}
+
+// DeleteDBInstance mocks base method.
+func (m *MockRDSInstanceAPI) DeleteDBInstance(ctx context.Context, params *rds.DeleteDBInstanceInput, optFns ...func(*rds.Options)) (*rds.DeleteDBInstanceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteDBInstance", varargs...)
+	ret0, _ := ret[0].(*rds.DeleteDBInstanceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDBInstance indicates an expected call of DeleteDBInstance.
+func (mr *MockRDSInstanceAPIMockRecorder) DeleteDBInstance(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDBInstance", reflect.TypeOf((*MockRDSInstanceAPI)(nil).DeleteDBInstance), varargs...)
+}
+
+// DescribeDBInstances mocks base method.
+func (m *MockRDSInstanceAPI) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeDBInstances", varargs...)
+	ret0, _ := ret[0].(*rds.DescribeDBInstancesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDBInstances indicates an expected call of DescribeDBInstances.
+func (mr *MockRDSInstanceAPIMockRecorder) DescribeDBInstances(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDBInstances", reflect.TypeOf((*MockRDSInstanceAPI)(nil).DescribeDBInstances), varargs...)
+}
+
+// DescribeGlobalClusters mocks base method.
+func (m *MockRDSInstanceAPI) DescribeGlobalClusters(ctx context.Context, params *rds.DescribeGlobalClustersInput, optFns ...func(*rds.Options)) (*rds.DescribeGlobalClustersOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeGlobalClusters", varargs...)
+	ret0, _ := ret[0].(*rds.DescribeGlobalClustersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeGlobalClusters indicates an expected call of DescribeGlobalClusters.
+func (mr *MockRDSInstanceAPIMockRecorder) DescribeGlobalClusters(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeGlobalClusters", reflect.TypeOf((*MockRDSInstanceAPI)(nil).DescribeGlobalClusters), varargs...)
+}
+
+// ListTagsForResource mocks base method.
+func (m *MockRDSInstanceAPI) ListTagsForResource(ctx context.Context, params *rds.ListTagsForResourceInput, optFns ...func(*rds.Options)) (*rds.ListTagsForResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListTagsForResource", varargs...)
+	ret0, _ := ret[0].(*rds.ListTagsForResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsForResource indicates an expected call of ListTagsForResource.
+func (mr *MockRDSInstanceAPIMockRecorder) ListTagsForResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsForResource", reflect.TypeOf((*MockRDSInstanceAPI)(nil).ListTagsForResource), varargs...)
+}
+
+// RemoveTagsFromResource mocks base method.
+func (m *MockRDSInstanceAPI) RemoveTagsFromResource(ctx context.Context, params *rds.RemoveTagsFromResourceInput, optFns ...func(*rds.Options)) (*rds.RemoveTagsFromResourceOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveTagsFromResource", varargs...)
+	ret0, _ := ret[0].(*rds.RemoveTagsFromResourceOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTagsFromResource indicates an expected call of RemoveTagsFromResource.
+func (mr *MockRDSInstanceAPIMockRecorder) RemoveTagsFromResource(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTagsFromResource", reflect.TypeOf((*MockRDSInstanceAPI)(nil).RemoveTagsFromResource), varargs...)
+}
+
+// MockSSMAPI is a mock of SSMAPI interface.
+type MockSSMAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSSMAPIMockRecorder
+}
+
+// MockSSMAPIMockRecorder is the mock recorder for MockSSMAPI.
+type MockSSMAPIMockRecorder struct {
+	mock *MockSSMAPI
+}
+
+// NewMockSSMAPI creates a new mock instance.
+func NewMockSSMAPI(ctrl *gomock.Controller) *MockSSMAPI {
+	mock := &MockSSMAPI{ctrl: ctrl}
+	mock.recorder = &MockSSMAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSSMAPI) EXPECT() *MockSSMAPIMockRecorder {
+	return m.recorder
+}
+
+// GetParameter mocks base method.
+func (m *MockSSMAPI) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetParameter", varargs...)
+	ret0, _ := ret[0].(*ssm.GetParameterOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParameter indicates an expected call of GetParameter.
+func (mr *MockSSMAPIMockRecorder) GetParameter(ctx, params interface{}, optFns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParameter", reflect.TypeOf((*MockSSMAPI)(nil).GetParameter), varargs...)
+}
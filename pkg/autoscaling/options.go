@@ -0,0 +1,218 @@
+package autoscaling
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// Defaults of the autoscalers made by New.
+const (
+	DefaultMinCapacity = 1
+	DefaultMaxCapacity = 15 // The most replicas a DocumentDB cluster can have
+)
+
+// Option configures an autoscaler made by New.
+type Option func(*DocumentDB)
+
+// New initializes the autoscaler of a cluster with options. Unless configured otherwise, it scales between
+// DefaultMinCapacity and DefaultMaxCapacity readers with the instance type of the writer, logs to the
+// default logger and notifies no one. The AWS clients are required, see WithClients.
+func New(clusterID string, options ...Option) (*DocumentDB, error) {
+	d := newDocumentDB(clusterID, options...)
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// newDocumentDB initializes the autoscaler of a cluster with the defaults and options of New, unchecked.
+func newDocumentDB(clusterID string, options ...Option) *DocumentDB {
+	d := &DocumentDB{
+		ClusterID:   clusterID,
+		MinCapacity: DefaultMinCapacity,
+		MaxCapacity: DefaultMaxCapacity,
+		Notifier:    notifications.Composite{},
+		Logger:      slog.Default(),
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// validate checks the settings of an autoscaler made by New or NewFromConfig.
+func (d *DocumentDB) validate() error {
+	var errs []error
+	if d.ClusterID == "" {
+		errs = append(errs, errors.New("cluster ID is not set"))
+	}
+	if d.DocDBClient == nil || d.CloudWatchClient == nil || d.RDSClient == nil {
+		errs = append(errs, errors.New("the DocumentDB, CloudWatch and RDS clients are required"))
+	}
+	if d.MinCapacity < 0 {
+		errs = append(errs, fmt.Errorf("min capacity must not be negative, got %d", d.MinCapacity))
+	}
+	if d.MinCapacity > d.MaxCapacity {
+		errs = append(errs, fmt.Errorf("min capacity (%d) must not exceed max capacity (%d)", d.MinCapacity, d.MaxCapacity))
+	}
+	if !d.ScheduledScaling && d.MetricName != "" && d.TargetValue <= 0 {
+		errs = append(errs, fmt.Errorf("target value must be positive, got %v", d.TargetValue))
+	}
+	if d.ScaleInCooldown < 0 || d.ScaleOutCooldown < 0 {
+		errs = append(errs, fmt.Errorf("cooldowns must not be negative, got %d and %d", d.ScaleInCooldown, d.ScaleOutCooldown))
+	}
+	if d.Notifier == nil || d.Logger == nil {
+		errs = append(errs, errors.New("the notifier and logger must not be nil"))
+	}
+	if d.ElasticScaleDimension != "" && d.ElasticScaleDimension != ElasticShardCount && d.ElasticScaleDimension != ElasticShardCapacity {
+		errs = append(errs, fmt.Errorf("elastic scale dimension must be %s or %s, got %s", ElasticShardCount, ElasticShardCapacity, d.ElasticScaleDimension))
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"bootstrap capacity", d.BootstrapCapacity},
+		{"managed replica cap", d.ManagedReplicaCap},
+		{"account replica cap", d.AccountReplicaCap},
+		{"bulk scale-in limit", d.BulkScaleInLimit},
+		{"circuit threshold", d.CircuitThreshold},
+		{"hourly churn budget", d.ChurnBudgetHourly},
+		{"daily churn budget", d.ChurnBudgetDaily},
+		{"metric concurrency", d.MetricConcurrency},
+	} {
+		if limit.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", limit.name, limit.value))
+		}
+	}
+	for _, duration := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"canary window", d.CanaryWindow},
+		{"scale-in stabilization", d.ScaleInStabilization},
+		{"drain timeout", d.DrainTimeout},
+		{"maintenance pause", d.MaintenancePause},
+		{"circuit backoff", d.CircuitBackoff},
+		{"deadline margin", d.DeadlineMargin},
+	} {
+		if duration.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", duration.name, duration.value))
+		}
+	}
+	if d.MaxHourlyCost < 0 {
+		errs = append(errs, fmt.Errorf("max hourly cost must not be negative, got %v", d.MaxHourlyCost))
+	}
+	return errors.Join(errs...)
+}
+
+// WithClients sets the AWS clients of the cluster.
+func WithClients(docdbClient DocDBAPI, cloudwatchClient CloudWatchAPI, rdsClient RDSAPI) Option {
+	return func(d *DocumentDB) {
+		d.DocDBClient = docdbClient
+		d.CloudWatchClient = cloudwatchClient
+		d.RDSClient = rdsClient
+	}
+}
+
+// WithEngine sets the engine of the cluster, DocDBEngine by default.
+func WithEngine(engine Engine) Option {
+	return func(d *DocumentDB) {
+		d.Engine = engine
+	}
+}
+
+// WithCapacity sets the readers the autoscaler scales between.
+func WithCapacity(minCapacity, maxCapacity int) Option {
+	return func(d *DocumentDB) {
+		d.MinCapacity = minCapacity
+		d.MaxCapacity = maxCapacity
+	}
+}
+
+// WithManagedCapacity makes the capacity bound the replicas of the autoscaler only, on top of the baseline
+// readers it did not create, e.g. static readers of a reporting workload.
+func WithManagedCapacity(managedCapacity bool) Option {
+	return func(d *DocumentDB) {
+		d.ManagedCapacity = managedCapacity
+	}
+}
+
+// WithMetric scales on the average of a metric of the readers, against its target value.
+func WithMetric(metricName string, targetValue float64) Option {
+	return func(d *DocumentDB) {
+		d.MetricName = metricName
+		d.TargetValue = targetValue
+	}
+}
+
+// WithMetricTargets sets per-metric targets, e.g. for the children of composite alarms.
+func WithMetricTargets(metricTargets map[string]float64) Option {
+	return func(d *DocumentDB) {
+		d.MetricTargets = metricTargets
+	}
+}
+
+// WithCooldowns sets the scale-in and scale-out cooldowns, in seconds.
+func WithCooldowns(scaleInCooldown, scaleOutCooldown int) Option {
+	return func(d *DocumentDB) {
+		d.ScaleInCooldown = scaleInCooldown
+		d.ScaleOutCooldown = scaleOutCooldown
+	}
+}
+
+// WithInstanceType sets the instance type of new replicas, e.g. "db.r6g.large", instead of that of the
+// writer.
+func WithInstanceType(instanceType string) Option {
+	return func(d *DocumentDB) {
+		d.InstanceType = instanceType
+	}
+}
+
+// WithDryRun plans scaling actions without creating, deleting or tagging anything.
+func WithDryRun(dryRun bool) Option {
+	return func(d *DocumentDB) {
+		d.DryRun = dryRun
+	}
+}
+
+// WithScheduledScaling scales by a number of replicas on schedule instead of on a metric: positive to add
+// replicas, negative to remove them.
+func WithScheduledScaling(scheduleNumberReplicas int) Option {
+	return func(d *DocumentDB) {
+		d.ScheduledScaling = true
+		d.ScheduleNumberReplicas = scheduleNumberReplicas
+	}
+}
+
+// WithSchedulePhase sets the direction of scheduled scaling, SchedulePhaseScaleOut or SchedulePhaseScaleIn,
+// rather than inferring it from the scheduled replicas of the cluster.
+func WithSchedulePhase(phase string) Option {
+	return func(d *DocumentDB) {
+		d.SchedulePhase = phase
+	}
+}
+
+// WithAllowZeroReaders permits removals that would leave the cluster with no readers.
+func WithAllowZeroReaders(allowZeroReaders bool) Option {
+	return func(d *DocumentDB) {
+		d.AllowZeroReaders = allowZeroReaders
+	}
+}
+
+// WithNotifier sets the notifier of scaling actions.
+func WithNotifier(notifier notifications.NotifierInterface) Option {
+	return func(d *DocumentDB) {
+		d.Notifier = notifier
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(d *DocumentDB) {
+		d.Logger = logger
+	}
+}
@@ -0,0 +1,156 @@
+package autoscaling
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// orphanStatuses are the statuses an instance does not leave without intervention, e.g. after its creation failed.
+var orphanStatuses = []string{
+	"failed",
+	"inaccessible-encryption-credentials",
+	"incompatible-network",
+	"incompatible-parameters",
+	"incompatible-restore",
+}
+
+// findOrphans returns the replicas created by the autoscaler or scheduled scaling that nothing reconciles:
+// the failed ones, stuck in an orphan status, and the available autoscaler-created ones in excess of
+// MaxCapacity, e.g. left by an invocation that crashed mid-scale-out. Readers created by hand are never returned.
+func (d *DocumentDB) findOrphans(ctx context.Context, readerInstances []docdbTypes.DBInstance) (failed, excess []docdbTypes.DBInstance, err error) {
+	var removable []docdbTypes.DBInstance
+	for _, instance := range readerInstances {
+		instanceID := aws.ToString(instance.DBInstanceIdentifier)
+		created, err := d.HasAutoscalerTag(ctx, instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		status := aws.ToString(instance.DBInstanceStatus)
+		if slices.Contains(orphanStatuses, status) {
+			scheduled, err := d.HasSchedulerTag(ctx, instance)
+			if err != nil {
+				return nil, nil, err
+			}
+			if created || scheduled {
+				d.Logger.Warn("Found failed replica", "InstanceID", instanceID, "Status", status, "ClusterID", d.ClusterID)
+				failed = append(failed, instance)
+			}
+			continue
+		}
+		if created && status == "available" {
+			removable = append(removable, instance)
+		}
+	}
+
+	// The failed replicas are not serving, so they do not count towards MaxCapacity once removed
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return nil, nil, err
+	}
+	excessCount := len(readerInstances) - len(failed) - d.maxReaders()
+	for i := 0; i < excessCount && i < len(removable); i++ {
+		d.Logger.Warn("Found replica beyond MAX_CAPACITY", "InstanceID", aws.ToString(removable[i].DBInstanceIdentifier), "MaxCapacity", d.MaxCapacity, "ClusterID", d.ClusterID)
+		excess = append(excess, removable[i])
+	}
+	return failed, excess, nil
+}
+
+// removeOrphans deletes the failed replicas of the cluster, and those beyond MaxCapacity when RemoveOrphans
+// is set, and reports whether it found any. Failed replicas do not serve reads, so they are removed regardless
+// of the reader floor, and recreated when ReplaceFailedReplicas is set.
+func (d *DocumentDB) removeOrphans(ctx context.Context) (bool, error) {
+	if (!d.RemoveOrphans && !d.ReplaceFailedReplicas) || d.MaxCapacity <= 0 {
+		return false, nil
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
+		return false, err
+	}
+	failed, excess, err := d.findOrphans(ctx, readerInstances)
+	if err != nil {
+		return false, err
+	}
+	if !d.RemoveOrphans {
+		excess = nil
+	}
+	orphans := append(failed, excess...)
+	if len(orphans) == 0 {
+		return false, nil
+	}
+	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to get writer instance identifier", "Error", err)
+		return false, err
+	}
+
+	d.recordReason(ReasonOrphanCleanup)
+	d.recordDecision(DecisionScaleIn)
+	before := d.captureTopology(ctx)
+	for _, instance := range orphans {
+		instanceID := aws.ToString(instance.DBInstanceIdentifier)
+		if d.DryRun {
+			d.Logger.Info("[Dry Run] Would remove orphaned replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+			d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+			continue
+		}
+		// Guard against a failover, or a replica changed, since the topology was read
+		if err := d.verifyBeforeDelete(ctx, writerInstanceIdentifier, instance); err != nil {
+			return true, err
+		}
+		if _, err := d.DocDBClient.DeleteDBInstance(ctx, &docdb.DeleteDBInstanceInput{DBInstanceIdentifier: instance.DBInstanceIdentifier}); err != nil {
+			d.Logger.Error("Failed to delete orphaned replica", "Error", err, "InstanceID", instanceID)
+			return true, err
+		}
+		d.invalidateSnapshot()
+		d.Logger.Info("Removed orphaned replica", "ClusterID", d.ClusterID, "InstanceID", instanceID, "Status", aws.ToString(instance.DBInstanceStatus))
+		d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaDeleted, InstanceID: instanceID, InstanceClass: aws.ToString(instance.DBInstanceClass)})
+		d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+	}
+
+	if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, len(orphans)); err != nil {
+		d.Logger.Error("Failed to send scale-in notification", "Error", err)
+	}
+
+	if d.ReplaceFailedReplicas && len(failed) > 0 {
+		return true, d.replaceFailedReplicas(ctx, len(readerInstances)-len(orphans), len(failed))
+	}
+	return true, nil
+}
+
+// replaceFailedReplicas creates a replica per removed failed one, so that the capacity holds without
+// intervention. Replacements are scale-outs: they wait for the scale-out cooldown, and stop at MaxCapacity.
+func (d *DocumentDB) replaceFailedReplicas(ctx context.Context, currentCapacity, failedCount int) error {
+	replacements := min(failedCount, d.maxReaders()-currentCapacity)
+	if replacements < failedCount {
+		d.recordConstraint(ConstraintMaxCapacity)
+	}
+	if replacements <= 0 {
+		return nil
+	}
+	cooling, err := d.inCooldown(ctx, DecisionScaleOut)
+	if err != nil {
+		return err
+	}
+	if cooling {
+		d.recordConstraint(ConstraintCooldown)
+		return nil
+	}
+
+	d.Logger.Info("Replacing failed replicas", "Replacements", replacements, "ClusterID", d.ClusterID)
+	d.recordDecision(DecisionReplace)
+	before := d.captureTopology(ctx)
+	if err := d.AddReplicas(ctx, replacements); err != nil {
+		d.Logger.Error("Failed to replace failed replicas", "Error", err, "Replacements", replacements)
+		return err
+	}
+	d.recordLastAction(ctx, DecisionScaleOut)
+	if err := d.notifierWithTopology(ctx, before).SendScaleOutNotification(ctx, d.ClusterID, replacements); err != nil {
+		d.Logger.Error("Failed to send scale-out notification", "Error", err)
+	}
+	return nil
+}
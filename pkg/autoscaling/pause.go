@@ -0,0 +1,62 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// pausedByParameter reports whether the SSM parameter PauseParameter is set to "true". A missing parameter
+// pauses nothing, while the error of failing to read it is returned, as the switch may be on.
+func (d *DocumentDB) pausedByParameter(ctx context.Context) (bool, error) {
+	if d.PauseParameter == "" || d.SSMClient == nil {
+		return false, nil
+	}
+	output, err := d.SSMClient.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(d.PauseParameter)})
+	var notFound *ssmTypes.ParameterNotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return output.Parameter != nil && strings.EqualFold(aws.ToString(output.Parameter.Value), "true"), nil
+}
+
+// pauseSwitch returns the emergency switch pausing the cluster, given whether the cluster carries the paused
+// tag, or "" when no switch is on. Set to "true", e.g. by on-call during an incident, the paused tag of a
+// cluster or the SSM parameter PauseParameter of all the clusters of the autoscaler switch it on. A parameter
+// that cannot be read counts as on, so that a switch turned on during an SSM outage is not missed.
+func (d *DocumentDB) pauseSwitch(ctx context.Context, tagged bool) string {
+	paused, err := d.pausedByParameter(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to read the pause parameter, pausing autoscaling", "Error", err, "Parameter", d.PauseParameter)
+		return "SSM parameter " + d.PauseParameter + ", which could not be read"
+	}
+	if paused {
+		return "SSM parameter " + d.PauseParameter
+	}
+	if tagged {
+		return "tag " + pausedTagKey
+	}
+	return ""
+}
+
+// skipForPauseSwitch records the current action as paused by the emergency switch, and sends a warning
+// notification on every invocation it skips, so that a switch left on does not go unnoticed. Failing to
+// notify is logged only.
+func (d *DocumentDB) skipForPauseSwitch(ctx context.Context, pauseSwitch string) {
+	d.Logger.Warn("Autoscaling is paused by the emergency switch, skipping scaling action", "Switch", pauseSwitch, "ClusterID", d.ClusterID)
+	d.recordDecision(DecisionPaused)
+	d.recordReason(ReasonPaused)
+	message := fmt.Sprintf("Autoscaling is paused by the %s, no scaling action was taken", pauseSwitch)
+	if err := notifications.SendWarning(ctx, d.Notifier, d.ClusterID, message, "scale"); err != nil {
+		d.Logger.Error("Failed to send paused notification", "Error", err)
+	}
+}
@@ -0,0 +1,58 @@
+package autoscaling
+
+import (
+	"context"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// archivePlan archives the plan of the last scaling action in dry-run, when configured, with the inputs,
+// metric and capacity it was decided on and the instances it would have created or removed.
+// Failures are logged only.
+func (d *DocumentDB) archivePlan(ctx context.Context, actionErr error) {
+	if d.Plans == nil || !d.DryRun {
+		return
+	}
+	result := d.LastResult()
+	plan := audit.Plan{
+		Time:          time.Now().UTC(),
+		ClusterID:     d.ClusterID,
+		CorrelationID: correlation.FromContext(ctx),
+		Inputs: audit.PlanInputs{
+			MinCapacity:            d.MinCapacity,
+			MaxCapacity:            d.MaxCapacity,
+			MetricName:             d.MetricName,
+			TargetValue:            d.TargetValue,
+			MetricTargets:          d.MetricTargets,
+			ScaleInCooldown:        d.ScaleInCooldown,
+			ScaleOutCooldown:       d.ScaleOutCooldown,
+			ScaleInStabilization:   int(d.ScaleInStabilization / time.Second),
+			InstanceType:           d.InstanceType,
+			ScheduledScaling:       d.ScheduledScaling,
+			ScheduleNumberReplicas: d.ScheduleNumberReplicas,
+			SchedulePhase:          d.SchedulePhase,
+		},
+		MetricName:        result.metricName,
+		MetricValue:       result.metricValue,
+		TargetValue:       result.targetValue,
+		CurrentCapacity:   result.currentCapacity,
+		DesiredCapacity:   result.desiredCapacity,
+		Decision:          result.Decision,
+		InstancesToCreate: result.AddedInstanceIDs,
+		InstancesToRemove: result.RemovedInstanceIDs,
+		ReasonCodes:       result.ReasonCodes,
+		Constraints:       result.Constraints,
+		CostDelta:         result.CostDelta,
+	}
+	if d.TriggerAlarm != nil {
+		plan.Inputs.TriggerAlarm = d.TriggerAlarm.AlarmName
+	}
+	if actionErr != nil {
+		plan.Error = actionErr.Error()
+	}
+	if err := d.Plans.SavePlan(ctx, plan); err != nil {
+		d.Logger.Error("Failed to archive dry-run plan", "Error", err)
+	}
+}
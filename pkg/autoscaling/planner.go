@@ -0,0 +1,387 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoPlanTarget is returned when the cluster has no metric target to plan against, e.g. with scheduled
+// scaling.
+var ErrNoPlanTarget = errors.New("scaling plans need METRIC_NAME and TARGET_VALUE")
+
+// ErrStalePlan is returned when a plan is applied to a cluster whose readers changed since it was made.
+var ErrStalePlan = errors.New("the readers of the cluster changed since the plan was made")
+
+// Observation is the state of the cluster a metric-based scaling plan is decided on, as read by the
+// autoscaler, so that plans are made without AWS calls.
+type Observation struct {
+	CurrentCapacity  int
+	DesiredCapacity  int     // Readers the trigger calls for, within MinCapacity and MaxCapacity
+	BaselineReaders  int     // Readers the autoscaler did not create, with ManagedCapacity
+	MetricName       string  // Metric the desired capacity was calculated from, if any
+	MetricValue      float64 // Average of the readers, when MetricName is set
+	TargetValue      float64 // Target of MetricName, when set
+	ScaleOutCooldown bool    // A scale-out is in its cooldown
+	ScaleInCooldown  bool    // A scale-in is in its cooldown
+	Stabilizing      bool    // A scale-in is within the stabilization window of the last scale-out
+	CanaryPending    bool    // The canary replica of the last scale-out is not verified yet
+	CanaryVerified   bool    // The canary replica of the last scale-out was just verified
+
+	canary      canaryReplica
+	canaryState canaryState
+}
+
+// ScalingPlan is the scaling action planned for a cluster: the replicas to add or remove, and the reasons
+// and constraints that decided them. Metric-based plans are made by PlanScaling and carried out by Apply,
+// so that they can be shown, approved or tested on their own; scheduled ones are made by
+// PlanScheduledScaling.
+type ScalingPlan struct {
+	ClusterID        string   `json:"ClusterID"`
+	Decision         string   `json:"Decision"` // ScaleOut, ScaleIn or NoAction
+	CurrentCapacity  int      `json:"CurrentCapacity"`
+	DesiredCapacity  int      `json:"DesiredCapacity"`
+	BaselineReaders  int      `json:"BaselineReaders"` // Readers the autoscaler did not create, with ManagedCapacity; the bounds apply on top of them
+	ReplicasToAdd    int      `json:"ReplicasToAdd"`
+	ReplicasToRemove int      `json:"ReplicasToRemove"`
+	ReplicasDeferred int      `json:"ReplicasDeferred"` // Replicas of a canary scale-out left until the canary is verified
+	Canary           bool     `json:"Canary"`           // The replica to add is the canary of the scale-out
+	MetricName       string   `json:"MetricName,omitempty"`
+	MetricValue      *float64 `json:"MetricValue,omitempty"`
+	TargetValue      *float64 `json:"TargetValue,omitempty"`
+	ReasonCodes      []string `json:"ReasonCodes"`
+	Constraints      []string `json:"Constraints"` // Constraints that changed the outcome, e.g. Cooldown
+	Explanation      string   `json:"Explanation"` // Human-readable, e.g. "CPUUtilization=82.3 > target=70 → +2; clamped by MaxCapacity"
+
+	canary      canaryReplica
+	canaryState canaryState
+}
+
+// PlanScaling plans the metric-based action of an observation: a scale-out to the desired capacity, or a
+// single canary replica first with CanaryScaleOut, or a scale-in by a single replica, unless a cooldown, the
+// canary of the last scale-out or the stabilization window after it holds it back. Nothing is read or changed.
+func (d *DocumentDB) PlanScaling(observation Observation) *ScalingPlan {
+	currentCapacity, desiredCapacity := observation.CurrentCapacity, observation.DesiredCapacity
+	plan := &ScalingPlan{
+		ClusterID:       d.ClusterID,
+		Decision:        DecisionNoAction,
+		CurrentCapacity: currentCapacity,
+		DesiredCapacity: desiredCapacity,
+		BaselineReaders: observation.BaselineReaders,
+		MetricName:      observation.MetricName,
+		ReasonCodes:     []string{},
+		Constraints:     []string{},
+		canary:          observation.canary,
+		canaryState:     observation.canaryState,
+	}
+	if observation.MetricName != "" {
+		plan.MetricValue = &observation.MetricValue
+		plan.TargetValue = &observation.TargetValue
+		plan.ReasonCodes = append(plan.ReasonCodes, metricReason(observation.MetricValue, observation.TargetValue))
+		if d.WriterOnlyBootstrap && currentCapacity == 0 {
+			plan.ReasonCodes = append(plan.ReasonCodes, ReasonWriterBootstrap)
+		}
+		if constraint := boundsConstraint(d.requestedCapacity(observation.MetricValue, currentCapacity, observation.TargetValue), desiredCapacity); constraint != "" {
+			plan.Constraints = append(plan.Constraints, constraint)
+		}
+	}
+
+	switch {
+	case desiredCapacity > currentCapacity && observation.CanaryPending:
+		plan.Constraints = append(plan.Constraints, ConstraintCanary)
+	// The scale-out cooldown started by a verified canary does not hold back the rest of its scale-out
+	case desiredCapacity > currentCapacity && observation.ScaleOutCooldown && !observation.CanaryVerified,
+		desiredCapacity < currentCapacity && observation.ScaleInCooldown:
+		plan.Constraints = append(plan.Constraints, ConstraintCooldown)
+	case desiredCapacity < currentCapacity && observation.Stabilizing:
+		plan.Constraints = append(plan.Constraints, ConstraintStabilization)
+	case desiredCapacity > currentCapacity:
+		plan.Decision = DecisionScaleOut
+		plan.ReplicasToAdd = desiredCapacity - currentCapacity
+		if d.CanaryScaleOut && !observation.CanaryVerified && plan.ReplicasToAdd > 1 {
+			plan.Canary = true
+			plan.ReplicasDeferred = plan.ReplicasToAdd - 1
+			plan.ReplicasToAdd = 1
+			plan.Constraints = append(plan.Constraints, ConstraintCanary)
+		}
+	case desiredCapacity < currentCapacity:
+		plan.Decision = DecisionScaleIn
+		plan.ReplicasToRemove = 1 // Only remove one replica at a time
+		if currentCapacity-desiredCapacity > plan.ReplicasToRemove {
+			plan.Constraints = append(plan.Constraints, ConstraintSingleScaleIn)
+		}
+	}
+	plan.Explanation = plan.explain()
+	return plan
+}
+
+// explain returns the explanation of the plan.
+func (p *ScalingPlan) explain() string {
+	return explain(p.MetricName, p.MetricValue, p.TargetValue, p.ReasonCodes, p.Decision, p.ReplicasToAdd, p.ReplicasToRemove, p.Constraints)
+}
+
+// Phases of scheduled scaling, taken by the triggering event rather than inferred from the scheduled replicas.
+const (
+	SchedulePhaseScaleOut = "ScaleOut"
+	SchedulePhaseScaleIn  = "ScaleIn"
+)
+
+// PlanScheduledScaling plans the scheduled action of a cluster with readers, scheduledReplicas of them
+// added by the last scheduled scale-out. Without scheduled replicas, the schedule scales out by
+// ScheduleNumberReplicas, within MinCapacity and MaxCapacity; with them, it scales in by removing them,
+// but those the readers left need to stay at MinCapacity, and at one reader unless AllowZeroReaders is set.
+// SchedulePhase takes the direction instead when set: a scale-out phase holds ScheduleNumberReplicas
+// scheduled replicas, adding those missing and removing those beyond it, e.g. left by an overlapping
+// schedule of more replicas that ended, and a scale-in phase removes those left, so that either phase may
+// be retried after a partial failure. The bounds apply on top of the baseline readers with ManagedCapacity. Scheduled
+// actions never remove readers other than the scheduled replicas, nor add replicas on scale-in. Nothing is
+// read or changed.
+func (d *DocumentDB) PlanScheduledScaling(readers, scheduledReplicas int) *ScalingPlan {
+	plan := &ScalingPlan{
+		ClusterID:       d.ClusterID,
+		Decision:        DecisionNoAction,
+		CurrentCapacity: readers,
+		DesiredCapacity: readers,
+		BaselineReaders: d.baselineReaders,
+		ReasonCodes:     []string{ReasonSchedule},
+		Constraints:     []string{},
+	}
+
+	scaleIn, surplus := scheduledReplicas > 0, scheduledReplicas
+	switch d.SchedulePhase {
+	case SchedulePhaseScaleOut:
+		surplus = scheduledReplicas - max(d.ScheduleNumberReplicas, 0)
+		scaleIn = surplus > 0
+	case SchedulePhaseScaleIn:
+		scaleIn = true
+	}
+
+	if scaleIn {
+		floor := d.minReaders()
+		if !d.AllowZeroReaders {
+			floor = max(floor, 1)
+		}
+		plan.ReplicasToRemove = max(min(surplus, readers-floor), 0)
+		if plan.ReplicasToRemove < surplus {
+			plan.Constraints = append(plan.Constraints, ConstraintMinCapacity)
+		}
+		if plan.ReplicasToRemove > 0 {
+			plan.Decision = DecisionScaleIn
+			plan.DesiredCapacity = readers - plan.ReplicasToRemove
+		}
+		plan.Explanation = plan.explain()
+		return plan
+	}
+
+	// Scheduled replicas are only left on scale-out in a scale-out phase, by a scale-out that partially failed
+	requestedCapacity := readers + d.ScheduleNumberReplicas - scheduledReplicas
+	desiredCapacity := d.clampCapacity(requestedCapacity)
+	if constraint := boundsConstraint(requestedCapacity, desiredCapacity); constraint != "" {
+		plan.Constraints = append(plan.Constraints, constraint)
+	}
+	if desiredCapacity > readers {
+		plan.Decision = DecisionScaleOut
+		plan.DesiredCapacity = desiredCapacity
+		plan.ReplicasToAdd = desiredCapacity - readers
+	}
+	plan.Explanation = plan.explain()
+	return plan
+}
+
+// Plan plans the metric-based action of the cluster for MetricName, as the next invocation would take it,
+// without changing the cluster.
+func (d *DocumentDB) Plan(ctx context.Context) (*ScalingPlan, error) {
+	if d.ScheduledScaling || d.MetricName == "" || d.TargetValue <= 0 {
+		return nil, ErrNoPlanTarget
+	}
+	d.beginSnapshot()
+	defer d.endSnapshot()
+	observation, err := d.observeMetric(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PlanScaling(observation), nil
+}
+
+// Apply carries out a plan, e.g. once approved, unless the cluster is paused or its readers changed since
+// the plan was made. The result is returned even when the action failed, with what was done.
+func (d *DocumentDB) Apply(ctx context.Context, plan *ScalingPlan) (*ScalingResult, error) {
+	err := d.apply(ctx, plan)
+	return d.LastResult(), err
+}
+
+// apply carries out a plan for Apply.
+func (d *DocumentDB) apply(ctx context.Context, plan *ScalingPlan) error {
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.beginSnapshot()
+	defer d.endSnapshot()
+
+	release, locked, err := d.acquireLock(ctx)
+	if err != nil || locked {
+		return err
+	}
+	defer release()
+
+	if paused, err := d.skipIfPaused(ctx); err != nil || paused {
+		return err
+	}
+	if open, err := d.skipIfCircuitOpen(ctx); err != nil || open {
+		return err
+	}
+	if exhausted, err := d.skipIfChurnExceeded(ctx); err != nil || exhausted {
+		return err
+	}
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return err
+	}
+	if currentCapacity != plan.CurrentCapacity {
+		d.Logger.Warn("Readers changed since the plan was made, not applying it", "PlannedCapacity", plan.CurrentCapacity, "CurrentCapacity", currentCapacity, "ClusterID", d.ClusterID)
+		return fmt.Errorf("%w: %d readers planned, %d now", ErrStalePlan, plan.CurrentCapacity, currentCapacity)
+	}
+	return d.executePlan(ctx, plan)
+}
+
+// observeMetric reads the metric and the readers of the cluster, and observes the cluster for the desired
+// capacity the metric calls for.
+func (d *DocumentDB) observeMetric(ctx context.Context) (Observation, error) {
+	// Step 1: Retrieve current metric value
+	currentMetricValue, err := d.GetCurrentMetricValue(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current metric value", "Error", err)
+		return Observation{}, err
+	}
+	d.Logger.Info("Retrieved current metric value", "MetricValue", currentMetricValue)
+
+	// Step 2: Retrieve current capacity
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return Observation{}, err
+	}
+	d.Logger.Info("Retrieved current capacity", "CurrentCapacity", currentCapacity)
+
+	// Step 3: Calculate desired capacity
+	desiredCapacity := d.CalculateDesiredCapacity(currentMetricValue, currentCapacity)
+	d.Logger.Info("Calculated desired capacity", "DesiredCapacity", desiredCapacity)
+	return d.observe(ctx, Observation{
+		CurrentCapacity: currentCapacity,
+		DesiredCapacity: desiredCapacity,
+		BaselineReaders: d.baselineReaders,
+		MetricName:      d.MetricName,
+		MetricValue:     currentMetricValue,
+		TargetValue:     d.TargetValue,
+	})
+}
+
+// observe completes an observation with the canary of the last scale-out, and the cooldown and stabilization
+// window of the action the desired capacity calls for, without changing the cluster.
+func (d *DocumentDB) observe(ctx context.Context, observation Observation) (Observation, error) {
+	canary, state, err := d.readCanary(ctx)
+	if err != nil {
+		return Observation{}, err
+	}
+	observation.canary, observation.canaryState = canary, state
+	observation.CanaryPending = state.holds()
+	observation.CanaryVerified = state == canaryVerified
+
+	switch {
+	case observation.DesiredCapacity > observation.CurrentCapacity && !observation.CanaryPending && !observation.CanaryVerified:
+		observation.ScaleOutCooldown, err = d.inCooldown(ctx, DecisionScaleOut)
+	case observation.DesiredCapacity < observation.CurrentCapacity:
+		observation.ScaleInCooldown, err = d.inCooldown(ctx, DecisionScaleIn)
+		if err == nil && !observation.ScaleInCooldown {
+			observation.Stabilizing, err = d.inStabilization(ctx)
+		}
+	}
+	if err != nil {
+		return Observation{}, err
+	}
+	return observation, nil
+}
+
+// applyDesiredCapacity scales out to desiredCapacity, or scales in by a single replica, as planned by
+// PlanScaling for the cluster as it is.
+func (d *DocumentDB) applyDesiredCapacity(ctx context.Context, currentCapacity, desiredCapacity int) error {
+	return d.applyObservation(ctx, Observation{CurrentCapacity: currentCapacity, DesiredCapacity: desiredCapacity})
+}
+
+// applyObservation observes the cluster for the desired capacity of the trigger, and carries out the plan.
+func (d *DocumentDB) applyObservation(ctx context.Context, observation Observation) error {
+	observation, err := d.observe(ctx, observation)
+	if err != nil {
+		return err
+	}
+	return d.executePlan(ctx, d.PlanScaling(observation))
+}
+
+// executePlan carries out a plan, recording it in the result of the current action: the replicas are added
+// or removed, the desired capacity, cooldowns and canary are kept in the cluster tags, and the action is
+// notified.
+func (d *DocumentDB) executePlan(ctx context.Context, plan *ScalingPlan) error {
+	if plan.MetricName != "" && plan.MetricValue != nil && plan.TargetValue != nil {
+		d.recordMetric(plan.MetricName, *plan.MetricValue, *plan.TargetValue)
+	}
+	for _, reason := range plan.ReasonCodes {
+		d.recordReason(reason)
+	}
+	d.recordCapacity(plan.CurrentCapacity, plan.DesiredCapacity)
+	for _, constraint := range plan.Constraints {
+		d.recordConstraint(constraint)
+	}
+	d.settleCanary(ctx, plan.canary, plan.canaryState)
+
+	switch plan.Decision {
+	case DecisionScaleOut:
+		planned := plan.ReplicasToAdd + plan.ReplicasDeferred
+		d.Logger.Info("Scaling Out", "ReplicasToAdd", planned, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleOut)
+		if plan.Canary {
+			d.Logger.Info("Adding a canary replica first", "ReplicasToAdd", planned, "CanaryWindow", d.canaryWindow(), "ClusterID", d.ClusterID)
+			d.recordPartial(plan.ReplicasDeferred)
+		}
+		d.saveDesiredCapacity(ctx, plan.CurrentCapacity+plan.ReplicasToAdd)
+		before := d.captureTopology(ctx)
+
+		if err := d.AddReplicas(ctx, plan.ReplicasToAdd); err != nil {
+			d.Logger.Error("Failed to add replicas", "Error", err, "ReplicasToAdd", plan.ReplicasToAdd)
+			return err
+		}
+		if plan.Canary && len(d.lastResult.PendingInstanceIDs) > 0 {
+			d.saveCanary(ctx, canaryReplica{instanceID: d.lastResult.PendingInstanceIDs[len(d.lastResult.PendingInstanceIDs)-1]})
+		}
+		d.recordLastAction(ctx, DecisionScaleOut)
+		// Send scale-out notification with the number actually added
+		if err := d.notifierWithTopology(ctx, before).SendScaleOutNotification(ctx, d.ClusterID, d.replicasDone(planned)); err != nil {
+			d.Logger.Error("Failed to send scale-out notification", "Error", err)
+		}
+
+	case DecisionScaleIn:
+		d.Logger.Info("Scaling In", "ReplicasToRemove", plan.ReplicasToRemove, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionScaleIn)
+		d.saveDesiredCapacity(ctx, plan.CurrentCapacity-plan.ReplicasToRemove)
+		before := d.captureTopology(ctx)
+
+		if err := d.RemoveReplicas(ctx, plan.ReplicasToRemove); err != nil {
+			d.Logger.Error("Failed to remove replicas", "Error", err, "ReplicasToRemove", plan.ReplicasToRemove)
+			return err
+		}
+		// Send scale-in notification with the number actually removed, as replicas may have been kept, e.g. undrained
+		if removed := d.lastResult.ReplicasRemoved; removed > 0 {
+			d.recordLastAction(ctx, DecisionScaleIn)
+			if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, removed); err != nil {
+				d.Logger.Error("Failed to send scale-in notification", "Error", err)
+			}
+		}
+
+	default:
+		if plan.canaryState == canaryVerified && plan.DesiredCapacity <= plan.CurrentCapacity {
+			d.Logger.Info("The metric no longer calls for the rest of the scale-out after the canary replica", "DesiredCapacity", plan.DesiredCapacity, "CurrentCapacity", plan.CurrentCapacity, "ClusterID", d.ClusterID)
+		}
+		if plan.DesiredCapacity == plan.CurrentCapacity {
+			d.Logger.Info("No scaling action needed", "DesiredCapacity", plan.DesiredCapacity, "CurrentCapacity", plan.CurrentCapacity, "ClusterID", d.ClusterID)
+		}
+	}
+	return nil
+}
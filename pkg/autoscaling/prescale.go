@@ -0,0 +1,347 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// prescaleTagKey is the instance tag holding the RFC 3339 expiry of a prescaled replica, so that the
+// replica is removed once it passed without a state table. nextPrescaleTagKey is the cluster tag holding the
+// earliest expiry of the prescaled replicas of the cluster, so that the tags of the readers are only listed
+// once one is due.
+const (
+	prescaleTagKey     = "docdb-autoscaler:prescale-expires"
+	nextPrescaleTagKey = "docdb-autoscaler:next-prescale-expiry"
+)
+
+// ErrPrescaleUnsupported is returned when prescaling an elastic cluster, whose shards are not replicas.
+var ErrPrescaleUnsupported = errors.New("prescaling is not supported for elastic clusters")
+
+// Prescale adds replicas ahead of a known peak, e.g. a marketing event or a load test, bounded by MaxCapacity.
+// The replicas are tagged with an expiry duration from now: until then scale-ins keep them, and the first
+// scaling action after it removes them, see removeExpiredPrescales. The outcome is available afterwards via
+// LastResult.
+func (d *DocumentDB) Prescale(ctx context.Context, replicas int, duration time.Duration) error {
+	if replicas <= 0 || duration <= 0 {
+		return fmt.Errorf("prescaling needs a positive number of replicas and duration, got %d replicas for %s", replicas, duration)
+	}
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.beginSnapshot()
+	defer d.endSnapshot()
+
+	release, locked, err := d.acquireLock(ctx)
+	if err != nil || locked {
+		return err
+	}
+	defer release()
+
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
+		if err != nil {
+			return err
+		}
+		return ErrPrescaleUnsupported
+	}
+
+	if paused, err := d.skipIfPaused(ctx); err != nil || paused {
+		return err
+	}
+	if open, err := d.skipIfCircuitOpen(ctx); err != nil || open {
+		return err
+	}
+	if exhausted, err := d.skipIfChurnExceeded(ctx); err != nil || exhausted {
+		return err
+	}
+
+	d.recordReason(ReasonPrescale)
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return err
+	}
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return err
+	}
+	requestedCapacity := currentCapacity + replicas
+	boundedCapacity := min(requestedCapacity, d.maxReaders())
+	d.recordBounds(requestedCapacity, boundedCapacity)
+	d.recordCapacity(currentCapacity, boundedCapacity)
+	if boundedCapacity <= currentCapacity {
+		d.Logger.Warn("No room below MAX_CAPACITY to prescale", "Replicas", replicas, "CurrentCapacity", currentCapacity, "MaxCapacity", d.MaxCapacity, "ClusterID", d.ClusterID)
+		return nil
+	}
+
+	replicasToAdd := boundedCapacity - currentCapacity
+	expiresAt := time.Now().Add(duration).UTC()
+	d.Logger.Info("Prescaling", "ReplicasToAdd", replicasToAdd, "ExpiresAt", expiresAt, "ClusterID", d.ClusterID)
+	d.recordDecision(DecisionScaleOut)
+	d.saveDesiredCapacity(ctx, boundedCapacity)
+	before := d.captureTopology(ctx)
+	addErr := d.AddReplicas(ctx, replicasToAdd)
+	// The replicas added before a failure are tagged too, or they would never expire
+	if err := d.tagPrescaled(ctx, d.lastResult.AddedInstanceIDs, expiresAt); err != nil {
+		return err
+	}
+	if next, found, err := d.loadNextPrescaleExpiry(ctx); err != nil {
+		return err
+	} else if !found || expiresAt.Before(next) {
+		d.saveNextPrescaleExpiry(ctx, expiresAt)
+	}
+	if addErr != nil {
+		d.Logger.Error("Failed to add prescaled replicas", "Error", addErr, "ReplicasToAdd", replicasToAdd)
+		return addErr
+	}
+	d.recordLastAction(ctx, DecisionScaleOut)
+	if err := d.notifierWithTopology(ctx, before).SendScaleOutNotification(ctx, d.ClusterID, d.replicasDone(replicasToAdd)); err != nil {
+		d.Logger.Error("Failed to send scale-out notification", "Error", err)
+	}
+	return nil
+}
+
+// tagPrescaled tags the added replicas with the expiry of the prescale.
+func (d *DocumentDB) tagPrescaled(ctx context.Context, instanceIDs []string, expiresAt time.Time) error {
+	if d.DryRun || len(instanceIDs) == 0 {
+		return nil
+	}
+	instances, err := d.describeInstances(ctx)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		instanceID := aws.ToString(instance.DBInstanceIdentifier)
+		if !slices.Contains(instanceIDs, instanceID) {
+			continue
+		}
+		_, err := d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+			ResourceName: instance.DBInstanceArn,
+			Tags:         []docdbTypes.Tag{{Key: aws.String(prescaleTagKey), Value: aws.String(expiresAt.Format(time.RFC3339))}},
+		})
+		if err != nil {
+			d.Logger.Error("Failed to tag prescaled replica", "Error", err, "InstanceID", instanceID)
+			return err
+		}
+	}
+	return nil
+}
+
+// prescaleExpiry returns the expiry of a prescaled replica from its tags, and whether it is one. A tag
+// that does not parse is taken as expired, so that the replica is not kept forever.
+func prescaleExpiry(tags []docdbTypes.Tag) (time.Time, bool) {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) != prescaleTagKey {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+		if err != nil {
+			return time.Time{}, true
+		}
+		return expiresAt, true
+	}
+	return time.Time{}, false
+}
+
+// loadNextPrescaleExpiry reads the earliest expiry of the prescaled replicas from the cluster tags, and
+// whether there are any.
+func (d *DocumentDB) loadNextPrescaleExpiry(ctx context.Context) (time.Time, bool, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) == nextPrescaleTagKey {
+			// A tag that does not parse is due, so that the replicas are looked up
+			next, _ := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+			return next, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// saveNextPrescaleExpiry writes the earliest expiry of the prescaled replicas to the cluster tags. Failures
+// are logged only: the replicas are then removed once a later prescale records its expiry.
+func (d *DocumentDB) saveNextPrescaleExpiry(ctx context.Context, next time.Time) {
+	if d.DryRun {
+		return
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to record the next prescale expiry", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(nextPrescaleTagKey), Value: aws.String(next.UTC().Format(time.RFC3339))}},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to record the next prescale expiry", "Error", err, "ExpiresAt", next)
+	}
+}
+
+// clearNextPrescaleExpiry removes the earliest expiry of the prescaled replicas from the cluster tags, once
+// none is left. Failures are logged only.
+func (d *DocumentDB) clearNextPrescaleExpiry(ctx context.Context) {
+	if d.DryRun {
+		return
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to clear the next prescale expiry", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.RemoveTagsFromResource(ctx, &docdb.RemoveTagsFromResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		TagKeys:      []string{nextPrescaleTagKey},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to clear the next prescale expiry", "Error", err)
+	}
+}
+
+// findExpiredPrescales returns the available prescaled replicas of the autoscaler whose expiry passed, and
+// the earliest expiry of the others, zero when there are none.
+func (d *DocumentDB) findExpiredPrescales(ctx context.Context, readerInstances []docdbTypes.DBInstance) ([]docdbTypes.DBInstance, time.Time, error) {
+	var expired []docdbTypes.DBInstance
+	var next time.Time
+	now := time.Now()
+	for _, instance := range readerInstances {
+		tags, err := d.instanceTags(ctx, instance)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		expiresAt, prescaled := prescaleExpiry(tags)
+		if !prescaled || !hasCreatedTag(tags) {
+			continue
+		}
+		// Replicas not available yet are removed by a later invocation
+		if expiresAt.After(now) || aws.ToString(instance.DBInstanceStatus) != "available" {
+			if next.IsZero() || expiresAt.Before(next) {
+				next = expiresAt
+			}
+			continue
+		}
+		expired = append(expired, instance)
+	}
+	return expired, next, nil
+}
+
+// removeExpiredPrescales deletes the prescaled replicas whose expiry passed, down to the reader floor, and
+// reports whether it removed any. The revert is not held by the scale-in cooldown, as it was planned with
+// the prescale. The tags of the readers are only listed once the earliest expiry of the cluster is due.
+func (d *DocumentDB) removeExpiredPrescales(ctx context.Context) (bool, error) {
+	next, found, err := d.loadNextPrescaleExpiry(ctx)
+	if err != nil || !found || next.After(time.Now()) {
+		return false, err
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
+		return false, err
+	}
+	expired, next, err := d.findExpiredPrescales(ctx, readerInstances)
+	if err != nil {
+		return false, err
+	}
+	if len(expired) == 0 {
+		d.updateNextPrescaleExpiry(ctx, next)
+		return false, nil
+	}
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return false, err
+	}
+	// The reader floor counts the readers the capacity counts, so that readers not serving yet do not make
+	// up for those removed
+	countable, err := d.countableReaders(ctx)
+	if err != nil {
+		return false, err
+	}
+	readerCount := len(countable)
+	var removable []docdbTypes.DBInstance
+	for _, instance := range expired {
+		if d.isCountable(instance) {
+			if !d.canRemoveReader(readerCount) {
+				continue
+			}
+			readerCount--
+		}
+		removable = append(removable, instance)
+	}
+	if len(removable) < len(expired) {
+		d.Logger.Warn("Keeping expired prescaled replicas to hold the reader floor", "Expired", len(expired), "CurrentReaders", len(countable), "ClusterID", d.ClusterID)
+		d.recordConstraint(ConstraintReaderFloor)
+	}
+	if len(removable) == 0 {
+		return false, nil
+	}
+	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to get writer instance identifier", "Error", err)
+		return false, err
+	}
+
+	d.recordReason(ReasonPrescaleExpired)
+	d.recordDecision(DecisionScaleIn)
+	d.recordCapacity(len(countable), readerCount)
+	d.saveDesiredCapacity(ctx, readerCount)
+	before := d.captureTopology(ctx)
+	for _, instance := range removable {
+		instanceID := aws.ToString(instance.DBInstanceIdentifier)
+		if d.DryRun {
+			d.Logger.Info("[Dry Run] Would remove expired prescaled replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+			d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+			continue
+		}
+		// Replicas still serving many clients are removed by a later invocation
+		drained, err := d.waitForDrain(ctx, instanceID)
+		if err != nil {
+			return true, err
+		}
+		if !drained {
+			d.recordConstraint(ConstraintConnectionDrain)
+			continue
+		}
+		// Guard against a failover, or a replica changed, since the topology was read
+		if err := d.verifyBeforeDelete(ctx, writerInstanceIdentifier, instance); err != nil {
+			return true, err
+		}
+		if _, err := d.DocDBClient.DeleteDBInstance(ctx, &docdb.DeleteDBInstanceInput{DBInstanceIdentifier: instance.DBInstanceIdentifier}); err != nil {
+			d.Logger.Error("Failed to delete expired prescaled replica", "Error", err, "InstanceID", instanceID)
+			return true, err
+		}
+		d.invalidateSnapshot()
+		d.Logger.Info("Removed expired prescaled replica", "ClusterID", d.ClusterID, "InstanceID", instanceID)
+		d.emitEvent(ctx, notifications.LifecycleEvent{DetailType: notifications.EventReplicaDeleted, InstanceID: instanceID, InstanceClass: aws.ToString(instance.DBInstanceClass)})
+		d.recordRemoved(instanceID, aws.ToString(instance.DBInstanceClass))
+	}
+
+	removed := d.lastResult.ReplicasRemoved
+	// The expired replicas left, e.g. still draining, keep the expiry of the cluster due
+	if removed == len(expired) {
+		d.updateNextPrescaleExpiry(ctx, next)
+	}
+	if removed == 0 {
+		return false, nil
+	}
+	if err := d.notifierWithTopology(ctx, before).SendScaleInNotification(ctx, d.ClusterID, removed); err != nil {
+		d.Logger.Error("Failed to send scale-in notification", "Error", err)
+	}
+	return true, nil
+}
+
+// updateNextPrescaleExpiry writes the earliest expiry of the prescaled replicas left to the cluster tags, or
+// removes it when there are none.
+func (d *DocumentDB) updateNextPrescaleExpiry(ctx context.Context, next time.Time) {
+	if next.IsZero() {
+		d.clearNextPrescaleExpiry(ctx)
+		return
+	}
+	d.saveNextPrescaleExpiry(ctx, next)
+}
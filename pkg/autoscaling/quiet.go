@@ -0,0 +1,92 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// quietDigestTagKey is the cluster tag holding the notifications deferred by quiet hours,
+// as "<scale-ins>:<replicas removed>".
+const quietDigestTagKey = "docdb-autoscaler-quiet-digest"
+
+// clusterTagQuietDigestStore keeps the quiet-hours digest in a tag of the cluster itself, like
+// clusterTagDedupStore.
+type clusterTagQuietDigestStore struct {
+	d *DocumentDB
+}
+
+// Ensure clusterTagQuietDigestStore implements notifications.QuietDigestStore
+var _ notifications.QuietDigestStore = clusterTagQuietDigestStore{}
+
+// LoadQuietDigest reads the digest from the cluster tags.
+func (s clusterTagQuietDigestStore) LoadQuietDigest(ctx context.Context, clusterID string) (notifications.QuietDigest, error) {
+	dbCluster, err := s.d.describeCluster(ctx)
+	if err != nil {
+		return notifications.QuietDigest{}, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != quietDigestTagKey {
+			continue
+		}
+		scaleIns, replicasRemoved, found := strings.Cut(aws.ToString(tag.Value), ":")
+		digest := notifications.QuietDigest{}
+		digest.ScaleIns, err = strconv.Atoi(scaleIns)
+		if err == nil && found {
+			digest.ReplicasRemoved, err = strconv.Atoi(replicasRemoved)
+		}
+		if err != nil || !found {
+			return notifications.QuietDigest{}, fmt.Errorf("invalid quiet hours digest %q", aws.ToString(tag.Value))
+		}
+		return digest, nil
+	}
+	return notifications.QuietDigest{}, nil
+}
+
+// SaveQuietDigest writes the digest to the cluster tags, or removes the tag when the digest is empty.
+func (s clusterTagQuietDigestStore) SaveQuietDigest(ctx context.Context, clusterID string, digest notifications.QuietDigest) error {
+	dbCluster, err := s.d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	if digest.ScaleIns == 0 {
+		_, err = s.d.DocDBClient.RemoveTagsFromResource(ctx, &docdb.RemoveTagsFromResourceInput{
+			ResourceName: dbCluster.DBClusterArn,
+			TagKeys:      []string{quietDigestTagKey},
+		})
+		s.d.invalidateSnapshot()
+		return err
+	}
+	value := fmt.Sprintf("%d:%d", digest.ScaleIns, digest.ReplicasRemoved)
+	_, err = s.d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(quietDigestTagKey), Value: aws.String(value)}},
+	})
+	s.d.invalidateSnapshot()
+	return err
+}
+
+// QuietNotifications defers scale-in notifications of the cluster during the quiet window into a
+// digest, keeping it in a tag of the cluster.
+func (d *DocumentDB) QuietNotifications(window notifications.QuietWindow) {
+	d.Notifier = &notifications.QuietHours{
+		Notifier: d.Notifier,
+		Window:   window,
+		Store:    clusterTagQuietDigestStore{d: d},
+		Logger:   d.Logger,
+	}
+}
+
+// flushQuietDigest sends the digest of quiet hours that ended, also when the invocation sends no
+// notification.
+func (d *DocumentDB) flushQuietDigest(ctx context.Context) {
+	if quietHours, ok := d.Notifier.(*notifications.QuietHours); ok {
+		quietHours.Flush(ctx, d.ClusterID)
+	}
+}
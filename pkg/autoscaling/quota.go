@@ -0,0 +1,47 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// instanceQuotaName is the account quota of DB instances in the region, shared by DocumentDB, Neptune and RDS.
+const instanceQuotaName = "DBInstances"
+
+// capToInstanceQuota returns how many of the replicas to add the DB instances quota of the account has room
+// for, so that a scale-out stops short of the quota rather than fails CreateDBInstance mid-batch. The replicas
+// left out are recorded as remaining, and reported in a warning notification. Failing to read the quota is
+// logged only, and leaves the scale-out uncapped.
+func (d *DocumentDB) capToInstanceQuota(ctx context.Context, replicasToAdd int) int {
+	if !d.CheckInstanceQuota || replicasToAdd <= 0 {
+		return replicasToAdd
+	}
+	output, err := d.RDSClient.DescribeAccountAttributes(ctx, &rds.DescribeAccountAttributesInput{})
+	if err != nil {
+		d.Logger.Warn("Failed to read the DB instances quota, scaling out uncapped", "Error", err, "ClusterID", d.ClusterID)
+		return replicasToAdd
+	}
+	for _, quota := range output.AccountQuotas {
+		if aws.ToString(quota.AccountQuotaName) != instanceQuotaName {
+			continue
+		}
+		used, limit := aws.ToInt64(quota.Used), aws.ToInt64(quota.Max)
+		room := int(max(limit-used, 0))
+		if room >= replicasToAdd {
+			return replicasToAdd
+		}
+		d.Logger.Warn("DB instances quota reached, capping scale-out", "ReplicasToAdd", replicasToAdd, "QuotaRoom", room, "QuotaUsed", used, "QuotaMax", limit, "ClusterID", d.ClusterID)
+		d.recordConstraint(ConstraintInstanceQuota)
+		d.recordPartial(replicasToAdd - room)
+		message := fmt.Sprintf("The DB instances quota of the account (%d of %d used) has room for %d of the %d replicas to add, request a quota increase to scale out further", used, limit, room, replicasToAdd)
+		if err := notifications.SendWarning(ctx, d.Notifier, d.ClusterID, message, "scale out"); err != nil {
+			d.Logger.Error("Failed to send quota warning notification", "Error", err)
+		}
+		return room
+	}
+	return replicasToAdd
+}
@@ -0,0 +1,75 @@
+package autoscaling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// rdsInstanceClient implements DocDBAPI over the RDS API, so that the scaling logic can manage Aurora instances.
+type rdsInstanceClient struct {
+	client RDSInstanceAPI
+}
+
+// NewRDSInstanceClient returns a DocDBAPI that manages instances through the RDS API.
+func NewRDSInstanceClient(client RDSInstanceAPI) DocDBAPI {
+	return &rdsInstanceClient{client: client}
+}
+
+// convertShape copies an API shape into the equivalent shape of the other API. The DocumentDB shapes
+// are a subset of the RDS shapes, with the same field names and types.
+func convertShape[T any](from any) (*T, error) {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T: %w", from, err)
+	}
+	to := new(T)
+	if err := json.Unmarshal(data, to); err != nil {
+		return nil, fmt.Errorf("failed to convert %T: %w", from, err)
+	}
+	return to, nil
+}
+
+// call converts the DocumentDB input, invokes the RDS operation and converts its output back.
+func call[DocDBOutput, RDSInput, RDSOutput any](ctx context.Context, params any, operation func(context.Context, *RDSInput, ...func(*rds.Options)) (*RDSOutput, error)) (*DocDBOutput, error) {
+	input, err := convertShape[RDSInput](params)
+	if err != nil {
+		return nil, err
+	}
+	output, err := operation(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return convertShape[DocDBOutput](output)
+}
+
+func (c *rdsInstanceClient) DescribeDBInstances(ctx context.Context, params *docdb.DescribeDBInstancesInput, optFns ...func(*docdb.Options)) (*docdb.DescribeDBInstancesOutput, error) {
+	return call[docdb.DescribeDBInstancesOutput](ctx, params, c.client.DescribeDBInstances)
+}
+
+func (c *rdsInstanceClient) CreateDBInstance(ctx context.Context, params *docdb.CreateDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.CreateDBInstanceOutput, error) {
+	return call[docdb.CreateDBInstanceOutput](ctx, params, c.client.CreateDBInstance)
+}
+
+func (c *rdsInstanceClient) DeleteDBInstance(ctx context.Context, params *docdb.DeleteDBInstanceInput, optFns ...func(*docdb.Options)) (*docdb.DeleteDBInstanceOutput, error) {
+	return call[docdb.DeleteDBInstanceOutput](ctx, params, c.client.DeleteDBInstance)
+}
+
+func (c *rdsInstanceClient) ListTagsForResource(ctx context.Context, params *docdb.ListTagsForResourceInput, optFns ...func(*docdb.Options)) (*docdb.ListTagsForResourceOutput, error) {
+	return call[docdb.ListTagsForResourceOutput](ctx, params, c.client.ListTagsForResource)
+}
+
+func (c *rdsInstanceClient) AddTagsToResource(ctx context.Context, params *docdb.AddTagsToResourceInput, optFns ...func(*docdb.Options)) (*docdb.AddTagsToResourceOutput, error) {
+	return call[docdb.AddTagsToResourceOutput](ctx, params, c.client.AddTagsToResource)
+}
+
+func (c *rdsInstanceClient) RemoveTagsFromResource(ctx context.Context, params *docdb.RemoveTagsFromResourceInput, optFns ...func(*docdb.Options)) (*docdb.RemoveTagsFromResourceOutput, error) {
+	return call[docdb.RemoveTagsFromResourceOutput](ctx, params, c.client.RemoveTagsFromResource)
+}
+
+func (c *rdsInstanceClient) DescribeGlobalClusters(ctx context.Context, params *docdb.DescribeGlobalClustersInput, optFns ...func(*docdb.Options)) (*docdb.DescribeGlobalClustersOutput, error) {
+	return call[docdb.DescribeGlobalClustersOutput](ctx, params, c.client.DescribeGlobalClusters)
+}
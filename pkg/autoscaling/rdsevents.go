@@ -0,0 +1,189 @@
+package autoscaling
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// Kinds of the RDS events the autoscaler reacts to.
+const (
+	RDSEventFailure              = "Failure"              // An instance failed
+	RDSEventFailover             = "Failover"             // A failover of the cluster completed
+	RDSEventMaintenanceStarted   = "MaintenanceStarted"   // Offline maintenance of an instance started
+	RDSEventMaintenanceCompleted = "MaintenanceCompleted" // Offline maintenance of an instance completed
+)
+
+// rdsEventKinds are the kinds of the RDS event IDs the autoscaler reacts to.
+var rdsEventKinds = map[string]string{
+	"RDS-EVENT-0031": RDSEventFailure,
+	"RDS-EVENT-0071": RDSEventFailover,
+	"RDS-EVENT-0026": RDSEventMaintenanceStarted,
+	"RDS-EVENT-0027": RDSEventMaintenanceCompleted,
+}
+
+// maintenanceTagKey is the cluster tag holding the maintenance in progress, as <source ID>@<RFC 3339 time>,
+// during which the autoscaler takes no action.
+const maintenanceTagKey = "docdb-autoscaler:maintenance"
+
+// DefaultMaintenancePause is how long a maintenance pauses autoscaling at most, should the event of its
+// completion be lost, unless configured otherwise.
+const DefaultMaintenancePause = 2 * time.Hour
+
+// RDSEvent is an RDS event notification, as delivered by an event subscription to SNS.
+type RDSEvent struct {
+	EventSource  string `json:"Event Source"` // db-instance or db-cluster
+	EventTime    string `json:"Event Time"`
+	SourceID     string `json:"Source ID"`
+	SourceARN    string `json:"Source ARN"`
+	EventID      string `json:"Event ID"` // Link to the documentation of the event, ending with #RDS-EVENT-<number>
+	EventMessage string `json:"Event Message"`
+}
+
+// ParseRDSEvent parses an SNS message as an RDS event notification, and reports whether it is one.
+func ParseRDSEvent(message []byte) (RDSEvent, bool) {
+	var event RDSEvent
+	if err := json.Unmarshal(message, &event); err != nil || event.SourceID == "" || !strings.Contains(event.EventID, "RDS-EVENT-") {
+		return RDSEvent{}, false
+	}
+	return event, true
+}
+
+// Code returns the code of the event, e.g. RDS-EVENT-0031.
+func (e RDSEvent) Code() string {
+	return e.EventID[strings.LastIndex(e.EventID, "RDS-EVENT-"):]
+}
+
+// Kind returns the kind of the event, or "" for the events the autoscaler does not react to.
+func (e RDSEvent) Kind() string {
+	return rdsEventKinds[e.Code()]
+}
+
+// RelatesTo reports whether the event is about the cluster or one of its instances.
+func (d *DocumentDB) RelatesTo(ctx context.Context, event RDSEvent) (bool, error) {
+	if event.EventSource == "db-cluster" {
+		return event.SourceID == d.ClusterID, nil
+	}
+	instances, err := d.describeInstances(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, instance := range instances {
+		if aws.ToString(instance.DBInstanceIdentifier) == event.SourceID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HandleRDSEvent reacts to an RDS event of the cluster instead of waiting for the next evaluation. A failed
+// instance is removed and replaced when it is a replica of the autoscaler, and a completed failover is
+// reconciled, each by a scaling action. A maintenance pauses autoscaling until it completes, or for
+// MaintenancePause at most, after which the cluster is evaluated again. The outcome is available afterwards
+// via LastResult.
+func (d *DocumentDB) HandleRDSEvent(ctx context.Context, event RDSEvent) error {
+	d.Logger.Info("Handling RDS event", "EventCode", event.Code(), "Kind", event.Kind(), "SourceID", event.SourceID, "EventMessage", event.EventMessage, "ClusterID", d.ClusterID)
+	switch event.Kind() {
+	case RDSEventFailure:
+		// Replacing the failed replica is the point of the event, whatever the invocations do otherwise
+		d.ReplaceFailedReplicas = true
+		err := d.ExecuteScalingAction(ctx)
+		d.recordReason(ReasonRDSEvent)
+		return err
+	case RDSEventFailover:
+		err := d.ExecuteScalingAction(ctx)
+		d.recordReason(ReasonRDSEvent)
+		return err
+	case RDSEventMaintenanceStarted:
+		d.lastResult = NewScalingResult(d.DryRun)
+		d.recordDecision(DecisionPaused)
+		d.recordReason(ReasonMaintenance)
+		if d.DryRun {
+			d.Logger.Info("[Dry Run] Would pause autoscaling during maintenance", "SourceID", event.SourceID, "ClusterID", d.ClusterID)
+			return nil
+		}
+		return d.saveMaintenance(ctx, event.SourceID)
+	case RDSEventMaintenanceCompleted:
+		if !d.DryRun {
+			if err := d.clearMaintenance(ctx); err != nil {
+				d.lastResult = NewScalingResult(d.DryRun)
+				return err
+			}
+		}
+		err := d.ExecuteScalingAction(ctx)
+		d.recordReason(ReasonRDSEvent)
+		return err
+	}
+	d.lastResult = NewScalingResult(d.DryRun)
+	d.Logger.Info("Ignoring RDS event", "EventCode", event.Code(), "SourceID", event.SourceID)
+	return nil
+}
+
+// maintenancePause returns how long a maintenance pauses autoscaling at most.
+func (d *DocumentDB) maintenancePause() time.Duration {
+	if d.MaintenancePause <= 0 {
+		return DefaultMaintenancePause
+	}
+	return d.MaintenancePause
+}
+
+// inMaintenance returns the source of the maintenance of the cluster, from its tags, and whether it still
+// pauses autoscaling. Tags edited by hand into invalid times pause nothing.
+func (d *DocumentDB) inMaintenance(dbCluster *rdsTypes.DBCluster) (string, bool) {
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != maintenanceTagKey {
+			continue
+		}
+		sourceID, startedAt, _ := strings.Cut(aws.ToString(tag.Value), "@")
+		t, err := time.Parse(time.RFC3339, startedAt)
+		if err != nil {
+			return "", false
+		}
+		return sourceID, time.Since(t) < d.maintenancePause()
+	}
+	return "", false
+}
+
+// saveMaintenance writes the maintenance of sourceID to the cluster tags.
+func (d *DocumentDB) saveMaintenance(ctx context.Context, sourceID string) error {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(maintenanceTagKey), Value: aws.String(sourceID + "@" + time.Now().UTC().Format(time.RFC3339))}},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Error("Failed to pause autoscaling during maintenance", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	d.Logger.Info("Paused autoscaling during maintenance", "SourceID", sourceID, "MaintenancePause", d.maintenancePause(), "ClusterID", d.ClusterID)
+	return nil
+}
+
+// clearMaintenance removes the maintenance from the cluster tags.
+func (d *DocumentDB) clearMaintenance(ctx context.Context) error {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = d.DocDBClient.RemoveTagsFromResource(ctx, &docdb.RemoveTagsFromResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		TagKeys:      []string{maintenanceTagKey},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Error("Failed to resume autoscaling after maintenance", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	d.Logger.Info("Resumed autoscaling after maintenance", "ClusterID", d.ClusterID)
+	return nil
+}
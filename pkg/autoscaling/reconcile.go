@@ -0,0 +1,86 @@
+package autoscaling
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+// desiredCapacityTagKey is the cluster tag holding the reader capacity the last scaling action aimed for,
+// so that every invocation can converge the cluster to it.
+const desiredCapacityTagKey = "docdb-autoscaler:desired-capacity"
+
+// loadDesiredCapacity reads the desired capacity from the cluster tags. It reports false when the cluster
+// has no desired capacity, or a tag edited by hand into an invalid one.
+func (d *DocumentDB) loadDesiredCapacity(ctx context.Context) (int, bool, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) != desiredCapacityTagKey {
+			continue
+		}
+		capacity, err := strconv.Atoi(aws.ToString(tag.Value))
+		if err != nil || capacity < 0 {
+			d.Logger.Warn("Ignoring invalid desired capacity tag", "Tag", desiredCapacityTagKey, "Value", aws.ToString(tag.Value))
+			return 0, false, nil
+		}
+		return capacity, true, nil
+	}
+	return 0, false, nil
+}
+
+// saveDesiredCapacity writes the capacity the current action aims for to the cluster tags, before the action
+// runs, so that an interrupted action is resumed by the next invocation. Dry-run actions change nothing,
+// so they are not recorded. Failures are logged only.
+func (d *DocumentDB) saveDesiredCapacity(ctx context.Context, capacity int) {
+	if !d.TrackDesiredCapacity || d.DryRun {
+		return
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to record the desired capacity", "Error", err)
+		return
+	}
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         []docdbTypes.Tag{{Key: aws.String(desiredCapacityTagKey), Value: aws.String(strconv.Itoa(capacity))}},
+	})
+	d.invalidateSnapshot()
+	if err != nil {
+		d.Logger.Warn("Failed to record the desired capacity", "Error", err, "DesiredCapacity", capacity)
+	}
+}
+
+// reconcile adds the readers missing from the desired capacity, e.g. after a scale-out was interrupted or a
+// replica was deleted by hand, and reports whether it did. Readers above the desired capacity are left to
+// the metric-based scale-in, which removes them one at a time. Scheduled scaling keeps its own state in the
+// tags of its replicas, so it is not reconciled.
+func (d *DocumentDB) reconcile(ctx context.Context) (bool, error) {
+	if !d.TrackDesiredCapacity || d.ScheduledScaling {
+		return false, nil
+	}
+	desiredCapacity, found, err := d.loadDesiredCapacity(ctx)
+	if err != nil || !found {
+		return false, err
+	}
+	// The current capacity loads the baseline readers the bounds sit on top of with ManagedCapacity
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return false, err
+	}
+	// The bounds may have changed since the desired capacity was recorded
+	desiredCapacity = d.clampCapacity(desiredCapacity)
+	if currentCapacity >= desiredCapacity {
+		return false, nil
+	}
+
+	d.Logger.Warn("Cluster has fewer readers than its desired capacity, reconciling", "CurrentCapacity", currentCapacity, "DesiredCapacity", desiredCapacity, "ClusterID", d.ClusterID)
+	d.recordReason(ReasonReconcile)
+	return true, d.convergeCapacity(ctx, currentCapacity, desiredCapacity)
+}
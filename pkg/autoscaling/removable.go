@@ -0,0 +1,30 @@
+package autoscaling
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	"github.com/aws/smithy-go"
+)
+
+// removalBlocker returns why a replica is not a candidate for removal now, or "" when it is: a replica that
+// is not available, e.g. backing-up or modifying, fails DeleteDBInstance, and one with pending modifications
+// is mid-change, so another candidate is removed instead.
+func removalBlocker(instance docdbTypes.DBInstance) string {
+	if status := aws.ToString(instance.DBInstanceStatus); status != "available" {
+		return fmt.Sprintf("status is %s", status)
+	}
+	if pending := instance.PendingModifiedValues; pending != nil && *pending != (docdbTypes.PendingModifiedValues{}) {
+		return "modifications are pending"
+	}
+	return ""
+}
+
+// isDeletionRefused reports whether DeleteDBInstance refused to delete a replica in its current state, e.g.
+// protected or changed since it was described, in which case another candidate can be removed instead.
+func isDeletionRefused(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidDBInstanceState"
+}
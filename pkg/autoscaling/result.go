@@ -0,0 +1,301 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+)
+
+// Decisions reported in a ScalingResult.
+const (
+	DecisionScaleOut = "ScaleOut"
+	DecisionScaleIn  = "ScaleIn"
+	DecisionNoAction = "NoAction"
+	DecisionVerify   = "Verify"
+	DecisionPaused   = "Paused"
+	DecisionReplace  = "Replace" // Failed replicas were removed and recreated
+)
+
+// ScalingResult summarizes the outcome of a scaling action for structured consumers such as Step Functions.
+// Instances added or removed are listed in dry-run too, while only the instances actually created are pending.
+// The reason codes and constraints explain the decision, e.g. why a scaling action was skipped.
+type ScalingResult struct {
+	Decision           string   `json:"Decision"`
+	ReplicasAdded      int      `json:"ReplicasAdded"`
+	ReplicasRemoved    int      `json:"ReplicasRemoved"`
+	AddedInstanceIDs   []string `json:"AddedInstanceIDs"`
+	RemovedInstanceIDs []string `json:"RemovedInstanceIDs"`
+	PendingInstanceIDs []string `json:"PendingInstanceIDs"`
+	ReasonCodes        []string `json:"ReasonCodes"`
+	Constraints        []string `json:"Constraints"` // Constraints that changed the outcome, e.g. MaxCapacity or ReaderFloor
+	DryRun             bool     `json:"DryRun"`
+
+	// Set when the action did not add or remove all the replicas it called for, with those it left out: when it
+	// stopped before the deadline of the invocation, or was capped by MAX_HOURLY_COST, BULK_SCALE_IN_LIMIT, a
+	// managed replica cap or the instance quota, or added a canary replica only, see the reason code Deadline
+	// and the constraints
+	Partial           bool `json:"Partial"`
+	ReplicasRemaining int  `json:"ReplicasRemaining"`
+
+	// SNS messages skipped as already processed, when IDEMPOTENCY_TABLE is set
+	DuplicateMessageIDs []string `json:"DuplicateMessageIDs"`
+
+	// SNS messages skipped as calling for the actions an earlier record of the invocation took, e.g. one alarm
+	// fanned out to several subscriptions of the topic
+	CoalescedMessageIDs []string `json:"CoalescedMessageIDs"`
+
+	// SNS records of the invocation whose processing failed, while the others were processed
+	FailedRecords []RecordFailure `json:"FailedRecords"`
+
+	// Human-readable explanation of the decision, e.g. "CPUUtilization=82.3 > target=70 → +2; clamped by MaxCapacity"
+	Explanation string `json:"Explanation"`
+
+	// Estimated on-demand cost delta of the instances added and removed, when COST_ESTIMATES is set
+	CostDelta *pricing.Cost `json:"CostDelta,omitempty"`
+
+	// For metrics of the action
+	startedAt       time.Time
+	decidedAt       time.Time
+	currentCapacity *int
+	desiredCapacity *int
+
+	// For the decision record of the action
+	metricName  string
+	metricValue *float64
+	targetValue *float64
+
+	// For the cost delta of the action
+	addedClasses   []string
+	removedClasses []string
+}
+
+// RecordFailure is an SNS record whose processing failed.
+type RecordFailure struct {
+	MessageID string `json:"MessageID"`
+	Error     string `json:"Error"`
+}
+
+// NewScalingResult returns an empty result with no action taken.
+func NewScalingResult(dryRun bool) *ScalingResult {
+	return &ScalingResult{
+		Decision:            DecisionNoAction,
+		AddedInstanceIDs:    []string{},
+		RemovedInstanceIDs:  []string{},
+		PendingInstanceIDs:  []string{},
+		ReasonCodes:         []string{},
+		Constraints:         []string{},
+		DryRun:              dryRun,
+		DuplicateMessageIDs: []string{},
+		CoalescedMessageIDs: []string{},
+		FailedRecords:       []RecordFailure{},
+		startedAt:           time.Now(),
+	}
+}
+
+// Merge folds another result into r, e.g. when one invocation processes several SNS records.
+func (r *ScalingResult) Merge(other *ScalingResult) {
+	if other == nil {
+		return
+	}
+	if other.Decision != DecisionNoAction {
+		r.Decision = other.Decision
+	}
+	r.ReplicasAdded += other.ReplicasAdded
+	r.ReplicasRemoved += other.ReplicasRemoved
+	r.AddedInstanceIDs = append(r.AddedInstanceIDs, other.AddedInstanceIDs...)
+	r.RemovedInstanceIDs = append(r.RemovedInstanceIDs, other.RemovedInstanceIDs...)
+	r.PendingInstanceIDs = append(r.PendingInstanceIDs, other.PendingInstanceIDs...)
+	r.ReasonCodes = appendMissing(r.ReasonCodes, other.ReasonCodes...)
+	r.Constraints = appendMissing(r.Constraints, other.Constraints...)
+	r.DryRun = r.DryRun || other.DryRun
+	r.Partial = r.Partial || other.Partial
+	r.ReplicasRemaining += other.ReplicasRemaining
+	r.DuplicateMessageIDs = append(r.DuplicateMessageIDs, other.DuplicateMessageIDs...)
+	r.CoalescedMessageIDs = append(r.CoalescedMessageIDs, other.CoalescedMessageIDs...)
+	r.FailedRecords = append(r.FailedRecords, other.FailedRecords...)
+	if r.Explanation == "" {
+		r.Explanation = other.Explanation
+	} else if other.Explanation != "" {
+		r.Explanation += " | " + other.Explanation
+	}
+	if other.CostDelta != nil {
+		var hourly float64
+		if r.CostDelta != nil {
+			hourly = r.CostDelta.Hourly
+		}
+		total := pricing.NewCost(hourly + other.CostDelta.Hourly)
+		r.CostDelta = &total
+	}
+}
+
+// appendMissing appends the values that are not yet in values.
+func appendMissing(values []string, others ...string) []string {
+	for _, other := range others {
+		if !slices.Contains(values, other) {
+			values = append(values, other)
+		}
+	}
+	return values
+}
+
+// LastResult returns the result of the most recent ExecuteScalingAction call, explained from what it
+// recorded so far.
+func (d *DocumentDB) LastResult() *ScalingResult {
+	if d.lastResult == nil {
+		result := NewScalingResult(d.DryRun)
+		result.Explanation = result.explain()
+		return result
+	}
+	d.lastResult.Explanation = d.lastResult.explain()
+	return d.lastResult
+}
+
+// explain returns the explanation of the result, with the replicas left when it is partial.
+func (r *ScalingResult) explain() string {
+	explanation := explain(r.metricName, r.metricValue, r.targetValue, r.ReasonCodes, r.Decision, r.ReplicasAdded, r.ReplicasRemoved, r.Constraints)
+	if r.Partial {
+		explanation += fmt.Sprintf("; %d replicas remaining", r.ReplicasRemaining)
+	}
+	return explanation
+}
+
+// recordAdded records a replica created (or, in dry-run, planned) by the current scaling action.
+func (d *DocumentDB) recordAdded(instanceID, instanceClass string) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.ReplicasAdded++
+	d.lastResult.AddedInstanceIDs = append(d.lastResult.AddedInstanceIDs, instanceID)
+	d.lastResult.addedClasses = append(d.lastResult.addedClasses, instanceClass)
+	if !d.DryRun {
+		d.lastResult.PendingInstanceIDs = append(d.lastResult.PendingInstanceIDs, instanceID)
+	}
+}
+
+// recordRemoved records a replica deleted (or, in dry-run, planned for deletion) by the current scaling action.
+func (d *DocumentDB) recordRemoved(instanceID, instanceClass string) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.ReplicasRemoved++
+	d.lastResult.RemovedInstanceIDs = append(d.lastResult.RemovedInstanceIDs, instanceID)
+	d.lastResult.removedClasses = append(d.lastResult.removedClasses, instanceClass)
+}
+
+// recordPartial records that the current scaling action left replicas out, e.g. stopped before the deadline
+// or capped, with the remaining replicas.
+func (d *DocumentDB) recordPartial(remaining int) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.Partial = true
+	d.lastResult.ReplicasRemaining += remaining
+}
+
+// recordDecision records the direction chosen by the current scaling action.
+func (d *DocumentDB) recordDecision(decision string) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.Decision = decision
+	if d.lastResult.decidedAt.IsZero() {
+		d.lastResult.decidedAt = time.Now()
+	}
+}
+
+// recordCapacity records the current and desired capacity the current scaling action decided on.
+func (d *DocumentDB) recordCapacity(currentCapacity, desiredCapacity int) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.currentCapacity = &currentCapacity
+	d.lastResult.desiredCapacity = &desiredCapacity
+	d.lastResult.decidedAt = time.Now()
+}
+
+// recordReason records a reason code of the decision of the current scaling action.
+func (d *DocumentDB) recordReason(reason string) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.ReasonCodes = appendMissing(d.lastResult.ReasonCodes, reason)
+}
+
+// recordConstraint records a constraint that changed the outcome of the current scaling action.
+func (d *DocumentDB) recordConstraint(constraint string) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.Constraints = appendMissing(d.lastResult.Constraints, constraint)
+}
+
+// recordBounds records the MinCapacity or MaxCapacity constraint when bounding changed the requested capacity.
+func (d *DocumentDB) recordBounds(requestedCapacity, boundedCapacity int) {
+	if constraint := boundsConstraint(requestedCapacity, boundedCapacity); constraint != "" {
+		d.recordConstraint(constraint)
+	}
+}
+
+// boundsConstraint returns the MinCapacity or MaxCapacity constraint when bounding changed the requested
+// capacity, and "" otherwise.
+func boundsConstraint(requestedCapacity, boundedCapacity int) string {
+	if boundedCapacity > requestedCapacity {
+		return ConstraintMinCapacity
+	} else if boundedCapacity < requestedCapacity {
+		return ConstraintMaxCapacity
+	}
+	return ""
+}
+
+// recordMetric records the metric the current scaling action was decided on, and how it compares to its target.
+func (d *DocumentDB) recordMetric(metricName string, metricValue, targetValue float64) {
+	if d.lastResult == nil {
+		return
+	}
+	d.lastResult.metricName = metricName
+	d.lastResult.metricValue = &metricValue
+	d.lastResult.targetValue = &targetValue
+	d.recordReason(metricReason(metricValue, targetValue))
+}
+
+// metricReason returns the reason code of how a metric compares to its target.
+func metricReason(metricValue, targetValue float64) string {
+	switch {
+	case metricValue > targetValue:
+		return ReasonMetricAboveTarget
+	case metricValue < targetValue:
+		return ReasonMetricBelowTarget
+	}
+	return ReasonMetricAtTarget
+}
+
+// GetPendingInstances returns the instances from instanceIDs that are not yet in 'available' state.
+// Instances that no longer exist in the cluster are not reported as pending.
+func (d *DocumentDB) GetPendingInstances(ctx context.Context, instanceIDs []string) ([]string, error) {
+	dbInstances, err := d.describeInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]string, len(dbInstances))
+	for _, instance := range dbInstances {
+		statuses[aws.ToString(instance.DBInstanceIdentifier)] = aws.ToString(instance.DBInstanceStatus)
+	}
+
+	pending := []string{}
+	for _, instanceID := range instanceIDs {
+		status, found := statuses[instanceID]
+		if !found {
+			d.Logger.Warn("Pending instance not found in cluster", "InstanceID", instanceID)
+			continue
+		}
+		if status != "available" {
+			pending = append(pending, instanceID)
+		}
+	}
+	return pending, nil
+}
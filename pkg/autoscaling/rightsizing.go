@@ -0,0 +1,235 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+)
+
+// Actions of rightsizing recommendations.
+const (
+	RecommendRemoveReplica = "RemoveReplica" // One reader fewer keeps the peak utilization below the target
+	RecommendDownsizeClass = "DownsizeClass" // The next smaller class keeps the peak utilization below the target
+)
+
+// DefaultRecommendationDays is the period of utilization analyzed by Recommend, in days.
+const DefaultRecommendationDays = 14
+
+// maxRecommendationDays is the longest period whose hourly datapoints fit in a single GetMetricStatistics call.
+const maxRecommendationDays = 60
+
+// ErrNoRecommendationTarget is returned when the cluster has no metric target to recommend against,
+// e.g. with scheduled scaling.
+var ErrNoRecommendationTarget = errors.New("rightsizing recommendations need METRIC_NAME and TARGET_VALUE")
+
+// Recommendation is a rightsizing recommendation.
+type Recommendation struct {
+	Action        string        `json:"Action"`
+	InstanceClass string        `json:"InstanceClass,omitempty"` // Class to downsize to, for DownsizeClass
+	Reason        string        `json:"Reason"`
+	Savings       *pricing.Cost `json:"Savings,omitempty"` // Estimated on-demand savings, when Prices is set
+}
+
+// Recommendations are the rightsizing recommendations of a cluster, from the utilization of its readers
+// over the period. Nothing acts on them.
+type Recommendations struct {
+	ClusterID          string           `json:"ClusterID"`
+	Days               int              `json:"Days"`
+	MetricName         string           `json:"MetricName"`
+	TargetValue        float64          `json:"TargetValue"`
+	Readers            int              `json:"Readers"`
+	AverageUtilization float64          `json:"AverageUtilization"` // Mean of the hourly averages of the readers
+	PeakUtilization    float64          `json:"PeakUtilization"`    // Highest hourly average of a reader
+	Recommendations    []Recommendation `json:"Recommendations"`
+}
+
+// Message returns the notification message of the recommendations.
+func (r *Recommendations) Message() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Rightsizing recommendations for cluster %s, from the last %d days of %s (target %g):\n", r.ClusterID, r.Days, r.MetricName, r.TargetValue)
+	fmt.Fprintf(&message, "- Readers: %d, average %.1f, peak hourly average %.1f\n", r.Readers, r.AverageUtilization, r.PeakUtilization)
+	for _, recommendation := range r.Recommendations {
+		fmt.Fprintf(&message, "- %s: %s", recommendation.Action, recommendation.Reason)
+		if recommendation.Savings != nil {
+			fmt.Fprintf(&message, ", saving an estimated $%.2f/month", recommendation.Savings.Monthly)
+		}
+		message.WriteString("\n")
+	}
+	if len(r.Recommendations) == 0 {
+		message.WriteString("- No change recommended\n")
+	}
+	return message.String()
+}
+
+// instanceSize is an instance size and its capacity, in vCPUs.
+type instanceSize struct {
+	name string
+	vcpu int
+}
+
+// instanceSizes are the instance sizes in increasing capacity, from which a smaller class is found and the
+// utilization after downsizing projected.
+var instanceSizes = []instanceSize{
+	{"large", 2}, {"xlarge", 4}, {"2xlarge", 8}, {"4xlarge", 16}, {"8xlarge", 32}, {"12xlarge", 48}, {"16xlarge", 64}, {"24xlarge", 96},
+}
+
+// smallerClass returns the next smaller class of an instance class, e.g. db.r6g.large for db.r6g.xlarge,
+// and the ratio of its capacity to that of the class. Burstable and the smallest classes have none.
+func smallerClass(instanceClass string) (string, float64, bool) {
+	family, size, found := strings.Cut(strings.TrimPrefix(instanceClass, "db."), ".")
+	if !found || strings.HasPrefix(family, "t") {
+		return "", 0, false
+	}
+	i := slices.IndexFunc(instanceSizes, func(s instanceSize) bool { return s.name == size })
+	if i <= 0 {
+		return "", 0, false
+	}
+	return "db." + family + "." + instanceSizes[i-1].name, float64(instanceSizes[i-1].vcpu) / float64(instanceSizes[i].vcpu), true
+}
+
+// Recommend analyzes the hourly utilization of the readers over the last days and recommends removing a
+// reader when the others would absorb its load below the target, and downsizing a reader class when the
+// next smaller size would, without acting on either. Readers without datapoints, e.g. just created, are
+// not counted.
+func (d *DocumentDB) Recommend(ctx context.Context, days int) (*Recommendations, error) {
+	if d.MetricName == "" || d.TargetValue <= 0 {
+		return nil, ErrNoRecommendationTarget
+	}
+	if days <= 0 {
+		days = DefaultRecommendationDays
+	}
+	if days > maxRecommendationDays {
+		return nil, fmt.Errorf("rightsizing recommendations cover at most %d days, got %d", maxRecommendationDays, days)
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve reader instances", "Error", err)
+		return nil, err
+	}
+	if err := d.loadBaselineReaders(ctx); err != nil {
+		return nil, err
+	}
+
+	recommendations := &Recommendations{ClusterID: d.ClusterID, Days: days, MetricName: d.MetricName, TargetValue: d.TargetValue, Recommendations: []Recommendation{}}
+	// Peak utilization and readers per class, for downsizing
+	classPeaks := map[string]float64{}
+	classReaders := map[string]int{}
+	var classes []string
+	var totalAverage float64
+	for _, instance := range readerInstances {
+		average, peak, found, err := d.hourlyUtilization(ctx, aws.ToString(instance.DBInstanceIdentifier), days)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		recommendations.Readers++
+		totalAverage += average
+		recommendations.PeakUtilization = max(recommendations.PeakUtilization, peak)
+		instanceClass := aws.ToString(instance.DBInstanceClass)
+		if _, seen := classPeaks[instanceClass]; !seen {
+			classes = append(classes, instanceClass)
+		}
+		classPeaks[instanceClass] = max(classPeaks[instanceClass], peak)
+		classReaders[instanceClass]++
+	}
+	if recommendations.Readers == 0 {
+		return recommendations, nil
+	}
+	recommendations.AverageUtilization = totalAverage / float64(recommendations.Readers)
+
+	// The load of a removed reader is spread over the others
+	readers := recommendations.Readers
+	if readers > 1 && d.canRemoveReader(len(readerInstances)) {
+		projectedPeak := recommendations.PeakUtilization * float64(readers) / float64(readers-1)
+		if projectedPeak < d.TargetValue {
+			recommendation := Recommendation{
+				Action: RecommendRemoveReplica,
+				Reason: fmt.Sprintf("with %d readers instead of %d, the peak hourly average would be about %.1f, below the target", readers-1, readers, projectedPeak),
+			}
+			if len(classes) == 1 {
+				recommendation.Savings = d.estimateSavings(ctx, classes[0], "", 1)
+			}
+			recommendations.Recommendations = append(recommendations.Recommendations, recommendation)
+		}
+	}
+	for _, instanceClass := range classes {
+		downsized, ratio, found := smallerClass(instanceClass)
+		if !found {
+			continue
+		}
+		projectedPeak := classPeaks[instanceClass] / ratio
+		if projectedPeak >= d.TargetValue {
+			continue
+		}
+		recommendations.Recommendations = append(recommendations.Recommendations, Recommendation{
+			Action:        RecommendDownsizeClass,
+			InstanceClass: downsized,
+			Reason:        fmt.Sprintf("with %s readers instead of %s, the peak hourly average would be about %.1f, below the target", downsized, instanceClass, projectedPeak),
+			Savings:       d.estimateSavings(ctx, instanceClass, downsized, classReaders[instanceClass]),
+		})
+	}
+	return recommendations, nil
+}
+
+// hourlyUtilization returns the mean and the highest of the hourly averages of the metric of a reader over
+// the last days, and false when the reader has no datapoints.
+func (d *DocumentDB) hourlyUtilization(ctx context.Context, instanceID string, days int) (float64, float64, bool, error) {
+	now := time.Now()
+	output, err := d.CloudWatchClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(d.engine().MetricNamespace),
+		MetricName: aws.String(d.MetricName),
+		Dimensions: []cwTypes.Dimension{{Name: aws.String("DBInstanceIdentifier"), Value: aws.String(instanceID)}},
+		StartTime:  aws.Time(now.Add(-time.Duration(days) * 24 * time.Hour)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int32(3600), // 1 hour
+		Statistics: []cwTypes.Statistic{cwTypes.StatisticAverage},
+	})
+	if err != nil {
+		d.Logger.Error("Failed to get metric statistics", "Error", err, "InstanceID", instanceID)
+		return 0, 0, false, err
+	}
+	if len(output.Datapoints) == 0 {
+		d.Logger.Info("No datapoints found for reader, skipping it", "InstanceID", instanceID)
+		return 0, 0, false, nil
+	}
+	var total, peak float64
+	for _, datapoint := range output.Datapoints {
+		average := aws.ToFloat64(datapoint.Average)
+		total += average
+		peak = max(peak, average)
+	}
+	return total / float64(len(output.Datapoints)), peak, true, nil
+}
+
+// estimateSavings returns the savings of replacing the readers of instanceClass with ones of downsized, or
+// of removing them when downsized is empty, when Prices is set. Failures are logged only.
+func (d *DocumentDB) estimateSavings(ctx context.Context, instanceClass, downsized string, readers int) *pricing.Cost {
+	if d.Prices == nil {
+		return nil
+	}
+	price, err := d.hourlyPrice(ctx, []string{instanceClass})
+	if err != nil {
+		d.Logger.Warn("Failed to estimate the savings of the recommendation", "Error", err, "InstanceClass", instanceClass)
+		return nil
+	}
+	if downsized != "" {
+		downsizedPrice, err := d.hourlyPrice(ctx, []string{downsized})
+		if err != nil {
+			d.Logger.Warn("Failed to estimate the savings of the recommendation", "Error", err, "InstanceClass", downsized)
+			return nil
+		}
+		price -= downsizedPrice
+	}
+	savings := pricing.NewCost(price * float64(readers))
+	return &savings
+}
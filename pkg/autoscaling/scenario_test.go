@@ -0,0 +1,344 @@
+package autoscaling_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling/fakes"
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// scenario is a scaling scenario of testdata/scenarios: a cluster, the metric series of its readers, the
+// settings of the autoscaler, and the steps it is evaluated in with their expected outcomes.
+type scenario struct {
+	Name    string               `yaml:"name"`
+	Config  scenarioConfig       `yaml:"config"`
+	Cluster scenarioCluster      `yaml:"cluster"`
+	Metrics map[string][]float64 `yaml:"metrics"` // Series by metric name, one value per step
+	Steps   []scenarioStep       `yaml:"steps"`
+}
+
+// scenarioConfig is the settings of the autoscaler of a scenario. Unset fields keep the defaults of New.
+type scenarioConfig struct {
+	MinCapacity      *int          `yaml:"minCapacity"`
+	MaxCapacity      *int          `yaml:"maxCapacity"`
+	ManagedCapacity  bool          `yaml:"managedCapacity"`
+	MetricName       string        `yaml:"metricName"`
+	TargetValue      float64       `yaml:"targetValue"`
+	ScaleInCooldown  int           `yaml:"scaleInCooldown"`
+	ScaleOutCooldown int           `yaml:"scaleOutCooldown"`
+	EnforceCooldowns bool          `yaml:"enforceCooldowns"`
+	Stabilization    time.Duration `yaml:"stabilization"` // Scale-in stabilization window
+	CanaryScaleOut   bool          `yaml:"canaryScaleOut"`
+	CanaryWindow     time.Duration `yaml:"canaryWindow"`
+	ChurnBudget      int           `yaml:"churnBudget"` // Hourly churn budget
+	InstanceType     string        `yaml:"instanceType"`
+	AllowZeroReaders bool          `yaml:"allowZeroReaders"`
+	WriterBootstrap  bool          `yaml:"writerOnlyBootstrap"`
+	Bootstrap        int           `yaml:"bootstrapCapacity"`
+	DryRun           bool          `yaml:"dryRun"`
+	ScheduleReplicas *int          `yaml:"scheduleReplicas"` // Scheduled scaling by this number of replicas when set
+	SchedulePhase    string        `yaml:"schedulePhase"`
+	HolidayToday     bool          `yaml:"holidayToday"`      // Today is a holiday of the calendar, in UTC
+	Countable        []string      `yaml:"countableStatuses"` // Statuses of the readers counted, every status when empty
+	TrackDesired     bool          `yaml:"trackDesiredCapacity"`
+}
+
+// scenarioCluster is the topology and tags of the cluster of a scenario.
+type scenarioCluster struct {
+	Readers []scenarioReader         `yaml:"readers"`
+	Tags    map[string]string        `yaml:"tags"`
+	TagsAgo map[string]time.Duration `yaml:"tagsAgo"` // Tags holding the RFC 3339 time this long before the scenario runs, e.g. cooldown tags
+}
+
+// scenarioReader is a reader of the cluster of a scenario.
+type scenarioReader struct {
+	ID      string                   `yaml:"id"`
+	Status  string                   `yaml:"status"`
+	Managed bool                     `yaml:"managed"` // Created by the autoscaler
+	Tags    map[string]string        `yaml:"tags"`
+	TagsAgo map[string]time.Duration `yaml:"tagsAgo"` // Tags holding the RFC 3339 time this long before the scenario runs, negative for a later time
+}
+
+// scenarioStep is an evaluation of a scenario: "plan" plans only, "apply" plans and applies the plan,
+// "evaluate" runs a whole scaling action, e.g. for scheduled scaling, "prescale" prescales the cluster and
+// "scaleTo" scales it to a requested capacity. The metric series move on after every step.
+type scenarioStep struct {
+	Action   string            `yaml:"action"`
+	Prescale *scenarioPrescale `yaml:"prescale"`
+	Capacity *int              `yaml:"capacity"` // Requested capacity of a "scaleTo" step
+	Expect   expect            `yaml:"expect"`
+	Readers  *int              `yaml:"readers"`  // Readers of the cluster after the step
+	Notified []string          `yaml:"notified"` // Notifications of the step, e.g. "ScaleIn 1", an empty list checking there are none
+	Untagged []string          `yaml:"untagged"` // Cluster tags absent after the step, e.g. the cooldown tags
+	Report   bool              `yaml:"report"`   // Report the outcome of the step, as the handler does, e.g. to count the churn
+}
+
+// scenarioPrescale is the prescale of a "prescale" step.
+type scenarioPrescale struct {
+	Replicas int           `yaml:"replicas"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+// expect is the expected outcome of a step. Unset fields are not checked, and an empty list checks that
+// there are none. Decision, reason codes and constraints are those of the plan, or of the result when
+// evaluated.
+type expect struct {
+	Error            string   `yaml:"error"` // Substring of the error of the step
+	Decision         string   `yaml:"decision"`
+	ReplicasToAdd    *int     `yaml:"replicasToAdd"`
+	ReplicasToRemove *int     `yaml:"replicasToRemove"`
+	ReplicasDeferred *int     `yaml:"replicasDeferred"`
+	DesiredCapacity  *int     `yaml:"desiredCapacity"`
+	BaselineReaders  *int     `yaml:"baselineReaders"`
+	ReplicasAdded    *int     `yaml:"replicasAdded"`
+	ReplicasRemoved  *int     `yaml:"replicasRemoved"`
+	ReasonCodes      []string `yaml:"reasonCodes"`
+	Constraints      []string `yaml:"constraints"`
+	Explanation      string   `yaml:"explanation"`
+}
+
+// TestScenarios runs the scaling scenarios of testdata/scenarios against fake clusters. Each file holds a
+// list of scenarios, so that a case of the matrix of cooldowns, schedules, clamps and tags is added without
+// writing a test.
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var scenarios []scenario
+		require.NoError(t, yaml.Unmarshal(data, &scenarios), file)
+
+		for _, sc := range scenarios {
+			sc := sc
+			name := strings.TrimSuffix(filepath.Base(file), ".yaml") + "/" + sc.Name
+			t.Run(name, func(t *testing.T) {
+				runScenario(t, sc)
+			})
+		}
+	}
+}
+
+// runScenario sets up the fake cluster and the autoscaler of a scenario, and checks its steps in order.
+func runScenario(t *testing.T, sc scenario) {
+	cluster := fakes.NewCluster("scenario")
+	for _, reader := range sc.Cluster.Readers {
+		tags := map[string]string{}
+		for key, value := range reader.Tags {
+			tags[key] = value
+		}
+		for key, ago := range reader.TagsAgo {
+			tags[key] = time.Now().Add(-ago).UTC().Format(time.RFC3339)
+		}
+		if reader.Managed {
+			tags["docdb-autoscaler-created"] = "true"
+		}
+		cluster.AddInstance(fakes.Instance{ID: reader.ID, Status: reader.Status, Tags: tags})
+	}
+	for key, value := range sc.Cluster.Tags {
+		cluster.Tags[key] = value
+	}
+	for key, ago := range sc.Cluster.TagsAgo {
+		cluster.Tags[key] = time.Now().Add(-ago).UTC().Format(time.RFC3339)
+	}
+	for metricName, series := range sc.Metrics {
+		cluster.SetMetric(metricName, series...)
+	}
+
+	autoscaler := newScenarioAutoscaler(t, cluster, sc.Config)
+	notifier := &scenarioNotifier{}
+	autoscaler.Notifier = notifier
+	ctx := context.Background()
+	for i, step := range sc.Steps {
+		t.Run(fmt.Sprintf("step %d", i+1), func(t *testing.T) {
+			notifier.sent = nil
+			switch step.Action {
+			case "", "plan", "apply":
+				plan, err := autoscaler.Plan(ctx)
+				if checkError(t, step.Expect, err) {
+					return
+				}
+				checkPlan(t, step.Expect, plan)
+				if step.Action == "apply" {
+					result, err := autoscaler.Apply(ctx, plan)
+					require.NoError(t, err)
+					checkReplicas(t, step.Expect, result)
+				}
+			case "evaluate":
+				result, err := autoscaler.Evaluate(ctx)
+				if checkError(t, step.Expect, err) {
+					return
+				}
+				checkResult(t, step.Expect, result)
+				if step.Report {
+					autoscaler.ReportOutcome(ctx, err)
+				}
+			case "prescale":
+				require.NotNil(t, step.Prescale, "prescale step without prescale")
+				err := autoscaler.Prescale(ctx, step.Prescale.Replicas, step.Prescale.Duration)
+				if checkError(t, step.Expect, err) {
+					return
+				}
+				checkResult(t, step.Expect, autoscaler.LastResult())
+			case "scaleTo":
+				require.NotNil(t, step.Capacity, "scaleTo step without capacity")
+				err := autoscaler.ScaleToCapacity(ctx, *step.Capacity)
+				if checkError(t, step.Expect, err) {
+					return
+				}
+				checkResult(t, step.Expect, autoscaler.LastResult())
+			default:
+				t.Fatalf("unknown action %q", step.Action)
+			}
+			if step.Readers != nil {
+				assert.Len(t, cluster.Readers(), *step.Readers, "readers after the step")
+			}
+			checkList(t, "notifications", step.Notified, notifier.sent)
+			for _, key := range step.Untagged {
+				assert.NotContains(t, cluster.ClusterTags(), key, "cluster tags after the step")
+			}
+		})
+		cluster.Step()
+	}
+}
+
+// scenarioNotifier records the scale-out and scale-in notifications of a step.
+type scenarioNotifier struct {
+	sent []string
+}
+
+func (n *scenarioNotifier) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	n.sent = append(n.sent, fmt.Sprintf("ScaleOut %d", replicasAdded))
+	return nil
+}
+
+func (n *scenarioNotifier) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	n.sent = append(n.sent, fmt.Sprintf("ScaleIn %d", replicasRemoved))
+	return nil
+}
+
+func (n *scenarioNotifier) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return nil
+}
+
+// newScenarioAutoscaler returns the autoscaler of a fake cluster with the settings of a scenario.
+func newScenarioAutoscaler(t *testing.T, cluster *fakes.Cluster, config scenarioConfig) *autoscaling.DocumentDB {
+	minCapacity, maxCapacity := autoscaling.DefaultMinCapacity, autoscaling.DefaultMaxCapacity
+	if config.MinCapacity != nil {
+		minCapacity = *config.MinCapacity
+	}
+	if config.MaxCapacity != nil {
+		maxCapacity = *config.MaxCapacity
+	}
+	options := []autoscaling.Option{
+		autoscaling.WithClients(cluster.DocDB(), cluster.CloudWatch(), cluster.RDS()),
+		autoscaling.WithCapacity(minCapacity, maxCapacity),
+		autoscaling.WithManagedCapacity(config.ManagedCapacity),
+		autoscaling.WithCooldowns(config.ScaleInCooldown, config.ScaleOutCooldown),
+		autoscaling.WithInstanceType(config.InstanceType),
+		autoscaling.WithAllowZeroReaders(config.AllowZeroReaders),
+		autoscaling.WithDryRun(config.DryRun),
+		autoscaling.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}
+	if config.ScheduleReplicas != nil {
+		options = append(options, autoscaling.WithScheduledScaling(*config.ScheduleReplicas), autoscaling.WithSchedulePhase(config.SchedulePhase))
+	} else if config.MetricName != "" {
+		options = append(options, autoscaling.WithMetric(config.MetricName, config.TargetValue))
+	}
+	autoscaler, err := autoscaling.New(cluster.ID, options...)
+	require.NoError(t, err)
+	autoscaler.EnforceCooldowns = config.EnforceCooldowns
+	autoscaler.ScaleInStabilization = config.Stabilization
+	autoscaler.CanaryScaleOut = config.CanaryScaleOut
+	autoscaler.CanaryWindow = config.CanaryWindow
+	autoscaler.ChurnBudgetHourly = config.ChurnBudget
+	autoscaler.CountableStatuses = config.Countable
+	autoscaler.WriterOnlyBootstrap = config.WriterBootstrap
+	autoscaler.BootstrapCapacity = config.Bootstrap
+	autoscaler.TrackDesiredCapacity = config.TrackDesired
+	if config.HolidayToday {
+		autoscaler.Holidays, err = schedule.NewCalendar("")
+		require.NoError(t, err)
+		require.NoError(t, autoscaler.Holidays.AddDate(time.Now().UTC().Format("2006-01-02"), "Scenario Day"))
+	}
+	return autoscaler
+}
+
+// checkError checks the error of a step against the expected one, and reports whether the step failed.
+func checkError(t *testing.T, expected expect, err error) bool {
+	if expected.Error == "" {
+		require.NoError(t, err)
+		return false
+	}
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), expected.Error)
+	return true
+}
+
+// checkPlan checks a plan against its expected decision, replicas, reason codes, constraints and explanation.
+func checkPlan(t *testing.T, expected expect, plan *autoscaling.ScalingPlan) {
+	if expected.Decision != "" {
+		assert.Equal(t, expected.Decision, plan.Decision, "decision")
+	}
+	checkInt(t, "replicas to add", expected.ReplicasToAdd, plan.ReplicasToAdd)
+	checkInt(t, "replicas to remove", expected.ReplicasToRemove, plan.ReplicasToRemove)
+	checkInt(t, "replicas deferred", expected.ReplicasDeferred, plan.ReplicasDeferred)
+	checkInt(t, "desired capacity", expected.DesiredCapacity, plan.DesiredCapacity)
+	checkInt(t, "baseline readers", expected.BaselineReaders, plan.BaselineReaders)
+	checkList(t, "reason codes", expected.ReasonCodes, plan.ReasonCodes)
+	checkList(t, "constraints", expected.Constraints, plan.Constraints)
+	checkExplanation(t, expected.Explanation, plan.Explanation)
+}
+
+// checkResult checks the result of a scaling action against its expected decision, replicas, reason codes,
+// constraints and explanation.
+func checkResult(t *testing.T, expected expect, result *autoscaling.ScalingResult) {
+	if expected.Decision != "" {
+		assert.Equal(t, expected.Decision, result.Decision, "decision")
+	}
+	checkReplicas(t, expected, result)
+	checkList(t, "reason codes", expected.ReasonCodes, result.ReasonCodes)
+	checkList(t, "constraints", expected.Constraints, result.Constraints)
+	checkExplanation(t, expected.Explanation, result.Explanation)
+}
+
+// checkExplanation checks the explanation of a decision, when expected.
+func checkExplanation(t *testing.T, expected, actual string) {
+	if expected != "" {
+		assert.Equal(t, expected, actual, "explanation")
+	}
+}
+
+// checkReplicas checks the replicas a scaling action added and removed.
+func checkReplicas(t *testing.T, expected expect, result *autoscaling.ScalingResult) {
+	checkInt(t, "replicas added", expected.ReplicasAdded, result.ReplicasAdded)
+	checkInt(t, "replicas removed", expected.ReplicasRemoved, result.ReplicasRemoved)
+}
+
+// checkInt checks a number, when expected.
+func checkInt(t *testing.T, name string, expected *int, actual int) {
+	if expected != nil {
+		assert.Equal(t, *expected, actual, name)
+	}
+}
+
+// checkList checks a list regardless of order, when expected, with an empty list expecting none.
+func checkList(t *testing.T, name string, expected, actual []string) {
+	if expected != nil {
+		assert.ElementsMatch(t, expected, actual, name)
+	}
+}
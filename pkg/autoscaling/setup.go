@@ -0,0 +1,304 @@
+package autoscaling
+
+import (
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/docdbelastic"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	awsPricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/cheelim1/docdb-autoscaler/pkg/idempotency"
+	"github.com/cheelim1/docdb-autoscaler/pkg/lock"
+	"github.com/cheelim1/docdb-autoscaler/pkg/metrics"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+	"github.com/cheelim1/docdb-autoscaler/pkg/ratelimit"
+)
+
+// NewFromConfig initializes the autoscaler of a resolved configuration, see config.Config.Resolve.
+// The cluster clients use the account and region of the cluster, while notifications are always
+// sent with cfg.
+func NewFromConfig(cfg aws.Config, settings *config.Config, logger *slog.Logger) (*DocumentDB, error) {
+	engine, err := LookupEngine(settings.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize AWS clients
+	clusterCfg := ClusterAWSConfig(cfg, settings)
+	var awsErrors *metrics.ErrorCounter
+	if settings.EMFMetrics {
+		// Count the failed calls of the cluster clients
+		awsErrors = &metrics.ErrorCounter{}
+		clusterCfg.APIOptions = append(slices.Clip(clusterCfg.APIOptions), awsErrors.APIOption)
+	}
+	var docdbClient DocDBAPI = docdb.NewFromConfig(clusterCfg)
+	cloudwatchClient := cloudwatch.NewFromConfig(clusterCfg)
+	snsClient := sns.NewFromConfig(cfg)
+	rdsClient := rds.NewFromConfig(clusterCfg)
+	if engine.RDSAPI {
+		// Aurora instances are managed through the RDS API
+		docdbClient = NewRDSInstanceClient(rdsClient)
+	}
+	if settings.AssumeRoleArn != "" || settings.Region != "" {
+		logger.Info("Using cluster account and region", "ClusterID", settings.ClusterID, "AssumeRoleArn", settings.AssumeRoleArn, "Region", clusterCfg.Region)
+	}
+
+	// Initialize notifier
+	notifier, err := NewNotifier(snsClient, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.InstanceType == "" {
+		logger.Info("INSTANCE_TYPE not set. Will use writer instance's type for scaling.")
+	} else {
+		logger.Info("INSTANCE_TYPE set", "InstanceType", settings.InstanceType)
+	}
+
+	options := []Option{
+		WithClients(docdbClient, cloudwatchClient, rdsClient),
+		WithEngine(engine),
+		WithCapacity(settings.MinCapacity, settings.MaxCapacity),
+		WithManagedCapacity(settings.ManagedCapacity),
+		WithInstanceType(settings.InstanceType),
+		WithDryRun(settings.DryRun || settings.ShadowMode),
+		WithAllowZeroReaders(settings.AllowZeroReaders),
+		WithNotifier(notifier),
+		WithLogger(logger),
+	}
+	// Metric settings only apply to metric-based scaling
+	if settings.ScheduledScaling {
+		options = append(options, WithScheduledScaling(settings.ScheduleNumberReplicas), WithSchedulePhase(settings.SchedulePhase))
+	} else {
+		options = append(options,
+			WithMetric(settings.MetricName, settings.TargetValue),
+			WithMetricTargets(settings.MetricTargets),
+			WithCooldowns(settings.ScaleInCooldown, settings.ScaleOutCooldown),
+		)
+	}
+	// The settings without an option are set below, and the autoscaler validated once they all are
+	docdbAutoscaler := newDocumentDB(settings.ClusterID, options...)
+	// Elastic clusters are detected automatically and scaled through the elastic clusters API
+	docdbAutoscaler.ElasticClient = docdbelastic.NewFromConfig(clusterCfg)
+	docdbAutoscaler.ElasticScaleDimension = settings.ElasticScaleDimension
+	docdbAutoscaler.EnforceCooldowns = settings.CooldownTags
+	docdbAutoscaler.BootstrapCapacity = settings.BootstrapCapacity
+	docdbAutoscaler.WriterOnlyBootstrap = settings.WriterOnlyBootstrap
+	docdbAutoscaler.TrackDesiredCapacity = settings.TrackDesiredCapacity
+	docdbAutoscaler.RemoveOrphans = settings.OrphanCleanup
+	docdbAutoscaler.ReplaceFailedReplicas = settings.ReplaceFailedReplicas
+	docdbAutoscaler.CheckInstanceQuota = settings.InstanceQuotaCheck
+	docdbAutoscaler.ManagedReplicaCap = settings.ManagedReplicaCap
+	docdbAutoscaler.AccountReplicaCap = settings.AccountReplicaCap
+	docdbAutoscaler.BulkScaleInLimit = settings.BulkScaleInLimit
+	docdbAutoscaler.AllowBulkScaleIn = settings.AllowBulkScaleIn
+	docdbAutoscaler.MaxHourlyCost = settings.MaxHourlyCost
+	docdbAutoscaler.CanaryScaleOut = settings.CanaryScaleOut
+	docdbAutoscaler.CanaryWindow = time.Duration(settings.CanaryWindow) * time.Second
+	docdbAutoscaler.ScaleInStabilization = time.Duration(settings.ScaleInStabilization) * time.Second
+	docdbAutoscaler.DrainConnections = settings.DrainConnections
+	docdbAutoscaler.DrainTimeout = time.Duration(settings.DrainTimeout) * time.Second
+	docdbAutoscaler.MaintenancePause = time.Duration(settings.MaintenancePause) * time.Second
+	docdbAutoscaler.CircuitThreshold = settings.CircuitThreshold
+	docdbAutoscaler.CircuitBackoff = time.Duration(settings.CircuitBackoff) * time.Second
+	docdbAutoscaler.ChurnBudgetHourly = settings.ChurnBudgetHourly
+	docdbAutoscaler.ChurnBudgetDaily = settings.ChurnBudgetDaily
+	docdbAutoscaler.DeadlineMargin = time.Duration(settings.DeadlineMargin) * time.Second
+	docdbAutoscaler.MetricConcurrency = settings.MetricConcurrency
+	docdbAutoscaler.CountableStatuses = settings.CountableStatuses
+	docdbAutoscaler.Region = clusterCfg.Region
+	if docdbAutoscaler.Holidays, err = settings.HolidayCalendar(); err != nil {
+		return nil, err
+	}
+	if settings.NotifyDedupWindow > 0 {
+		docdbAutoscaler.DedupNotifications(time.Duration(settings.NotifyDedupWindow) * time.Second)
+	}
+	// Quiet hours only defer scale-ins, which NOTIFY_MIN_SEVERITY warn or critical drops anyway
+	if settings.NotifyQuietHours != "" && (settings.NotifyMinSeverity == "" || settings.NotifyMinSeverity == notifications.SeverityInfo.String()) {
+		window, err := notifications.ParseQuietWindow(settings.NotifyQuietHours, settings.NotifyQuietTimezone)
+		if err != nil {
+			return nil, err
+		}
+		docdbAutoscaler.QuietNotifications(window)
+	}
+	if settings.PagerDutyRoutingKey != "" {
+		docdbAutoscaler.Incidents = notifications.NewPagerDuty(settings.PagerDutyRoutingKey)
+	}
+	if settings.TicketSystem != "" {
+		tickets, err := notifications.NewTicketNotifier(settings.TicketSystem, settings.TicketURL, settings.TicketUser, settings.TicketToken, settings.TicketProject)
+		if err != nil {
+			return nil, err
+		}
+		docdbAutoscaler.Tickets = tickets
+		docdbAutoscaler.TicketThreshold = settings.TicketThreshold
+	}
+	if settings.AuditS3URI != "" {
+		store, err := NewAuditStore(cfg, settings)
+		if err != nil {
+			return nil, err
+		}
+		docdbAutoscaler.Audit = store
+	}
+	if settings.PlanS3URI != "" {
+		plans, err := audit.NewS3Store(NewS3Client(cfg), settings.PlanS3URI)
+		if err != nil {
+			return nil, err
+		}
+		docdbAutoscaler.Plans = plans
+	}
+	namespace := settings.MetricsNamespace
+	if namespace == "" {
+		namespace = metrics.DefaultNamespace
+	}
+	var recorders metrics.Recorders
+	if settings.EMFMetrics {
+		recorders = append(recorders, metrics.NewEMF(os.Stdout, namespace))
+		docdbAutoscaler.AWSErrors = awsErrors
+	}
+	if settings.CapacityMetrics {
+		// Like notifications, metrics are published in the account and region of the autoscaler
+		recorders = append(recorders, metrics.NewCloudWatch(cloudwatch.NewFromConfig(cfg), namespace))
+		docdbAutoscaler.CountManagedReplicas = true
+	}
+	if settings.HeartbeatMetric {
+		recorders = append(recorders, metrics.NewHeartbeat(cloudwatch.NewFromConfig(cfg), namespace))
+	}
+	if settings.DatadogAPIKey != "" {
+		recorders = append(recorders, metrics.NewDatadog(settings.DatadogAPIKey, settings.DatadogSite))
+		docdbAutoscaler.CountManagedReplicas = true
+	}
+	if len(recorders) == 1 {
+		docdbAutoscaler.Metrics = recorders[0]
+	} else if len(recorders) > 1 {
+		docdbAutoscaler.Metrics = recorders
+	}
+	if settings.LockTable != "" {
+		// Like notifications, locks are kept in the account and region of the autoscaler
+		docdbAutoscaler.Lock = lock.NewDynamoDB(dynamodb.NewFromConfig(cfg), settings.LockTable, time.Duration(settings.LockLease)*time.Second)
+	}
+	if settings.PauseParameter != "" {
+		// Like locks, the pause switch of all the clusters is kept in the account and region of the autoscaler
+		docdbAutoscaler.SSMClient = ssm.NewFromConfig(cfg)
+		docdbAutoscaler.PauseParameter = settings.PauseParameter
+	}
+	if settings.CostEstimates || settings.MaxHourlyCost > 0 {
+		// The Price List API is only served from a few regions, and prices every region
+		docdbAutoscaler.Prices = pricing.Shared(awsPricing.NewFromConfig(cfg, func(o *awsPricing.Options) { o.Region = pricing.Region }))
+	}
+	if settings.EventBusName != "" {
+		// Like notifications, events are put in the account and region of the autoscaler
+		docdbAutoscaler.Events = notifications.NewEventBridge(eventbridge.NewFromConfig(cfg), settings.EventBusName)
+	}
+	if settings.ShadowMode {
+		// A shadow autoscaler runs side by side with the live one: it notifies no one, and only logs EMF
+		// metrics, with the dimension Mode, as its capacity metrics would mix with the live ones
+		docdbAutoscaler.Shadow = true
+		docdbAutoscaler.Notifier = notifications.Composite{}
+		docdbAutoscaler.Metrics = nil
+		docdbAutoscaler.CountManagedReplicas = false
+		if settings.EMFMetrics {
+			docdbAutoscaler.Metrics = metrics.NewEMF(os.Stdout, namespace)
+		}
+	}
+	if err := docdbAutoscaler.validate(); err != nil {
+		return nil, err
+	}
+	return docdbAutoscaler, nil
+}
+
+// NewNotifier returns the notifier of the configured channels: the SNS topic, and the Slack and
+// generic webhooks when set. Messages of the SNS topic and Slack follow NOTIFICATION_TEMPLATES, and
+// notifications below NOTIFY_MIN_SEVERITY are dropped.
+func NewNotifier(snsClient notifications.SNSAPI, settings *config.Config) (notifications.NotifierInterface, error) {
+	templates, err := notifications.ParseTemplates(settings.NotificationTemplates)
+	if err != nil {
+		return nil, err
+	}
+	notifier := notifications.NewNotifier(snsClient, settings.SNSTopicArn)
+	notifier.Templates = templates
+	notifier.EventTopicARNs = map[string]string{
+		notifications.EventScaleOut: settings.ScaleOutTopicArn,
+		notifications.EventScaleIn:  settings.ScaleInTopicArn,
+		notifications.EventFailure:  settings.FailureTopicArn,
+	}
+	if settings.SlackWebhookURL == "" && settings.NotificationWebhookURL == "" {
+		return withMinSeverity(notifier, settings)
+	}
+	composite := notifications.Composite{"sns": notifier}
+	if settings.SlackWebhookURL != "" {
+		slack := notifications.NewSlack(settings.SlackWebhookURL)
+		slack.Templates = templates
+		composite["slack"] = slack
+	}
+	if settings.NotificationWebhookURL != "" {
+		composite["webhook"] = notifications.NewWebhook(settings.NotificationWebhookURL)
+	}
+	return withMinSeverity(composite, settings)
+}
+
+// NewAuditStore returns the audit log of AUDIT_S3_URI. Like notifications, the audit log is kept
+// in the account of the autoscaler.
+func NewAuditStore(cfg aws.Config, settings *config.Config) (audit.Store, error) {
+	return audit.NewS3Store(NewS3Client(cfg), settings.AuditS3URI)
+}
+
+// NewIdempotencyStore returns the store of the processed SNS messages of IDEMPOTENCY_TABLE, kept in the
+// account of the autoscaler.
+func NewIdempotencyStore(cfg aws.Config, settings *config.Config) idempotency.Store {
+	return idempotency.NewDynamoDB(dynamodb.NewFromConfig(cfg), settings.IdempotencyTable, time.Duration(settings.IdempotencyTTL)*time.Second)
+}
+
+// withMinSeverity drops the notifications below NOTIFY_MIN_SEVERITY, if set.
+func withMinSeverity(notifier notifications.NotifierInterface, settings *config.Config) (notifications.NotifierInterface, error) {
+	if settings.NotifyMinSeverity == "" {
+		return notifier, nil
+	}
+	minSeverity, err := notifications.ParseSeverity(settings.NotifyMinSeverity)
+	if err != nil {
+		return nil, err
+	}
+	return &notifications.Filter{Notifier: notifier, MinSeverity: minSeverity}, nil
+}
+
+// ClusterAWSConfig returns the AWS configuration for the clients of a cluster, in the configured region
+// and with credentials of the configured role when the cluster lives in another account. The calls
+// are limited to APIRateLimit per second when set.
+func ClusterAWSConfig(cfg aws.Config, settings *config.Config) aws.Config {
+	if settings.AssumeRoleArn == "" && settings.Region == "" && settings.APIRateLimit <= 0 {
+		return cfg
+	}
+	clusterCfg := cfg.Copy()
+	if settings.APIRateLimit > 0 {
+		// The clusters of the process draw from the same bucket, as the throttles are per account
+		clusterCfg.APIOptions = append(slices.Clip(clusterCfg.APIOptions), ratelimit.Shared(settings.APIRateLimit).APIOption)
+	}
+	if settings.Region != "" {
+		clusterCfg.Region = settings.Region
+	}
+	if settings.AssumeRoleArn == "" {
+		return clusterCfg
+	}
+	// STS is called in the region of cfg
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), settings.AssumeRoleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "docdb-autoscaler"
+		if settings.AssumeRoleExternalID != "" {
+			o.ExternalID = aws.String(settings.AssumeRoleExternalID)
+		}
+	})
+	clusterCfg.Credentials = aws.NewCredentialsCache(provider)
+	return clusterCfg
+}
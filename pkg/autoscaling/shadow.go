@@ -0,0 +1,32 @@
+package autoscaling
+
+import (
+	"context"
+	"time"
+)
+
+// inShadowCooldown reports whether a shadow decision must wait for a cooldown. Shadow actions leave no
+// cluster tags, so the times of the last ones are read from the shadow records of the audit log, as if
+// they had been taken, and the shadow decisions follow the cooldowns of a live autoscaler. Without an
+// audit log, shadow decisions have no cooldowns.
+func (d *DocumentDB) inShadowCooldown(ctx context.Context, decision string, cooldown time.Duration) (bool, error) {
+	if d.Audit == nil {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	records, err := d.Audit.List(ctx, d.ClusterID, now.Add(-cooldown), now)
+	if err != nil {
+		d.Logger.Error("Failed to list audit records for shadow cooldowns", "Error", err)
+		return false, err
+	}
+	for _, record := range records {
+		if !record.Shadow || record.Error != "" {
+			continue
+		}
+		if record.Decision == DecisionScaleOut || (decision == DecisionScaleIn && record.Decision == DecisionScaleIn) {
+			d.Logger.Info("Shadow scaling action is in cooldown, skipping", "Decision", decision, "LastAction", record.Decision, "LastActionTime", record.Time, "Cooldown", cooldown, "ClusterID", d.ClusterID)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,238 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/audit"
+)
+
+// DefaultSimulationInterval is the interval of the evaluations of a simulation, as for a schedule of the
+// autoscaler every 5 minutes.
+const DefaultSimulationInterval = 5 * time.Minute
+
+// maxDatapointsPerCall is the most datapoints a single GetMetricStatistics call returns.
+const maxDatapointsPerCall = 1440
+
+// ErrNoSimulationTarget is returned when the cluster has no metric target to simulate, e.g. with
+// scheduled scaling.
+var ErrNoSimulationTarget = errors.New("simulations need METRIC_NAME and TARGET_VALUE")
+
+// SimulationStep is an evaluation of a simulation.
+type SimulationStep struct {
+	Time            time.Time `json:"Time"`
+	MetricValue     float64   `json:"MetricValue"`     // Average of the readers as recorded, with ActualCapacity readers
+	ActualCapacity  int       `json:"ActualCapacity"`  // Readers the cluster had
+	SimulatedMetric float64   `json:"SimulatedMetric"` // The recorded load spread over the simulated readers
+	DesiredCapacity int       `json:"DesiredCapacity"`
+	Capacity        int       `json:"Capacity"` // Simulated readers after the evaluation
+	Decision        string    `json:"Decision"`
+	Constraint      string    `json:"Constraint,omitempty"` // Cooldown, when it held the decision back
+}
+
+// Simulation is the capacity timeline the autoscaler would have produced over a period of recorded
+// metrics, for tuning TargetValue offline.
+type Simulation struct {
+	ClusterID         string           `json:"ClusterID"`
+	MetricName        string           `json:"MetricName"`
+	TargetValue       float64          `json:"TargetValue"`
+	Since             time.Time        `json:"Since"`
+	Until             time.Time        `json:"Until"`
+	Interval          int              `json:"Interval"` // In seconds
+	ScaleOuts         int              `json:"ScaleOuts"`
+	ScaleIns          int              `json:"ScaleIns"`
+	PeakCapacity      int              `json:"PeakCapacity"`
+	ReplicaHours      float64          `json:"ReplicaHours"`      // Simulated reader-hours
+	ActualHours       float64          `json:"ActualHours"`       // Reader-hours the cluster ran
+	AboveTargetSteps  int              `json:"AboveTargetSteps"`  // Evaluations whose simulated metric exceeded the target
+	MissingDatapoints int              `json:"MissingDatapoints"` // Intervals without a recorded metric, skipped
+	Steps             []SimulationStep `json:"Steps"`
+}
+
+// metricPoint is a recorded average of the readers of the cluster.
+type metricPoint struct {
+	time  time.Time
+	value float64
+}
+
+// Simulate replays the reader average of MetricName from since to until, every interval, through
+// CalculateDesiredCapacity and the cooldowns, without acting. The recorded load is assumed to scale with
+// the readers: a metric recorded with ActualCapacity readers is spread over the simulated ones. The actual
+// readers are read from the capacity of the audit log when set, and are the current readers otherwise.
+// Like the autoscaler, scale-ins remove one reader at a time, and new readers serve from the next
+// evaluation.
+func (d *DocumentDB) Simulate(ctx context.Context, since, until time.Time, interval time.Duration) (*Simulation, error) {
+	if d.MetricName == "" || d.TargetValue <= 0 {
+		return nil, ErrNoSimulationTarget
+	}
+	if interval <= 0 {
+		interval = DefaultSimulationInterval
+	}
+	if interval%time.Minute != 0 {
+		return nil, fmt.Errorf("the interval of a simulation must be a whole number of minutes, got %s", interval)
+	}
+	if !until.After(since) {
+		return nil, fmt.Errorf("the simulation period must end after it starts, got %s to %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	points, err := d.readerMetricHistory(ctx, since, until, interval)
+	if err != nil {
+		return nil, err
+	}
+	currentCapacity, err := d.GetCurrentCapacity(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to retrieve current capacity", "Error", err)
+		return nil, err
+	}
+	actualCapacity := func(time.Time) int { return currentCapacity }
+	if d.Audit != nil {
+		// The records of the week before hold the readers at the start of the period
+		records, err := d.Audit.List(ctx, d.ClusterID, since.Add(-7*24*time.Hour), until)
+		if err != nil {
+			d.Logger.Error("Failed to list audit records for the simulation", "Error", err)
+			return nil, err
+		}
+		actualCapacity = capacityTimeline(records, currentCapacity)
+	}
+	return d.simulate(since, until, interval, points, actualCapacity), nil
+}
+
+// simulate runs the evaluations of a simulation over the recorded points, oldest first.
+func (d *DocumentDB) simulate(since, until time.Time, interval time.Duration, points []metricPoint, actualCapacity func(time.Time) int) *Simulation {
+	simulation := &Simulation{
+		ClusterID:   d.ClusterID,
+		MetricName:  d.MetricName,
+		TargetValue: d.TargetValue,
+		Since:       since,
+		Until:       until,
+		Interval:    int(interval.Seconds()),
+		Steps:       []SimulationStep{},
+	}
+	byTime := make(map[int64]float64, len(points))
+	for _, point := range points {
+		byTime[point.time.Truncate(interval).Unix()] = point.value
+	}
+
+	capacity := -1
+	var lastScaleOut, lastScaleIn time.Time
+	for t := since.Truncate(interval); t.Before(until); t = t.Add(interval) {
+		value, found := byTime[t.Unix()]
+		if !found {
+			simulation.MissingDatapoints++
+			continue
+		}
+		actual := actualCapacity(t)
+		if capacity < 0 {
+			// The simulation starts from the readers the cluster had
+			capacity = d.clampCapacity(actual)
+		}
+		step := SimulationStep{Time: t, MetricValue: value, ActualCapacity: actual, Decision: DecisionNoAction}
+		// The load of the actual readers spread over the simulated ones
+		step.SimulatedMetric = value
+		if capacity > 0 && actual > 0 {
+			step.SimulatedMetric = value * float64(actual) / float64(capacity)
+		}
+		step.DesiredCapacity = d.CalculateDesiredCapacity(step.SimulatedMetric, capacity)
+		if step.SimulatedMetric > d.TargetValue {
+			simulation.AboveTargetSteps++
+		}
+
+		switch {
+		case step.DesiredCapacity > capacity:
+			if d.simulatedCooldown(t, lastScaleOut, d.ScaleOutCooldown) {
+				step.Constraint = ConstraintCooldown
+				break
+			}
+			step.Decision = DecisionScaleOut
+			capacity = step.DesiredCapacity
+			lastScaleOut = t
+			simulation.ScaleOuts++
+		case step.DesiredCapacity < capacity:
+			if d.simulatedCooldown(t, lastScaleOut, d.ScaleInCooldown) || d.simulatedCooldown(t, lastScaleIn, d.ScaleInCooldown) {
+				step.Constraint = ConstraintCooldown
+				break
+			}
+			step.Decision = DecisionScaleIn
+			capacity--
+			lastScaleIn = t
+			simulation.ScaleIns++
+		}
+		step.Capacity = capacity
+		simulation.PeakCapacity = max(simulation.PeakCapacity, capacity)
+		simulation.ReplicaHours += interval.Hours() * float64(capacity)
+		simulation.ActualHours += interval.Hours() * float64(actual)
+		simulation.Steps = append(simulation.Steps, step)
+	}
+	return simulation
+}
+
+// simulatedCooldown reports whether an action at t is in the cooldown of seconds after lastAction, when
+// the autoscaler enforces cooldowns.
+func (d *DocumentDB) simulatedCooldown(t, lastAction time.Time, seconds int) bool {
+	if !d.EnforceCooldowns || seconds <= 0 || lastAction.IsZero() {
+		return false
+	}
+	return t.Sub(lastAction) < time.Duration(seconds)*time.Second
+}
+
+// readerMetricHistory returns the average of MetricName across the readers of the cluster from since
+// to until, every interval, oldest first, from the metrics of the cluster by role.
+func (d *DocumentDB) readerMetricHistory(ctx context.Context, since, until time.Time, interval time.Duration) ([]metricPoint, error) {
+	var points []metricPoint
+	window := interval * maxDatapointsPerCall
+	for start := since; start.Before(until); start = start.Add(window) {
+		end := start.Add(window)
+		if end.After(until) {
+			end = until
+		}
+		output, err := d.CloudWatchClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+			Namespace:  aws.String(d.engine().MetricNamespace),
+			MetricName: aws.String(d.MetricName),
+			Dimensions: []cwTypes.Dimension{
+				{Name: aws.String("DBClusterIdentifier"), Value: aws.String(d.ClusterID)},
+				{Name: aws.String("Role"), Value: aws.String("READER")},
+			},
+			StartTime:  aws.Time(start),
+			EndTime:    aws.Time(end),
+			Period:     aws.Int32(int32(interval.Seconds())),
+			Statistics: []cwTypes.Statistic{cwTypes.StatisticAverage},
+		})
+		if err != nil {
+			d.Logger.Error("Failed to get metric statistics", "Error", err, "ClusterID", d.ClusterID)
+			return nil, err
+		}
+		for _, datapoint := range output.Datapoints {
+			points = append(points, metricPoint{time: aws.ToTime(datapoint.Timestamp), value: aws.ToFloat64(datapoint.Average)})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].time.Before(points[j].time) })
+	return points, nil
+}
+
+// capacityTimeline returns the readers of the cluster at a time from the capacity of the audit records,
+// oldest first: that of the last record before the time, or of the first record before any, and
+// currentCapacity without records.
+func capacityTimeline(records []audit.Record, currentCapacity int) func(time.Time) int {
+	var known []audit.Record
+	for _, record := range records {
+		if record.Capacity != nil {
+			known = append(known, record)
+		}
+	}
+	return func(t time.Time) int {
+		if len(known) == 0 {
+			return currentCapacity
+		}
+		i := sort.Search(len(known), func(i int) bool { return known[i].Time.After(t) })
+		if i == 0 {
+			return *known[0].Capacity
+		}
+		return *known[i-1].Capacity
+	}
+}
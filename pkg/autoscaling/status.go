@@ -0,0 +1,282 @@
+package autoscaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	elasticTypes "github.com/aws/aws-sdk-go-v2/service/docdbelastic/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// pausedTagKey marks a cluster on which the autoscaler must not take any action.
+const pausedTagKey = "docdb-autoscaler-paused"
+
+// ReaderStatus describes a single reader instance.
+type ReaderStatus struct {
+	InstanceID       string `json:"InstanceID"`
+	InstanceClass    string `json:"InstanceClass"`
+	AvailabilityZone string `json:"AvailabilityZone"`
+	Status           string `json:"Status"`
+	ManagedBy        string `json:"ManagedBy"` // "autoscaler", "scheduler" or empty for unmanaged readers
+}
+
+// ClusterStatus describes the reader topology of the cluster and the autoscaler state.
+type ClusterStatus struct {
+	ClusterID        string         `json:"ClusterID"`
+	WriterInstanceID string         `json:"WriterInstanceID"`
+	CurrentCapacity  int            `json:"CurrentCapacity"`
+	MinCapacity      int            `json:"MinCapacity"`
+	MaxCapacity      int            `json:"MaxCapacity"`
+	Paused           bool           `json:"Paused"`
+	GlobalRole       string         `json:"GlobalRole,omitempty"`       // "primary" or "secondary" for members of a global cluster
+	ElasticDimension string         `json:"ElasticDimension,omitempty"` // For elastic clusters, what CurrentCapacity counts
+	Readers          []ReaderStatus `json:"Readers"`
+}
+
+// describeCluster retrieves the cluster details, including its ARN and tags. During an action, the
+// cluster is described once and read from the topology snapshot afterwards.
+func (d *DocumentDB) describeCluster(ctx context.Context) (*rdsTypes.DBCluster, error) {
+	if d.snapshot != nil && d.snapshot.cluster != nil {
+		return d.snapshot.cluster, nil
+	}
+	describeClustersInput := &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(d.ClusterID),
+	}
+	dbClustersOutput, err := d.RDSClient.DescribeDBClusters(ctx, describeClustersInput)
+	if err != nil {
+		// A cluster not found is expected of elastic clusters, and reported by the callers otherwise
+		var notFound *rdsTypes.DBClusterNotFoundFault
+		if !errors.As(err, &notFound) {
+			d.Logger.Error("Failed to describe DB clusters", "Error", err)
+		}
+		return nil, err
+	}
+	if len(dbClustersOutput.DBClusters) == 0 {
+		return nil, fmt.Errorf("no clusters found with identifier %s", d.ClusterID)
+	}
+	if d.snapshot != nil {
+		d.snapshot.cluster = &dbClustersOutput.DBClusters[0]
+	}
+	return &dbClustersOutput.DBClusters[0], nil
+}
+
+// IsPaused checks if the cluster carries the paused tag, or the pause parameter is on.
+func (d *DocumentDB) IsPaused(ctx context.Context) (bool, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return false, err
+	}
+	return d.pauseSwitch(ctx, hasTrueTag(dbCluster, pausedTagKey)) != "", nil
+}
+
+// hasTrueTag checks if the cluster details carry the tag with the value "true".
+func hasTrueTag(dbCluster *rdsTypes.DBCluster, key string) bool {
+	for _, tag := range dbCluster.TagList {
+		if aws.ToString(tag.Key) == key && aws.ToString(tag.Value) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// skipIfPaused reports whether the current scaling action must be skipped because the cluster is paused,
+// by the paused tag or the pause parameter, or during a maintenance.
+func (d *DocumentDB) skipIfPaused(ctx context.Context) (bool, error) {
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		d.Logger.Error("Failed to check if autoscaling is paused", "Error", err)
+		return false, err
+	}
+	if pauseSwitch := d.pauseSwitch(ctx, hasTrueTag(dbCluster, pausedTagKey)); pauseSwitch != "" {
+		d.skipForPauseSwitch(ctx, pauseSwitch)
+		return true, nil
+	}
+	if sourceID, paused := d.inMaintenance(dbCluster); paused {
+		d.Logger.Warn("Autoscaling is paused during maintenance, skipping scaling action", "SourceID", sourceID, "ClusterID", d.ClusterID)
+		d.recordDecision(DecisionPaused)
+		d.recordReason(ReasonMaintenance)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Pause tags the cluster so that subsequent invocations take no scaling action.
+func (d *DocumentDB) Pause(ctx context.Context) error {
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
+		if err != nil {
+			return err
+		}
+		if err := d.setElasticClusterPaused(ctx, cluster, true); err != nil {
+			return err
+		}
+		d.Logger.Info("Paused autoscaling", "ClusterID", d.ClusterID)
+		return nil
+	}
+
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	tagInput := &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags: []docdbTypes.Tag{
+			{
+				Key:   aws.String(pausedTagKey),
+				Value: aws.String("true"),
+			},
+		},
+	}
+	if _, err := d.DocDBClient.AddTagsToResource(ctx, tagInput); err != nil {
+		d.Logger.Error("Failed to tag cluster as paused", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	d.Logger.Info("Paused autoscaling", "ClusterID", d.ClusterID)
+	return nil
+}
+
+// Resume removes the paused tag from the cluster. A pause parameter that is on keeps autoscaling paused.
+func (d *DocumentDB) Resume(ctx context.Context) error {
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
+		if err != nil {
+			return err
+		}
+		if err := d.setElasticClusterPaused(ctx, cluster, false); err != nil {
+			return err
+		}
+		d.Logger.Info("Resumed autoscaling", "ClusterID", d.ClusterID)
+		return nil
+	}
+
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	untagInput := &docdb.RemoveTagsFromResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		TagKeys:      []string{pausedTagKey},
+	}
+	if _, err := d.DocDBClient.RemoveTagsFromResource(ctx, untagInput); err != nil {
+		d.Logger.Error("Failed to remove paused tag from cluster", "Error", err, "ClusterID", d.ClusterID)
+		return err
+	}
+	d.Logger.Info("Resumed autoscaling", "ClusterID", d.ClusterID)
+	return nil
+}
+
+// Status reports the current reader topology and autoscaler state.
+func (d *DocumentDB) Status(ctx context.Context) (*ClusterStatus, error) {
+	if cluster, err := d.getElasticCluster(ctx); err != nil || cluster != nil {
+		if err != nil {
+			return nil, err
+		}
+		return d.elasticStatus(ctx, cluster)
+	}
+
+	writerInstanceIdentifier, err := d.GetWriterInstanceIdentifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paused := d.pauseSwitch(ctx, hasTrueTag(dbCluster, pausedTagKey)) != ""
+	globalRole, err := d.GetGlobalClusterRole(ctx, dbCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ClusterStatus{
+		ClusterID:        d.ClusterID,
+		WriterInstanceID: writerInstanceIdentifier,
+		CurrentCapacity:  len(readerInstances),
+		MinCapacity:      d.MinCapacity,
+		MaxCapacity:      d.MaxCapacity,
+		Paused:           paused,
+		GlobalRole:       globalRole,
+		Readers:          []ReaderStatus{},
+	}
+	for _, instance := range readerInstances {
+		managedBy, err := d.managedBy(ctx, instance)
+		if err != nil {
+			return nil, err
+		}
+		status.Readers = append(status.Readers, ReaderStatus{
+			InstanceID:       aws.ToString(instance.DBInstanceIdentifier),
+			InstanceClass:    aws.ToString(instance.DBInstanceClass),
+			AvailabilityZone: aws.ToString(instance.AvailabilityZone),
+			Status:           aws.ToString(instance.DBInstanceStatus),
+			ManagedBy:        managedBy,
+		})
+	}
+	return status, nil
+}
+
+// elasticStatus reports the capacity of an elastic cluster, which has no reader instances.
+func (d *DocumentDB) elasticStatus(ctx context.Context, cluster *elasticTypes.Cluster) (*ClusterStatus, error) {
+	paused, err := d.isElasticClusterPaused(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterStatus{
+		ClusterID:        d.ClusterID,
+		CurrentCapacity:  d.elasticCapacity(cluster),
+		MinCapacity:      d.MinCapacity,
+		MaxCapacity:      d.MaxCapacity,
+		Paused:           d.pauseSwitch(ctx, paused) != "",
+		ElasticDimension: d.elasticScaleDimension(),
+		Readers:          []ReaderStatus{},
+	}, nil
+}
+
+// managedBy reports which scaling policy created the instance, if any.
+func (d *DocumentDB) managedBy(ctx context.Context, instance docdbTypes.DBInstance) (string, error) {
+	hasAutoscalerTag, err := d.HasAutoscalerTag(ctx, instance)
+	if err != nil {
+		return "", err
+	}
+	if hasAutoscalerTag {
+		return "autoscaler", nil
+	}
+	hasSchedulerTag, err := d.HasSchedulerTag(ctx, instance)
+	if err != nil {
+		return "", err
+	}
+	if hasSchedulerTag {
+		return "scheduler", nil
+	}
+	return "", nil
+}
+
+// ListClusterIdentifiers returns the identifiers of the clusters of the engine in the account and region of the client.
+func ListClusterIdentifiers(ctx context.Context, rdsClient RDSAPI, engine Engine) ([]string, error) {
+	var clusterIDs []string
+	describeClustersInput := &rds.DescribeDBClustersInput{
+		Filters: []rdsTypes.Filter{
+			{
+				Name:   aws.String("engine"),
+				Values: []string{engine.Name},
+			},
+		},
+	}
+	paginator := rds.NewDescribeDBClustersPaginator(rdsClient, describeClustersInput)
+	for paginator.HasMorePages() {
+		dbClustersOutput, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, dbCluster := range dbClustersOutput.DBClusters {
+			clusterIDs = append(clusterIDs, aws.ToString(dbCluster.DBClusterIdentifier))
+		}
+	}
+	return clusterIDs, nil
+}
@@ -0,0 +1,139 @@
+package autoscaling
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+)
+
+const (
+	// failuresTagKey counts the consecutive failed invocations of the cluster.
+	failuresTagKey = "docdb-autoscaler-failures"
+	// failureTagPrefix prefixes the tags holding the recent errors, e.g.
+	// docdb-autoscaler-failure-1 = "<unix time> <error>".
+	failureTagPrefix = "docdb-autoscaler-failure-"
+	// ticketTagKey records when the ticket of the current failure streak was opened.
+	ticketTagKey = "docdb-autoscaler-ticket"
+
+	// maxFailureHistory is the number of recent errors kept for the ticket.
+	maxFailureHistory = 10
+	// maxTagValue is the longest tag value accepted by the cluster.
+	maxTagValue = 256
+)
+
+// invalidTagValueChars are the characters not allowed in tag values.
+var invalidTagValueChars = regexp.MustCompile(`[^\p{L}\p{Z}\p{N}_.:/=+\-@]+`)
+
+// failure is a failed invocation in the error history of the cluster.
+type failure struct {
+	At    time.Time
+	Error string
+}
+
+// trackFailures counts the consecutive failed invocations of the cluster in its tags, opens a ticket
+// with the error history once TicketThreshold is reached, and opens the circuit once CircuitThreshold
+// is reached. The streak ends, the circuit closes and the next streak may open a new ticket, when an
+// action succeeds. Actions skipped by the open circuit neither succeed nor fail.
+func (d *DocumentDB) trackFailures(ctx context.Context, actionErr error) error {
+	if slices.Contains(d.LastResult().ReasonCodes, ReasonCircuitOpen) {
+		return nil
+	}
+	dbCluster, err := d.describeCluster(ctx)
+	if err != nil {
+		return err
+	}
+	tags := map[string]string{}
+	for _, tag := range dbCluster.TagList {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	failures, _ := strconv.Atoi(tags[failuresTagKey])
+
+	if actionErr == nil {
+		if failures == 0 {
+			return nil
+		}
+		keys := []string{failuresTagKey, ticketTagKey, circuitTagKey}
+		for i := 1; i <= maxFailureHistory; i++ {
+			keys = append(keys, failureTagPrefix+strconv.Itoa(i))
+		}
+		_, err := d.DocDBClient.RemoveTagsFromResource(ctx, &docdb.RemoveTagsFromResourceInput{
+			ResourceName: dbCluster.DBClusterArn,
+			TagKeys:      keys,
+		})
+		return err
+	}
+
+	failures++
+	now := time.Now()
+	errorValue := invalidTagValueChars.ReplaceAllString(actionErr.Error(), " ")
+	errorValue = fmt.Sprintf("%d %s", now.Unix(), errorValue)
+	if len(errorValue) > maxTagValue {
+		errorValue = errorValue[:maxTagValue]
+	}
+	// The history is a ring of the most recent errors
+	historyKey := failureTagPrefix + strconv.Itoa((failures-1)%maxFailureHistory+1)
+	tags[historyKey] = errorValue
+	newTags := []docdbTypes.Tag{
+		{Key: aws.String(failuresTagKey), Value: aws.String(strconv.Itoa(failures))},
+		{Key: aws.String(historyKey), Value: aws.String(errorValue)},
+	}
+
+	if d.Tickets != nil && failures >= d.TicketThreshold && tags[ticketTagKey] == "" {
+		summary := fmt.Sprintf("DocumentDB autoscaler failing on cluster %s", d.ClusterID)
+		if err := d.Tickets.OpenTicket(ctx, d.ClusterID, summary, describeFailures(d.ClusterID, failures, failureHistory(tags))); err != nil {
+			d.Logger.Error("Failed to open ticket", "Error", err, "ConsecutiveFailures", failures)
+		} else {
+			d.Logger.Info("Opened ticket for repeated failures", "ClusterID", d.ClusterID, "ConsecutiveFailures", failures)
+			newTags = append(newTags, docdbTypes.Tag{Key: aws.String(ticketTagKey), Value: aws.String(strconv.FormatInt(now.Unix(), 10))})
+		}
+	}
+
+	if d.circuitEnabled() && failures >= d.CircuitThreshold {
+		newTags = append(newTags, d.openCircuit(ctx, failures, now))
+	}
+
+	_, err = d.DocDBClient.AddTagsToResource(ctx, &docdb.AddTagsToResourceInput{
+		ResourceName: dbCluster.DBClusterArn,
+		Tags:         newTags,
+	})
+	return err
+}
+
+// failureHistory returns the errors of the history tags, oldest first.
+func failureHistory(tags map[string]string) []failure {
+	var history []failure
+	for i := 1; i <= maxFailureHistory; i++ {
+		value, found := tags[failureTagPrefix+strconv.Itoa(i)]
+		if !found {
+			continue
+		}
+		at, message, _ := strings.Cut(value, " ")
+		unix, err := strconv.ParseInt(at, 10, 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, failure{At: time.Unix(unix, 0).UTC(), Error: message})
+	}
+	// Ring positions are not in time order once the history wrapped around
+	sort.SliceStable(history, func(i, j int) bool { return history[i].At.Before(history[j].At) })
+	return history
+}
+
+// describeFailures returns the description of the ticket of a failure streak.
+func describeFailures(clusterID string, failures int, history []failure) string {
+	var description strings.Builder
+	fmt.Fprintf(&description, "Scaling of cluster %s has failed on %d consecutive invocations.\n\nRecent errors:\n", clusterID, failures)
+	for _, f := range history {
+		fmt.Fprintf(&description, "- %s: %s\n", f.At.Format(time.RFC3339), f.Error)
+	}
+	return description.String()
+}
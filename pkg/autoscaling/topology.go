@@ -0,0 +1,73 @@
+package autoscaling
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
+	elasticTypes "github.com/aws/aws-sdk-go-v2/service/docdbelastic/types"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+)
+
+// topologySnapshot holds the descriptions of the cluster and its instances for the duration of an action,
+// so that its steps share one view of the topology instead of each describing the cluster again.
+type topologySnapshot struct {
+	cluster         *rdsTypes.DBCluster
+	instances       []docdbTypes.DBInstance
+	instancesLoaded bool
+	elasticCluster  *elasticTypes.Cluster
+}
+
+// beginSnapshot starts the topology snapshot of an action. The cluster is described on first use.
+func (d *DocumentDB) beginSnapshot() {
+	d.snapshot = &topologySnapshot{}
+}
+
+// endSnapshot ends the topology snapshot of an action, so that later calls describe the cluster again.
+func (d *DocumentDB) endSnapshot() {
+	d.snapshot = nil
+}
+
+// invalidateSnapshot drops the topology snapshot after the action changed the cluster, e.g. added a
+// replica or a tag, so that the next lookup describes the cluster again.
+func (d *DocumentDB) invalidateSnapshot() {
+	if d.snapshot != nil {
+		*d.snapshot = topologySnapshot{}
+	}
+}
+
+// captureTopology returns the reader topology of the cluster, when the notifier reports it.
+// Failures are logged only, so that notifications are still sent without the topology.
+func (d *DocumentDB) captureTopology(ctx context.Context) []notifications.Instance {
+	if _, ok := d.Notifier.(notifications.TopologyNotifier); !ok {
+		return nil
+	}
+	readerInstances, err := d.GetReaderInstances(ctx)
+	if err != nil {
+		d.Logger.Warn("Failed to capture reader topology for notification", "Error", err)
+		return nil
+	}
+	instances := make([]notifications.Instance, 0, len(readerInstances))
+	for _, instance := range readerInstances {
+		instances = append(instances, notifications.Instance{
+			ID:               aws.ToString(instance.DBInstanceIdentifier),
+			Class:            aws.ToString(instance.DBInstanceClass),
+			AvailabilityZone: aws.ToString(instance.AvailabilityZone),
+			Status:           aws.ToString(instance.DBInstanceStatus),
+		})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances
+}
+
+// notifierWithTopology returns the notifier reporting the reader topology from before the action
+// and the current one, with the explanation of the action, when it supports it.
+func (d *DocumentDB) notifierWithTopology(ctx context.Context, before []notifications.Instance) notifications.NotifierInterface {
+	topologyNotifier, ok := d.Notifier.(notifications.TopologyNotifier)
+	if !ok {
+		return d.Notifier
+	}
+	return topologyNotifier.WithTopology(&notifications.Topology{Before: before, After: d.captureTopology(ctx), Cost: d.costDelta(ctx), Explanation: d.LastResult().Explanation})
+}
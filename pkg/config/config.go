@@ -0,0 +1,566 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"gopkg.in/yaml.v3"
+)
+
+// S3API defines the interface for Amazon S3 interactions.
+type S3API interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Config holds the autoscaler settings. Values are read from an optional YAML/JSON config file,
+// and environment variables that are set take precedence over the file.
+type Config struct {
+	SNSTopicArn            string             `json:"snsTopicArn" yaml:"snsTopicArn"`
+	ScaleOutTopicArn       string             `json:"scaleOutTopicArn" yaml:"scaleOutTopicArn"` // Replaces SNSTopicArn for scale-out notifications
+	ScaleInTopicArn        string             `json:"scaleInTopicArn" yaml:"scaleInTopicArn"`   // Replaces SNSTopicArn for scale-in notifications
+	FailureTopicArn        string             `json:"failureTopicArn" yaml:"failureTopicArn"`   // Replaces SNSTopicArn for failure notifications
+	EventBusName           string             `json:"eventBusName" yaml:"eventBusName"`         // Optional EventBridge bus of lifecycle events
+	AuditS3URI             string             `json:"auditS3Uri" yaml:"auditS3Uri"`             // Optional s3://bucket/prefix of the audit log
+	PlanS3URI              string             `json:"planS3Uri" yaml:"planS3Uri"`               // Optional s3://bucket/prefix of the plans of dry-run invocations
+	ReportS3URI            string             `json:"reportS3Uri" yaml:"reportS3Uri"`           // Optional s3://bucket/prefix of the savings reports
+	EMFMetrics             bool               `json:"emfMetrics" yaml:"emfMetrics"`             // Log metrics of every invocation in the CloudWatch embedded metric format
+	CapacityMetrics        bool               `json:"capacityMetrics" yaml:"capacityMetrics"`   // Publish the capacity of the cluster after every invocation as custom CloudWatch metrics
+	HeartbeatMetric        bool               `json:"heartbeatMetric" yaml:"heartbeatMetric"`   // Publish a Heartbeat custom CloudWatch metric on every evaluation of a cluster
+	MetricsNamespace       string             `json:"metricsNamespace" yaml:"metricsNamespace"` // CloudWatch namespace of the autoscaler metrics, DocDBAutoscaler when empty
+	ClusterID              string             `json:"clusterIdentifier" yaml:"clusterIdentifier"`
+	Engine                 string             `json:"engine" yaml:"engine"` // "docdb" (default), "neptune", "aurora-mysql" or "aurora-postgresql"
+	MinCapacity            int                `json:"minCapacity" yaml:"minCapacity"`
+	MaxCapacity            int                `json:"maxCapacity" yaml:"maxCapacity"`
+	ManagedCapacity        bool               `json:"managedCapacity" yaml:"managedCapacity"`     // MinCapacity and MaxCapacity bound the replicas of the autoscaler, on top of the baseline readers
+	BootstrapCapacity      int                `json:"bootstrapCapacity" yaml:"bootstrapCapacity"` // Readers a metric-based scale-out brings a cluster without readers to, MinCapacity (at least 1) when 0
+	ManagedReplicaCap      int                `json:"managedReplicaCap" yaml:"managedReplicaCap"` // Hard cap on the replicas of the autoscaler in the cluster, 0 disables
+	AccountReplicaCap      int                `json:"accountReplicaCap" yaml:"accountReplicaCap"` // Hard cap on the replicas of the autoscaler in the account, 0 disables
+	ScheduledScaling       bool               `json:"scheduledScaling" yaml:"scheduledScaling"`
+	ScheduleNumberReplicas int                `json:"scheduleNumberReplicas" yaml:"scheduleNumberReplicas"`
+	SchedulePhase          string             `json:"schedulePhase" yaml:"schedulePhase"`                   // ScaleOut or ScaleIn, inferred from the scheduled replicas when empty
+	ScheduleConflictPolicy string             `json:"scheduleConflictPolicy" yaml:"scheduleConflictPolicy"` // MaxReplicas (default) or Priority, for overlapping scaling windows
+	MetricName             string             `json:"metricName" yaml:"metricName"`
+	TargetValue            float64            `json:"targetValue" yaml:"targetValue"`
+	MetricTargets          map[string]float64 `json:"metricTargets" yaml:"metricTargets"`
+	MetricConcurrency      int                `json:"metricConcurrency" yaml:"metricConcurrency"` // Readers whose metric is fetched at the same time, 10 when 0
+	CountableStatuses      []string           `json:"countableStatuses" yaml:"countableStatuses"` // Statuses of the readers counted in the capacity and the metric average, every status when empty
+	ScaleInCooldown        int                `json:"scaleInCooldown" yaml:"scaleInCooldown"`
+	ScaleOutCooldown       int                `json:"scaleOutCooldown" yaml:"scaleOutCooldown"`
+	CooldownTags           bool               `json:"cooldownTags" yaml:"cooldownTags"`                   // Enforce the cooldowns, keeping the time of the last actions in cluster tags
+	ScaleInStabilization   int                `json:"scaleInStabilization" yaml:"scaleInStabilization"`   // In seconds, how long metric-based scale-ins are held after any scale-out, 0 disables
+	TrackDesiredCapacity   bool               `json:"trackDesiredCapacity" yaml:"trackDesiredCapacity"`   // Keep the desired capacity in a cluster tag and restore missing readers
+	OrphanCleanup          bool               `json:"orphanCleanup" yaml:"orphanCleanup"`                 // Remove the replicas of the autoscaler left failed or beyond MaxCapacity
+	ReplaceFailedReplicas  bool               `json:"replaceFailedReplicas" yaml:"replaceFailedReplicas"` // Remove and recreate the failed replicas of the autoscaler
+	InstanceQuotaCheck     bool               `json:"instanceQuotaCheck" yaml:"instanceQuotaCheck"`       // Cap scale-outs to the room left in the DB instances quota of the account
+	BulkScaleInLimit       int                `json:"bulkScaleInLimit" yaml:"bulkScaleInLimit"`           // Replicas a scale-in may remove without approval, 0 disables
+	AllowBulkScaleIn       bool               `json:"allowBulkScaleIn" yaml:"allowBulkScaleIn"`           // Approve scale-ins beyond BulkScaleInLimit
+	MaxHourlyCost          float64            `json:"maxHourlyCost" yaml:"maxHourlyCost"`                 // Budget of the estimated hourly cost of the readers in USD, 0 disables
+	CanaryScaleOut         bool               `json:"canaryScaleOut" yaml:"canaryScaleOut"`               // Add a canary replica first on metric-based scale-outs
+	CanaryWindow           int                `json:"canaryWindow" yaml:"canaryWindow"`                   // In seconds, how long the canary serves before the metric is evaluated again, 300 when 0
+	DrainConnections       float64            `json:"drainConnections" yaml:"drainConnections"`           // DatabaseConnections of a replica below which scale-ins delete it, 0 disables
+	DrainTimeout           int                `json:"drainTimeout" yaml:"drainTimeout"`                   // In seconds, how long a scale-in waits for a replica to drain
+	MaintenancePause       int                `json:"maintenancePause" yaml:"maintenancePause"`           // In seconds, how long a maintenance pauses autoscaling at most, 7200 when 0
+	MaxRetries             int                `json:"maxRetries" yaml:"maxRetries"`
+	InitialBackoff         int                `json:"initialBackoff" yaml:"initialBackoff"` // In seconds
+	DeadlineMargin         int                `json:"deadlineMargin" yaml:"deadlineMargin"` // In seconds, 10 when 0
+	APIRateLimit           float64            `json:"apiRateLimit" yaml:"apiRateLimit"`     // AWS API calls per second of the cluster clients, 0 disables
+	DryRun                 bool               `json:"dryRun" yaml:"dryRun"`
+	ShadowMode             bool               `json:"shadowMode" yaml:"shadowMode"` // Run the full pipeline without changes or notifications, recording shadow decisions
+	AllowZeroReaders       bool               `json:"allowZeroReaders" yaml:"allowZeroReaders"`
+	WriterOnlyBootstrap    bool               `json:"writerOnlyBootstrap" yaml:"writerOnlyBootstrap"` // Scale a cluster without readers on the metric of its writer
+	InstanceType           string             `json:"instanceType" yaml:"instanceType"`
+	StructuredOutput       bool               `json:"structuredOutput" yaml:"structuredOutput"`
+	StrictEvents           bool               `json:"strictEvents" yaml:"strictEvents"`
+	HTTPSharedSecret       string             `json:"httpSharedSecret" yaml:"httpSharedSecret"`
+	PagerDutyRoutingKey    string             `json:"pagerDutyRoutingKey" yaml:"pagerDutyRoutingKey"`
+	DatadogAPIKey          string             `json:"datadogApiKey" yaml:"datadogApiKey"`
+	DatadogSite            string             `json:"datadogSite" yaml:"datadogSite"`   // e.g. datadoghq.eu, datadoghq.com when empty
+	TicketSystem           string             `json:"ticketSystem" yaml:"ticketSystem"` // "jira" or "servicenow"
+	TicketURL              string             `json:"ticketUrl" yaml:"ticketUrl"`
+	TicketUser             string             `json:"ticketUser" yaml:"ticketUser"`
+	TicketToken            string             `json:"ticketToken" yaml:"ticketToken"`             // Jira API token or ServiceNow password
+	TicketProject          string             `json:"ticketProject" yaml:"ticketProject"`         // Jira project key
+	TicketThreshold        int                `json:"ticketThreshold" yaml:"ticketThreshold"`     // Consecutive failed invocations before opening a ticket
+	CircuitThreshold       int                `json:"circuitThreshold" yaml:"circuitThreshold"`   // Consecutive failed invocations before pausing scaling attempts, 0 disables
+	CircuitBackoff         int                `json:"circuitBackoff" yaml:"circuitBackoff"`       // In seconds, 1800 when 0
+	ChurnBudgetHourly      int                `json:"churnBudgetHourly" yaml:"churnBudgetHourly"` // Instances created and deleted in an hour before scaling pauses, 0 disables
+	ChurnBudgetDaily       int                `json:"churnBudgetDaily" yaml:"churnBudgetDaily"`   // Instances created and deleted in a day before scaling pauses, 0 disables
+	SlackWebhookURL        string             `json:"slackWebhookUrl" yaml:"slackWebhookUrl"`
+	NotificationWebhookURL string             `json:"notificationWebhookUrl" yaml:"notificationWebhookUrl"`
+	NotifyMinSeverity      string             `json:"notifyMinSeverity" yaml:"notifyMinSeverity"` // "info", "warn" or "critical"
+	NotifyDedupWindow      int                `json:"notifyDedupWindow" yaml:"notifyDedupWindow"` // In seconds, 0 disables deduplication
+	NotifyQuietHours       string             `json:"notifyQuietHours" yaml:"notifyQuietHours"`   // HH:MM-HH:MM, e.g. 22:00-07:00
+	NotifyQuietTimezone    string             `json:"notifyQuietTimezone" yaml:"notifyQuietTimezone"`
+	Region                 string             `json:"region" yaml:"region"`
+	AssumeRoleArn          string             `json:"assumeRoleArn" yaml:"assumeRoleArn"`
+	AssumeRoleExternalID   string             `json:"assumeRoleExternalId" yaml:"assumeRoleExternalId"`
+	ElasticScaleDimension  string             `json:"elasticScaleDimension" yaml:"elasticScaleDimension"` // "shardCount" or "shardCapacity"
+	LockTable              string             `json:"lockTable" yaml:"lockTable"`                         // Optional DynamoDB table of the cluster locks
+	LockLease              int                `json:"lockLease" yaml:"lockLease"`                         // In seconds, 300 when 0
+	IdempotencyTable       string             `json:"idempotencyTable" yaml:"idempotencyTable"`           // Optional DynamoDB table of the processed SNS messages
+	IdempotencyTTL         int                `json:"idempotencyTtl" yaml:"idempotencyTtl"`               // In seconds, 86400 when 0
+	PauseParameter         string             `json:"pauseParameter" yaml:"pauseParameter"`               // Optional SSM parameter that, set to "true", pauses autoscaling
+	AllowedClusters        []string           `json:"allowedClusters" yaml:"allowedClusters"`             // Clusters this deployment may scale, identifiers or patterns, any when empty
+	CostEstimates          bool               `json:"costEstimates" yaml:"costEstimates"`                 // Estimate the cost delta of every action with the AWS Price List API
+	Holidays               []string           `json:"holidays" yaml:"holidays"`                           // Dates, as YYYY-MM-DD, scheduled scale-outs are skipped on
+	HolidayCalendarS3URI   string             `json:"holidayCalendarS3Uri" yaml:"holidayCalendarS3Uri"`   // Optional s3://bucket/key of an iCalendar (.ics) file of more holidays
+	HolidayTimezone        string             `json:"holidayTimezone" yaml:"holidayTimezone"`             // IANA timezone of the holidays, UTC when empty
+
+	// Schedules are named scheduled-scaling settings that EventBridge events can refer to.
+	Schedules map[string]Schedule `json:"schedules" yaml:"schedules"`
+	// Clusters holds per-cluster overrides, keyed by cluster identifier. When CLUSTER_IDENTIFIER is not set,
+	// these are the clusters managed by a single deployment.
+	Clusters map[string]ClusterOverride `json:"clusters" yaml:"clusters"`
+	// NotificationTemplates are text/template message templates, keyed by event: scaleOut, scaleIn or failure.
+	NotificationTemplates map[string]string `json:"notificationTemplates" yaml:"notificationTemplates"`
+
+	// present records which settings were provided, by environment variable name.
+	present map[string]bool
+	// holidayCalendar is the iCalendar file of HOLIDAY_CALENDAR_S3_URI, read by Load.
+	holidayCalendar []byte
+}
+
+// Schedule is a named scheduled-scaling setting.
+type Schedule struct {
+	ClusterID      string `json:"clusterIdentifier" yaml:"clusterIdentifier"`
+	NumberReplicas int    `json:"numberReplicas" yaml:"numberReplicas"`
+	InstanceType   string `json:"instanceType" yaml:"instanceType"`
+	Phase          string `json:"phase" yaml:"phase"` // ScaleOut or ScaleIn, inferred when empty
+	// Start and End make a scaling window: the ticks of the schedule inside it scale out, those outside it scale in
+	Start    string `json:"start" yaml:"start"`       // Cron of the start of the window, e.g. "0 8 * * MON-FRI"
+	End      string `json:"end" yaml:"end"`           // Cron of the end of the window
+	Timezone string `json:"timezone" yaml:"timezone"` // IANA timezone of the window, UTC when empty
+	Priority int    `json:"priority" yaml:"priority"` // Rank among overlapping windows with SCHEDULE_CONFLICT_POLICY Priority, higher wins
+}
+
+// ClusterOverride overrides settings for a single cluster. Unset fields keep the top-level value.
+type ClusterOverride struct {
+	MinCapacity      *int               `json:"minCapacity" yaml:"minCapacity"`
+	MaxCapacity      *int               `json:"maxCapacity" yaml:"maxCapacity"`
+	MetricName       string             `json:"metricName" yaml:"metricName"`
+	TargetValue      *float64           `json:"targetValue" yaml:"targetValue"`
+	MetricTargets    map[string]float64 `json:"metricTargets" yaml:"metricTargets"`
+	ScaleInCooldown  *int               `json:"scaleInCooldown" yaml:"scaleInCooldown"`
+	ScaleOutCooldown *int               `json:"scaleOutCooldown" yaml:"scaleOutCooldown"`
+	InstanceType     string             `json:"instanceType" yaml:"instanceType"`
+	DryRun           *bool              `json:"dryRun" yaml:"dryRun"`
+	AllowZeroReaders *bool              `json:"allowZeroReaders" yaml:"allowZeroReaders"`
+	// Region is the region of the cluster, when not the region of the Lambda
+	Region string `json:"region" yaml:"region"`
+	// AssumeRoleArn is the role to assume, e.g. in the workload account owning the cluster
+	AssumeRoleArn        string `json:"assumeRoleArn" yaml:"assumeRoleArn"`
+	AssumeRoleExternalID string `json:"assumeRoleExternalId" yaml:"assumeRoleExternalId"`
+	// Engine is the engine of the cluster, when not the top-level engine
+	Engine string `json:"engine" yaml:"engine"`
+	// ElasticScaleDimension is what to scale when the cluster is an elastic cluster
+	ElasticScaleDimension string `json:"elasticScaleDimension" yaml:"elasticScaleDimension"`
+}
+
+// setting binds an environment variable and its config file key to a Config field.
+type setting struct {
+	env    string
+	key    string
+	target any
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"SNS_TOPIC_ARN", "snsTopicArn", &c.SNSTopicArn},
+		{"SCALE_OUT_TOPIC_ARN", "scaleOutTopicArn", &c.ScaleOutTopicArn},
+		{"SCALE_IN_TOPIC_ARN", "scaleInTopicArn", &c.ScaleInTopicArn},
+		{"FAILURE_TOPIC_ARN", "failureTopicArn", &c.FailureTopicArn},
+		{"EVENT_BUS_NAME", "eventBusName", &c.EventBusName},
+		{"AUDIT_S3_URI", "auditS3Uri", &c.AuditS3URI},
+		{"PLAN_S3_URI", "planS3Uri", &c.PlanS3URI},
+		{"REPORT_S3_URI", "reportS3Uri", &c.ReportS3URI},
+		{"EMF_METRICS", "emfMetrics", &c.EMFMetrics},
+		{"CAPACITY_METRICS", "capacityMetrics", &c.CapacityMetrics},
+		{"HEARTBEAT_METRIC", "heartbeatMetric", &c.HeartbeatMetric},
+		{"METRICS_NAMESPACE", "metricsNamespace", &c.MetricsNamespace},
+		{"CLUSTER_IDENTIFIER", "clusterIdentifier", &c.ClusterID},
+		{"ENGINE", "engine", &c.Engine},
+		{"MIN_CAPACITY", "minCapacity", &c.MinCapacity},
+		{"MAX_CAPACITY", "maxCapacity", &c.MaxCapacity},
+		{"MANAGED_CAPACITY", "managedCapacity", &c.ManagedCapacity},
+		{"BOOTSTRAP_CAPACITY", "bootstrapCapacity", &c.BootstrapCapacity},
+		{"MANAGED_REPLICA_CAP", "managedReplicaCap", &c.ManagedReplicaCap},
+		{"ACCOUNT_REPLICA_CAP", "accountReplicaCap", &c.AccountReplicaCap},
+		{"SCHEDULED_SCALING", "scheduledScaling", &c.ScheduledScaling},
+		{"SCHEDULE_NUMBER_REPLICAS", "scheduleNumberReplicas", &c.ScheduleNumberReplicas},
+		{"SCHEDULE_PHASE", "schedulePhase", &c.SchedulePhase},
+		{"SCHEDULE_CONFLICT_POLICY", "scheduleConflictPolicy", &c.ScheduleConflictPolicy},
+		{"METRIC_NAME", "metricName", &c.MetricName},
+		{"TARGET_VALUE", "targetValue", &c.TargetValue},
+		{"METRIC_TARGETS", "metricTargets", &c.MetricTargets},
+		{"METRIC_CONCURRENCY", "metricConcurrency", &c.MetricConcurrency},
+		{"COUNTABLE_STATUSES", "countableStatuses", &c.CountableStatuses},
+		{"SCALE_IN_COOLDOWN", "scaleInCooldown", &c.ScaleInCooldown},
+		{"SCALE_OUT_COOLDOWN", "scaleOutCooldown", &c.ScaleOutCooldown},
+		{"COOLDOWN_TAGS", "cooldownTags", &c.CooldownTags},
+		{"SCALE_IN_STABILIZATION", "scaleInStabilization", &c.ScaleInStabilization},
+		{"TRACK_DESIRED_CAPACITY", "trackDesiredCapacity", &c.TrackDesiredCapacity},
+		{"ORPHAN_CLEANUP", "orphanCleanup", &c.OrphanCleanup},
+		{"REPLACE_FAILED_REPLICAS", "replaceFailedReplicas", &c.ReplaceFailedReplicas},
+		{"INSTANCE_QUOTA_CHECK", "instanceQuotaCheck", &c.InstanceQuotaCheck},
+		{"BULK_SCALE_IN_LIMIT", "bulkScaleInLimit", &c.BulkScaleInLimit},
+		{"ALLOW_BULK_SCALE_IN", "allowBulkScaleIn", &c.AllowBulkScaleIn},
+		{"MAX_HOURLY_COST", "maxHourlyCost", &c.MaxHourlyCost},
+		{"CANARY_SCALE_OUT", "canaryScaleOut", &c.CanaryScaleOut},
+		{"CANARY_WINDOW", "canaryWindow", &c.CanaryWindow},
+		{"DRAIN_CONNECTIONS", "drainConnections", &c.DrainConnections},
+		{"DRAIN_TIMEOUT", "drainTimeout", &c.DrainTimeout},
+		{"MAINTENANCE_PAUSE", "maintenancePause", &c.MaintenancePause},
+		{"MAX_RETRIES", "maxRetries", &c.MaxRetries},
+		{"INITIAL_BACKOFF", "initialBackoff", &c.InitialBackoff},
+		{"DEADLINE_MARGIN", "deadlineMargin", &c.DeadlineMargin},
+		{"API_RATE_LIMIT", "apiRateLimit", &c.APIRateLimit},
+		{"DRYRUN", "dryRun", &c.DryRun},
+		{"SHADOW_MODE", "shadowMode", &c.ShadowMode},
+		{"ALLOW_ZERO_READERS", "allowZeroReaders", &c.AllowZeroReaders},
+		{"WRITER_ONLY_BOOTSTRAP", "writerOnlyBootstrap", &c.WriterOnlyBootstrap},
+		{"INSTANCE_TYPE", "instanceType", &c.InstanceType},
+		{"STRUCTURED_OUTPUT", "structuredOutput", &c.StructuredOutput},
+		{"STRICT_EVENTS", "strictEvents", &c.StrictEvents},
+		{"HTTP_SHARED_SECRET", "httpSharedSecret", &c.HTTPSharedSecret},
+		{"PAGERDUTY_ROUTING_KEY", "pagerDutyRoutingKey", &c.PagerDutyRoutingKey},
+		{"DATADOG_API_KEY", "datadogApiKey", &c.DatadogAPIKey},
+		{"DATADOG_SITE", "datadogSite", &c.DatadogSite},
+		{"TICKET_SYSTEM", "ticketSystem", &c.TicketSystem},
+		{"TICKET_URL", "ticketUrl", &c.TicketURL},
+		{"TICKET_USER", "ticketUser", &c.TicketUser},
+		{"TICKET_TOKEN", "ticketToken", &c.TicketToken},
+		{"TICKET_PROJECT", "ticketProject", &c.TicketProject},
+		{"TICKET_THRESHOLD", "ticketThreshold", &c.TicketThreshold},
+		{"CIRCUIT_THRESHOLD", "circuitThreshold", &c.CircuitThreshold},
+		{"CIRCUIT_BACKOFF", "circuitBackoff", &c.CircuitBackoff},
+		{"CHURN_BUDGET_HOURLY", "churnBudgetHourly", &c.ChurnBudgetHourly},
+		{"CHURN_BUDGET_DAILY", "churnBudgetDaily", &c.ChurnBudgetDaily},
+		{"SLACK_WEBHOOK_URL", "slackWebhookUrl", &c.SlackWebhookURL},
+		{"NOTIFICATION_WEBHOOK_URL", "notificationWebhookUrl", &c.NotificationWebhookURL},
+		{"NOTIFICATION_TEMPLATES", "notificationTemplates", &c.NotificationTemplates},
+		{"NOTIFY_MIN_SEVERITY", "notifyMinSeverity", &c.NotifyMinSeverity},
+		{"NOTIFY_DEDUP_WINDOW", "notifyDedupWindow", &c.NotifyDedupWindow},
+		{"NOTIFY_QUIET_HOURS", "notifyQuietHours", &c.NotifyQuietHours},
+		{"NOTIFY_QUIET_TIMEZONE", "notifyQuietTimezone", &c.NotifyQuietTimezone},
+		{"CLUSTERS", "clusters", &c.Clusters},
+		{"REGION", "region", &c.Region},
+		{"ASSUME_ROLE_ARN", "assumeRoleArn", &c.AssumeRoleArn},
+		{"ASSUME_ROLE_EXTERNAL_ID", "assumeRoleExternalId", &c.AssumeRoleExternalID},
+		{"ELASTIC_SCALE_DIMENSION", "elasticScaleDimension", &c.ElasticScaleDimension},
+		{"LOCK_TABLE", "lockTable", &c.LockTable},
+		{"LOCK_LEASE", "lockLease", &c.LockLease},
+		{"IDEMPOTENCY_TABLE", "idempotencyTable", &c.IdempotencyTable},
+		{"IDEMPOTENCY_TTL", "idempotencyTtl", &c.IdempotencyTTL},
+		{"PAUSE_PARAMETER", "pauseParameter", &c.PauseParameter},
+		{"ALLOWED_CLUSTERS", "allowedClusters", &c.AllowedClusters},
+		{"COST_ESTIMATES", "costEstimates", &c.CostEstimates},
+		{"HOLIDAYS", "holidays", &c.Holidays},
+		{"HOLIDAY_CALENDAR_S3_URI", "holidayCalendarS3Uri", &c.HolidayCalendarS3URI},
+		{"HOLIDAY_TIMEZONE", "holidayTimezone", &c.HolidayTimezone},
+	}
+}
+
+// newConfig returns a Config holding the defaults.
+func newConfig() *Config {
+	return &Config{
+		MaxRetries:      5,
+		InitialBackoff:  1,
+		TicketThreshold: 3,
+		MetricTargets:   map[string]float64{},
+		present:         map[string]bool{},
+	}
+}
+
+// Load reads the config file named by CONFIG_FILE (a local path, e.g. bundled in the image)
+// or CONFIG_S3_URI (s3://bucket/key), if any, then applies the environment variables, and reads the
+// holidays of HOLIDAY_CALENDAR_S3_URI. s3Client is only used when CONFIG_S3_URI or
+// HOLIDAY_CALENDAR_S3_URI is set.
+func Load(ctx context.Context, s3Client S3API) (*Config, error) {
+	c := newConfig()
+
+	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
+		}
+		if err := c.decode(configFile, data); err != nil {
+			return nil, err
+		}
+	} else if configURI := os.Getenv("CONFIG_S3_URI"); configURI != "" {
+		data, err := readS3Object(ctx, s3Client, configURI)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.decode(configURI, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+	if c.HolidayCalendarS3URI != "" {
+		data, err := readS3Object(ctx, s3Client, c.HolidayCalendarS3URI)
+		if err != nil {
+			return nil, fmt.Errorf("HOLIDAY_CALENDAR_S3_URI: %w", err)
+		}
+		c.holidayCalendar = data
+	}
+	return c, nil
+}
+
+// IsSet reports whether a setting, named by its environment variable, was provided by the config file or the environment.
+func (c *Config) IsSet(env string) bool {
+	return c.present[env]
+}
+
+// decode parses a config file, as JSON when its name ends in .json and as YAML otherwise.
+func (c *Config) decode(name string, data []byte) error {
+	keys := map[string]any{}
+	var err error
+	if strings.EqualFold(path.Ext(name), ".json") {
+		if err = json.Unmarshal(data, &keys); err == nil {
+			err = json.Unmarshal(data, c)
+		}
+	} else {
+		if err = yaml.Unmarshal(data, &keys); err == nil {
+			err = yaml.Unmarshal(data, c)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	for _, s := range c.settings() {
+		if _, found := keys[s.key]; found {
+			c.present[s.env] = true
+		}
+	}
+	return nil
+}
+
+// applyEnv overrides the settings with the environment variables that are set.
+func (c *Config) applyEnv() error {
+	for _, s := range c.settings() {
+		value := os.Getenv(s.env)
+		if value == "" {
+			continue
+		}
+		if err := setValue(s.target, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", s.env, err)
+		}
+		c.present[s.env] = true
+	}
+	return nil
+}
+
+// setValue parses value into the field pointed to by target.
+func setValue(target any, value string) error {
+	var err error
+	switch field := target.(type) {
+	case *string:
+		*field = value
+	case *int:
+		*field, err = strconv.Atoi(value)
+	case *float64:
+		*field, err = strconv.ParseFloat(value, 64)
+	case *bool:
+		*field, err = strconv.ParseBool(value)
+	case *[]string:
+		*field = parseList(value)
+	case *map[string]float64:
+		*field, err = parseMetricTargets(value)
+	case *map[string]string:
+		values := map[string]string{}
+		if err = yaml.Unmarshal([]byte(value), &values); err == nil {
+			*field = values
+		}
+	case *map[string]ClusterOverride:
+		// JSON is valid YAML, so both formats are accepted
+		clusters := map[string]ClusterOverride{}
+		if err = yaml.Unmarshal([]byte(value), &clusters); err == nil {
+			*field = clusters
+		}
+	default:
+		err = fmt.Errorf("unsupported setting type %T", target)
+	}
+	return err
+}
+
+// HasCluster reports whether the cluster is the configured cluster or one of the per-cluster entries.
+func (c *Config) HasCluster(clusterID string) bool {
+	if clusterID == "" {
+		return false
+	}
+	_, found := c.clusterOverride(clusterID)
+	return found || matchesCluster(c.ClusterID, clusterID)
+}
+
+// ClusterTargets returns the clusters an invocation applies to: the cluster named by the event if any,
+// otherwise CLUSTER_IDENTIFIER, otherwise every cluster of the Clusters map in name order.
+// Targets may be patterns (see IsClusterPattern), to be resolved against the clusters of the account.
+func (c *Config) ClusterTargets(namedClusterID string) []string {
+	if namedClusterID != "" {
+		return []string{namedClusterID}
+	}
+	if c.ClusterID != "" || len(c.Clusters) == 0 {
+		return []string{c.ClusterID}
+	}
+	clusterIDs := make([]string, 0, len(c.Clusters))
+	for clusterID := range c.Clusters {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+	return clusterIDs
+}
+
+// ForCluster returns a copy of the config for the given cluster, with its per-cluster overrides applied.
+func (c *Config) ForCluster(clusterID string) *Config {
+	clusterConfig := *c
+	clusterConfig.present = make(map[string]bool, len(c.present))
+	for env, set := range c.present {
+		clusterConfig.present[env] = set
+	}
+	if clusterID != "" {
+		clusterConfig.ClusterID = clusterID
+		clusterConfig.present["CLUSTER_IDENTIFIER"] = true
+	}
+
+	override, found := c.clusterOverride(clusterConfig.ClusterID)
+	if !found {
+		return &clusterConfig
+	}
+	if override.MinCapacity != nil {
+		clusterConfig.MinCapacity = *override.MinCapacity
+		clusterConfig.present["MIN_CAPACITY"] = true
+	}
+	if override.MaxCapacity != nil {
+		clusterConfig.MaxCapacity = *override.MaxCapacity
+		clusterConfig.present["MAX_CAPACITY"] = true
+	}
+	if override.MetricName != "" {
+		clusterConfig.MetricName = override.MetricName
+		clusterConfig.present["METRIC_NAME"] = true
+	}
+	if override.TargetValue != nil {
+		clusterConfig.TargetValue = *override.TargetValue
+		clusterConfig.present["TARGET_VALUE"] = true
+	}
+	if override.MetricTargets != nil {
+		clusterConfig.MetricTargets = override.MetricTargets
+		clusterConfig.present["METRIC_TARGETS"] = true
+	}
+	if override.ScaleInCooldown != nil {
+		clusterConfig.ScaleInCooldown = *override.ScaleInCooldown
+		clusterConfig.present["SCALE_IN_COOLDOWN"] = true
+	}
+	if override.ScaleOutCooldown != nil {
+		clusterConfig.ScaleOutCooldown = *override.ScaleOutCooldown
+		clusterConfig.present["SCALE_OUT_COOLDOWN"] = true
+	}
+	if override.InstanceType != "" {
+		clusterConfig.InstanceType = override.InstanceType
+		clusterConfig.present["INSTANCE_TYPE"] = true
+	}
+	if override.DryRun != nil {
+		clusterConfig.DryRun = *override.DryRun
+		clusterConfig.present["DRYRUN"] = true
+	}
+	if override.AllowZeroReaders != nil {
+		clusterConfig.AllowZeroReaders = *override.AllowZeroReaders
+		clusterConfig.present["ALLOW_ZERO_READERS"] = true
+	}
+	if override.Region != "" {
+		clusterConfig.Region = override.Region
+		clusterConfig.present["REGION"] = true
+	}
+	if override.AssumeRoleArn != "" {
+		clusterConfig.AssumeRoleArn = override.AssumeRoleArn
+		clusterConfig.AssumeRoleExternalID = override.AssumeRoleExternalID
+		clusterConfig.present["ASSUME_ROLE_ARN"] = true
+	}
+	if override.Engine != "" {
+		clusterConfig.Engine = override.Engine
+		clusterConfig.present["ENGINE"] = true
+	}
+	if override.ElasticScaleDimension != "" {
+		clusterConfig.ElasticScaleDimension = override.ElasticScaleDimension
+		clusterConfig.present["ELASTIC_SCALE_DIMENSION"] = true
+	}
+	return &clusterConfig
+}
+
+// readS3Object downloads the object at an s3://bucket/key URI.
+func readS3Object(ctx context.Context, s3Client S3API, uri string) ([]byte, error) {
+	bucket, key, err := parseS3URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config file %s: %w", uri, err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", uri, err)
+	}
+	return data, nil
+}
+
+// parseS3URI splits an s3://bucket/key URI into its bucket and key.
+func parseS3URI(uri string) (string, string, error) {
+	location, found := strings.CutPrefix(uri, "s3://")
+	if !found {
+		return "", "", fmt.Errorf("invalid S3 URI %q, expected s3://bucket/key", uri)
+	}
+	bucket, key, found := strings.Cut(location, "/")
+	if !found || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid S3 URI %q, expected s3://bucket/key", uri)
+	}
+	return bucket, key, nil
+}
+
+// parseList parses a comma-separated list, e.g. "orders,payments", ignoring empty entries.
+func parseList(value string) []string {
+	var values []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+// parseMetricTargets parses a comma-separated list of metric targets, e.g. "CPUUtilization=70,DatabaseConnections=500".
+func parseMetricTargets(value string) (map[string]float64, error) {
+	targets := map[string]float64{}
+	if value == "" {
+		return targets, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		metricName, targetStr, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || metricName == "" {
+			return nil, fmt.Errorf("invalid metric target %q, expected MetricName=Value", pair)
+		}
+		target, err := strconv.ParseFloat(targetStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target value for metric %s: %w", metricName, err)
+		}
+		targets[metricName] = target
+	}
+	return targets, nil
+}
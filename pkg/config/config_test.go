@@ -0,0 +1,345 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 serves a single object from memory.
+type fakeS3 struct {
+	bucket, key string
+	body        []byte
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if aws.ToString(params.Bucket) != f.bucket || aws.ToString(params.Key) != f.key {
+		return nil, os.ErrNotExist
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(f.body))}, nil
+}
+
+const testYAML = `
+clusterIdentifier: test-cluster
+minCapacity: 0
+maxCapacity: 5
+metricName: CPUUtilization
+targetValue: 60
+metricTargets:
+  DatabaseConnections: 500
+schedules:
+  business-hours:
+    numberReplicas: 3
+    instanceType: db.r6g.large
+clusters:
+  big-cluster:
+    maxCapacity: 10
+    targetValue: 50
+`
+
+// TestLoad_FileWithEnvOverride tests that environment variables take precedence over the config file.
+func TestLoad_FileWithEnvOverride(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte(testYAML), 0o600))
+	t.Setenv("CONFIG_FILE", configFile)
+	t.Setenv("MAX_CAPACITY", "7")
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "test-cluster", c.ClusterID)
+	assert.Equal(t, 0, c.MinCapacity)
+	assert.True(t, c.IsSet("MIN_CAPACITY"))
+	assert.Equal(t, 7, c.MaxCapacity)
+	assert.Equal(t, 60.0, c.TargetValue)
+	assert.Equal(t, map[string]float64{"DatabaseConnections": 500}, c.MetricTargets)
+	assert.Equal(t, 3, c.Schedules["business-hours"].NumberReplicas)
+	assert.False(t, c.IsSet("SCALE_IN_COOLDOWN"))
+	assert.Equal(t, 5, c.MaxRetries) // Default
+}
+
+// TestLoad_S3 tests loading a JSON config file from S3.
+func TestLoad_S3(t *testing.T) {
+	t.Setenv("CONFIG_S3_URI", "s3://config-bucket/docdb/autoscaler.json")
+	s3Client := &fakeS3{
+		bucket: "config-bucket",
+		key:    "docdb/autoscaler.json",
+		body:   []byte(`{"clusterIdentifier": "test-cluster", "minCapacity": 1, "maxCapacity": 3, "dryRun": true}`),
+	}
+
+	c, err := Load(context.Background(), s3Client)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-cluster", c.ClusterID)
+	assert.Equal(t, 3, c.MaxCapacity)
+	assert.True(t, c.DryRun)
+}
+
+// TestLoad_HolidayCalendar tests that the holidays of HOLIDAYS and of the calendar of HOLIDAY_CALENDAR_S3_URI
+// are combined.
+func TestLoad_HolidayCalendar(t *testing.T) {
+	t.Setenv("HOLIDAYS", "2024-08-09, 2024-12-26")
+	t.Setenv("HOLIDAY_TIMEZONE", "Asia/Singapore")
+	t.Setenv("HOLIDAY_CALENDAR_S3_URI", "s3://config-bucket/holidays.ics")
+	s3Client := &fakeS3{
+		bucket: "config-bucket",
+		key:    "holidays.ics",
+		body:   []byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20241225\nSUMMARY:Christmas Day\nEND:VEVENT\nEND:VCALENDAR\n"),
+	}
+
+	c, err := Load(context.Background(), s3Client)
+	assert.NoError(t, err)
+	calendar, err := c.HolidayCalendar()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calendar.Len())
+	name, found := calendar.Holiday(time.Date(2024, time.December, 24, 18, 0, 0, 0, time.UTC))
+	assert.True(t, found)
+	assert.Equal(t, "Christmas Day", name)
+
+	c.Holidays = []string{"26/12/2024"}
+	assert.ErrorContains(t, c.Validate(), `HOLIDAYS: invalid holiday "26/12/2024"`)
+
+	t.Setenv("HOLIDAY_CALENDAR_S3_URI", "s3://config-bucket/missing.ics")
+	_, err = Load(context.Background(), s3Client)
+	assert.ErrorContains(t, err, "HOLIDAY_CALENDAR_S3_URI")
+}
+
+// TestLoad_InvalidEnv tests that invalid environment values are reported by name.
+func TestLoad_InvalidEnv(t *testing.T) {
+	t.Setenv("MIN_CAPACITY", "one")
+
+	_, err := Load(context.Background(), nil)
+	assert.ErrorContains(t, err, "MIN_CAPACITY")
+}
+
+// TestForCluster tests that per-cluster overrides only apply to their cluster.
+func TestForCluster(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(t, os.WriteFile(configFile, []byte(testYAML), 0o600))
+	t.Setenv("CONFIG_FILE", configFile)
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+
+	bigCluster := c.ForCluster("big-cluster")
+	assert.Equal(t, "big-cluster", bigCluster.ClusterID)
+	assert.Equal(t, 10, bigCluster.MaxCapacity)
+	assert.Equal(t, 50.0, bigCluster.TargetValue)
+	assert.Equal(t, "CPUUtilization", bigCluster.MetricName)
+
+	defaultCluster := c.ForCluster("")
+	assert.Equal(t, "test-cluster", defaultCluster.ClusterID)
+	assert.Equal(t, 5, defaultCluster.MaxCapacity)
+	assert.Equal(t, 5, c.MaxCapacity)
+}
+
+// TestParseS3URI tests the parsing of s3://bucket/key URIs.
+func TestParseS3URI(t *testing.T) {
+	bucket, key, err := parseS3URI("s3://bucket/path/to/config.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "path/to/config.yaml", key)
+
+	for _, uri := range []string{"bucket/key", "s3://bucket", "s3:///key"} {
+		_, _, err := parseS3URI(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+// TestValidate tests that all validation errors are reported at once.
+func TestValidate(t *testing.T) {
+	t.Setenv("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:notify")
+	t.Setenv("CLUSTER_IDENTIFIER", "test-cluster")
+	t.Setenv("MIN_CAPACITY", "4")
+	t.Setenv("MAX_CAPACITY", "2")
+	t.Setenv("SCHEDULED_SCALING", "true")
+	t.Setenv("METRIC_NAME", "CPUUtilization")
+	t.Setenv("ENGINE", "mongodb")
+	t.Setenv("MANAGED_CAPACITY", "true")
+	t.Setenv("ELASTIC_SCALE_DIMENSION", "shardCount")
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+	c.Schedules = map[string]Schedule{
+		"business-hours": {NumberReplicas: 2, Start: "0 8 * * MON-FRI", End: "0 20 * * MON-FRI", Timezone: "Europe/London"},
+		"no-end":         {NumberReplicas: 2, Start: "0 8 * * *"},
+		"bad-timezone":   {NumberReplicas: 2, Start: "0 8 * * *", End: "0 20 * * *", Timezone: "Europe/Nowhere"},
+	}
+
+	err = c.Validate()
+	assert.ErrorContains(t, err, "MIN_CAPACITY (4) must not exceed MAX_CAPACITY (2)")
+	assert.NotContains(t, err.Error(), "schedule business-hours")
+	assert.ErrorContains(t, err, "schedule no-end: invalid end")
+	assert.ErrorContains(t, err, "schedule bad-timezone: invalid start: invalid timezone Europe/Nowhere")
+	assert.ErrorContains(t, err, "SCHEDULE_NUMBER_REPLICAS is not set")
+	assert.ErrorContains(t, err, "METRIC_NAME must not be set when SCHEDULED_SCALING is enabled")
+	assert.ErrorContains(t, err, "ENGINE must be one of docdb, neptune, aurora-mysql, aurora-postgresql, got mongodb")
+	assert.ErrorContains(t, err, "MANAGED_CAPACITY applies to the readers of instance-based clusters")
+}
+
+// TestActiveSchedule tests that overlapping scaling windows of a cluster resolve to the same schedule,
+// by SCHEDULE_CONFLICT_POLICY.
+func TestActiveSchedule(t *testing.T) {
+	c := &Config{Schedules: map[string]Schedule{
+		"business-hours": {ClusterID: "orders", NumberReplicas: 2, Start: "0 8 * * *", End: "0 20 * * *", Priority: 1},
+		"batch":          {ClusterID: "orders", NumberReplicas: 1, Start: "0 12 * * *", End: "0 14 * * *", Priority: 2},
+		"other-cluster":  {ClusterID: "payments", NumberReplicas: 8, Start: "0 0 * * *", End: "59 23 * * *"},
+	}}
+
+	noon := time.Date(2024, time.June, 14, 13, 0, 0, 0, time.UTC)
+	name, active, found, err := c.ActiveSchedule("batch", noon)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "business-hours", name)
+	assert.Equal(t, 2, active.NumberReplicas)
+
+	c.ScheduleConflictPolicy = ConflictPriority
+	name, _, _, err = c.ActiveSchedule("business-hours", noon)
+	assert.NoError(t, err)
+	assert.Equal(t, "batch", name)
+
+	_, _, found, err = c.ActiveSchedule("business-hours", noon.Add(10*time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	c.ScheduleConflictPolicy = "LastWins"
+	assert.ErrorContains(t, c.Validate(), "SCHEDULE_CONFLICT_POLICY must be MaxReplicas or Priority, got LastWins")
+}
+
+// TestResolve tests that invocation overrides switch a metric-based configuration to scheduled scaling.
+func TestResolve(t *testing.T) {
+	t.Setenv("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:notify")
+	t.Setenv("CLUSTER_IDENTIFIER", "test-cluster")
+	t.Setenv("MIN_CAPACITY", "1")
+	t.Setenv("MAX_CAPACITY", "5")
+	t.Setenv("METRIC_NAME", "CPUUtilization")
+	t.Setenv("TARGET_VALUE", "60")
+	t.Setenv("SCALE_IN_COOLDOWN", "1200")
+	t.Setenv("SCALE_OUT_COOLDOWN", "600")
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Validate())
+
+	scheduledScaling := true
+	numberReplicas := 3
+	resolved, err := c.Resolve(Overrides{
+		ClusterID:              "other-cluster",
+		ScheduledScaling:       &scheduledScaling,
+		ScheduleNumberReplicas: &numberReplicas,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "other-cluster", resolved.ClusterID)
+	assert.True(t, resolved.ScheduledScaling)
+	assert.Equal(t, 3, resolved.ScheduleNumberReplicas)
+	assert.False(t, c.ScheduledScaling)
+
+	resolved, err = c.Resolve(Overrides{ScheduledScaling: &scheduledScaling, ScheduleNumberReplicas: &numberReplicas, SchedulePhase: "ScaleIn"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ScaleIn", resolved.SchedulePhase)
+	_, err = c.Resolve(Overrides{ScheduledScaling: &scheduledScaling, ScheduleNumberReplicas: &numberReplicas, SchedulePhase: "Down"})
+	assert.ErrorContains(t, err, "SCHEDULE_PHASE must be ScaleOut or ScaleIn, got Down")
+}
+
+// TestResolve_AllowedClusters tests that clusters outside ALLOWED_CLUSTERS are rejected.
+func TestResolve_AllowedClusters(t *testing.T) {
+	t.Setenv("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:notify")
+	t.Setenv("CLUSTER_IDENTIFIER", "prod-orders")
+	t.Setenv("MIN_CAPACITY", "1")
+	t.Setenv("MAX_CAPACITY", "5")
+	t.Setenv("SCHEDULED_SCALING", "true")
+	t.Setenv("SCHEDULE_NUMBER_REPLICAS", "1")
+	t.Setenv("ALLOWED_CLUSTERS", "prod-*, regex:orders-(blue|green)")
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod-*", "regex:orders-(blue|green)"}, c.AllowedClusters)
+
+	_, err = c.Resolve(Overrides{})
+	assert.NoError(t, err)
+	_, err = c.Resolve(Overrides{ClusterID: "orders-green"})
+	assert.NoError(t, err)
+	_, err = c.Resolve(Overrides{ClusterID: "staging-orders"})
+	assert.ErrorIs(t, err, ErrClusterNotAllowed)
+
+	c.AllowedClusters = nil
+	_, err = c.Resolve(Overrides{ClusterID: "staging-orders"})
+	assert.NoError(t, err)
+}
+
+// TestClusterTargets tests which clusters an invocation applies to.
+func TestClusterTargets(t *testing.T) {
+	t.Setenv("CLUSTERS", `{"prod-b": {"maxCapacity": 10}, "prod-a": {"minCapacity": 2}}`)
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"prod-a", "prod-b"}, c.ClusterTargets(""))
+	assert.Equal(t, []string{"prod-b"}, c.ClusterTargets("prod-b"))
+	assert.True(t, c.HasCluster("prod-a"))
+	assert.False(t, c.HasCluster("staging"))
+	assert.Equal(t, 2, c.ForCluster("prod-a").MinCapacity)
+
+	c.ClusterID = "single"
+	assert.Equal(t, []string{"single"}, c.ClusterTargets(""))
+}
+
+// TestMatchCluster tests glob and regex cluster patterns.
+func TestMatchCluster(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		clusterID string
+		expected  bool
+	}{
+		{"prod-orders", "prod-orders", true},
+		{"prod-orders", "prod-orders-green", false},
+		{"prod-*-docdb", "prod-orders-docdb", true},
+		{"prod-*-docdb", "staging-orders-docdb", false},
+		{"regex:prod-orders(-green)?", "prod-orders-green", true},
+		{"regex:prod-orders(-green)?", "prod-orders-green-old", false},
+	}
+	for _, tt := range tests {
+		matched, err := MatchCluster(tt.pattern, tt.clusterID)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, matched, "%s matching %s", tt.pattern, tt.clusterID)
+	}
+
+	_, err := MatchCluster("regex:prod-(", "prod-orders")
+	assert.Error(t, err)
+}
+
+// TestForCluster_AssumeRole tests that a cluster's role replaces the top-level role together with its external ID.
+func TestForCluster_AssumeRole(t *testing.T) {
+	t.Setenv("ASSUME_ROLE_ARN", "arn:aws:iam::111111111111:role/docdb-autoscaler")
+	t.Setenv("ASSUME_ROLE_EXTERNAL_ID", "tooling")
+	t.Setenv("CLUSTERS", `{"workload-cluster": {"assumeRoleArn": "arn:aws:iam::222222222222:role/docdb-autoscaler"}}`)
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+
+	workload := c.ForCluster("workload-cluster")
+	assert.Equal(t, "arn:aws:iam::222222222222:role/docdb-autoscaler", workload.AssumeRoleArn)
+	assert.Empty(t, workload.AssumeRoleExternalID)
+
+	other := c.ForCluster("other-cluster")
+	assert.Equal(t, "arn:aws:iam::111111111111:role/docdb-autoscaler", other.AssumeRoleArn)
+	assert.Equal(t, "tooling", other.AssumeRoleExternalID)
+}
+
+// TestForCluster_Region tests the region override of a cluster.
+func TestForCluster_Region(t *testing.T) {
+	t.Setenv("REGION", "ap-southeast-1")
+	t.Setenv("CLUSTERS", `{"eu-cluster": {"region": "eu-west-1"}}`)
+
+	c, err := Load(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", c.ForCluster("eu-cluster").Region)
+	assert.Equal(t, "ap-southeast-1", c.ForCluster("other-cluster").Region)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
+)
+
+// HolidayCalendar returns the calendar of the holidays of HOLIDAYS and HOLIDAY_CALENDAR_S3_URI, in
+// HOLIDAY_TIMEZONE, or nil when there are none.
+func (c *Config) HolidayCalendar() (*schedule.Calendar, error) {
+	if len(c.Holidays) == 0 && len(c.holidayCalendar) == 0 {
+		return nil, nil
+	}
+	calendar, err := schedule.NewCalendar(c.HolidayTimezone)
+	if err != nil {
+		return nil, err
+	}
+	for _, date := range c.Holidays {
+		if err := calendar.AddDate(date, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := calendar.AddICS(c.holidayCalendar); err != nil {
+		return nil, fmt.Errorf("calendar %s: %w", c.HolidayCalendarS3URI, err)
+	}
+	return calendar, nil
+}
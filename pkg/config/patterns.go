@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// regexPrefix marks a cluster identifier as a regular expression, e.g. regex:prod-orders(-green)?
+const regexPrefix = "regex:"
+
+// IsClusterPattern reports whether a cluster identifier is a glob (e.g. prod-*-docdb) or a regex: pattern.
+func IsClusterPattern(clusterID string) bool {
+	return strings.HasPrefix(clusterID, regexPrefix) || strings.ContainsAny(clusterID, "*?[")
+}
+
+// MatchCluster reports whether clusterID matches the pattern. Regular expressions must match the whole identifier.
+// Identifiers that are not patterns only match themselves.
+func MatchCluster(pattern, clusterID string) (bool, error) {
+	if expression, found := strings.CutPrefix(pattern, regexPrefix); found {
+		re, err := regexp.Compile("^(?:" + expression + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid cluster pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(clusterID), nil
+	}
+	if IsClusterPattern(pattern) {
+		matched, err := path.Match(pattern, clusterID)
+		if err != nil {
+			return false, fmt.Errorf("invalid cluster pattern %q: %w", pattern, err)
+		}
+		return matched, nil
+	}
+	return pattern == clusterID, nil
+}
+
+// matchesCluster is MatchCluster treating invalid patterns as not matching.
+func matchesCluster(pattern, clusterID string) bool {
+	matched, err := MatchCluster(pattern, clusterID)
+	return err == nil && matched
+}
+
+// clusterOverride returns the per-cluster overrides of a cluster: the entry keyed by its identifier,
+// otherwise the first (in name order) entry whose key is a pattern matching it.
+func (c *Config) clusterOverride(clusterID string) (ClusterOverride, bool) {
+	if override, found := c.Clusters[clusterID]; found {
+		return override, true
+	}
+	keys := make([]string, 0, len(c.Clusters))
+	for key := range c.Clusters {
+		if IsClusterPattern(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if matchesCluster(key, clusterID) {
+			return c.Clusters[key], true
+		}
+	}
+	return ClusterOverride{}, false
+}
+
+// AllowsCluster reports whether the deployment may scale the cluster: any cluster when ALLOWED_CLUSTERS is empty,
+// otherwise the clusters matching one of its identifiers or patterns.
+func (c *Config) AllowsCluster(clusterID string) bool {
+	if len(c.AllowedClusters) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.AllowedClusters, func(pattern string) bool {
+		return matchesCluster(pattern, clusterID)
+	})
+}
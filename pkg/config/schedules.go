@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
+)
+
+// Policies of SCHEDULE_CONFLICT_POLICY, deciding which of the overlapping scaling windows of a cluster is
+// in effect.
+const (
+	ConflictMaxReplicas = "MaxReplicas" // The schedule of the most replicas wins (default)
+	ConflictPriority    = "Priority"    // The schedule of the highest priority wins, then that of the most replicas
+)
+
+// conflictPolicies are the supported values of SCHEDULE_CONFLICT_POLICY.
+var conflictPolicies = []string{ConflictMaxReplicas, ConflictPriority}
+
+// ActiveSchedule returns the name and settings of the schedule in effect at a tick, among the configured
+// schedules with a scaling window of the cluster of the schedule named, and false when the tick is outside
+// all their windows. Overlapping windows are resolved by SCHEDULE_CONFLICT_POLICY, then by name, so that
+// every tick of any of them takes the same schedule rather than that of the last trigger.
+func (c *Config) ActiveSchedule(named string, tick time.Time) (string, Schedule, bool, error) {
+	clusterID := c.Schedules[named].ClusterID
+	names := make([]string, 0, len(c.Schedules))
+	for name, s := range c.Schedules {
+		if s.ClusterID == clusterID && (s.Start != "" || s.End != "") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var active string
+	for _, name := range names {
+		s := c.Schedules[name]
+		window, err := schedule.ParseWindow(s.Start, s.End, s.Timezone)
+		if err != nil {
+			return "", Schedule{}, false, fmt.Errorf("schedule %s: %w", name, err)
+		}
+		if window.Contains(tick) && (active == "" || c.outranks(s, c.Schedules[active])) {
+			active = name
+		}
+	}
+	if active == "" {
+		return "", Schedule{}, false, nil
+	}
+	return active, c.Schedules[active], true, nil
+}
+
+// outranks reports whether a schedule wins over another by SCHEDULE_CONFLICT_POLICY.
+func (c *Config) outranks(s, other Schedule) bool {
+	if c.ScheduleConflictPolicy == ConflictPriority && s.Priority != other.Priority {
+		return s.Priority > other.Priority
+	}
+	return s.NumberReplicas > other.NumberReplicas
+}
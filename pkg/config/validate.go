@@ -0,0 +1,335 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/notifications"
+	"github.com/cheelim1/docdb-autoscaler/pkg/schedule"
+)
+
+// maxReplicas is the maximum number of read replicas AWS allows in a DocumentDB cluster.
+const maxReplicas = 15
+
+// engines are the supported values of ENGINE.
+var engines = []string{"docdb", "neptune", "aurora-mysql", "aurora-postgresql"}
+
+// schedulePhases are the supported values of SCHEDULE_PHASE.
+var schedulePhases = []string{"ScaleOut", "ScaleIn"}
+
+// maxElasticCapacity is the upper bound of MAX_CAPACITY for each ELASTIC_SCALE_DIMENSION:
+// the maximum shard count, and the largest vCPU capacity of a shard.
+var maxElasticCapacity = map[string]int{
+	"shardCount":    32,
+	"shardCapacity": 64,
+}
+
+// metricSettings are only used by metric-based scaling.
+var metricSettings = []string{"METRIC_NAME", "TARGET_VALUE", "SCALE_IN_COOLDOWN", "SCALE_OUT_COOLDOWN"}
+
+// ErrClusterNotAllowed is returned when resolving the config of a cluster outside ALLOWED_CLUSTERS.
+var ErrClusterNotAllowed = errors.New("cluster is not in ALLOWED_CLUSTERS")
+
+// Overrides holds per-invocation settings taken from the triggering event.
+// Set fields take precedence over, and make optional, the corresponding settings.
+type Overrides struct {
+	ClusterID              string
+	ScheduledScaling       *bool
+	ScheduleNumberReplicas *int
+	SchedulePhase          string
+	InstanceType           string
+	DryRun                 *bool
+	ApproveBulkScaleIn     bool // Approves a scale-in beyond BULK_SCALE_IN_LIMIT
+}
+
+// Resolve returns the validated config of a single invocation, with the per-cluster overrides
+// of the target cluster and then the invocation overrides applied. It returns ErrClusterNotAllowed
+// when the target cluster is outside ALLOWED_CLUSTERS.
+func (c *Config) Resolve(overrides Overrides) (*Config, error) {
+	resolved := c.ForCluster(overrides.ClusterID)
+
+	if overrides.ScheduledScaling != nil && *overrides.ScheduledScaling != resolved.ScheduledScaling {
+		resolved.ScheduledScaling = *overrides.ScheduledScaling
+		if resolved.ScheduledScaling {
+			// The metric settings of the configuration do not apply to this scheduled invocation
+			for _, env := range append(metricSettings, "METRIC_TARGETS") {
+				resolved.present[env] = false
+			}
+		}
+	}
+	if overrides.ScheduleNumberReplicas != nil {
+		resolved.ScheduleNumberReplicas = *overrides.ScheduleNumberReplicas
+		resolved.present["SCHEDULE_NUMBER_REPLICAS"] = true
+	}
+	if overrides.SchedulePhase != "" {
+		resolved.SchedulePhase = overrides.SchedulePhase
+	}
+	if overrides.InstanceType != "" {
+		resolved.InstanceType = overrides.InstanceType
+	}
+	if overrides.DryRun != nil {
+		resolved.DryRun = *overrides.DryRun
+	}
+	if overrides.ApproveBulkScaleIn {
+		resolved.AllowBulkScaleIn = true
+	}
+
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+	if !resolved.AllowsCluster(resolved.ClusterID) {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotAllowed, resolved.ClusterID)
+	}
+	return resolved, nil
+}
+
+// Validate checks the settings and returns all the problems found at once.
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(env string) bool {
+		if !c.IsSet(env) {
+			errs = append(errs, fmt.Errorf("%s is not set", env))
+			return false
+		}
+		return true
+	}
+
+	if c.SNSTopicArn == "" {
+		errs = append(errs, errors.New("SNS_TOPIC_ARN is not set"))
+	}
+	if c.ClusterID == "" {
+		errs = append(errs, errors.New("CLUSTER_IDENTIFIER is not set"))
+	} else if IsClusterPattern(c.ClusterID) {
+		errs = append(errs, fmt.Errorf("CLUSTER_IDENTIFIER %s is a pattern, a single cluster is required", c.ClusterID))
+	}
+
+	for _, pattern := range c.AllowedClusters {
+		if _, err := MatchCluster(pattern, c.ClusterID); err != nil {
+			errs = append(errs, fmt.Errorf("ALLOWED_CLUSTERS: %w", err))
+		}
+	}
+
+	if c.Engine != "" && !slices.Contains(engines, c.Engine) {
+		errs = append(errs, fmt.Errorf("ENGINE must be one of %s, got %s", strings.Join(engines, ", "), c.Engine))
+	}
+
+	maxCapacity := maxReplicas
+	if c.ElasticScaleDimension != "" {
+		var found bool
+		if maxCapacity, found = maxElasticCapacity[c.ElasticScaleDimension]; !found {
+			errs = append(errs, fmt.Errorf("ELASTIC_SCALE_DIMENSION must be shardCount or shardCapacity, got %s", c.ElasticScaleDimension))
+			maxCapacity = maxReplicas
+		}
+	}
+
+	if c.ManagedCapacity && c.ElasticScaleDimension != "" {
+		errs = append(errs, errors.New("MANAGED_CAPACITY applies to the readers of instance-based clusters, not to elastic clusters"))
+	}
+
+	minSet := require("MIN_CAPACITY")
+	maxSet := require("MAX_CAPACITY")
+	if minSet && c.MinCapacity < 0 {
+		errs = append(errs, fmt.Errorf("MIN_CAPACITY must not be negative, got %d", c.MinCapacity))
+	}
+	if maxSet && c.MaxCapacity > maxCapacity {
+		errs = append(errs, fmt.Errorf("MAX_CAPACITY must not exceed %d, got %d", maxCapacity, c.MaxCapacity))
+	}
+	if minSet && maxSet && c.MinCapacity > c.MaxCapacity {
+		errs = append(errs, fmt.Errorf("MIN_CAPACITY (%d) must not exceed MAX_CAPACITY (%d)", c.MinCapacity, c.MaxCapacity))
+	}
+	if c.BootstrapCapacity < 0 {
+		errs = append(errs, fmt.Errorf("BOOTSTRAP_CAPACITY must not be negative, got %d", c.BootstrapCapacity))
+	}
+
+	if c.ScheduledScaling {
+		if require("SCHEDULE_NUMBER_REPLICAS") {
+			if c.ScheduleNumberReplicas == 0 || c.ScheduleNumberReplicas > maxReplicas || c.ScheduleNumberReplicas < -maxReplicas {
+				errs = append(errs, fmt.Errorf("SCHEDULE_NUMBER_REPLICAS must be between -%d and %d and not 0, got %d", maxReplicas, maxReplicas, c.ScheduleNumberReplicas))
+			}
+		}
+		if c.SchedulePhase != "" && !slices.Contains(schedulePhases, c.SchedulePhase) {
+			errs = append(errs, fmt.Errorf("SCHEDULE_PHASE must be ScaleOut or ScaleIn, got %s", c.SchedulePhase))
+		}
+		for _, env := range append(metricSettings, "METRIC_TARGETS") {
+			if c.IsSet(env) {
+				errs = append(errs, fmt.Errorf("%s must not be set when SCHEDULED_SCALING is enabled", env))
+			}
+		}
+		if c.WriterOnlyBootstrap {
+			errs = append(errs, errors.New("WRITER_ONLY_BOOTSTRAP applies to metric-based scaling, not to SCHEDULED_SCALING"))
+		}
+	} else {
+		for _, env := range metricSettings {
+			require(env)
+		}
+		if c.IsSet("TARGET_VALUE") && c.TargetValue <= 0 {
+			errs = append(errs, fmt.Errorf("TARGET_VALUE must be positive, got %v", c.TargetValue))
+		}
+		if c.ScaleInCooldown < 0 {
+			errs = append(errs, fmt.Errorf("SCALE_IN_COOLDOWN must not be negative, got %d", c.ScaleInCooldown))
+		}
+		if c.ScaleOutCooldown < 0 {
+			errs = append(errs, fmt.Errorf("SCALE_OUT_COOLDOWN must not be negative, got %d", c.ScaleOutCooldown))
+		}
+		for metricName, target := range c.MetricTargets {
+			if target <= 0 {
+				errs = append(errs, fmt.Errorf("METRIC_TARGETS target for %s must be positive, got %v", metricName, target))
+			}
+		}
+		if c.IsSet("SCHEDULE_NUMBER_REPLICAS") {
+			errs = append(errs, errors.New("SCHEDULE_NUMBER_REPLICAS must not be set when SCHEDULED_SCALING is disabled"))
+		}
+	}
+
+	if c.ScheduleConflictPolicy != "" && !slices.Contains(conflictPolicies, c.ScheduleConflictPolicy) {
+		errs = append(errs, fmt.Errorf("SCHEDULE_CONFLICT_POLICY must be MaxReplicas or Priority, got %s", c.ScheduleConflictPolicy))
+	}
+	for name, s := range c.Schedules {
+		if s.Phase != "" && !slices.Contains(schedulePhases, s.Phase) {
+			errs = append(errs, fmt.Errorf("schedule %s: phase must be ScaleOut or ScaleIn, got %s", name, s.Phase))
+		}
+		if s.Start == "" && s.End == "" {
+			continue
+		}
+		if s.Phase != "" {
+			errs = append(errs, fmt.Errorf("schedule %s: phase must not be set with a scaling window, which decides it", name))
+		}
+		if _, err := schedule.ParseWindow(s.Start, s.End, s.Timezone); err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", name, err))
+		}
+	}
+
+	if _, err := notifications.ParseTemplates(c.NotificationTemplates); err != nil {
+		errs = append(errs, fmt.Errorf("NOTIFICATION_TEMPLATES: %w", err))
+	}
+	if c.NotifyDedupWindow < 0 {
+		errs = append(errs, fmt.Errorf("NOTIFY_DEDUP_WINDOW must not be negative, got %d", c.NotifyDedupWindow))
+	}
+	if c.LockLease < 0 {
+		errs = append(errs, fmt.Errorf("LOCK_LEASE must not be negative, got %d", c.LockLease))
+	}
+	if c.BulkScaleInLimit < 0 {
+		errs = append(errs, fmt.Errorf("BULK_SCALE_IN_LIMIT must not be negative, got %d", c.BulkScaleInLimit))
+	}
+	if c.ShadowMode && c.AuditS3URI == "" && !c.EMFMetrics {
+		errs = append(errs, errors.New("SHADOW_MODE requires AUDIT_S3_URI or EMF_METRICS, where shadow decisions are recorded"))
+	}
+	if c.MaxHourlyCost < 0 {
+		errs = append(errs, fmt.Errorf("MAX_HOURLY_COST must not be negative, got %g", c.MaxHourlyCost))
+	}
+	if c.ScaleInStabilization < 0 {
+		errs = append(errs, fmt.Errorf("SCALE_IN_STABILIZATION must not be negative, got %d", c.ScaleInStabilization))
+	}
+	if c.CanaryWindow < 0 {
+		errs = append(errs, fmt.Errorf("CANARY_WINDOW must not be negative, got %d", c.CanaryWindow))
+	}
+	if c.DrainConnections < 0 {
+		errs = append(errs, fmt.Errorf("DRAIN_CONNECTIONS must not be negative, got %g", c.DrainConnections))
+	}
+	if c.DrainTimeout < 0 {
+		errs = append(errs, fmt.Errorf("DRAIN_TIMEOUT must not be negative, got %d", c.DrainTimeout))
+	}
+	if c.DrainTimeout > 0 && c.DrainConnections == 0 {
+		errs = append(errs, errors.New("DRAIN_TIMEOUT requires DRAIN_CONNECTIONS, the threshold replicas drain below"))
+	}
+	if c.MaintenancePause < 0 {
+		errs = append(errs, fmt.Errorf("MAINTENANCE_PAUSE must not be negative, got %d", c.MaintenancePause))
+	}
+	if c.ManagedReplicaCap < 0 {
+		errs = append(errs, fmt.Errorf("MANAGED_REPLICA_CAP must not be negative, got %d", c.ManagedReplicaCap))
+	}
+	if c.AccountReplicaCap < 0 {
+		errs = append(errs, fmt.Errorf("ACCOUNT_REPLICA_CAP must not be negative, got %d", c.AccountReplicaCap))
+	}
+	if c.CircuitThreshold < 0 {
+		errs = append(errs, fmt.Errorf("CIRCUIT_THRESHOLD must not be negative, got %d", c.CircuitThreshold))
+	}
+	if c.CircuitBackoff < 0 {
+		errs = append(errs, fmt.Errorf("CIRCUIT_BACKOFF must not be negative, got %d", c.CircuitBackoff))
+	}
+	if c.ChurnBudgetHourly < 0 {
+		errs = append(errs, fmt.Errorf("CHURN_BUDGET_HOURLY must not be negative, got %d", c.ChurnBudgetHourly))
+	}
+	if c.ChurnBudgetDaily < 0 {
+		errs = append(errs, fmt.Errorf("CHURN_BUDGET_DAILY must not be negative, got %d", c.ChurnBudgetDaily))
+	}
+	if c.IdempotencyTTL < 0 {
+		errs = append(errs, fmt.Errorf("IDEMPOTENCY_TTL must not be negative, got %d", c.IdempotencyTTL))
+	}
+	if c.NotifyMinSeverity != "" {
+		if _, err := notifications.ParseSeverity(c.NotifyMinSeverity); err != nil {
+			errs = append(errs, fmt.Errorf("NOTIFY_MIN_SEVERITY: %w", err))
+		}
+	}
+
+	if _, err := c.HolidayCalendar(); err != nil {
+		errs = append(errs, fmt.Errorf("HOLIDAYS: %w", err))
+	}
+
+	if c.NotifyQuietHours != "" {
+		if _, err := notifications.ParseQuietWindow(c.NotifyQuietHours, c.NotifyQuietTimezone); err != nil {
+			errs = append(errs, fmt.Errorf("NOTIFY_QUIET_HOURS: %w", err))
+		}
+	}
+
+	if c.AuditS3URI != "" && !strings.HasPrefix(c.AuditS3URI, "s3://") {
+		errs = append(errs, fmt.Errorf("AUDIT_S3_URI must be an s3://bucket/prefix URI, got %s", c.AuditS3URI))
+	}
+
+	if c.PlanS3URI != "" {
+		if !strings.HasPrefix(c.PlanS3URI, "s3://") {
+			errs = append(errs, fmt.Errorf("PLAN_S3_URI must be an s3://bucket/prefix URI, got %s", c.PlanS3URI))
+		}
+		if strings.TrimSuffix(c.PlanS3URI, "/") == strings.TrimSuffix(c.AuditS3URI, "/") {
+			errs = append(errs, errors.New("PLAN_S3_URI must differ from AUDIT_S3_URI, as plans are not audit records"))
+		}
+	}
+
+	if c.ReportS3URI != "" {
+		if !strings.HasPrefix(c.ReportS3URI, "s3://") {
+			errs = append(errs, fmt.Errorf("REPORT_S3_URI must be an s3://bucket/prefix URI, got %s", c.ReportS3URI))
+		}
+		if strings.TrimSuffix(c.ReportS3URI, "/") == strings.TrimSuffix(c.AuditS3URI, "/") {
+			errs = append(errs, errors.New("REPORT_S3_URI must differ from AUDIT_S3_URI, as reports are not audit records"))
+		}
+	}
+
+	if c.TicketSystem != "" {
+		if !slices.Contains(notifications.TicketSystems, c.TicketSystem) {
+			errs = append(errs, fmt.Errorf("TICKET_SYSTEM must be one of %s, got %s", strings.Join(notifications.TicketSystems, ", "), c.TicketSystem))
+		}
+		if c.TicketURL == "" {
+			errs = append(errs, errors.New("TICKET_URL is not set"))
+		}
+		if c.TicketSystem == notifications.TicketSystemJira && c.TicketProject == "" {
+			errs = append(errs, errors.New("TICKET_PROJECT is not set"))
+		}
+		if c.TicketThreshold < 1 {
+			errs = append(errs, fmt.Errorf("TICKET_THRESHOLD must be at least 1, got %d", c.TicketThreshold))
+		}
+	}
+
+	if c.AssumeRoleExternalID != "" && c.AssumeRoleArn == "" {
+		errs = append(errs, errors.New("ASSUME_ROLE_EXTERNAL_ID is set without ASSUME_ROLE_ARN"))
+	}
+
+	if c.MaxRetries < 1 {
+		errs = append(errs, fmt.Errorf("MAX_RETRIES must be at least 1, got %d", c.MaxRetries))
+	}
+	if c.InitialBackoff < 0 {
+		errs = append(errs, fmt.Errorf("INITIAL_BACKOFF must not be negative, got %d", c.InitialBackoff))
+	}
+	if c.MetricConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("METRIC_CONCURRENCY must not be negative, got %d", c.MetricConcurrency))
+	}
+	if c.APIRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("API_RATE_LIMIT must not be negative, got %v", c.APIRateLimit))
+	}
+	if c.DeadlineMargin < 0 {
+		errs = append(errs, fmt.Errorf("DEADLINE_MARGIN must not be negative, got %d", c.DeadlineMargin))
+	}
+
+	return errors.Join(errs...)
+}
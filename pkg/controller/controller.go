@@ -0,0 +1,153 @@
+// Package controller reconciles DocDBAutoscaler custom resources, so that autoscaling policies can be
+// declared as Kubernetes manifests, e.g. from a GitOps repository.
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+)
+
+// Scaler takes the scaling action of a cluster, an *autoscaling.DocumentDB outside tests.
+type Scaler interface {
+	ExecuteScalingAction(ctx context.Context) error
+	LastResult() *autoscaling.ScalingResult
+}
+
+// Controller periodically takes the metric-based scaling action of every DocDBAutoscaler resource
+// and records the outcome in the resource status.
+type Controller struct {
+	Client    ResourceClient
+	Namespace string         // Namespace of the resources, all namespaces when empty
+	Settings  *config.Config // Settings the resources do not declare, e.g. SNS_TOPIC_ARN
+	NewScaler func(settings *config.Config) (Scaler, error)
+	Interval  time.Duration
+	Logger    *slog.Logger
+
+	now func() time.Time
+}
+
+// Run reconciles the resources every Interval until ctx is done.
+func (c *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.ReconcileAll(ctx); err != nil {
+			c.Logger.Error("Failed to reconcile resources", "Error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileAll reconciles every resource. A failing resource does not stop the others.
+func (c *Controller) ReconcileAll(ctx context.Context) error {
+	resources, err := c.Client.List(ctx, c.Namespace)
+	if err != nil {
+		return err
+	}
+	for i := range resources {
+		resource := &resources[i]
+		c.Reconcile(ctx, resource)
+		if err := c.Client.UpdateStatus(ctx, resource); err != nil {
+			c.Logger.Error("Failed to update resource status", "Error", err, "Resource", resource.Metadata.Namespace+"/"+resource.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+// Reconcile takes the scaling action declared by the resource and updates its status.
+// No action is taken during the cooldown of the last scaling action.
+func (c *Controller) Reconcile(ctx context.Context, resource *DocDBAutoscaler) {
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+	logger := c.Logger.With("Resource", resource.Metadata.Namespace+"/"+resource.Metadata.Name, "ClusterID", resource.Spec.ClusterID)
+	status := &resource.Status
+	status.ObservedGeneration = resource.Metadata.Generation
+	status.LastReconcileTime = now.UTC().Format(time.RFC3339)
+
+	fail := func(message string, err error) {
+		logger.Error(message, "Error", err)
+		status.LastDecision = ""
+		status.Error = err.Error()
+	}
+
+	settings, err := c.resolve(resource.Spec)
+	if err != nil {
+		fail("Invalid DocDBAutoscaler spec", err)
+		return
+	}
+	status.Error = ""
+
+	if coolingDown(status, settings, now) {
+		logger.Info("Scaling action is in cooldown, skipping", "LastScaleDecision", status.LastScaleDecision, "LastScaleTime", status.LastScaleTime)
+		status.LastDecision = autoscaling.DecisionNoAction
+		return
+	}
+
+	scaler, err := c.NewScaler(settings)
+	if err != nil {
+		fail("Failed to initialize autoscaler", err)
+		return
+	}
+	if err := scaler.ExecuteScalingAction(ctx); err != nil {
+		fail("Scaling action failed", err)
+		return
+	}
+
+	result := scaler.LastResult()
+	status.LastDecision = result.Decision
+	status.ReplicasAdded = result.ReplicasAdded
+	status.ReplicasRemoved = result.ReplicasRemoved
+	if (result.Decision == autoscaling.DecisionScaleOut || result.Decision == autoscaling.DecisionScaleIn) && !result.DryRun {
+		status.LastScaleDecision = result.Decision
+		status.LastScaleTime = status.LastReconcileTime
+	}
+	logger.Info("Reconciled DocDBAutoscaler", "Decision", result.Decision, "ReplicasAdded", result.ReplicasAdded, "ReplicasRemoved", result.ReplicasRemoved)
+}
+
+// resolve returns the validated settings of the resource: the spec, as per-cluster overrides of Settings.
+func (c *Controller) resolve(spec DocDBAutoscalerSpec) (*config.Config, error) {
+	settings := *c.Settings
+	settings.Clusters = map[string]config.ClusterOverride{
+		spec.ClusterID: {
+			MinCapacity:      &spec.MinCapacity,
+			MaxCapacity:      &spec.MaxCapacity,
+			MetricName:       spec.MetricName,
+			TargetValue:      &spec.TargetValue,
+			ScaleInCooldown:  spec.ScaleInCooldown,
+			ScaleOutCooldown: spec.ScaleOutCooldown,
+			InstanceType:     spec.InstanceType,
+			DryRun:           spec.DryRun,
+			Engine:           spec.Engine,
+			Region:           spec.Region,
+			AssumeRoleArn:    spec.AssumeRoleArn,
+		},
+	}
+	scheduledScaling := false
+	return settings.Resolve(config.Overrides{ClusterID: spec.ClusterID, ScheduledScaling: &scheduledScaling})
+}
+
+// coolingDown reports whether the cooldown of the last scaling action has not elapsed yet.
+func coolingDown(status *DocDBAutoscalerStatus, settings *config.Config, now time.Time) bool {
+	if status.LastScaleTime == "" {
+		return false
+	}
+	lastScaleTime, err := time.Parse(time.RFC3339, status.LastScaleTime)
+	if err != nil {
+		return false
+	}
+	cooldown := settings.ScaleOutCooldown
+	if status.LastScaleDecision == autoscaling.DecisionScaleIn {
+		cooldown = settings.ScaleInCooldown
+	}
+	return now.Sub(lastScaleTime) < time.Duration(cooldown)*time.Second
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/autoscaling"
+	"github.com/cheelim1/docdb-autoscaler/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeScaler records the settings it was built with and returns a fixed decision.
+type fakeScaler struct {
+	settings *config.Config
+	decision string
+	calls    int
+}
+
+func (f *fakeScaler) ExecuteScalingAction(ctx context.Context) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeScaler) LastResult() *autoscaling.ScalingResult {
+	result := autoscaling.NewScalingResult(f.settings.DryRun)
+	result.Decision = f.decision
+	if f.decision == autoscaling.DecisionScaleOut {
+		result.ReplicasAdded = 1
+	}
+	return result
+}
+
+func newTestController(t *testing.T, scaler *fakeScaler) *Controller {
+	t.Setenv("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:notify")
+	settings, err := config.Load(context.Background(), nil)
+	assert.NoError(t, err)
+
+	return &Controller{
+		Settings: settings,
+		NewScaler: func(settings *config.Config) (Scaler, error) {
+			scaler.settings = settings
+			return scaler, nil
+		},
+		Logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+}
+
+func newTestResource() *DocDBAutoscaler {
+	cooldown := 600
+	return &DocDBAutoscaler{
+		Metadata: ObjectMeta{Name: "orders", Namespace: "payments", Generation: 2},
+		Spec: DocDBAutoscalerSpec{
+			ClusterID:        "orders-cluster",
+			MinCapacity:      1,
+			MaxCapacity:      4,
+			MetricName:       "CPUUtilization",
+			TargetValue:      60,
+			ScaleInCooldown:  &cooldown,
+			ScaleOutCooldown: &cooldown,
+		},
+	}
+}
+
+// TestReconcile tests that the spec drives the scaling action and that the cooldown of a scale-out is honored.
+func TestReconcile(t *testing.T) {
+	scaler := &fakeScaler{decision: autoscaling.DecisionScaleOut}
+	c := newTestController(t, scaler)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	resource := newTestResource()
+	c.Reconcile(context.Background(), resource)
+
+	assert.Equal(t, "orders-cluster", scaler.settings.ClusterID)
+	assert.Equal(t, 4, scaler.settings.MaxCapacity)
+	assert.Equal(t, "CPUUtilization", scaler.settings.MetricName)
+	assert.Equal(t, int64(2), resource.Status.ObservedGeneration)
+	assert.Equal(t, autoscaling.DecisionScaleOut, resource.Status.LastDecision)
+	assert.Equal(t, 1, resource.Status.ReplicasAdded)
+	assert.Equal(t, "2024-05-01T12:00:00Z", resource.Status.LastScaleTime)
+	assert.Empty(t, resource.Status.Error)
+
+	// Within the scale-out cooldown
+	now = now.Add(5 * time.Minute)
+	c.Reconcile(context.Background(), resource)
+	assert.Equal(t, 1, scaler.calls)
+	assert.Equal(t, autoscaling.DecisionNoAction, resource.Status.LastDecision)
+
+	// After the cooldown
+	now = now.Add(10 * time.Minute)
+	c.Reconcile(context.Background(), resource)
+	assert.Equal(t, 2, scaler.calls)
+}
+
+// TestReconcile_InvalidSpec tests that validation errors are reported in the status.
+func TestReconcile_InvalidSpec(t *testing.T) {
+	scaler := &fakeScaler{decision: autoscaling.DecisionNoAction}
+	c := newTestController(t, scaler)
+
+	resource := newTestResource()
+	resource.Spec.MinCapacity = 5
+	c.Reconcile(context.Background(), resource)
+
+	assert.Equal(t, 0, scaler.calls)
+	assert.Contains(t, resource.Status.Error, "MIN_CAPACITY (5) must not exceed MAX_CAPACITY (4)")
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir holds the credentials of the pod's service account.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ResourceClient lists DocDBAutoscaler resources and updates their status.
+type ResourceClient interface {
+	List(ctx context.Context, namespace string) ([]DocDBAutoscaler, error)
+	UpdateStatus(ctx context.Context, resource *DocDBAutoscaler) error
+}
+
+// KubeClient is a ResourceClient for the Kubernetes API server, authenticated with a service account token.
+type KubeClient struct {
+	BaseURL    string
+	TokenFile  string // Read on every request, as projected tokens are rotated
+	HTTPClient *http.Client
+}
+
+// NewInClusterClient returns a KubeClient for the API server of the cluster the pod runs in.
+func NewInClusterClient() (*KubeClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	caCert, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA certificate: %w", err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("invalid service account CA certificate")
+	}
+	return &KubeClient{
+		BaseURL:   "https://" + net.JoinHostPort(host, port),
+		TokenFile: serviceAccountDir + "/token",
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}},
+		},
+	}, nil
+}
+
+// resourcePath returns the API path of the resources of a namespace, or of all namespaces when namespace is empty.
+func resourcePath(namespace string) string {
+	if namespace == "" {
+		return fmt.Sprintf("/apis/%s/%s/%s", Group, Version, Resource)
+	}
+	return fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", Group, Version, namespace, Resource)
+}
+
+// List returns the DocDBAutoscaler resources of a namespace, or of all namespaces when namespace is empty.
+func (k *KubeClient) List(ctx context.Context, namespace string) ([]DocDBAutoscaler, error) {
+	var list struct {
+		Items []DocDBAutoscaler `json:"items"`
+	}
+	if err := k.do(ctx, http.MethodGet, resourcePath(namespace), nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// UpdateStatus replaces the status subresource of the resource.
+func (k *KubeClient) UpdateStatus(ctx context.Context, resource *DocDBAutoscaler) error {
+	path := resourcePath(resource.Metadata.Namespace) + "/" + resource.Metadata.Name + "/status"
+	return k.do(ctx, http.MethodPut, path, resource, nil)
+}
+
+// do sends a JSON request to the API server and decodes the JSON response into out, if any.
+func (k *KubeClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, k.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if in != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if k.TokenFile != "" {
+		token, err := os.ReadFile(k.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	response, err := k.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, response.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(out)
+}
@@ -0,0 +1,54 @@
+package controller
+
+// API group, version and plural of the DocDBAutoscaler custom resource.
+const (
+	Group    = "docdb.cheelim1.github.io"
+	Version  = "v1alpha1"
+	Resource = "docdbautoscalers"
+)
+
+// DocDBAutoscaler declares the autoscaling policy of a cluster as a Kubernetes custom resource.
+type DocDBAutoscaler struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Metadata   ObjectMeta            `json:"metadata"`
+	Spec       DocDBAutoscalerSpec   `json:"spec"`
+	Status     DocDBAutoscalerStatus `json:"status"`
+}
+
+// ObjectMeta holds the Kubernetes object metadata used by the controller.
+type ObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Generation      int64  `json:"generation,omitempty"`
+}
+
+// DocDBAutoscalerSpec is the metric-based scaling policy of a cluster.
+// Optional fields keep the controller's configuration, e.g. its environment variables.
+type DocDBAutoscalerSpec struct {
+	ClusterID        string  `json:"clusterIdentifier"`
+	MinCapacity      int     `json:"minCapacity"`
+	MaxCapacity      int     `json:"maxCapacity"`
+	MetricName       string  `json:"metricName"`
+	TargetValue      float64 `json:"targetValue"`
+	ScaleInCooldown  *int    `json:"scaleInCooldown,omitempty"`  // In seconds
+	ScaleOutCooldown *int    `json:"scaleOutCooldown,omitempty"` // In seconds
+	InstanceType     string  `json:"instanceType,omitempty"`
+	DryRun           *bool   `json:"dryRun,omitempty"`
+	Engine           string  `json:"engine,omitempty"`
+	Region           string  `json:"region,omitempty"`
+	AssumeRoleArn    string  `json:"assumeRoleArn,omitempty"`
+}
+
+// DocDBAutoscalerStatus reports the outcome of the last reconciliation.
+type DocDBAutoscalerStatus struct {
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	LastReconcileTime  string `json:"lastReconcileTime,omitempty"` // RFC 3339
+	LastDecision       string `json:"lastDecision,omitempty"`
+	LastScaleDecision  string `json:"lastScaleDecision,omitempty"` // ScaleOut or ScaleIn
+	LastScaleTime      string `json:"lastScaleTime,omitempty"`     // RFC 3339
+	ReplicasAdded      int    `json:"replicasAdded,omitempty"`
+	ReplicasRemoved    int    `json:"replicasRemoved,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
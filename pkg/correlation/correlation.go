@@ -0,0 +1,30 @@
+// Package correlation carries the correlation ID of an invocation in its context, so that a scaling
+// action can be traced across logs, notifications, events and audit records.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// contextKey is the key of the correlation ID in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the correlation ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID of ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// NewID generates a random correlation ID.
+func NewID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
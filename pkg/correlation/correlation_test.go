@@ -0,0 +1,17 @@
+package correlation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", FromContext(context.Background()))
+	assert.Equal(t, "abc", FromContext(NewContext(context.Background(), "abc")))
+
+	id := NewID()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, NewID())
+}
@@ -0,0 +1,121 @@
+// Package events routes the payloads a Lambda function is invoked with to the handlers of their shape,
+// e.g. SNS, EventBridge, SQS, HTTP requests or direct invocations, so that an event source is added by
+// registering a handler.
+//
+// Routes are tried in registration order, and the first one whose shape matches the payload handles it.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler handles a payload, returning the response of the invocation.
+type Handler func(ctx context.Context, logger *slog.Logger, payload json.RawMessage) (any, error)
+
+// Matcher reports whether a payload has the shape of a route.
+type Matcher func(payload json.RawMessage) bool
+
+// route is a registered handler and the shape of its payloads.
+type route struct {
+	name    string
+	match   Matcher
+	handler Handler
+}
+
+// Router routes payloads to the first registered handler of their shape.
+type Router struct {
+	routes   []route
+	fallback Handler
+}
+
+// NewRouter returns a router without routes, ignoring every payload.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle registers the handler of the payloads match accepts. The name is logged when a payload is
+// routed to it.
+func (r *Router) Handle(name string, match Matcher, handler Handler) {
+	r.routes = append(r.routes, route{name: name, match: match, handler: handler})
+}
+
+// Fallback registers the handler of the payloads no route matches.
+func (r *Router) Fallback(handler Handler) {
+	r.fallback = handler
+}
+
+// Register registers the handler of the payloads that decode as T and that match accepts, handing it the
+// decoded event.
+func Register[T any](r *Router, name string, match func(event T) bool, handler func(ctx context.Context, logger *slog.Logger, event T) (any, error)) {
+	r.Handle(name, Shape(match), func(ctx context.Context, logger *slog.Logger, payload json.RawMessage) (any, error) {
+		var event T
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+		return handler(ctx, logger, event)
+	})
+}
+
+// Route handles a payload with the first route of its shape, or the fallback. Payloads nothing handles
+// are ignored.
+func (r *Router) Route(ctx context.Context, logger *slog.Logger, payload json.RawMessage) (any, error) {
+	for _, route := range r.routes {
+		if route.match(payload) {
+			logger.Info("Detected " + route.name)
+			return route.handler(ctx, logger, payload)
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, logger, payload)
+	}
+	return nil, nil
+}
+
+// Shape returns the matcher of the payloads that decode as T and that match accepts.
+func Shape[T any](match func(event T) bool) Matcher {
+	return func(payload json.RawMessage) bool {
+		var event T
+		return json.Unmarshal(payload, &event) == nil && match(event)
+	}
+}
+
+// Event sources of records.
+const (
+	SourceSNS = "aws:sns"
+	SourceSQS = "aws:sqs"
+)
+
+// IsSNS reports whether an event is a delivery of SNS messages. Records without an event source are
+// taken as SNS messages, e.g. in hand-written test events.
+func IsSNS(event events.SNSEvent) bool {
+	return len(event.Records) > 0 && (event.Records[0].EventSource == SourceSNS || event.Records[0].EventSource == "")
+}
+
+// IsSQS reports whether an event is a batch of SQS messages.
+func IsSQS(event events.SQSEvent) bool {
+	return len(event.Records) > 0 && event.Records[0].EventSource == SourceSQS
+}
+
+// IsEventBridge reports whether an event was sent by EventBridge, e.g. by a schedule or a rule.
+func IsEventBridge(event events.CloudWatchEvent) bool {
+	return event.Source != ""
+}
+
+// IsHTTP reports whether an event is an HTTP request of a Lambda function URL or an API Gateway HTTP API.
+func IsHTTP(event events.APIGatewayV2HTTPRequest) bool {
+	return event.RequestContext.HTTP.Method != ""
+}
+
+// SNSMessage returns the SNS message an SQS message carries, for queues subscribed to topics, and
+// the body itself as the message otherwise, e.g. with raw message delivery.
+func SNSMessage(message events.SQSMessage) events.SNSEntity {
+	var entity events.SNSEntity
+	if err := json.Unmarshal([]byte(message.Body), &entity); err == nil && entity.Type == "Notification" && entity.MessageID != "" {
+		return entity
+	}
+	return events.SNSEntity{MessageID: message.MessageId, Message: message.Body}
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRoute tests that payloads are handled by the first route of their shape, and the others by the
+// fallback.
+func TestRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := NewRouter()
+	Register(r, "SNSEvent", IsSNS, func(ctx context.Context, logger *slog.Logger, event events.SNSEvent) (any, error) {
+		return "sns:" + event.Records[0].SNS.Message, nil
+	})
+	Register(r, "SQSEvent", IsSQS, func(ctx context.Context, logger *slog.Logger, event events.SQSEvent) (any, error) {
+		return "sqs:" + SNSMessage(event.Records[0]).Message, nil
+	})
+	Register(r, "CloudWatchEvent", IsEventBridge, func(ctx context.Context, logger *slog.Logger, event events.CloudWatchEvent) (any, error) {
+		return "eventbridge:" + event.Source, nil
+	})
+	Register(r, "HTTP request", IsHTTP, func(ctx context.Context, logger *slog.Logger, event events.APIGatewayV2HTTPRequest) (any, error) {
+		return "http:" + event.RequestContext.HTTP.Method, nil
+	})
+	Register(r, "DirectInvocation", func(event struct{ DesiredReplicas *int }) bool { return event.DesiredReplicas != nil }, func(ctx context.Context, logger *slog.Logger, event struct{ DesiredReplicas *int }) (any, error) {
+		return *event.DesiredReplicas, nil
+	})
+
+	route := func(payload string) any {
+		response, err := r.Route(context.Background(), logger, json.RawMessage(payload))
+		assert.NoError(t, err)
+		return response
+	}
+	assert.Equal(t, "sns:scale", route(`{"Records":[{"EventSource":"aws:sns","Sns":{"MessageId":"1","Message":"scale"}}]}`))
+	assert.Equal(t, "sns:scale", route(`{"Records":[{"Sns":{"MessageId":"1","Message":"scale"}}]}`))
+	assert.Equal(t, "sqs:scale", route(`{"Records":[{"eventSource":"aws:sqs","messageId":"1","body":"{\"Type\":\"Notification\",\"MessageId\":\"2\",\"Message\":\"scale\"}"}]}`))
+	assert.Equal(t, "sqs:raw", route(`{"Records":[{"eventSource":"aws:sqs","messageId":"1","body":"raw"}]}`))
+	assert.Equal(t, "eventbridge:aws.events", route(`{"source":"aws.events","detail-type":"Scheduled Event"}`))
+	assert.Equal(t, "http:POST", route(`{"requestContext":{"http":{"method":"POST"}}}`))
+	assert.Equal(t, 3, route(`{"DesiredReplicas":3}`))
+	assert.Nil(t, route(`{"Unknown":true}`))
+
+	r.Fallback(func(ctx context.Context, logger *slog.Logger, payload json.RawMessage) (any, error) {
+		return "fallback", nil
+	})
+	assert.Equal(t, "fallback", route(`{"Unknown":true}`))
+	assert.Equal(t, "fallback", route(`not json`))
+}
@@ -0,0 +1,104 @@
+// Package idempotency records the messages that were processed, so that a message delivered twice,
+// e.g. by the at-least-once delivery of SNS, is processed once.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrDuplicate is returned when a message was already processed.
+var ErrDuplicate = errors.New("message was already processed")
+
+// DefaultTTL is how long a processed message is remembered, longer than SNS retries its deliveries.
+const DefaultTTL = 24 * time.Hour
+
+// Store records processed messages.
+type Store interface {
+	// Claim records the message of id as processed, or returns ErrDuplicate.
+	Claim(ctx context.Context, id string) error
+	// Forget removes the record of the message of id, so that a redelivery is processed again,
+	// e.g. after its processing failed.
+	Forget(ctx context.Context, id string) error
+}
+
+// DynamoDBAPI defines the interface for Amazon DynamoDB client methods used.
+type DynamoDBAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDB keeps one item per processed message, with the partition key MessageId, in a table whose TTL
+// attribute is ExpiresAt (in seconds since the epoch). A message is claimed with a conditional put, which
+// only succeeds when the message is unknown or its record expired but was not yet deleted by the TTL.
+type DynamoDB struct {
+	Client DynamoDBAPI
+	Table  string
+	TTL    time.Duration
+
+	now func() time.Time
+}
+
+// NewDynamoDB creates a new DynamoDB store of the table, with DefaultTTL when ttl is zero.
+func NewDynamoDB(client DynamoDBAPI, table string, ttl time.Duration) *DynamoDB {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &DynamoDB{Client: client, Table: table, TTL: ttl}
+}
+
+// Ensure DynamoDB implements Store
+var _ Store = (*DynamoDB)(nil)
+
+// Claim records the message of id as processed for the TTL, or returns ErrDuplicate.
+func (d *DynamoDB) Claim(ctx context.Context, id string) error {
+	now := d.currentTime()
+	_, err := d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.Table),
+		Item: map[string]dynamodbTypes.AttributeValue{
+			"MessageId": &dynamodbTypes.AttributeValueMemberS{Value: id},
+			"ExpiresAt": &dynamodbTypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(d.TTL).Unix(), 10)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(MessageId) OR ExpiresAt < :now"),
+		ExpressionAttributeValues: map[string]dynamodbTypes.AttributeValue{
+			":now": &dynamodbTypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	})
+	var conditionFailed *dynamodbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record message %s: %w", id, err)
+	}
+	return nil
+}
+
+// Forget removes the record of the message of id.
+func (d *DynamoDB) Forget(ctx context.Context, id string) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.Table),
+		Key: map[string]dynamodbTypes.AttributeValue{
+			"MessageId": &dynamodbTypes.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to forget message %s: %w", id, err)
+	}
+	return nil
+}
+
+// currentTime returns the current time, or the fixed time of tests.
+func (d *DynamoDB) currentTime() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
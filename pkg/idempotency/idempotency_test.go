@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDynamoDB is an in-memory table evaluating the conditions of the store.
+type memoryDynamoDB map[string]map[string]dynamodbTypes.AttributeValue
+
+func stringValue(item map[string]dynamodbTypes.AttributeValue, name string) string {
+	if value, ok := item[name].(*dynamodbTypes.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+func numberValue(item map[string]dynamodbTypes.AttributeValue, name string) int64 {
+	value, _ := item[name].(*dynamodbTypes.AttributeValueMemberN)
+	if value == nil {
+		return 0
+	}
+	number, _ := strconv.ParseInt(value.Value, 10, 64)
+	return number
+}
+
+func (m memoryDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key := stringValue(params.Item, "MessageId")
+	if existing, found := m[key]; found && numberValue(existing, "ExpiresAt") >= numberValue(params.ExpressionAttributeValues, ":now") {
+		return nil, &dynamodbTypes.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	m[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m memoryDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	delete(m, stringValue(params.Key, "MessageId"))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// TestDynamoDB tests that a message is claimed once until it is forgotten or its record expires.
+func TestDynamoDB(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	table := memoryDynamoDB{}
+	store := NewDynamoDB(table, "messages", time.Hour)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, store.Claim(ctx, "message-1"))
+	assert.ErrorIs(t, store.Claim(ctx, "message-1"), ErrDuplicate)
+	assert.NoError(t, store.Claim(ctx, "message-2"), "messages are recorded per ID")
+
+	// A forgotten message is processed again
+	assert.NoError(t, store.Forget(ctx, "message-1"))
+	assert.NoError(t, store.Claim(ctx, "message-1"))
+
+	// An expired record is replaced
+	now = now.Add(2 * time.Hour)
+	assert.NoError(t, store.Claim(ctx, "message-2"))
+}
@@ -0,0 +1,120 @@
+// Package lock provides per-cluster locks, so that only one invocation mutates a cluster at a time,
+// e.g. when an SNS retry and an EventBridge schedule fire concurrently.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrLocked is returned when another owner holds an unexpired lease of the lock.
+var ErrLocked = errors.New("lock is held by another owner")
+
+// DefaultLease is how long a lock is held when its owner does not release it, e.g. after a timeout.
+const DefaultLease = 5 * time.Minute
+
+// Locker takes and releases locks.
+type Locker interface {
+	// Acquire takes the lock of key for owner, or returns ErrLocked.
+	Acquire(ctx context.Context, key, owner string) error
+	// Release releases the lock of key, if owner still holds it.
+	Release(ctx context.Context, key, owner string) error
+}
+
+// DynamoDBAPI defines the interface for Amazon DynamoDB client methods used.
+type DynamoDBAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDB keeps one item per lock, with the partition key LockKey, in a table whose TTL attribute is
+// ExpiresAt (in seconds since the epoch). A lock is taken with a conditional put, which only succeeds
+// when the lock is free, its lease expired, or the owner already holds it.
+type DynamoDB struct {
+	Client DynamoDBAPI
+	Table  string
+	Lease  time.Duration
+
+	now func() time.Time
+}
+
+// NewDynamoDB creates a new DynamoDB locker of the table, with DefaultLease when lease is zero.
+func NewDynamoDB(client DynamoDBAPI, table string, lease time.Duration) *DynamoDB {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	return &DynamoDB{Client: client, Table: table, Lease: lease}
+}
+
+// Ensure DynamoDB implements Locker
+var _ Locker = (*DynamoDB)(nil)
+
+// Acquire takes the lock of key for owner for the lease, or returns ErrLocked.
+func (d *DynamoDB) Acquire(ctx context.Context, key, owner string) error {
+	now := d.currentTime()
+	_, err := d.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.Table),
+		Item: map[string]dynamodbTypes.AttributeValue{
+			"LockKey":   &dynamodbTypes.AttributeValueMemberS{Value: key},
+			"Owner":     &dynamodbTypes.AttributeValueMemberS{Value: owner},
+			"ExpiresAt": &dynamodbTypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(d.Lease).Unix(), 10)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(LockKey) OR ExpiresAt < :now OR #owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner": "Owner", // Reserved word
+		},
+		ExpressionAttributeValues: map[string]dynamodbTypes.AttributeValue{
+			":now":   &dynamodbTypes.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			":owner": &dynamodbTypes.AttributeValueMemberS{Value: owner},
+		},
+	})
+	var conditionFailed *dynamodbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrLocked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	return nil
+}
+
+// Release releases the lock of key, if owner still holds it. A lock taken over after its lease
+// expired is left to its new owner.
+func (d *DynamoDB) Release(ctx context.Context, key, owner string) error {
+	_, err := d.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.Table),
+		Key: map[string]dynamodbTypes.AttributeValue{
+			"LockKey": &dynamodbTypes.AttributeValueMemberS{Value: key},
+		},
+		ConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner": "Owner",
+		},
+		ExpressionAttributeValues: map[string]dynamodbTypes.AttributeValue{
+			":owner": &dynamodbTypes.AttributeValueMemberS{Value: owner},
+		},
+	})
+	var conditionFailed *dynamodbTypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", key, err)
+	}
+	return nil
+}
+
+// currentTime returns the current time, or the fixed time of tests.
+func (d *DynamoDB) currentTime() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDynamoDB is an in-memory table evaluating the conditions of the locker.
+type memoryDynamoDB map[string]map[string]dynamodbTypes.AttributeValue
+
+func stringValue(item map[string]dynamodbTypes.AttributeValue, name string) string {
+	if value, ok := item[name].(*dynamodbTypes.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+func numberValue(item map[string]dynamodbTypes.AttributeValue, name string) int64 {
+	value, _ := item[name].(*dynamodbTypes.AttributeValueMemberN)
+	if value == nil {
+		return 0
+	}
+	number, _ := strconv.ParseInt(value.Value, 10, 64)
+	return number
+}
+
+func (m memoryDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key := stringValue(params.Item, "LockKey")
+	if existing, found := m[key]; found {
+		expired := numberValue(existing, "ExpiresAt") < numberValue(params.ExpressionAttributeValues, ":now")
+		if !expired && stringValue(existing, "Owner") != stringValue(params.ExpressionAttributeValues, ":owner") {
+			return nil, &dynamodbTypes.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+		}
+	}
+	m[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m memoryDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	key := stringValue(params.Key, "LockKey")
+	if stringValue(m[key], "Owner") != stringValue(params.ExpressionAttributeValues, ":owner") {
+		return nil, &dynamodbTypes.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	delete(m, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// TestDynamoDB tests that a lock has a single owner until it is released or its lease expires.
+func TestDynamoDB(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	table := memoryDynamoDB{}
+	locker := NewDynamoDB(table, "locks", time.Minute)
+	locker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, locker.Acquire(ctx, "cluster/orders", "first"))
+	assert.ErrorIs(t, locker.Acquire(ctx, "cluster/orders", "second"), ErrLocked)
+	assert.NoError(t, locker.Acquire(ctx, "cluster/orders", "first"), "the owner may renew its lease")
+	assert.NoError(t, locker.Acquire(ctx, "cluster/users", "second"), "locks are per key")
+
+	// Releasing a lock held by another owner leaves it in place
+	assert.NoError(t, locker.Release(ctx, "cluster/orders", "second"))
+	assert.ErrorIs(t, locker.Acquire(ctx, "cluster/orders", "second"), ErrLocked)
+	assert.NoError(t, locker.Release(ctx, "cluster/orders", "first"))
+	assert.NoError(t, locker.Acquire(ctx, "cluster/orders", "second"))
+
+	// An expired lease is taken over
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, locker.Acquire(ctx, "cluster/orders", "third"))
+}
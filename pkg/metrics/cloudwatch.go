@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// CloudWatchAPI defines the interface for Amazon CloudWatch client methods used.
+type CloudWatchAPI interface {
+	PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
+
+// CloudWatch publishes the capacity of the cluster after every invocation as custom metrics, with the
+// dimension ClusterId, so that capacity history can be graphed and alarmed on even when no scaling occurred:
+// CurrentCapacity, DesiredCapacity and AutoscalerManagedReplicas.
+type CloudWatch struct {
+	Client    CloudWatchAPI
+	Namespace string
+}
+
+// NewCloudWatch creates a new CloudWatch publishing in the namespace.
+func NewCloudWatch(client CloudWatchAPI, namespace string) *CloudWatch {
+	return &CloudWatch{Client: client, Namespace: namespace}
+}
+
+// Ensure CloudWatch implements Recorder
+var _ Recorder = (*CloudWatch)(nil)
+
+// RecordInvocation publishes the capacity metrics of the invocation that are known, if any.
+func (c *CloudWatch) RecordInvocation(ctx context.Context, invocation Invocation) error {
+	var data []cwTypes.MetricDatum
+	for _, metric := range []struct {
+		name  string
+		value *int
+	}{
+		{"CurrentCapacity", invocation.CurrentCapacity},
+		{"DesiredCapacity", invocation.DesiredCapacity},
+		{"AutoscalerManagedReplicas", invocation.ManagedReplicas},
+	} {
+		if metric.value == nil {
+			continue
+		}
+		data = append(data, cwTypes.MetricDatum{
+			MetricName: aws.String(metric.name),
+			Dimensions: []cwTypes.Dimension{{Name: aws.String("ClusterId"), Value: aws.String(invocation.ClusterID)}},
+			Unit:       cwTypes.StandardUnitCount,
+			Value:      aws.Float64(float64(*metric.value)),
+		})
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := c.Client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(c.Namespace),
+		MetricData: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put capacity metrics: %w", err)
+	}
+	return nil
+}
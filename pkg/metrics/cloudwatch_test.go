@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCloudWatch records the metric data put.
+type recordingCloudWatch struct {
+	inputs []*cloudwatch.PutMetricDataInput
+	err    error
+}
+
+func (r *recordingCloudWatch) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	r.inputs = append(r.inputs, params)
+	return &cloudwatch.PutMetricDataOutput{}, r.err
+}
+
+// TestCloudWatch_RecordInvocation tests that the known capacity metrics are published per cluster.
+func TestCloudWatch_RecordInvocation(t *testing.T) {
+	client := &recordingCloudWatch{}
+	publisher := NewCloudWatch(client, DefaultNamespace)
+
+	current, desired, managed := 3, 3, 1
+	err := publisher.RecordInvocation(context.Background(), Invocation{
+		ClusterID:       "orders",
+		Decision:        "NoAction",
+		CurrentCapacity: &current,
+		DesiredCapacity: &desired,
+		ManagedReplicas: &managed,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, client.inputs, 1)
+	assert.Equal(t, "DocDBAutoscaler", aws.ToString(client.inputs[0].Namespace))
+
+	values := map[string]float64{}
+	for _, datum := range client.inputs[0].MetricData {
+		assert.Equal(t, "ClusterId", aws.ToString(datum.Dimensions[0].Name))
+		assert.Equal(t, "orders", aws.ToString(datum.Dimensions[0].Value))
+		values[aws.ToString(datum.MetricName)] = aws.ToFloat64(datum.Value)
+	}
+	assert.Equal(t, map[string]float64{"CurrentCapacity": 3, "DesiredCapacity": 3, "AutoscalerManagedReplicas": 1}, values)
+}
+
+// TestCloudWatch_RecordInvocation_Unknown tests that nothing is published when no capacity is known.
+func TestCloudWatch_RecordInvocation_Unknown(t *testing.T) {
+	client := &recordingCloudWatch{}
+	publisher := NewCloudWatch(client, DefaultNamespace)
+
+	assert.NoError(t, publisher.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Decision: "Paused"}))
+	assert.Empty(t, client.inputs)
+}
+
+// TestRecorders tests that every recorder records the invocation, even after a failure.
+func TestRecorders(t *testing.T) {
+	failing := &recordingCloudWatch{err: errors.New("throttled")}
+	working := &recordingCloudWatch{}
+	desired := 2
+	recorders := Recorders{NewCloudWatch(failing, "A"), NewCloudWatch(working, "B")}
+
+	err := recorders.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", DesiredCapacity: &desired})
+	assert.ErrorContains(t, err, "throttled")
+	assert.Len(t, failing.inputs, 1)
+	assert.Len(t, working.inputs, 1)
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDatadogSite is the Datadog site of the API, unless configured otherwise.
+const DefaultDatadogSite = "datadoghq.com"
+
+// Datadog submits the metrics of every invocation, and an event of every scaling action or failure,
+// through the Datadog API. Metrics are prefixed with docdb_autoscaler. and tagged with cluster_id and decision.
+type Datadog struct {
+	APIKey     string
+	APIURL     string // e.g. https://api.datadoghq.com
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+// NewDatadog creates a new Datadog instance for an API key of a site, e.g. datadoghq.eu.
+// The default site is used when site is empty.
+func NewDatadog(apiKey, site string) *Datadog {
+	if site == "" {
+		site = DefaultDatadogSite
+	}
+	return &Datadog{
+		APIKey:     apiKey,
+		APIURL:     "https://api." + site,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure Datadog implements Recorder
+var _ Recorder = (*Datadog)(nil)
+
+// Metric types of the series API.
+const (
+	datadogCount = 1
+	datadogGauge = 3
+)
+
+// datadogSeries is a metric of the series API v2.
+type datadogSeries struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"`
+	Points []datadogPoint `json:"points"`
+	Tags   []string       `json:"tags"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// datadogEvent is an event of the events API v1.
+type datadogEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	AlertType      string   `json:"alert_type"` // "info", "warning", "error" or "success"
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+	Tags           []string `json:"tags"`
+}
+
+// RecordInvocation submits the metrics of the invocation, then its event when it scaled the cluster or failed.
+// Capacity metrics are only submitted when known.
+func (d *Datadog) RecordInvocation(ctx context.Context, invocation Invocation) error {
+	timestamp := d.currentTime().Unix()
+	tags := []string{
+		"cluster_id:" + invocation.ClusterID,
+		"decision:" + invocation.Decision,
+		"dry_run:" + strconv.FormatBool(invocation.DryRun),
+	}
+	failures := 0
+	if invocation.Failed {
+		failures = 1
+	}
+	var series []datadogSeries
+	add := func(name string, metricType int, value int) {
+		series = append(series, datadogSeries{
+			Metric: "docdb_autoscaler." + name,
+			Type:   metricType,
+			Points: []datadogPoint{{Timestamp: timestamp, Value: float64(value)}},
+			Tags:   tags,
+		})
+	}
+	add("invocations", datadogCount, 1)
+	add("failures", datadogCount, failures)
+	add("replicas_added", datadogCount, invocation.ReplicasAdded)
+	add("replicas_removed", datadogCount, invocation.ReplicasRemoved)
+	for _, gauge := range []struct {
+		name  string
+		value *int
+	}{
+		{"current_capacity", invocation.CurrentCapacity},
+		{"desired_capacity", invocation.DesiredCapacity},
+		{"managed_replicas", invocation.ManagedReplicas},
+	} {
+		if gauge.value != nil {
+			add(gauge.name, datadogGauge, *gauge.value)
+		}
+	}
+	seriesErr := d.post(ctx, "/api/v2/series", map[string]any{"series": series})
+
+	event, found := datadogEventOf(invocation, tags)
+	if !found {
+		return seriesErr
+	}
+	return errors.Join(seriesErr, d.post(ctx, "/api/v1/events", event))
+}
+
+// datadogEventOf returns the event of an invocation that scaled the cluster or failed.
+func datadogEventOf(invocation Invocation, tags []string) (datadogEvent, bool) {
+	event := datadogEvent{
+		AggregationKey: "docdb-autoscaler/" + invocation.ClusterID,
+		SourceTypeName: "docdb-autoscaler",
+		Tags:           tags,
+	}
+	dryRun := ""
+	if invocation.DryRun {
+		dryRun = " (dry run)"
+	}
+	switch {
+	case invocation.Failed:
+		event.Title = fmt.Sprintf("DocumentDB autoscaler failed on cluster %s", invocation.ClusterID)
+		event.Text = invocation.Error
+		event.AlertType = "error"
+	case invocation.Decision == "ScaleOut":
+		event.Title = fmt.Sprintf("Scaled out cluster %s%s", invocation.ClusterID, dryRun)
+		event.Text = fmt.Sprintf("Added %d replicas.", invocation.ReplicasAdded)
+		event.AlertType = "warning"
+	case invocation.Decision == "ScaleIn":
+		event.Title = fmt.Sprintf("Scaled in cluster %s%s", invocation.ClusterID, dryRun)
+		event.Text = fmt.Sprintf("Removed %d replicas.", invocation.ReplicasRemoved)
+		event.AlertType = "info"
+	default:
+		return datadogEvent{}, false
+	}
+	return event, true
+}
+
+// post posts a JSON body to a path of the API.
+func (d *Datadog) post(ctx context.Context, path string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, d.APIURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("DD-API-KEY", d.APIKey)
+
+	response, err := d.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("Datadog %s: %s: %s", path, response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// currentTime returns the current time, or the fixed time of tests.
+func (d *Datadog) currentTime() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// datadogServer records the requests to the Datadog API.
+func datadogServer(t *testing.T, status int) (*httptest.Server, map[string]map[string]any) {
+	requests := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("DD-API-KEY"))
+		body := map[string]any{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests[r.URL.Path] = body
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// TestDatadog_RecordInvocation tests that metrics and an event are submitted for a scale-out.
+func TestDatadog_RecordInvocation(t *testing.T) {
+	server, requests := datadogServer(t, http.StatusAccepted)
+	datadog := NewDatadog("test-key", "")
+	assert.Equal(t, "https://api.datadoghq.com", datadog.APIURL)
+	datadog.APIURL = server.URL
+	datadog.now = func() time.Time { return time.Unix(1705312800, 0) }
+
+	current, desired := 2, 4
+	err := datadog.RecordInvocation(context.Background(), Invocation{
+		ClusterID:       "orders",
+		Decision:        "ScaleOut",
+		ReplicasAdded:   2,
+		CurrentCapacity: &current,
+		DesiredCapacity: &desired,
+	})
+	assert.NoError(t, err)
+
+	series := requests["/api/v2/series"]["series"].([]any)
+	assert.Len(t, series, 6)
+	first := series[0].(map[string]any)
+	assert.Equal(t, "docdb_autoscaler.invocations", first["metric"])
+	assert.Equal(t, []any{"cluster_id:orders", "decision:ScaleOut", "dry_run:false"}, first["tags"])
+	assert.Equal(t, []any{map[string]any{"timestamp": float64(1705312800), "value": float64(1)}}, first["points"])
+
+	event := requests["/api/v1/events"]
+	assert.Equal(t, "Scaled out cluster orders", event["title"])
+	assert.Equal(t, "Added 2 replicas.", event["text"])
+	assert.Equal(t, "docdb-autoscaler/orders", event["aggregation_key"])
+}
+
+// TestDatadog_RecordInvocation_NoAction tests that no event is submitted when the cluster was not scaled.
+func TestDatadog_RecordInvocation_NoAction(t *testing.T) {
+	server, requests := datadogServer(t, http.StatusAccepted)
+	datadog := NewDatadog("test-key", "datadoghq.eu")
+	datadog.APIURL = server.URL
+
+	assert.NoError(t, datadog.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Decision: "NoAction"}))
+	assert.Contains(t, requests, "/api/v2/series")
+	assert.NotContains(t, requests, "/api/v1/events")
+}
+
+// TestDatadog_RecordInvocation_Failure tests the error event of a failure, and the error of a rejected request.
+func TestDatadog_RecordInvocation_Failure(t *testing.T) {
+	server, requests := datadogServer(t, http.StatusForbidden)
+	datadog := NewDatadog("test-key", "")
+	datadog.APIURL = server.URL
+
+	err := datadog.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Decision: "ScaleOut", Failed: true, Error: "throttled"})
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.Equal(t, "error", requests["/api/v1/events"]["alert_type"])
+	assert.Equal(t, "throttled", requests["/api/v1/events"]["text"])
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultNamespace is the CloudWatch namespace of the autoscaler metrics, unless configured otherwise.
+const DefaultNamespace = "DocDBAutoscaler"
+
+// EMF writes one log line per invocation in the CloudWatch embedded metric format, which CloudWatch Logs
+// extracts into metrics without any API call. Metrics have the dimensions ClusterId, and ClusterId and
+// Decision, so that decisions can be counted with the Invocations metric. Those of shadow invocations
+// also have the dimension Mode, so that they are not aggregated with the live ones.
+type EMF struct {
+	Writer    io.Writer // Typically stdout, which Lambda sends to CloudWatch Logs
+	Namespace string
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewEMF creates a new EMF writing to w in the namespace.
+func NewEMF(w io.Writer, namespace string) *EMF {
+	return &EMF{Writer: w, Namespace: namespace}
+}
+
+// Ensure EMF implements Recorder
+var _ Recorder = (*EMF)(nil)
+
+// emfMetric is the definition of a metric in the _aws metadata.
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// emfDirective is the set of metrics of a namespace in the _aws metadata.
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+// emfMetadata is the _aws member of an EMF log line.
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"` // In milliseconds since the epoch
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// RecordInvocation writes the metrics of the invocation. Capacity and latency are only reported when known.
+func (e *EMF) RecordInvocation(ctx context.Context, invocation Invocation) error {
+	failures := 0
+	if invocation.Failed {
+		failures = 1
+	}
+	line := map[string]any{
+		"ClusterId":       invocation.ClusterID,
+		"Decision":        invocation.Decision,
+		"DryRun":          invocation.DryRun,
+		"Invocations":     1,
+		"Failures":        failures,
+		"ReplicasAdded":   invocation.ReplicasAdded,
+		"ReplicasRemoved": invocation.ReplicasRemoved,
+		"AWSCallErrors":   invocation.AWSCallErrors,
+	}
+	metrics := []emfMetric{
+		{Name: "Invocations", Unit: "Count"},
+		{Name: "Failures", Unit: "Count"},
+		{Name: "ReplicasAdded", Unit: "Count"},
+		{Name: "ReplicasRemoved", Unit: "Count"},
+		{Name: "AWSCallErrors", Unit: "Count"},
+	}
+	if invocation.CurrentCapacity != nil {
+		line["CurrentCapacity"] = *invocation.CurrentCapacity
+		metrics = append(metrics, emfMetric{Name: "CurrentCapacity", Unit: "Count"})
+	}
+	if invocation.DesiredCapacity != nil {
+		line["DesiredCapacity"] = *invocation.DesiredCapacity
+		metrics = append(metrics, emfMetric{Name: "DesiredCapacity", Unit: "Count"})
+	}
+	if invocation.ManagedReplicas != nil {
+		line["AutoscalerManagedReplicas"] = *invocation.ManagedReplicas
+		metrics = append(metrics, emfMetric{Name: "AutoscalerManagedReplicas", Unit: "Count"})
+	}
+	dimensions := [][]string{{"ClusterId"}, {"ClusterId", "Decision"}}
+	if invocation.Shadow {
+		line["Mode"] = "Shadow"
+		dimensions = [][]string{{"ClusterId", "Mode"}, {"ClusterId", "Mode", "Decision"}}
+	}
+	if invocation.DecisionLatency > 0 {
+		line["DecisionLatency"] = float64(invocation.DecisionLatency.Microseconds()) / 1000
+		metrics = append(metrics, emfMetric{Name: "DecisionLatency", Unit: "Milliseconds"})
+	}
+	line["_aws"] = emfMetadata{
+		Timestamp: e.currentTime().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  e.Namespace,
+			Dimensions: dimensions,
+			Metrics:    metrics,
+		}},
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	// Lines of concurrent invocations must not interleave
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.Writer.Write(append(data, '\n'))
+	return err
+}
+
+// currentTime returns the current time, or the fixed time of tests.
+func (e *EMF) currentTime() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEMF_RecordInvocation tests the embedded metric format of an invocation.
+func TestEMF_RecordInvocation(t *testing.T) {
+	var output bytes.Buffer
+	emf := NewEMF(&output, DefaultNamespace)
+	emf.now = func() time.Time { return time.UnixMilli(1705312800000) }
+
+	current, desired := 2, 4
+	err := emf.RecordInvocation(context.Background(), Invocation{
+		ClusterID:       "orders",
+		Decision:        "ScaleOut",
+		ReplicasAdded:   2,
+		CurrentCapacity: &current,
+		DesiredCapacity: &desired,
+		DecisionLatency: 1500 * time.Microsecond,
+		AWSCallErrors:   1,
+	})
+	assert.NoError(t, err)
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &line))
+	assert.Equal(t, "orders", line["ClusterId"])
+	assert.Equal(t, "ScaleOut", line["Decision"])
+	assert.Equal(t, float64(1), line["Invocations"])
+	assert.Equal(t, float64(0), line["Failures"])
+	assert.Equal(t, float64(2), line["ReplicasAdded"])
+	assert.Equal(t, float64(4), line["DesiredCapacity"])
+	assert.Equal(t, 1.5, line["DecisionLatency"])
+	assert.Equal(t, float64(1), line["AWSCallErrors"])
+
+	metadata := line["_aws"].(map[string]any)
+	assert.Equal(t, float64(1705312800000), metadata["Timestamp"])
+	directive := metadata["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	assert.Equal(t, "DocDBAutoscaler", directive["Namespace"])
+	assert.Equal(t, []any{[]any{"ClusterId"}, []any{"ClusterId", "Decision"}}, directive["Dimensions"])
+	assert.Len(t, directive["Metrics"], 8)
+}
+
+// TestEMF_RecordInvocation_UnknownCapacity tests that unknown capacity and latency are not reported.
+func TestEMF_RecordInvocation_UnknownCapacity(t *testing.T) {
+	var output bytes.Buffer
+	emf := NewEMF(&output, "Custom")
+
+	err := emf.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Decision: "NoAction", Failed: true})
+	assert.NoError(t, err)
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &line))
+	assert.Equal(t, float64(1), line["Failures"])
+	assert.NotContains(t, line, "CurrentCapacity")
+	assert.NotContains(t, line, "DesiredCapacity")
+	assert.NotContains(t, line, "DecisionLatency")
+	directive := line["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Custom", directive["Namespace"])
+	assert.Len(t, directive["Metrics"], 5)
+}
+
+// TestEMF_RecordInvocation_Shadow tests that shadow invocations have the dimension Mode.
+func TestEMF_RecordInvocation_Shadow(t *testing.T) {
+	var output bytes.Buffer
+	emf := NewEMF(&output, DefaultNamespace)
+
+	err := emf.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Decision: "ScaleOut", DryRun: true, Shadow: true})
+	assert.NoError(t, err)
+
+	var line map[string]any
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &line))
+	assert.Equal(t, "Shadow", line["Mode"])
+	directive := line["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	assert.Equal(t, []any{[]any{"ClusterId", "Mode"}, []any{"ClusterId", "Mode", "Decision"}}, directive["Dimensions"])
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// HeartbeatMetric is the name of the heartbeat metric.
+const HeartbeatMetric = "Heartbeat"
+
+// Heartbeat publishes a Heartbeat metric of 1, with the dimension ClusterId, on every evaluation of a
+// cluster, failed or not, so that an alarm treating missing data as breaching fires when the autoscaler
+// has not evaluated the cluster for a while, e.g. after a broken trigger or a permissions regression.
+type Heartbeat struct {
+	Client    CloudWatchAPI
+	Namespace string
+}
+
+// NewHeartbeat creates a new Heartbeat publishing in the namespace.
+func NewHeartbeat(client CloudWatchAPI, namespace string) *Heartbeat {
+	return &Heartbeat{Client: client, Namespace: namespace}
+}
+
+// Ensure Heartbeat implements Recorder
+var _ Recorder = (*Heartbeat)(nil)
+
+// RecordInvocation publishes the heartbeat of the cluster of the invocation.
+func (h *Heartbeat) RecordInvocation(ctx context.Context, invocation Invocation) error {
+	_, err := h.Client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+		Namespace: aws.String(h.Namespace),
+		MetricData: []cwTypes.MetricDatum{{
+			MetricName: aws.String(HeartbeatMetric),
+			Dimensions: []cwTypes.Dimension{{Name: aws.String("ClusterId"), Value: aws.String(invocation.ClusterID)}},
+			Unit:       cwTypes.StandardUnitCount,
+			Value:      aws.Float64(1),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put heartbeat metric: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHeartbeat_RecordInvocation tests that a heartbeat is published also when the invocation failed.
+func TestHeartbeat_RecordInvocation(t *testing.T) {
+	client := &recordingCloudWatch{}
+	heartbeat := NewHeartbeat(client, DefaultNamespace)
+
+	assert.NoError(t, heartbeat.RecordInvocation(context.Background(), Invocation{ClusterID: "orders", Failed: true}))
+	assert.Len(t, client.inputs, 1)
+	datum := client.inputs[0].MetricData[0]
+	assert.Equal(t, HeartbeatMetric, aws.ToString(datum.MetricName))
+	assert.Equal(t, "orders", aws.ToString(datum.Dimensions[0].Value))
+	assert.Equal(t, float64(1), aws.ToFloat64(datum.Value))
+}
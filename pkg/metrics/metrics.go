@@ -0,0 +1,86 @@
+// Package metrics reports the behavior of the autoscaler itself, e.g. as CloudWatch embedded metric
+// format logs, for dashboards and alarms on the autoscaler.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Invocation is the outcome of the scaling action of a cluster in an invocation.
+type Invocation struct {
+	ClusterID       string
+	Decision        string // ScaleOut, ScaleIn, NoAction, Verify or Paused
+	ReplicasAdded   int
+	ReplicasRemoved int
+	CurrentCapacity *int          // Readers before the action, when known
+	DesiredCapacity *int          // Readers wanted by the action, when known
+	ManagedReplicas *int          // Readers created by the autoscaler or scheduled scaling after the action, when counted
+	DecisionLatency time.Duration // From the start of the action to its decision, 0 when unknown
+	AWSCallErrors   int
+	Failed          bool
+	Error           string // Error of a failed action
+	DryRun          bool
+	Shadow          bool // Decided in shadow mode, side by side with a live autoscaler
+}
+
+// Recorder records the metrics of invocations.
+type Recorder interface {
+	RecordInvocation(ctx context.Context, invocation Invocation) error
+}
+
+// Recorders records invocations with each of its recorders.
+type Recorders []Recorder
+
+// Ensure Recorders implements Recorder
+var _ Recorder = Recorders(nil)
+
+// RecordInvocation records the invocation with every recorder, and returns their errors.
+func (r Recorders) RecordInvocation(ctx context.Context, invocation Invocation) error {
+	var errs []error
+	for _, recorder := range r {
+		if err := recorder.RecordInvocation(ctx, invocation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrorCounter counts the failed calls of the AWS clients it is added to, after the retries of the SDK.
+// Not-found errors are not counted, as the autoscaler uses them to tell what a resource is, e.g. an
+// elastic cluster.
+type ErrorCounter struct {
+	count atomic.Int64
+}
+
+// APIOption adds the counter to the middleware stack of a client, see aws.Config.APIOptions.
+func (c *ErrorCounter) APIOption(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DocDBAutoscalerErrorCounter", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleInitialize(ctx, in)
+		if err != nil && !isNotFound(err) {
+			c.count.Add(1)
+		}
+		return out, metadata, err
+	}), middleware.After)
+}
+
+// Take returns the number of failed calls since the last call of Take.
+func (c *ErrorCounter) Take() int {
+	return int(c.count.Swap(0))
+}
+
+// isNotFound reports whether err is an API error of a missing resource, e.g. DBClusterNotFoundFault.
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	code := apiErr.ErrorCode()
+	return strings.HasSuffix(code, "NotFound") || strings.HasSuffix(code, "NotFoundFault") || code == "ResourceNotFoundException"
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestErrorCounter tests that failed calls are counted, except not-found errors.
+func TestErrorCounter(t *testing.T) {
+	counter := &ErrorCounter{}
+	stack := middleware.NewStack("test", func() interface{} { return nil })
+	assert.NoError(t, counter.APIOption(stack))
+
+	for _, callErr := range []error{
+		nil,
+		&smithy.GenericAPIError{Code: "Throttling"},
+		&smithy.GenericAPIError{Code: "DBClusterNotFoundFault"},
+		errors.New("connection reset"),
+	} {
+		handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+			return nil, middleware.Metadata{}, callErr
+		}), stack)
+		_, _, _ = handler.Handle(context.Background(), nil)
+	}
+
+	assert.Equal(t, 2, counter.Take())
+	assert.Equal(t, 0, counter.Take())
+}
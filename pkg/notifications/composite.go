@@ -0,0 +1,86 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Composite sends every notification to all of its notifiers, keyed by channel name (e.g. "sns", "slack").
+// Notifiers are called concurrently, so that a slow or failing channel does not hold up the others.
+type Composite map[string]NotifierInterface
+
+// Ensure Composite implements NotifierInterface
+var _ NotifierInterface = Composite(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (c Composite) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		return notifier.SendScaleOutNotification(ctx, clusterID, replicasAdded)
+	})
+}
+
+// SendScaleInNotification sends a notification when scaling in.
+func (c Composite) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		return notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+	})
+}
+
+// SendFailureNotification sends a notification when a scaling action fails.
+func (c Composite) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		return notifier.SendFailureNotification(ctx, clusterID, errorMessage, action)
+	})
+}
+
+// WithRepeats returns a copy of the composite whose notifiers report the number of suppressed repeats,
+// when they support it.
+func (c Composite) WithRepeats(repeats int) NotifierInterface {
+	copied := make(Composite, len(c))
+	for name, notifier := range c {
+		if repeatNotifier, ok := notifier.(RepeatNotifier); ok {
+			notifier = repeatNotifier.WithRepeats(repeats)
+		}
+		copied[name] = notifier
+	}
+	return copied
+}
+
+// WithTopology returns a copy of the composite whose notifiers report the reader topology,
+// when they support it.
+func (c Composite) WithTopology(topology *Topology) NotifierInterface {
+	copied := make(Composite, len(c))
+	for name, notifier := range c {
+		if topologyNotifier, ok := notifier.(TopologyNotifier); ok {
+			notifier = topologyNotifier.WithTopology(topology)
+		}
+		copied[name] = notifier
+	}
+	return copied
+}
+
+// fanOut calls send for every notifier and joins their errors, prefixed by channel name in name order.
+func (c Composite) fanOut(send func(notifier NotifierInterface) error) error {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, notifier NotifierInterface) {
+			defer wg.Done()
+			if err := send(notifier); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", names[i], err)
+			}
+		}(i, c[name])
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier records the clusters it was notified of, failing every notification when err is set.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	clusters []string
+	err      error
+}
+
+func (r *recordingNotifier) record(clusterID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clusters = append(r.clusters, clusterID)
+	return r.err
+}
+
+func (r *recordingNotifier) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	return r.record(clusterID)
+}
+
+func (r *recordingNotifier) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	return r.record(clusterID)
+}
+
+func (r *recordingNotifier) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return r.record(clusterID)
+}
+
+// TestComposite tests that a failing channel does not stop the others and that its error is reported.
+func TestComposite(t *testing.T) {
+	sns := &recordingNotifier{}
+	slack := &recordingNotifier{err: errors.New("webhook disabled")}
+	webhook := &recordingNotifier{}
+	composite := Composite{"sns": sns, "slack": slack, "webhook": webhook}
+
+	err := composite.SendScaleOutNotification(context.Background(), "orders", 2)
+	assert.EqualError(t, err, "slack: webhook disabled")
+	assert.Equal(t, []string{"orders"}, sns.clusters)
+	assert.Equal(t, []string{"orders"}, slack.clusters)
+	assert.Equal(t, []string{"orders"}, webhook.clusters)
+
+	assert.NoError(t, Composite{"sns": sns}.SendScaleInNotification(context.Background(), "orders", 1))
+}
+
+// TestWebhook tests the JSON event of a webhook notification.
+func TestWebhook(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		body = string(payload)
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewWebhook(server.URL).SendFailureNotification(context.Background(), "orders", "throttled", "scale out"))
+	assert.JSONEq(t, `{"event":"Failure","severity":"critical","clusterId":"orders","action":"scale out","error":"throttled"}`, body)
+}
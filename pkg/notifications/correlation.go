@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// withCorrelationID appends the correlation ID of the invocation of ctx, if any, to a message.
+func withCorrelationID(ctx context.Context, message string) string {
+	correlationID := correlation.FromContext(ctx)
+	if correlationID == "" {
+		return message
+	}
+	return fmt.Sprintf("%s\n\nCorrelation ID: %s", message, correlationID)
+}
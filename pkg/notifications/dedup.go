@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DedupState is the last notification sent for an event of a cluster, and the number of identical
+// notifications suppressed since.
+type DedupState struct {
+	Fingerprint string
+	Sent        time.Time
+	Suppressed  int
+}
+
+// DedupStore persists the dedup state of the notification events of a cluster, keyed by event
+// (EventScaleOut, EventScaleIn, EventFailure or EventWarning).
+type DedupStore interface {
+	LoadDedupState(ctx context.Context, clusterID, event string) (state DedupState, found bool, err error)
+	SaveDedupState(ctx context.Context, clusterID, event string, state DedupState) error
+}
+
+// RepeatNotifier is implemented by notifiers that can report how many identical notifications
+// were suppressed before the one being sent.
+type RepeatNotifier interface {
+	NotifierInterface
+	WithRepeats(repeats int) NotifierInterface
+}
+
+// Deduplicator collapses identical notifications of an event of a cluster within Window into one.
+// The next notification sent for the event reports how many were suppressed, when Notifier is a
+// RepeatNotifier. Notifications are sent when the store fails, so that none is lost.
+type Deduplicator struct {
+	Notifier NotifierInterface
+	Store    DedupStore
+	Window   time.Duration
+	Logger   *slog.Logger
+
+	now func() time.Time
+}
+
+// Ensure Deduplicator implements NotifierInterface
+var _ NotifierInterface = (*Deduplicator)(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (d *Deduplicator) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	return d.send(ctx, clusterID, EventScaleOut, fmt.Sprint(replicasAdded), func(notifier NotifierInterface) error {
+		return notifier.SendScaleOutNotification(ctx, clusterID, replicasAdded)
+	})
+}
+
+// SendScaleInNotification sends a notification when scaling in.
+func (d *Deduplicator) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	return d.send(ctx, clusterID, EventScaleIn, fmt.Sprint(replicasRemoved), func(notifier NotifierInterface) error {
+		return notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+	})
+}
+
+// SendFailureNotification sends a notification when a scaling action fails.
+func (d *Deduplicator) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return d.send(ctx, clusterID, EventFailure, action+"\n"+errorMessage, func(notifier NotifierInterface) error {
+		return notifier.SendFailureNotification(ctx, clusterID, errorMessage, action)
+	})
+}
+
+// WithTopology returns a copy of the deduplicator whose notifier reports the reader topology,
+// when it supports it. The topology is not part of the fingerprint of a notification.
+func (d *Deduplicator) WithTopology(topology *Topology) NotifierInterface {
+	copied := *d
+	if topologyNotifier, ok := d.Notifier.(TopologyNotifier); ok {
+		copied.Notifier = topologyNotifier.WithTopology(topology)
+	}
+	return &copied
+}
+
+// send suppresses the notification when an identical one was sent within the window, and sends it otherwise.
+func (d *Deduplicator) send(ctx context.Context, clusterID, event, content string, notify func(notifier NotifierInterface) error) error {
+	now := time.Now()
+	if d.now != nil {
+		now = d.now()
+	}
+	sum := sha256.Sum256([]byte(content))
+	fingerprint := hex.EncodeToString(sum[:8])
+
+	state, found, err := d.Store.LoadDedupState(ctx, clusterID, event)
+	if err != nil {
+		d.Logger.Warn("Failed to load notification dedup state, sending notification", "Error", err, "ClusterID", clusterID, "Event", event)
+		return notify(d.Notifier)
+	}
+
+	if found && state.Fingerprint == fingerprint && now.Sub(state.Sent) < d.Window {
+		state.Suppressed++
+		d.Logger.Info("Suppressed repeated notification", "ClusterID", clusterID, "Event", event, "Suppressed", state.Suppressed)
+		if err := d.Store.SaveDedupState(ctx, clusterID, event, state); err != nil {
+			d.Logger.Warn("Failed to save notification dedup state", "Error", err, "ClusterID", clusterID, "Event", event)
+		}
+		return nil
+	}
+
+	notifier := d.Notifier
+	if repeatNotifier, ok := notifier.(RepeatNotifier); ok && found && state.Suppressed > 0 {
+		notifier = repeatNotifier.WithRepeats(state.Suppressed)
+	}
+	if err := notify(notifier); err != nil {
+		return err
+	}
+	if err := d.Store.SaveDedupState(ctx, clusterID, event, DedupState{Fingerprint: fingerprint, Sent: now}); err != nil {
+		d.Logger.Warn("Failed to save notification dedup state", "Error", err, "ClusterID", clusterID, "Event", event)
+	}
+	return nil
+}
+
+// withRepeats appends the number of suppressed repeats to a message.
+func withRepeats(message string, repeats int) string {
+	if repeats == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s (%d repeated notifications suppressed since the previous one)", message, repeats)
+}
@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDedupStore keeps dedup states in memory.
+type memoryDedupStore map[string]DedupState
+
+func (m memoryDedupStore) LoadDedupState(ctx context.Context, clusterID, event string) (DedupState, bool, error) {
+	state, found := m[clusterID+"/"+event]
+	return state, found, nil
+}
+
+func (m memoryDedupStore) SaveDedupState(ctx context.Context, clusterID, event string, state DedupState) error {
+	m[clusterID+"/"+event] = state
+	return nil
+}
+
+// TestDeduplicator tests that identical notifications within the window are suppressed and counted
+// in the next notification sent.
+func TestDeduplicator(t *testing.T) {
+	var events []WebhookEvent
+	recorder := &webhookRecorder{events: &events}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	deduplicator := &Deduplicator{
+		Notifier: recorder,
+		Store:    memoryDedupStore{},
+		Window:   time.Hour,
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		now:      func() time.Time { return now },
+	}
+
+	assert.NoError(t, deduplicator.SendScaleOutNotification(context.Background(), "orders", 1))
+	now = now.Add(10 * time.Minute)
+	assert.NoError(t, deduplicator.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.NoError(t, deduplicator.SendScaleOutNotification(context.Background(), "orders", 1))
+	// Other clusters and events are not suppressed
+	assert.NoError(t, deduplicator.SendScaleOutNotification(context.Background(), "payments", 1))
+	assert.NoError(t, deduplicator.SendScaleInNotification(context.Background(), "orders", 1))
+	assert.Len(t, events, 3)
+
+	// After the window, the repeats are reported
+	now = now.Add(time.Hour)
+	assert.NoError(t, deduplicator.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.Len(t, events, 4)
+	assert.Equal(t, WebhookEvent{Event: EventScaleOut, ClusterID: "orders", Replicas: 1, Repeats: 2}, events[3])
+}
+
+// webhookRecorder records webhook events instead of posting them.
+type webhookRecorder struct {
+	events  *[]WebhookEvent
+	repeats int
+}
+
+func (w *webhookRecorder) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	*w.events = append(*w.events, WebhookEvent{Event: EventScaleOut, ClusterID: clusterID, Replicas: replicasAdded, Repeats: w.repeats})
+	return nil
+}
+
+func (w *webhookRecorder) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	*w.events = append(*w.events, WebhookEvent{Event: EventScaleIn, ClusterID: clusterID, Replicas: replicasRemoved, Repeats: w.repeats})
+	return nil
+}
+
+func (w *webhookRecorder) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	*w.events = append(*w.events, WebhookEvent{Event: EventFailure, ClusterID: clusterID, Action: action, Error: errorMessage, Repeats: w.repeats})
+	return nil
+}
+
+func (w *webhookRecorder) WithRepeats(repeats int) NotifierInterface {
+	return &webhookRecorder{events: w.events, repeats: repeats}
+}
+
+// TestWithRepeats tests the message suffix of suppressed repeats.
+func TestWithRepeats(t *testing.T) {
+	assert.Equal(t, "Scaled out.", withRepeats("Scaled out.", 0))
+	assert.Equal(t, "Scaled out. (3 repeated notifications suppressed since the previous one)", withRepeats("Scaled out.", 3))
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// DigestNotifier is implemented by notifiers that can send a digest, a summary of events of a cluster
+// sent as a single notification.
+type DigestNotifier interface {
+	SendDigest(ctx context.Context, clusterID, message string) error
+}
+
+// SendDigest sends the digest through notifier, when it supports digests.
+func SendDigest(ctx context.Context, notifier NotifierInterface, clusterID, message string) error {
+	digestNotifier, ok := notifier.(DigestNotifier)
+	if !ok {
+		return errors.New("notifier does not support digests")
+	}
+	return digestNotifier.SendDigest(ctx, clusterID, message)
+}
+
+// SendDigest sends a digest to the SNS topic.
+func (n *Notifier) SendDigest(ctx context.Context, clusterID, message string) error {
+	return n.publish(ctx, clusterID, EventDigest, message, SeverityInfo)
+}
+
+// SendDigest posts a digest to the webhook.
+func (s *Slack) SendDigest(ctx context.Context, clusterID, message string) error {
+	return s.post(ctx, message)
+}
+
+// SendDigest posts a digest event to the endpoint.
+func (w *Webhook) SendDigest(ctx context.Context, clusterID, message string) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventDigest, Severity: SeverityInfo.String(), ClusterID: clusterID, Message: message, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// SendDigest sends the digest to all the notifiers supporting digests.
+func (c Composite) SendDigest(ctx context.Context, clusterID, message string) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		if digestNotifier, ok := notifier.(DigestNotifier); ok {
+			return digestNotifier.SendDigest(ctx, clusterID, message)
+		}
+		return nil
+	})
+}
+
+// SendDigest sends the digest, which was requested explicitly, whatever the minimum severity.
+func (f *Filter) SendDigest(ctx context.Context, clusterID, message string) error {
+	return SendDigest(ctx, f.Notifier, clusterID, message)
+}
+
+// SendDigest sends the digest. Digests are never deduplicated.
+func (d *Deduplicator) SendDigest(ctx context.Context, clusterID, message string) error {
+	return SendDigest(ctx, d.Notifier, clusterID, message)
+}
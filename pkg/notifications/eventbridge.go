@@ -0,0 +1,94 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// eventSource is the source of the lifecycle events put on EventBridge.
+const eventSource = "docdb-autoscaler"
+
+// Detail types of lifecycle events.
+const (
+	EventScalingDecisionMade = "ScalingDecisionMade"
+	EventReplicaCreated      = "ReplicaCreated"
+	EventReplicaDeleted      = "ReplicaDeleted"
+	EventScalingFailed       = "ScalingFailed"
+)
+
+// EventBridgeAPI defines the interface for EventBridge client methods used.
+type EventBridgeAPI interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// LifecycleEvent is a structured event of the scaling lifecycle of a cluster.
+// DetailType is one of the Event* detail types; the other fields form the event detail.
+type LifecycleEvent struct {
+	DetailType      string `json:"-"`
+	ClusterID       string `json:"clusterId"`
+	Decision        string `json:"decision,omitempty"`
+	ReplicasAdded   int    `json:"replicasAdded,omitempty"`
+	ReplicasRemoved int    `json:"replicasRemoved,omitempty"`
+	Explanation     string `json:"explanation,omitempty"` // Why the decision was made, with ScalingDecisionMade
+	InstanceID      string `json:"instanceId,omitempty"`
+	InstanceClass   string `json:"instanceClass,omitempty"`
+	Error           string `json:"error,omitempty"`
+	DryRun          bool   `json:"dryRun"`
+	CorrelationID   string `json:"correlationId,omitempty"`
+}
+
+// LifecycleNotifier publishes lifecycle events for other automation, e.g. cost reporting or CMDB sync.
+type LifecycleNotifier interface {
+	PublishLifecycleEvent(ctx context.Context, event LifecycleEvent) error
+}
+
+// EventBridge is a LifecycleNotifier putting events on an EventBridge bus, with source "docdb-autoscaler".
+type EventBridge struct {
+	Client  EventBridgeAPI
+	BusName string // Name or ARN of the event bus
+}
+
+// NewEventBridge creates a new EventBridge instance for an event bus.
+func NewEventBridge(client EventBridgeAPI, busName string) *EventBridge {
+	return &EventBridge{
+		Client:  client,
+		BusName: busName,
+	}
+}
+
+// Ensure EventBridge implements LifecycleNotifier
+var _ LifecycleNotifier = (*EventBridge)(nil)
+
+// PublishLifecycleEvent puts the event on the bus.
+func (e *EventBridge) PublishLifecycleEvent(ctx context.Context, event LifecycleEvent) error {
+	detail, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	output, err := e.Client.PutEvents(ctx, &eventbridge.PutEventsInput{
+		Entries: []eventbridgeTypes.PutEventsRequestEntry{
+			{
+				EventBusName: aws.String(e.BusName),
+				Source:       aws.String(eventSource),
+				DetailType:   aws.String(event.DetailType),
+				Detail:       aws.String(string(detail)),
+				Resources:    []string{},
+				Time:         aws.Time(time.Now()),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// PutEvents reports rejected entries in the output rather than as an error
+	if output.FailedEntryCount > 0 && len(output.Entries) > 0 {
+		return fmt.Errorf("EventBridge rejected %s event: %s: %s", event.DetailType, aws.ToString(output.Entries[0].ErrorCode), aws.ToString(output.Entries[0].ErrorMessage))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingEventBridge records the put events, and rejects them when failEntries is set.
+type recordingEventBridge struct {
+	inputs      []*eventbridge.PutEventsInput
+	failEntries bool
+}
+
+func (r *recordingEventBridge) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	r.inputs = append(r.inputs, params)
+	if r.failEntries {
+		return &eventbridge.PutEventsOutput{
+			FailedEntryCount: 1,
+			Entries:          []eventbridgeTypes.PutEventsResultEntry{{ErrorCode: aws.String("AccessDenied"), ErrorMessage: aws.String("not authorized")}},
+		}, nil
+	}
+	return &eventbridge.PutEventsOutput{}, nil
+}
+
+// TestEventBridge tests that lifecycle events are put on the bus with their detail.
+func TestEventBridge(t *testing.T) {
+	client := &recordingEventBridge{}
+	bus := NewEventBridge(client, "automation")
+	assert.NoError(t, bus.PublishLifecycleEvent(context.Background(), LifecycleEvent{
+		DetailType:    EventReplicaCreated,
+		ClusterID:     "orders",
+		InstanceID:    "orders-reader-123",
+		InstanceClass: "db.r6g.large",
+	}))
+
+	entry := client.inputs[0].Entries[0]
+	assert.Equal(t, "automation", aws.ToString(entry.EventBusName))
+	assert.Equal(t, "docdb-autoscaler", aws.ToString(entry.Source))
+	assert.Equal(t, EventReplicaCreated, aws.ToString(entry.DetailType))
+
+	var detail map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(aws.ToString(entry.Detail)), &detail))
+	assert.Equal(t, map[string]any{
+		"clusterId":     "orders",
+		"instanceId":    "orders-reader-123",
+		"instanceClass": "db.r6g.large",
+		"dryRun":        false,
+	}, detail)
+}
+
+// TestEventBridge_Rejected tests that events rejected by EventBridge are reported.
+func TestEventBridge_Rejected(t *testing.T) {
+	bus := NewEventBridge(&recordingEventBridge{failEntries: true}, "automation")
+	err := bus.PublishLifecycleEvent(context.Background(), LifecycleEvent{DetailType: EventScalingFailed, ClusterID: "orders", Error: "boom"})
+	assert.ErrorContains(t, err, "AccessDenied")
+}
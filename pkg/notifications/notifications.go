@@ -2,9 +2,17 @@ package notifications
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
 )
 
 // SNSAPI defines the interface for Amazon SNS interactions.
@@ -15,9 +23,9 @@ type SNSAPI interface {
 // NotifierInterface defines the methods that our notifier should implement.
 // This allows us to use different implementations, such as a NoOpNotifier in tests.
 type NotifierInterface interface {
-	SendScaleOutNotification(clusterID string, replicasAdded int) error
-	SendScaleInNotification(clusterID string, replicasRemoved int) error
-	SendFailureNotification(clusterID, errorMessage, action string) error
+	SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error
+	SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error
+	SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error
 }
 
 // Notifier is responsible for sending notifications using SNS.
@@ -25,14 +33,27 @@ type Notifier struct {
 	SNSClient SNSAPI
 	TopicARN  string
 	Subject   string
+	Templates Templates // Optional message templates
+
+	// EventTopicARNs are the topics of the events that are not sent to TopicARN, keyed by event
+	// (EventScaleOut, EventScaleIn, EventFailure or EventWarning).
+	EventTopicARNs map[string]string
+
+	PublishTimeout time.Duration // Timeout of each publish attempt
+	MaxAttempts    int
+
+	repeats  int
+	topology *Topology
 }
 
 // NewNotifier creates a new Notifier instance.
 func NewNotifier(snsClient SNSAPI, topicARN string) *Notifier {
 	return &Notifier{
-		SNSClient: snsClient,
-		TopicARN:  topicARN,
-		Subject:   "DocumentDB Autoscaler Notification",
+		SNSClient:      snsClient,
+		TopicARN:       topicARN,
+		Subject:        "DocumentDB Autoscaler Notification",
+		PublishTimeout: 5 * time.Second,
+		MaxAttempts:    3,
 	}
 }
 
@@ -40,30 +61,95 @@ func NewNotifier(snsClient SNSAPI, topicARN string) *Notifier {
 var _ NotifierInterface = (*Notifier)(nil)
 
 // SendScaleOutNotification sends a notification when scaling out.
-func (n *Notifier) SendScaleOutNotification(clusterID string, replicasAdded int) error {
+func (n *Notifier) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
 	message := fmt.Sprintf("Scaled out cluster %s by adding %d replicas.", clusterID, replicasAdded)
-	return n.publish(message)
+	return n.publish(ctx, clusterID, EventScaleOut, n.Templates.render(TemplateScaleOut, TemplateData{ClusterID: clusterID, Replicas: replicasAdded, Severity: ScaleOutSeverity, Repeats: n.repeats, Topology: n.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, n.repeats), n.topology))), ScaleOutSeverity)
 }
 
 // SendScaleInNotification sends a notification when scaling in.
-func (n *Notifier) SendScaleInNotification(clusterID string, replicasRemoved int) error {
+func (n *Notifier) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
 	message := fmt.Sprintf("Scaled in cluster %s by removing %d replicas.", clusterID, replicasRemoved)
-	return n.publish(message)
+	return n.publish(ctx, clusterID, EventScaleIn, n.Templates.render(TemplateScaleIn, TemplateData{ClusterID: clusterID, Replicas: replicasRemoved, Severity: ScaleInSeverity, Repeats: n.repeats, Topology: n.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, n.repeats), n.topology))), ScaleInSeverity)
 }
 
 // SendFailureNotification sends a notification when a scaling action fails.
-func (n *Notifier) SendFailureNotification(clusterID, errorMessage, action string) error {
+func (n *Notifier) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
 	message := fmt.Sprintf("Failed to %s on cluster %s: %s", action, clusterID, errorMessage)
-	return n.publish(message)
+	return n.publish(ctx, clusterID, EventFailure, n.Templates.render(TemplateFailure, TemplateData{ClusterID: clusterID, Action: action, Error: errorMessage, Severity: FailureSeverity, Repeats: n.repeats, Topology: n.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, n.repeats), n.topology))), FailureSeverity)
+}
+
+// WithRepeats returns a copy of the notifier reporting the number of suppressed repeats in its messages.
+func (n *Notifier) WithRepeats(repeats int) NotifierInterface {
+	copied := *n
+	copied.repeats = repeats
+	return &copied
+}
+
+// WithTopology returns a copy of the notifier reporting the reader topology in its messages.
+func (n *Notifier) WithTopology(topology *Topology) NotifierInterface {
+	copied := *n
+	copied.topology = topology
+	return &copied
 }
 
-// publish sends a message to the SNS topic.
-func (n *Notifier) publish(message string) error {
+// publish sends a message to the SNS topic, with its severity in the "severity" message attribute
+// so that subscriptions can filter on it, and the correlation ID of the invocation in "correlationId".
+// Messages to FIFO topics are ordered per cluster, and identical messages of an event are
+// deduplicated by SNS within its 5-minute deduplication interval, e.g. when a publish is retried.
+func (n *Notifier) publish(ctx context.Context, clusterID, event, message string, severity Severity) error {
+	topicARN := n.TopicARN
+	if eventTopicARN := n.EventTopicARNs[event]; eventTopicARN != "" {
+		topicARN = eventTopicARN
+	}
 	input := &sns.PublishInput{
 		Message:  &message,
-		TopicArn: &n.TopicARN,
+		TopicArn: &topicARN,
 		Subject:  &n.Subject,
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"severity": {DataType: aws.String("String"), StringValue: aws.String(severity.String())},
+		},
+	}
+	if correlationID := correlation.FromContext(ctx); correlationID != "" {
+		input.MessageAttributes["correlationId"] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(correlationID)}
+	}
+	if IsFIFOTopic(topicARN) {
+		sum := sha256.Sum256([]byte(event + "\n" + clusterID + "\n" + message))
+		input.MessageGroupId = aws.String(clusterID)
+		input.MessageDeduplicationId = aws.String(hex.EncodeToString(sum[:]))
+	}
+	return withRetries(ctx, n.MaxAttempts, n.PublishTimeout, func(ctx context.Context) error {
+		_, err := n.SNSClient.Publish(ctx, input)
+		return err
+	})
+}
+
+// IsFIFOTopic reports whether the topic is an SNS FIFO topic, whose name ends in .fifo.
+func IsFIFOTopic(topicARN string) bool {
+	return strings.HasSuffix(topicARN, ".fifo")
+}
+
+// withRetries calls send until it succeeds, at most attempts times, each with its own timeout,
+// so that a slow or failing channel cannot hang the invocation. It stops early when ctx is done,
+// e.g. when the Lambda deadline is near.
+func withRetries(ctx context.Context, attempts int, timeout time.Duration, send func(ctx context.Context) error) error {
+	backoff := 100 * time.Millisecond
+	var err error
+	for attempt := 1; attempt <= max(attempts, 1); attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err = send(attemptCtx)
+		cancel()
+		if err == nil || attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
-	_, err := n.SNSClient.Publish(context.Background(), input)
 	return err
 }
@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakySNS fails the first failures publish calls, and blocks until the deadline when slow is set.
+type flakySNS struct {
+	failures int
+	slow     bool
+	calls    int
+	severity string
+}
+
+func (f *flakySNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.calls++
+	f.severity = aws.ToString(params.MessageAttributes["severity"].StringValue)
+	if f.slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if f.calls <= f.failures {
+		return nil, errors.New("throttled")
+	}
+	return &sns.PublishOutput{}, nil
+}
+
+// TestNotifier_Retries tests that failed publish attempts are retried.
+func TestNotifier_Retries(t *testing.T) {
+	client := &flakySNS{failures: 2}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify")
+
+	assert.NoError(t, notifier.SendFailureNotification(context.Background(), "orders", "throttled", "scale out"))
+	assert.Equal(t, 3, client.calls)
+	assert.Equal(t, "critical", client.severity)
+
+	client = &flakySNS{failures: 3}
+	notifier.SNSClient = client
+	assert.EqualError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1), "throttled")
+	assert.Equal(t, 3, client.calls)
+}
+
+// TestNotifier_Timeout tests that a hanging publish call is abandoned after the publish timeout.
+func TestNotifier_Timeout(t *testing.T) {
+	client := &flakySNS{slow: true}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify")
+	notifier.PublishTimeout = 10 * time.Millisecond
+	notifier.MaxAttempts = 2
+
+	err := notifier.SendScaleInNotification(context.Background(), "orders", 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, client.calls)
+}
+
+// recordingSNS records the publish inputs.
+type recordingSNS struct {
+	inputs []*sns.PublishInput
+}
+
+func (r *recordingSNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	r.inputs = append(r.inputs, params)
+	return &sns.PublishOutput{}, nil
+}
+
+// TestNotifier_FIFO tests that messages to FIFO topics are grouped by cluster and deduplicated by event.
+func TestNotifier_FIFO(t *testing.T) {
+	client := &recordingSNS{}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify.fifo")
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 2))
+
+	assert.Equal(t, "orders", aws.ToString(client.inputs[0].MessageGroupId))
+	assert.Equal(t, aws.ToString(client.inputs[0].MessageDeduplicationId), aws.ToString(client.inputs[1].MessageDeduplicationId))
+	assert.NotEqual(t, aws.ToString(client.inputs[0].MessageDeduplicationId), aws.ToString(client.inputs[2].MessageDeduplicationId))
+
+	notifier.TopicARN = "arn:aws:sns:us-east-1:123456789012:notify"
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.Nil(t, client.inputs[3].MessageGroupId)
+	assert.Nil(t, client.inputs[3].MessageDeduplicationId)
+}
+
+func TestNotifier_EventTopics(t *testing.T) {
+	client := &recordingSNS{}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify")
+	notifier.EventTopicARNs = map[string]string{
+		EventFailure: "arn:aws:sns:us-east-1:123456789012:oncall.fifo",
+		EventScaleIn: "",
+	}
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.NoError(t, notifier.SendScaleInNotification(context.Background(), "orders", 1))
+	assert.NoError(t, notifier.SendFailureNotification(context.Background(), "orders", "boom", "scale out"))
+
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:notify", aws.ToString(client.inputs[0].TopicArn))
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:notify", aws.ToString(client.inputs[1].TopicArn))
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:oncall.fifo", aws.ToString(client.inputs[2].TopicArn))
+	assert.Equal(t, "orders", aws.ToString(client.inputs[2].MessageGroupId))
+}
+
+func TestNotifier_CorrelationID(t *testing.T) {
+	client := &recordingSNS{}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify")
+	ctx := correlation.NewContext(context.Background(), "c0ffee")
+	assert.NoError(t, notifier.SendScaleOutNotification(ctx, "orders", 1))
+
+	assert.Equal(t, "Scaled out cluster orders by adding 1 replicas.\n\nCorrelation ID: c0ffee", aws.ToString(client.inputs[0].Message))
+	assert.Equal(t, "c0ffee", aws.ToString(client.inputs[0].MessageAttributes["correlationId"].StringValue))
+}
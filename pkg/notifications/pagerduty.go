@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pagerDutyEventsURL is the endpoint of the PagerDuty Events API v2.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxPagerDutySummary is the longest summary accepted by the Events API.
+const maxPagerDutySummary = 1024
+
+// IncidentNotifier opens and resolves the incident of a cluster.
+type IncidentNotifier interface {
+	TriggerIncident(ctx context.Context, clusterID, summary string) error
+	ResolveIncident(ctx context.Context, clusterID string) error
+}
+
+// PagerDuty is an IncidentNotifier sending events to a PagerDuty service through the Events API v2.
+// Events of a cluster share a dedup key, so repeated failures update a single incident.
+type PagerDuty struct {
+	RoutingKey string // Integration key of the service
+	EventsURL  string
+	HTTPClient *http.Client
+}
+
+// NewPagerDuty creates a new PagerDuty instance for the integration key of a service.
+func NewPagerDuty(routingKey string) *PagerDuty {
+	return &PagerDuty{
+		RoutingKey: routingKey,
+		EventsURL:  pagerDutyEventsURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure PagerDuty implements IncidentNotifier
+var _ IncidentNotifier = (*PagerDuty)(nil)
+
+// pagerDutyEvent is the request body of the Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // "trigger" or "resolve"
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary   string `json:"summary"`
+	Source    string `json:"source"`
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+}
+
+// TriggerIncident opens the incident of the cluster, or adds to it when it is still open.
+func (p *PagerDuty) TriggerIncident(ctx context.Context, clusterID, summary string) error {
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:maxPagerDutySummary]
+	}
+	return p.send(ctx, pagerDutyEvent{
+		EventAction: "trigger",
+		DedupKey:    pagerDutyDedupKey(clusterID),
+		Payload: &pagerDutyPayload{
+			Summary:   summary,
+			Source:    clusterID,
+			Severity:  "error",
+			Component: "docdb-autoscaler",
+		},
+	})
+}
+
+// ResolveIncident resolves the incident of the cluster. Nothing happens when there is no open incident.
+func (p *PagerDuty) ResolveIncident(ctx context.Context, clusterID string) error {
+	return p.send(ctx, pagerDutyEvent{
+		EventAction: "resolve",
+		DedupKey:    pagerDutyDedupKey(clusterID),
+	})
+}
+
+// pagerDutyDedupKey returns the dedup key of the incident of a cluster.
+func pagerDutyDedupKey(clusterID string) string {
+	return "docdb-autoscaler/" + clusterID
+}
+
+// send posts an event to the Events API.
+func (p *PagerDuty) send(ctx context.Context, event pagerDutyEvent) error {
+	event.RoutingKey = p.RoutingKey
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.EventsURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := p.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("PagerDuty %s event: %s: %s", event.EventAction, response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPagerDuty tests that incidents of a cluster are triggered and resolved with the same dedup key.
+func TestPagerDuty(t *testing.T) {
+	var events []pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pagerDuty := NewPagerDuty("routing-key")
+	pagerDuty.EventsURL = server.URL
+	assert.NoError(t, pagerDuty.TriggerIncident(context.Background(), "orders", "Scaling action failed on cluster orders"))
+	assert.NoError(t, pagerDuty.ResolveIncident(context.Background(), "orders"))
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, "trigger", events[0].EventAction)
+	assert.Equal(t, "routing-key", events[0].RoutingKey)
+	assert.Equal(t, "docdb-autoscaler/orders", events[0].DedupKey)
+	assert.Equal(t, "Scaling action failed on cluster orders", events[0].Payload.Summary)
+	assert.Equal(t, "resolve", events[1].EventAction)
+	assert.Equal(t, "docdb-autoscaler/orders", events[1].DedupKey)
+	assert.Nil(t, events[1].Payload)
+}
+
+// TestPagerDuty_Rejected tests that events rejected by PagerDuty are reported.
+func TestPagerDuty_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"status":"invalid event"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	pagerDuty := NewPagerDuty("routing-key")
+	pagerDuty.EventsURL = server.URL
+	assert.ErrorContains(t, pagerDuty.ResolveIncident(context.Background(), "orders"), "PagerDuty resolve event: 400 Bad Request")
+}
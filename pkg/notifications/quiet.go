@@ -0,0 +1,171 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	_ "time/tzdata" // The image has no zoneinfo for the timezones of quiet hours
+)
+
+// QuietWindow is a daily window of wall-clock time in a timezone, e.g. 22:00-07:00 in Europe/Berlin.
+// Windows ending before they start span midnight.
+type QuietWindow struct {
+	Start    time.Duration // Since midnight
+	End      time.Duration // Since midnight
+	Location *time.Location
+}
+
+// ParseQuietWindow parses a window of the form HH:MM-HH:MM in the named timezone, UTC when empty.
+func ParseQuietWindow(spec, timezone string) (QuietWindow, error) {
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return QuietWindow{}, fmt.Errorf("invalid timezone %s: %w", timezone, err)
+		}
+	}
+	startSpec, endSpec, found := strings.Cut(spec, "-")
+	if !found {
+		return QuietWindow{}, fmt.Errorf("quiet hours must be of the form HH:MM-HH:MM, got %s", spec)
+	}
+	start, err := parseClock(startSpec)
+	if err != nil {
+		return QuietWindow{}, err
+	}
+	end, err := parseClock(endSpec)
+	if err != nil {
+		return QuietWindow{}, err
+	}
+	if start == end {
+		return QuietWindow{}, fmt.Errorf("quiet hours %s must not start and end at the same time", spec)
+	}
+	return QuietWindow{Start: start, End: end, Location: location}, nil
+}
+
+// parseClock parses HH:MM into the time since midnight.
+func parseClock(clock string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s, must be HH:MM", clock)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t is within the window.
+func (w QuietWindow) Contains(t time.Time) bool {
+	local := t.In(w.Location)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	if w.Start < w.End {
+		return sinceMidnight >= w.Start && sinceMidnight < w.End
+	}
+	return sinceMidnight >= w.Start || sinceMidnight < w.End
+}
+
+// QuietDigest counts the info notifications of a cluster suppressed during quiet hours.
+type QuietDigest struct {
+	ScaleIns        int
+	ReplicasRemoved int
+}
+
+// QuietDigestStore persists the quiet-hours digest of a cluster. Saving an empty digest clears it.
+type QuietDigestStore interface {
+	LoadQuietDigest(ctx context.Context, clusterID string) (QuietDigest, error)
+	SaveQuietDigest(ctx context.Context, clusterID string, digest QuietDigest) error
+}
+
+// QuietHours suppresses info notifications (scale-ins) during the quiet window and batches them into
+// a digest, which is sent with the first notification or Flush after the window. Warnings and critical
+// failures still go out immediately.
+type QuietHours struct {
+	Notifier NotifierInterface
+	Window   QuietWindow
+	Store    QuietDigestStore
+	Logger   *slog.Logger
+
+	now func() time.Time
+}
+
+// Ensure QuietHours implements NotifierInterface
+var _ NotifierInterface = (*QuietHours)(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (q *QuietHours) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	q.Flush(ctx, clusterID)
+	return q.Notifier.SendScaleOutNotification(ctx, clusterID, replicasAdded)
+}
+
+// SendScaleInNotification sends a notification when scaling in, or adds it to the digest during quiet hours.
+func (q *QuietHours) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	if !q.Window.Contains(q.currentTime()) {
+		q.Flush(ctx, clusterID)
+		return q.Notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+	}
+	digest, err := q.Store.LoadQuietDigest(ctx, clusterID)
+	if err != nil {
+		q.Logger.Warn("Failed to load quiet hours digest, sending notification", "Error", err, "ClusterID", clusterID)
+		return q.Notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+	}
+	digest.ScaleIns++
+	digest.ReplicasRemoved += replicasRemoved
+	if err := q.Store.SaveQuietDigest(ctx, clusterID, digest); err != nil {
+		q.Logger.Warn("Failed to save quiet hours digest, sending notification", "Error", err, "ClusterID", clusterID)
+		return q.Notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+	}
+	q.Logger.Info("Deferred notification to quiet hours digest", "ClusterID", clusterID, "ScaleIns", digest.ScaleIns)
+	return nil
+}
+
+// SendFailureNotification sends a notification when a scaling action fails, also during quiet hours.
+func (q *QuietHours) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	q.Flush(ctx, clusterID)
+	return q.Notifier.SendFailureNotification(ctx, clusterID, errorMessage, action)
+}
+
+// SendDigest sends a digest.
+func (q *QuietHours) SendDigest(ctx context.Context, clusterID, message string) error {
+	return SendDigest(ctx, q.Notifier, clusterID, message)
+}
+
+// WithTopology returns a copy whose notifier reports the reader topology, when it supports it.
+func (q *QuietHours) WithTopology(topology *Topology) NotifierInterface {
+	copied := *q
+	if topologyNotifier, ok := q.Notifier.(TopologyNotifier); ok {
+		copied.Notifier = topologyNotifier.WithTopology(topology)
+	}
+	return &copied
+}
+
+// Flush sends the digest of the quiet hours that ended, if any, and clears it, so that it precedes
+// later notifications. Nothing is sent during quiet hours. Failures are logged only; the digest is
+// kept to be sent later.
+func (q *QuietHours) Flush(ctx context.Context, clusterID string) {
+	if q.Window.Contains(q.currentTime()) {
+		return
+	}
+	digest, err := q.Store.LoadQuietDigest(ctx, clusterID)
+	if err != nil {
+		q.Logger.Warn("Failed to load quiet hours digest", "Error", err, "ClusterID", clusterID)
+		return
+	}
+	if digest.ScaleIns == 0 {
+		return
+	}
+	message := fmt.Sprintf("During quiet hours, cluster %s was scaled in %d times, removing %d replicas.", clusterID, digest.ScaleIns, digest.ReplicasRemoved)
+	if err := SendDigest(ctx, q.Notifier, clusterID, message); err != nil {
+		q.Logger.Error("Failed to send quiet hours digest", "Error", err, "ClusterID", clusterID)
+		return
+	}
+	if err := q.Store.SaveQuietDigest(ctx, clusterID, QuietDigest{}); err != nil {
+		q.Logger.Warn("Failed to clear quiet hours digest", "Error", err, "ClusterID", clusterID)
+	}
+}
+
+// currentTime returns the current time, or the fixed time of tests.
+func (q *QuietHours) currentTime() time.Time {
+	if q.now != nil {
+		return q.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQuietDigestStore keeps quiet-hours digests in memory.
+type memoryQuietDigestStore map[string]QuietDigest
+
+func (m memoryQuietDigestStore) LoadQuietDigest(ctx context.Context, clusterID string) (QuietDigest, error) {
+	return m[clusterID], nil
+}
+
+func (m memoryQuietDigestStore) SaveQuietDigest(ctx context.Context, clusterID string, digest QuietDigest) error {
+	m[clusterID] = digest
+	return nil
+}
+
+// TestParseQuietWindow tests windows within a day and across midnight, in a timezone.
+func TestParseQuietWindow(t *testing.T) {
+	window, err := ParseQuietWindow("22:00-07:00", "Europe/Berlin")
+	assert.NoError(t, err)
+	assert.True(t, window.Contains(time.Date(2024, 1, 15, 21, 30, 0, 0, time.UTC))) // 22:30 in Berlin
+	assert.True(t, window.Contains(time.Date(2024, 1, 15, 5, 59, 0, 0, time.UTC)))  // 06:59 in Berlin
+	assert.False(t, window.Contains(time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)))  // 07:00 in Berlin
+	assert.False(t, window.Contains(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))) // 13:00 in Berlin
+
+	window, err = ParseQuietWindow("12:00-13:00", "")
+	assert.NoError(t, err)
+	assert.True(t, window.Contains(time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, 1, 15, 13, 30, 0, 0, time.UTC)))
+
+	_, err = ParseQuietWindow("22:00", "")
+	assert.Error(t, err)
+	_, err = ParseQuietWindow("22:00-07:00", "Mars/Olympus_Mons")
+	assert.Error(t, err)
+}
+
+// TestQuietHours tests that scale-ins are batched during quiet hours and sent as a digest afterwards,
+// while failures go out immediately.
+func TestQuietHours(t *testing.T) {
+	client := &recordingSNS{}
+	window, err := ParseQuietWindow("22:00-07:00", "")
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)
+	quietHours := &QuietHours{
+		Notifier: NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify"),
+		Window:   window,
+		Store:    memoryQuietDigestStore{},
+		Logger:   slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		now:      func() time.Time { return now },
+	}
+
+	assert.NoError(t, quietHours.SendScaleInNotification(context.Background(), "orders", 1))
+	assert.NoError(t, quietHours.SendScaleInNotification(context.Background(), "orders", 2))
+	assert.NoError(t, quietHours.SendFailureNotification(context.Background(), "orders", "boom", "scale in"))
+	assert.Len(t, client.inputs, 1)
+	assert.Contains(t, aws.ToString(client.inputs[0].Message), "Failed to scale in")
+
+	now = time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)
+	assert.NoError(t, quietHours.SendScaleInNotification(context.Background(), "orders", 1))
+	assert.Len(t, client.inputs, 3)
+	assert.Equal(t, "During quiet hours, cluster orders was scaled in 2 times, removing 3 replicas.", aws.ToString(client.inputs[1].Message))
+	assert.Equal(t, "Scaled in cluster orders by removing 1 replicas.", aws.ToString(client.inputs[2].Message))
+
+	// The digest was cleared
+	quietHours.Flush(context.Background(), "orders")
+	assert.Len(t, client.inputs, 3)
+}
@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// RecommendationNotifier is implemented by notifiers that can send rightsizing recommendations, advice
+// on the capacity of a cluster that nothing acts on.
+type RecommendationNotifier interface {
+	SendRecommendations(ctx context.Context, clusterID, message string) error
+}
+
+// SendRecommendations sends the recommendations through notifier, when it supports recommendations.
+func SendRecommendations(ctx context.Context, notifier NotifierInterface, clusterID, message string) error {
+	recommendationNotifier, ok := notifier.(RecommendationNotifier)
+	if !ok {
+		return errors.New("notifier does not support recommendations")
+	}
+	return recommendationNotifier.SendRecommendations(ctx, clusterID, message)
+}
+
+// SendRecommendations sends recommendations to the SNS topic.
+func (n *Notifier) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return n.publish(ctx, clusterID, EventRecommendation, message, SeverityInfo)
+}
+
+// SendRecommendations posts recommendations to the webhook.
+func (s *Slack) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return s.post(ctx, message)
+}
+
+// SendRecommendations posts a recommendation event to the endpoint.
+func (w *Webhook) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventRecommendation, Severity: SeverityInfo.String(), ClusterID: clusterID, Message: message, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// SendRecommendations sends the recommendations to all the notifiers supporting recommendations.
+func (c Composite) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		if recommendationNotifier, ok := notifier.(RecommendationNotifier); ok {
+			return recommendationNotifier.SendRecommendations(ctx, clusterID, message)
+		}
+		return nil
+	})
+}
+
+// SendRecommendations sends the recommendations, which were requested explicitly, whatever the minimum severity.
+func (f *Filter) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return SendRecommendations(ctx, f.Notifier, clusterID, message)
+}
+
+// SendRecommendations sends the recommendations. Recommendations are never deduplicated.
+func (d *Deduplicator) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return SendRecommendations(ctx, d.Notifier, clusterID, message)
+}
+
+// SendRecommendations sends the recommendations. Recommendations are advice, never deferred to the end of quiet hours.
+func (q *QuietHours) SendRecommendations(ctx context.Context, clusterID, message string) error {
+	return SendRecommendations(ctx, q.Notifier, clusterID, message)
+}
@@ -0,0 +1,97 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+)
+
+// Severity ranks notifications, so that routine events can be filtered out.
+type Severity int
+
+// Severity levels, in increasing order.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarn
+	SeverityCritical
+)
+
+// Severities of the notification events: scale-ins are routine, scale-outs signal load and
+// failures need attention.
+const (
+	ScaleInSeverity  = SeverityInfo
+	ScaleOutSeverity = SeverityWarn
+	FailureSeverity  = SeverityCritical
+)
+
+// String returns "info", "warn" or "critical".
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity parses "info", "warn" or "critical".
+func ParseSeverity(name string) (Severity, error) {
+	for _, severity := range []Severity{SeverityInfo, SeverityWarn, SeverityCritical} {
+		if severity.String() == name {
+			return severity, nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("severity must be one of info, warn, critical, got %s", name)
+}
+
+// Filter drops the notifications below a minimum severity. Failures are critical, so they are never dropped.
+type Filter struct {
+	Notifier    NotifierInterface
+	MinSeverity Severity
+}
+
+// Ensure Filter implements NotifierInterface
+var _ NotifierInterface = (*Filter)(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (f *Filter) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	if ScaleOutSeverity < f.MinSeverity {
+		return nil
+	}
+	return f.Notifier.SendScaleOutNotification(ctx, clusterID, replicasAdded)
+}
+
+// SendScaleInNotification sends a notification when scaling in.
+func (f *Filter) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	if ScaleInSeverity < f.MinSeverity {
+		return nil
+	}
+	return f.Notifier.SendScaleInNotification(ctx, clusterID, replicasRemoved)
+}
+
+// SendFailureNotification sends a notification when a scaling action fails.
+func (f *Filter) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return f.Notifier.SendFailureNotification(ctx, clusterID, errorMessage, action)
+}
+
+// WithRepeats returns a copy of the filter whose notifier reports the number of suppressed repeats,
+// when it supports it.
+func (f *Filter) WithRepeats(repeats int) NotifierInterface {
+	copied := *f
+	if repeatNotifier, ok := f.Notifier.(RepeatNotifier); ok {
+		copied.Notifier = repeatNotifier.WithRepeats(repeats)
+	}
+	return &copied
+}
+
+// WithTopology returns a copy of the filter whose notifier reports the reader topology,
+// when it supports it.
+func (f *Filter) WithTopology(topology *Topology) NotifierInterface {
+	copied := *f
+	if topologyNotifier, ok := f.Notifier.(TopologyNotifier); ok {
+		copied.Notifier = topologyNotifier.WithTopology(topology)
+	}
+	return &copied
+}
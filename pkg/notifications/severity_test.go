@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFilter tests that events below the minimum severity are dropped while failures are always sent.
+func TestFilter(t *testing.T) {
+	recorder := &recordingNotifier{}
+	filter := &Filter{Notifier: recorder, MinSeverity: SeverityWarn}
+
+	assert.NoError(t, filter.SendScaleInNotification(context.Background(), "scaled-in", 1))
+	assert.NoError(t, filter.SendScaleOutNotification(context.Background(), "scaled-out", 2))
+	assert.NoError(t, filter.SendFailureNotification(context.Background(), "failed", "throttled", "scale out"))
+	assert.Equal(t, []string{"scaled-out", "failed"}, recorder.clusters)
+
+	filter.MinSeverity = SeverityCritical
+	assert.NoError(t, filter.SendScaleOutNotification(context.Background(), "scaled-out", 2))
+	assert.Equal(t, []string{"scaled-out", "failed"}, recorder.clusters)
+}
+
+// TestParseSeverity tests the severity names.
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("critical")
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityCritical, severity)
+
+	_, err = ParseSeverity("debug")
+	assert.EqualError(t, err, "severity must be one of info, warn, critical, got debug")
+}
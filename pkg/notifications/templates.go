@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template names of the notification events.
+const (
+	TemplateScaleOut = "scaleOut"
+	TemplateScaleIn  = "scaleIn"
+	TemplateFailure  = "failure"
+)
+
+var templateNames = []string{TemplateScaleOut, TemplateScaleIn, TemplateFailure}
+
+// TemplateData is the data of notification templates. Replicas is set for scaling events,
+// Action and Error for failures.
+type TemplateData struct {
+	ClusterID string
+	Severity  Severity
+	Replicas  int
+	Action    string
+	Error     string
+	Repeats   int       // Number of repeated notifications suppressed before this one
+	Topology  *Topology // Reader topology before and after the action, when known
+
+	CorrelationID string // Correlation ID of the invocation, when known
+}
+
+// templateFuncs are the functions available to templates, e.g. {{ env "ENVIRONMENT" }}.
+var templateFuncs = template.FuncMap{
+	"env": os.Getenv,
+}
+
+// Templates holds the text/template message templates of the notification events, keyed by template name.
+// Events without a template keep the default message.
+type Templates map[string]*template.Template
+
+// ParseTemplates parses the message templates of the notification events, keyed by template name.
+func ParseTemplates(sources map[string]string) (Templates, error) {
+	templates := Templates{}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(templateNames, name) {
+			return nil, fmt.Errorf("unknown notification template %s, must be one of %s", name, strings.Join(templateNames, ", "))
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(sources[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification template %s: %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// render returns the message of the template, or the default message when the event has no template
+// or the template fails, so that notifications are never lost.
+func (t Templates) render(name string, data TemplateData, defaultMessage string) string {
+	tmpl, found := t[name]
+	if !found {
+		return defaultMessage
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, data); err != nil {
+		return defaultMessage + fmt.Sprintf(" (notification template %s failed: %v)", name, err)
+	}
+	return message.String()
+}
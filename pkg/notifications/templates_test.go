@@ -0,0 +1,29 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplates tests that templates replace the default message of their event only.
+func TestTemplates(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+	templates, err := ParseTemplates(map[string]string{
+		TemplateFailure: `[{{ env "ENVIRONMENT" }}] {{ .Action }} failed on {{ .ClusterID }}: {{ .Error }}. Runbook: https://wiki.example.com/docdb`,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "[production] scale out failed on orders: throttled. Runbook: https://wiki.example.com/docdb",
+		templates.render(TemplateFailure, TemplateData{ClusterID: "orders", Action: "scale out", Error: "throttled"}, "default"))
+	assert.Equal(t, "default", templates.render(TemplateScaleOut, TemplateData{ClusterID: "orders", Replicas: 2}, "default"))
+}
+
+// TestParseTemplates_Invalid tests that unknown events and invalid templates are rejected.
+func TestParseTemplates_Invalid(t *testing.T) {
+	_, err := ParseTemplates(map[string]string{"scaleUp": "{{ .ClusterID }}"})
+	assert.EqualError(t, err, "unknown notification template scaleUp, must be one of scaleOut, scaleIn, failure")
+
+	_, err = ParseTemplates(map[string]string{TemplateScaleIn: "{{ .ClusterID "})
+	assert.ErrorContains(t, err, "invalid notification template scaleIn")
+}
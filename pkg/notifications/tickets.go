@@ -0,0 +1,126 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Ticket systems of NewTicketNotifier.
+const (
+	TicketSystemJira       = "jira"
+	TicketSystemServiceNow = "servicenow"
+)
+
+// TicketSystems are the supported ticket systems.
+var TicketSystems = []string{TicketSystemJira, TicketSystemServiceNow}
+
+// TicketNotifier opens a ticket for a persistent problem of a cluster, so that it is tracked
+// outside chat notifications.
+type TicketNotifier interface {
+	OpenTicket(ctx context.Context, clusterID, summary, description string) error
+}
+
+// NewTicketNotifier returns the TicketNotifier of a ticket system. project is the Jira project key,
+// and is not used by ServiceNow.
+func NewTicketNotifier(system, url, user, token, project string) (TicketNotifier, error) {
+	switch system {
+	case TicketSystemJira:
+		return NewJira(url, user, token, project), nil
+	case TicketSystemServiceNow:
+		return NewServiceNow(url, user, token), nil
+	default:
+		return nil, fmt.Errorf("unknown ticket system %s, must be one of %s", system, strings.Join(TicketSystems, ", "))
+	}
+}
+
+// Jira opens issues through the Jira REST API.
+type Jira struct {
+	URL        string // Base URL of the site, e.g. https://example.atlassian.net
+	User       string
+	APIToken   string
+	ProjectKey string
+	IssueType  string
+	HTTPClient *http.Client
+}
+
+// NewJira creates a new Jira instance opening tasks in a project.
+func NewJira(url, user, apiToken, projectKey string) *Jira {
+	return &Jira{
+		URL:        strings.TrimRight(url, "/"),
+		User:       user,
+		APIToken:   apiToken,
+		ProjectKey: projectKey,
+		IssueType:  "Task",
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure Jira implements TicketNotifier
+var _ TicketNotifier = (*Jira)(nil)
+
+// jiraIssue is the request body of the create issue endpoint.
+type jiraIssue struct {
+	Fields jiraFields `json:"fields"`
+}
+
+type jiraFields struct {
+	Project     jiraKey  `json:"project"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	IssueType   jiraName `json:"issuetype"`
+	Labels      []string `json:"labels"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+// OpenTicket creates an issue in the project.
+func (j *Jira) OpenTicket(ctx context.Context, clusterID, summary, description string) error {
+	return postJSONAuth(ctx, j.HTTPClient, j.URL+"/rest/api/2/issue", j.User, j.APIToken, jiraIssue{
+		Fields: jiraFields{
+			Project:     jiraKey{Key: j.ProjectKey},
+			Summary:     summary,
+			Description: description,
+			IssueType:   jiraName{Name: j.IssueType},
+			Labels:      []string{"docdb-autoscaler"},
+		},
+	})
+}
+
+// ServiceNow opens incidents through the ServiceNow Table API.
+type ServiceNow struct {
+	URL        string // Base URL of the instance, e.g. https://example.service-now.com
+	User       string
+	Password   string
+	HTTPClient *http.Client
+}
+
+// NewServiceNow creates a new ServiceNow instance opening incidents.
+func NewServiceNow(url, user, password string) *ServiceNow {
+	return &ServiceNow{
+		URL:        strings.TrimRight(url, "/"),
+		User:       user,
+		Password:   password,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure ServiceNow implements TicketNotifier
+var _ TicketNotifier = (*ServiceNow)(nil)
+
+// OpenTicket creates an incident.
+func (s *ServiceNow) OpenTicket(ctx context.Context, clusterID, summary, description string) error {
+	return postJSONAuth(ctx, s.HTTPClient, s.URL+"/api/now/table/incident", s.User, s.Password, map[string]string{
+		"short_description": summary,
+		"description":       description,
+		"correlation_id":    "docdb-autoscaler/" + clusterID,
+	})
+}
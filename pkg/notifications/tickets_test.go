@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJira tests that issues are created in the project with basic authentication.
+func TestJira(t *testing.T) {
+	var issue jiraIssue
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue", r.URL.Path)
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ops@example.com", user)
+		assert.Equal(t, "api-token", token)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&issue))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	tickets, err := NewTicketNotifier(TicketSystemJira, server.URL+"/", "ops@example.com", "api-token", "OPS")
+	assert.NoError(t, err)
+	assert.NoError(t, tickets.OpenTicket(context.Background(), "orders", "Scaling failing", "Recent errors"))
+	assert.Equal(t, "OPS", issue.Fields.Project.Key)
+	assert.Equal(t, "Task", issue.Fields.IssueType.Name)
+	assert.Equal(t, "Scaling failing", issue.Fields.Summary)
+	assert.Equal(t, "Recent errors", issue.Fields.Description)
+}
+
+// TestServiceNow tests that incidents are created and rejected requests are reported.
+func TestServiceNow(t *testing.T) {
+	var incident map[string]string
+	status := http.StatusCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/now/table/incident", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&incident))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	tickets, err := NewTicketNotifier(TicketSystemServiceNow, server.URL, "svc", "password", "")
+	assert.NoError(t, err)
+	assert.NoError(t, tickets.OpenTicket(context.Background(), "orders", "Scaling failing", "Recent errors"))
+	assert.Equal(t, "Scaling failing", incident["short_description"])
+	assert.Equal(t, "docdb-autoscaler/orders", incident["correlation_id"])
+
+	status = http.StatusUnauthorized
+	assert.ErrorContains(t, tickets.OpenTicket(context.Background(), "orders", "Scaling failing", "Recent errors"), "401")
+}
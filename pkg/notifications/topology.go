@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+)
+
+// Instance is a reader instance of a cluster.
+type Instance struct {
+	ID               string `json:"id"`
+	Class            string `json:"class"`
+	AvailabilityZone string `json:"availabilityZone"`
+	Status           string `json:"status"`
+}
+
+// Topology is the reader topology of a cluster before and after a scaling action, so that reviewers
+// can verify what changed without opening the console.
+type Topology struct {
+	Before []Instance    `json:"before"`
+	After  []Instance    `json:"after"`
+	Cost   *pricing.Cost `json:"cost,omitempty"` // Estimated cost delta of the action, when known
+
+	// Why the action was taken, e.g. "CPUUtilization=82.3 > target=70 → +2; clamped by MaxCapacity"
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// TopologyNotifier is implemented by notifiers that can report the reader topology of the cluster
+// in the notification being sent.
+type TopologyNotifier interface {
+	NotifierInterface
+	WithTopology(topology *Topology) NotifierInterface
+}
+
+// withTopology appends the reader topology to a message.
+func withTopology(message string, topology *Topology) string {
+	if topology == nil {
+		return message
+	}
+	if topology.Explanation != "" {
+		message += "\n\nReason: " + topology.Explanation
+	}
+	message += "\n\nReaders before:\n" + formatInstances(topology.Before) + "\nReaders after:\n" + formatInstances(topology.After)
+	if topology.Cost != nil {
+		message += "\nEstimated cost delta: " + topology.Cost.String() + "\n"
+	}
+	return message
+}
+
+// formatInstances lists instances one per line.
+func formatInstances(instances []Instance) string {
+	if len(instances) == 0 {
+		return "- none\n"
+	}
+	var lines strings.Builder
+	for _, instance := range instances {
+		fmt.Fprintf(&lines, "- %s (%s, %s, %s)\n", instance.ID, instance.Class, instance.AvailabilityZone, instance.Status)
+	}
+	return lines.String()
+}
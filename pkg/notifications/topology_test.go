@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/cheelim1/docdb-autoscaler/pkg/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithTopology tests that the reader topology before and after an action is appended to messages.
+func TestWithTopology(t *testing.T) {
+	client := &recordingSNS{}
+	topology := &Topology{
+		Before: []Instance{{ID: "orders-1", Class: "db.r6g.large", AvailabilityZone: "us-east-1a", Status: "available"}},
+		After: []Instance{
+			{ID: "orders-1", Class: "db.r6g.large", AvailabilityZone: "us-east-1a", Status: "available"},
+			{ID: "orders-reader-123", Class: "db.r6g.large", AvailabilityZone: "us-east-1b", Status: "creating"},
+		},
+	}
+	notifier := NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify").WithTopology(topology)
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+
+	assert.Equal(t, "Scaled out cluster orders by adding 1 replicas.\n\n"+
+		"Readers before:\n- orders-1 (db.r6g.large, us-east-1a, available)\n\n"+
+		"Readers after:\n- orders-1 (db.r6g.large, us-east-1a, available)\n- orders-reader-123 (db.r6g.large, us-east-1b, creating)\n",
+		aws.ToString(client.inputs[0].Message))
+
+	// The cost delta of the action is appended when estimated
+	topology.Cost = &pricing.Cost{Hourly: 0.277, Monthly: 202.21}
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.Contains(t, aws.ToString(client.inputs[1].Message), "\nEstimated cost delta: +$0.277/hour (+$202.21/month)\n")
+
+	// So is the explanation of the decision
+	topology.Explanation = "CPUUtilization=82.3 > target=70 → +1"
+	assert.NoError(t, notifier.SendScaleOutNotification(context.Background(), "orders", 1))
+	assert.Contains(t, aws.ToString(client.inputs[2].Message), "Scaled out cluster orders by adding 1 replicas.\n\nReason: CPUUtilization=82.3 > target=70 → +1\n\nReaders before:\n")
+}
+
+// TestWithTopology_Composite tests that composites and filters pass the topology on to their notifiers.
+func TestWithTopology_Composite(t *testing.T) {
+	client := &recordingSNS{}
+	var notifier NotifierInterface = &Filter{
+		Notifier:    Composite{"sns": NewNotifier(client, "arn:aws:sns:us-east-1:123456789012:notify")},
+		MinSeverity: SeverityInfo,
+	}
+	notifier = notifier.(TopologyNotifier).WithTopology(&Topology{})
+	assert.NoError(t, notifier.SendScaleInNotification(context.Background(), "orders", 1))
+	assert.Contains(t, aws.ToString(client.inputs[0].Message), "Readers before:\n- none\n")
+}
@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// WarningNotifier is implemented by notifiers that can send a warning, about a scaling action that went
+// ahead degraded rather than failed, e.g. a scale-out capped by a service quota.
+type WarningNotifier interface {
+	SendWarning(ctx context.Context, clusterID, message, action string) error
+}
+
+// SendWarning sends the warning through notifier, or as a failure notification when the notifier
+// does not support warnings, so that the warning is not lost.
+func SendWarning(ctx context.Context, notifier NotifierInterface, clusterID, message, action string) error {
+	warningNotifier, ok := notifier.(WarningNotifier)
+	if !ok {
+		return notifier.SendFailureNotification(ctx, clusterID, message, action)
+	}
+	return warningNotifier.SendWarning(ctx, clusterID, message, action)
+}
+
+// SendWarning sends a warning to the SNS topic.
+func (n *Notifier) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	message = fmt.Sprintf("Warning during %s on cluster %s: %s", action, clusterID, message)
+	return n.publish(ctx, clusterID, EventWarning, withCorrelationID(ctx, withTopology(message, n.topology)), SeverityWarn)
+}
+
+// SendWarning posts a warning to the webhook.
+func (s *Slack) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	message = fmt.Sprintf(":warning: Warning during %s on cluster %s: %s", action, clusterID, message)
+	return s.post(ctx, withCorrelationID(ctx, withTopology(message, s.topology)))
+}
+
+// SendWarning posts a warning event to the endpoint.
+func (w *Webhook) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventWarning, Severity: SeverityWarn.String(), ClusterID: clusterID, Action: action, Message: message, Topology: w.topology, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// SendWarning sends the warning to all the notifiers.
+func (c Composite) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	return c.fanOut(func(notifier NotifierInterface) error {
+		return SendWarning(ctx, notifier, clusterID, message, action)
+	})
+}
+
+// SendWarning sends the warning, unless the minimum severity is critical.
+func (f *Filter) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	if SeverityWarn < f.MinSeverity {
+		return nil
+	}
+	return SendWarning(ctx, f.Notifier, clusterID, message, action)
+}
+
+// SendWarning sends a warning, unless an identical one was sent within the window.
+func (d *Deduplicator) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	return d.send(ctx, clusterID, EventWarning, action+"\n"+message, func(notifier NotifierInterface) error {
+		return SendWarning(ctx, notifier, clusterID, message, action)
+	})
+}
+
+// SendWarning sends a warning, also during quiet hours.
+func (q *QuietHours) SendWarning(ctx context.Context, clusterID, message, action string) error {
+	q.Flush(ctx, clusterID)
+	return SendWarning(ctx, q.Notifier, clusterID, message, action)
+}
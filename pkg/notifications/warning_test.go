@@ -0,0 +1,32 @@
+package notifications
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSendWarning tests that warnings are posted as warn events, filtered below critical, and sent as
+// failures by the notifiers that do not support warnings.
+func TestSendWarning(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		body = string(payload)
+	}))
+	defer server.Close()
+
+	recorder := &recordingNotifier{}
+	composite := Composite{"webhook": NewWebhook(server.URL), "custom": recorder}
+	assert.NoError(t, SendWarning(context.Background(), composite, "orders", "quota reached", "scale out"))
+	assert.JSONEq(t, `{"event":"Warning","severity":"warn","clusterId":"orders","action":"scale out","message":"quota reached"}`, body)
+	assert.Equal(t, []string{"orders"}, recorder.clusters)
+
+	filter := &Filter{Notifier: recorder, MinSeverity: SeverityCritical}
+	assert.NoError(t, SendWarning(context.Background(), filter, "orders", "quota reached", "scale out"))
+	assert.Equal(t, []string{"orders"}, recorder.clusters)
+}
@@ -0,0 +1,178 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cheelim1/docdb-autoscaler/pkg/correlation"
+)
+
+// Event types of webhook notifications.
+const (
+	EventScaleOut       = "ScaleOut"
+	EventScaleIn        = "ScaleIn"
+	EventFailure        = "Failure"
+	EventDigest         = "Digest"
+	EventWarning        = "Warning"
+	EventRecommendation = "Recommendation"
+)
+
+// postJSON posts a JSON body and fails on a non-2xx response.
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	return postJSONAuth(ctx, client, url, "", "", body)
+}
+
+// postJSONAuth posts a JSON body with basic authentication, when user is set, and fails on a non-2xx response.
+func postJSONAuth(ctx context.Context, client *http.Client, url, user, password string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		request.SetBasicAuth(user, password)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("POST %s: %s: %s", request.URL.Redacted(), response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Slack posts notifications to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	HTTPClient *http.Client
+	Templates  Templates // Optional message templates
+
+	repeats  int
+	topology *Topology
+}
+
+// NewSlack creates a new Slack instance for an incoming webhook URL.
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{
+		WebhookURL: webhookURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure Slack implements NotifierInterface
+var _ NotifierInterface = (*Slack)(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (s *Slack) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	message := fmt.Sprintf("Scaled out cluster %s by adding %d replicas.", clusterID, replicasAdded)
+	return s.post(ctx, s.Templates.render(TemplateScaleOut, TemplateData{ClusterID: clusterID, Replicas: replicasAdded, Severity: ScaleOutSeverity, Repeats: s.repeats, Topology: s.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, s.repeats), s.topology))))
+}
+
+// SendScaleInNotification sends a notification when scaling in.
+func (s *Slack) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	message := fmt.Sprintf("Scaled in cluster %s by removing %d replicas.", clusterID, replicasRemoved)
+	return s.post(ctx, s.Templates.render(TemplateScaleIn, TemplateData{ClusterID: clusterID, Replicas: replicasRemoved, Severity: ScaleInSeverity, Repeats: s.repeats, Topology: s.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, s.repeats), s.topology))))
+}
+
+// SendFailureNotification sends a notification when a scaling action fails.
+func (s *Slack) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	message := fmt.Sprintf(":rotating_light: Failed to %s on cluster %s: %s", action, clusterID, errorMessage)
+	return s.post(ctx, s.Templates.render(TemplateFailure, TemplateData{ClusterID: clusterID, Action: action, Error: errorMessage, Severity: FailureSeverity, Repeats: s.repeats, Topology: s.topology, CorrelationID: correlation.FromContext(ctx)}, withCorrelationID(ctx, withTopology(withRepeats(message, s.repeats), s.topology))))
+}
+
+// WithRepeats returns a copy of the notifier reporting the number of suppressed repeats in its messages.
+func (s *Slack) WithRepeats(repeats int) NotifierInterface {
+	copied := *s
+	copied.repeats = repeats
+	return &copied
+}
+
+// WithTopology returns a copy of the notifier reporting the reader topology in its messages.
+func (s *Slack) WithTopology(topology *Topology) NotifierInterface {
+	copied := *s
+	copied.topology = topology
+	return &copied
+}
+
+// post sends a message to the webhook.
+func (s *Slack) post(ctx context.Context, text string) error {
+	return postJSON(ctx, s.HTTPClient, s.WebhookURL, map[string]string{"text": text})
+}
+
+// Webhook posts notifications as JSON events to an HTTP endpoint.
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+
+	repeats  int
+	topology *Topology
+}
+
+// WebhookEvent is the body of a webhook notification.
+type WebhookEvent struct {
+	Event     string    `json:"event"`    // EventScaleOut, EventScaleIn, EventFailure, EventDigest, EventWarning or EventRecommendation
+	Severity  string    `json:"severity"` // "info", "warn" or "critical"
+	ClusterID string    `json:"clusterId"`
+	Replicas  int       `json:"replicas,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Repeats   int       `json:"repeats,omitempty"` // Number of repeated notifications suppressed before this one
+	Topology  *Topology `json:"topology,omitempty"`
+	Message   string    `json:"message,omitempty"` // Summary of a digest
+
+	CorrelationID string `json:"correlationId,omitempty"` // Correlation ID of the invocation
+}
+
+// NewWebhook creates a new Webhook instance for an endpoint URL.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Ensure Webhook implements NotifierInterface
+var _ NotifierInterface = (*Webhook)(nil)
+
+// SendScaleOutNotification sends a notification when scaling out.
+func (w *Webhook) SendScaleOutNotification(ctx context.Context, clusterID string, replicasAdded int) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventScaleOut, Severity: ScaleOutSeverity.String(), ClusterID: clusterID, Replicas: replicasAdded, Repeats: w.repeats, Topology: w.topology, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// SendScaleInNotification sends a notification when scaling in.
+func (w *Webhook) SendScaleInNotification(ctx context.Context, clusterID string, replicasRemoved int) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventScaleIn, Severity: ScaleInSeverity.String(), ClusterID: clusterID, Replicas: replicasRemoved, Repeats: w.repeats, Topology: w.topology, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// SendFailureNotification sends a notification when a scaling action fails.
+func (w *Webhook) SendFailureNotification(ctx context.Context, clusterID, errorMessage, action string) error {
+	return postJSON(ctx, w.HTTPClient, w.URL, WebhookEvent{Event: EventFailure, Severity: FailureSeverity.String(), ClusterID: clusterID, Action: action, Error: errorMessage, Repeats: w.repeats, Topology: w.topology, CorrelationID: correlation.FromContext(ctx)})
+}
+
+// WithRepeats returns a copy of the notifier reporting the number of suppressed repeats in its events.
+func (w *Webhook) WithRepeats(repeats int) NotifierInterface {
+	copied := *w
+	copied.repeats = repeats
+	return &copied
+}
+
+// WithTopology returns a copy of the notifier reporting the reader topology in its events.
+func (w *Webhook) WithTopology(topology *Topology) NotifierInterface {
+	copied := *w
+	copied.topology = topology
+	return &copied
+}
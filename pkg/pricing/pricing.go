@@ -0,0 +1,220 @@
+// Package pricing estimates the on-demand cost of instances from the AWS Price List API, so that scaling
+// decisions carry a price tag.
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingTypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// Region is the region of the Price List API endpoint queried for the prices of all regions.
+const Region = "us-east-1"
+
+// DefaultCacheTTL is how long a price is reused before it is fetched again. Prices change rarely.
+const DefaultCacheTTL = 24 * time.Hour
+
+// HoursPerMonth is the average number of hours in a month, as used by AWS pricing.
+const HoursPerMonth = 730
+
+// Cost is an estimated on-demand cost, in USD, e.g. the cost delta of a scaling action.
+type Cost struct {
+	Hourly  float64 `json:"hourly"`
+	Monthly float64 `json:"monthly"`
+}
+
+// NewCost returns the cost of an hourly price, rounded to the cent per month.
+func NewCost(hourly float64) Cost {
+	return Cost{Hourly: math.Round(hourly*10000) / 10000, Monthly: math.Round(hourly*HoursPerMonth*100) / 100}
+}
+
+// String formats the cost as a signed delta, e.g. "+$0.55/hour (+$401.50/month)".
+func (c Cost) String() string {
+	sign := "+"
+	if c.Hourly < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s$%.4g/hour (%s$%.2f/month)", sign, math.Abs(c.Hourly), sign, math.Abs(c.Monthly))
+}
+
+// Estimator returns the prices of instances.
+type Estimator interface {
+	// HourlyPrice returns the on-demand hourly price, in USD, of an instance of the engine and class in the region.
+	HourlyPrice(ctx context.Context, engine, region, instanceClass string) (float64, error)
+}
+
+// PricingAPI defines the interface for AWS Price List API client methods used.
+type PricingAPI interface {
+	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
+}
+
+// product is how the price list of an engine is filtered: the service code, and the attributes of the
+// products of the engine besides the instance type and region.
+type product struct {
+	serviceCode string
+	attributes  map[string]string
+}
+
+// products are the price lists of the supported engines.
+var products = map[string]product{
+	"docdb":             {serviceCode: "AmazonDocDB"},
+	"neptune":           {serviceCode: "AmazonNeptune"},
+	"aurora-mysql":      {serviceCode: "AmazonRDS", attributes: map[string]string{"databaseEngine": "Aurora MySQL"}},
+	"aurora-postgresql": {serviceCode: "AmazonRDS", attributes: map[string]string{"databaseEngine": "Aurora PostgreSQL"}},
+}
+
+// PriceList estimates prices from the AWS Price List API, caching the price of every engine, region and
+// instance class for the TTL. It is safe for concurrent use, so that one price list can serve all the
+// invocations of a warm Lambda.
+type PriceList struct {
+	Client PricingAPI
+	TTL    time.Duration
+
+	mu     sync.Mutex
+	prices map[string]cachedPrice
+	now    func() time.Time
+}
+
+// cachedPrice is a price and when it expires.
+type cachedPrice struct {
+	hourly    float64
+	expiresAt time.Time
+}
+
+// NewPriceList creates a new price list, with DefaultCacheTTL when ttl is zero.
+func NewPriceList(client PricingAPI, ttl time.Duration) *PriceList {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &PriceList{Client: client, TTL: ttl}
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   *PriceList
+)
+
+// Shared returns the price list of the process, created with client on the first call, so that the
+// clusters evaluated by a warm Lambda reuse the cached prices.
+func Shared(client PricingAPI) *PriceList {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if shared == nil {
+		shared = NewPriceList(client, DefaultCacheTTL)
+	}
+	return shared
+}
+
+// Ensure PriceList implements Estimator
+var _ Estimator = (*PriceList)(nil)
+
+// HourlyPrice returns the on-demand hourly price of an instance with standard storage, from the cache
+// when it has not expired.
+func (p *PriceList) HourlyPrice(ctx context.Context, engine, region, instanceClass string) (float64, error) {
+	key := engine + "/" + region + "/" + instanceClass
+	p.mu.Lock()
+	cached, found := p.prices[key]
+	p.mu.Unlock()
+	if found && p.currentTime().Before(cached.expiresAt) {
+		return cached.hourly, nil
+	}
+
+	hourly, err := p.fetchHourlyPrice(ctx, engine, region, instanceClass)
+	if err != nil {
+		return 0, err
+	}
+	p.mu.Lock()
+	if p.prices == nil {
+		p.prices = map[string]cachedPrice{}
+	}
+	p.prices[key] = cachedPrice{hourly: hourly, expiresAt: p.currentTime().Add(p.TTL)}
+	p.mu.Unlock()
+	return hourly, nil
+}
+
+// fetchHourlyPrice reads the price of an instance from the price list. I/O-optimized storage is priced
+// differently, so its products are skipped.
+func (p *PriceList) fetchHourlyPrice(ctx context.Context, engine, region, instanceClass string) (float64, error) {
+	engineProduct, found := products[engine]
+	if !found {
+		return 0, fmt.Errorf("no price list for engine %s", engine)
+	}
+	attributes := map[string]string{"instanceType": instanceClass, "regionCode": region}
+	for name, value := range engineProduct.attributes {
+		attributes[name] = value
+	}
+	input := &pricing.GetProductsInput{ServiceCode: aws.String(engineProduct.serviceCode)}
+	for name, value := range attributes {
+		input.Filters = append(input.Filters, pricingTypes.Filter{Type: pricingTypes.FilterTypeTermMatch, Field: aws.String(name), Value: aws.String(value)})
+	}
+
+	paginator := pricing.NewGetProductsPaginator(p.Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the price of %s in %s: %w", instanceClass, region, err)
+		}
+		for _, priceList := range page.PriceList {
+			if hourly, found := onDemandHourlyPrice(priceList); found {
+				return hourly, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no on-demand price for %s %s in %s", engine, instanceClass, region)
+}
+
+// priceListItem is the part of a product of the price list holding its on-demand price.
+type priceListItem struct {
+	Product struct {
+		Attributes map[string]string `json:"attributes"`
+	} `json:"product"`
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// onDemandHourlyPrice returns the hourly on-demand price in USD of a product of the price list, unless it
+// is the product of I/O-optimized storage.
+func onDemandHourlyPrice(priceList string) (float64, bool) {
+	var item priceListItem
+	if err := json.Unmarshal([]byte(priceList), &item); err != nil {
+		return 0, false
+	}
+	if strings.Contains(item.Product.Attributes["usagetype"], "IOOptimized") {
+		return 0, false
+	}
+	for _, term := range item.Terms.OnDemand {
+		for _, dimension := range term.PriceDimensions {
+			if dimension.Unit != "Hrs" {
+				continue
+			}
+			hourly, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+			if err == nil && hourly > 0 {
+				return hourly, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// currentTime returns the current time, which tests can override.
+func (p *PriceList) currentTime() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+// priceListClient returns the same products for every query, and records the queries.
+type priceListClient struct {
+	priceList []string
+	inputs    []*pricing.GetProductsInput
+}
+
+func (c *priceListClient) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	c.inputs = append(c.inputs, params)
+	return &pricing.GetProductsOutput{PriceList: c.priceList}, nil
+}
+
+func (c *priceListClient) filter(field string) string {
+	for _, filter := range c.inputs[len(c.inputs)-1].Filters {
+		if aws.ToString(filter.Field) == field {
+			return aws.ToString(filter.Value)
+		}
+	}
+	return ""
+}
+
+const (
+	ioOptimizedProduct = `{"product":{"attributes":{"usagetype":"USE1-IOOptimizedUsage:db.r6g.large"}},"terms":{"OnDemand":{"A.1":{"priceDimensions":{"A.1.1":{"unit":"Hrs","pricePerUnit":{"USD":"0.3588"}}}}}}}`
+	standardProduct    = `{"product":{"attributes":{"usagetype":"USE1-InstanceUsage:db.r6g.large"}},"terms":{"OnDemand":{"B.1":{"priceDimensions":{"B.1.1":{"unit":"Hrs","pricePerUnit":{"USD":"0.2770000000"}}}}}}}`
+)
+
+// TestPriceList tests that the on-demand price of standard storage is read from the price list, and
+// cached until the TTL elapsed.
+func TestPriceList(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	client := &priceListClient{priceList: []string{ioOptimizedProduct, standardProduct}}
+	prices := NewPriceList(client, time.Hour)
+	prices.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	hourly, err := prices.HourlyPrice(ctx, "docdb", "us-east-1", "db.r6g.large")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.277, hourly, "the I/O-optimized product is skipped")
+	assert.Equal(t, "AmazonDocDB", aws.ToString(client.inputs[0].ServiceCode))
+	assert.Equal(t, "db.r6g.large", client.filter("instanceType"))
+	assert.Equal(t, "us-east-1", client.filter("regionCode"))
+
+	_, err = prices.HourlyPrice(ctx, "docdb", "us-east-1", "db.r6g.large")
+	assert.NoError(t, err)
+	assert.Len(t, client.inputs, 1, "the price is cached")
+
+	now = now.Add(2 * time.Hour)
+	_, err = prices.HourlyPrice(ctx, "docdb", "us-east-1", "db.r6g.large")
+	assert.NoError(t, err)
+	assert.Len(t, client.inputs, 2, "the price is fetched again once the TTL elapsed")
+
+	_, err = prices.HourlyPrice(ctx, "aurora-postgresql", "eu-west-1", "db.r6g.large")
+	assert.NoError(t, err)
+	assert.Equal(t, "AmazonRDS", aws.ToString(client.inputs[2].ServiceCode))
+	assert.Equal(t, "Aurora PostgreSQL", client.filter("databaseEngine"))
+
+	_, err = prices.HourlyPrice(ctx, "mongodb", "us-east-1", "db.r6g.large")
+	assert.Error(t, err, "unsupported engines have no price list")
+
+	client.priceList = []string{ioOptimizedProduct}
+	_, err = prices.HourlyPrice(ctx, "neptune", "us-east-1", "db.r6g.large")
+	assert.Error(t, err, "no standard storage product")
+}
+
+// TestCost tests that costs are rounded and formatted as signed deltas.
+func TestCost(t *testing.T) {
+	cost := NewCost(0.55)
+	assert.Equal(t, Cost{Hourly: 0.55, Monthly: 401.5}, cost)
+	assert.Equal(t, "+$0.55/hour (+$401.50/month)", cost.String())
+	assert.Equal(t, "-$0.277/hour (-$202.21/month)", NewCost(-0.277).String())
+}
@@ -0,0 +1,57 @@
+// Package ratelimit limits the rate of the AWS API calls of the autoscaler on the client side, so that
+// evaluating many clusters does not trip the account-level throttles shared with other tooling.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+	"golang.org/x/time/rate"
+)
+
+// Limiter is a token bucket shared by the AWS clients it is added to.
+type Limiter struct {
+	limiter *rate.Limiter
+}
+
+// New returns a limiter of rps calls per second on average, allowing bursts of up to rps calls.
+func New(rps float64) *Limiter {
+	burst := max(1, int(math.Ceil(rps)))
+	return &Limiter{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+}
+
+var (
+	sharedMu sync.Mutex
+	shared   = map[float64]*Limiter{}
+)
+
+// Shared returns the limiter of rps calls per second of the process, so that the clients of every
+// cluster evaluated by a warm Lambda draw from the same bucket.
+func Shared(rps float64) *Limiter {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	limiter, ok := shared[rps]
+	if !ok {
+		limiter = New(rps)
+		shared[rps] = limiter
+	}
+	return limiter
+}
+
+// Wait blocks until a call is allowed, or ctx ends.
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.limiter.Wait(ctx)
+}
+
+// APIOption adds the limiter to the middleware stack of a client, see aws.Config.APIOptions. Every
+// operation waits for a token before it is sent, retries of the SDK included in the operation.
+func (l *Limiter) APIOption(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("DocDBAutoscalerRateLimit", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		if err := l.Wait(ctx); err != nil {
+			return middleware.InitializeOutput{}, middleware.Metadata{}, err
+		}
+		return next.HandleInitialize(ctx, in)
+	}), middleware.Before)
+}
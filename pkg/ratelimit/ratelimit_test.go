@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLimiter tests that calls beyond the burst wait for a token.
+func TestLimiter(t *testing.T) {
+	limiter := New(20)
+	stack := middleware.NewStack("test", func() interface{} { return nil })
+	assert.NoError(t, limiter.APIOption(stack))
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+		return nil, middleware.Metadata{}, nil
+	}), stack)
+
+	start := time.Now()
+	for i := 0; i < 25; i++ {
+		_, _, err := handler.Handle(context.Background(), nil)
+		assert.NoError(t, err)
+	}
+	// The burst of 20 calls passes right away, the next 5 wait for a token every 50ms
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+// TestLimiter_Canceled tests that a call waiting for a token ends with its context.
+func TestLimiter_Canceled(t *testing.T) {
+	limiter := New(0.1)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, limiter.Wait(ctx))
+}
+
+// TestShared tests that the limiters of the same rate are shared.
+func TestShared(t *testing.T) {
+	assert.Same(t, Shared(5), Shared(5))
+	assert.NotSame(t, Shared(5), Shared(10))
+}
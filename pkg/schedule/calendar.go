@@ -0,0 +1,148 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of the dates of a calendar, and icsDateLayout that of the dates of iCalendar.
+const (
+	dateLayout    = "2006-01-02"
+	icsDateLayout = "20060102"
+)
+
+// Calendar is a set of holidays, whole days of a timezone, e.g. the public holidays scheduled scale-outs
+// are skipped on. The methods of a nil Calendar report no holidays.
+type Calendar struct {
+	holidays map[string]string // Names by date, in dateLayout
+	location *time.Location
+}
+
+// NewCalendar returns an empty calendar of the named timezone, UTC when empty.
+func NewCalendar(timezone string) (*Calendar, error) {
+	location, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &Calendar{holidays: map[string]string{}, location: location}, nil
+}
+
+// AddDate adds a holiday of the form YYYY-MM-DD, with an optional name.
+func (c *Calendar) AddDate(date, name string) error {
+	day, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return fmt.Errorf("invalid holiday %q, must be YYYY-MM-DD", date)
+	}
+	c.add(day, name)
+	return nil
+}
+
+// add adds the holiday of a day, keeping the name of a day added before.
+func (c *Calendar) add(day time.Time, name string) {
+	key := day.Format(dateLayout)
+	if c.holidays[key] == "" {
+		c.holidays[key] = name
+	}
+}
+
+// AddICS adds the events of an iCalendar file as holidays, named by their summary. All-day events are
+// holidays from their start to the day before their end; other events make a holiday of their start
+// date. Recurrence rules are not expanded, as calendars of public holidays list every date.
+func (c *Calendar) AddICS(data []byte) error {
+	var inEvent bool
+	var start, end, summary string
+	for i, line := range unfoldICS(data) {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		property, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(property) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, start, end, summary = true, "", "", ""
+			}
+		case "END":
+			if !inEvent || !strings.EqualFold(value, "VEVENT") {
+				continue
+			}
+			inEvent = false
+			if err := c.addEvent(start, end, summary); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		case "DTSTART":
+			start = value
+		case "DTEND":
+			// The end of timed events does not make more holidays
+			if strings.Contains(strings.ToUpper(params), "VALUE=DATE") || len(value) == len(icsDateLayout) {
+				end = value
+			}
+		case "SUMMARY":
+			summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+		}
+	}
+	return nil
+}
+
+// addEvent adds the holidays of an event from its DTSTART and DTEND.
+func (c *Calendar) addEvent(start, end, summary string) error {
+	if len(start) < len(icsDateLayout) {
+		return fmt.Errorf("event %q has no valid DTSTART", summary)
+	}
+	first, err := time.Parse(icsDateLayout, start[:len(icsDateLayout)])
+	if err != nil {
+		return fmt.Errorf("event %q has an invalid DTSTART %s", summary, start)
+	}
+	last := first
+	if end != "" {
+		endDate, err := time.Parse(icsDateLayout, end)
+		if err != nil {
+			return fmt.Errorf("event %q has an invalid DTEND %s", summary, end)
+		}
+		// The end of all-day events is exclusive
+		if endDate.After(first) {
+			last = endDate.AddDate(0, 0, -1)
+		}
+	}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		c.add(day, summary)
+	}
+	return nil
+}
+
+// unfoldICS returns the lines of an iCalendar file, with the continuation lines, which start with a space
+// or a tab, joined to the line they continue.
+func unfoldICS(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Holiday returns the name of the holiday t falls on in the timezone of the calendar, and whether it
+// falls on one.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	name, found := c.holidays[t.In(c.location).Format(dateLayout)]
+	return name, found
+}
+
+// Len returns the number of holidays of the calendar.
+func (c *Calendar) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.holidays)
+}
@@ -0,0 +1,239 @@
+// Package schedule evaluates cron expressions in an IANA timezone, so that the autoscaler itself decides
+// whether a tick of EventBridge falls inside a scaling window, whatever the UTC offset of the day.
+//
+// Expressions have the five fields of cron: minute, hour, day of month, month and day of week, e.g.
+// "0 8 * * MON-FRI". Fields are *, values, ranges and lists of them, with an optional /step; months and
+// days of week may be named by their first three letters, and Sunday is 0 or 7. As in cron, a day matches
+// either of the day-of-month and day-of-week fields when both are restricted.
+//
+// Occurrences are wall-clock times of the timezone: one that falls in the gap of a DST transition occurs
+// once the clocks went forward, and one that is repeated when they go back occurs the first time.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // The image has no zoneinfo for the timezones of schedules
+)
+
+// searchDays bounds the days searched back for an occurrence, so that a date that recurs once every
+// leap year, e.g. "0 0 29 2 *", is still found.
+const searchDays = 8 * 366
+
+// field is a cron field: the bounds of its values and their names, if any.
+type field struct {
+	name  string
+	min   int
+	max   int
+	names []string // Names of the values from min
+}
+
+var (
+	minuteField  = field{name: "minute", min: 0, max: 59}
+	hourField    = field{name: "hour", min: 0, max: 23}
+	dayField     = field{name: "day of month", min: 1, max: 31}
+	monthField   = field{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	weekdayField = field{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// Cron is a parsed cron expression in a timezone.
+type Cron struct {
+	spec     string
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool // Sunday is 0
+	anyDay   bool   // Day of month is *
+	anyWeek  bool   // Day of week is *
+	location *time.Location
+}
+
+// Parse parses a cron expression in the named timezone, UTC when empty.
+func Parse(spec, timezone string) (*Cron, error) {
+	location, err := loadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields: minute, hour, day of month, month and day of week", spec)
+	}
+	c := &Cron{spec: spec, location: location, anyDay: fields[2] == "*", anyWeek: fields[4] == "*"}
+	for i, target := range []*[]bool{&c.minutes, &c.hours, &c.days, &c.months, &c.weekdays} {
+		f := []field{minuteField, hourField, dayField, monthField, weekdayField}[i]
+		if *target, err = f.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", spec, err)
+		}
+	}
+	if c.weekdays[7] {
+		c.weekdays[0] = true
+	}
+	return c, nil
+}
+
+// String returns the expression of the cron.
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// parse parses a field of a cron expression into the values it matches.
+func (f field) parse(spec string) ([]bool, error) {
+	values := make([]bool, f.max+1)
+	for _, part := range strings.Split(spec, ",") {
+		rangeSpec, stepSpec, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q of the %s field", stepSpec, f.name)
+			}
+		}
+		low, high := f.min, f.max
+		if rangeSpec != "*" {
+			lowSpec, highSpec, ranged := strings.Cut(rangeSpec, "-")
+			var err error
+			if low, err = f.value(lowSpec); err != nil {
+				return nil, err
+			}
+			high = low
+			if ranged {
+				if high, err = f.value(highSpec); err != nil {
+					return nil, err
+				}
+			} else if stepped {
+				high = f.max
+			}
+			if low > high {
+				return nil, fmt.Errorf("range %q of the %s field must not end before it starts", rangeSpec, f.name)
+			}
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// value parses a value of the field, a number or a name.
+func (f field) value(spec string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(spec, name) {
+			return f.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(spec)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid value %q of the %s field, must be between %d and %d", spec, f.name, f.min, f.max)
+	}
+	return value, nil
+}
+
+// matchesDay reports whether the cron occurs on a date.
+func (c *Cron) matchesDay(year int, month time.Month, day int) bool {
+	if !c.months[month] {
+		return false
+	}
+	weekday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()
+	switch {
+	case c.anyDay && c.anyWeek:
+		return true
+	case c.anyDay:
+		return c.weekdays[weekday]
+	case c.anyWeek:
+		return c.days[day]
+	default:
+		return c.days[day] || c.weekdays[weekday]
+	}
+}
+
+// Prev returns the last occurrence of the cron at or before t, or the zero time when it did not occur
+// in the last eight years.
+func (c *Cron) Prev(t time.Time) time.Time {
+	local := t.In(c.location)
+	// An occurrence moved forward by a DST gap may be past the wall clock of t, so the search starts from
+	// the end of the day
+	year, month, day := local.Date()
+	for i := 0; i < searchDays; i++ {
+		date := time.Date(year, month, day-i, 0, 0, 0, 0, time.UTC)
+		if !c.matchesDay(date.Year(), date.Month(), date.Day()) {
+			continue
+		}
+		for hour := hourField.max; hour >= hourField.min; hour-- {
+			if !c.hours[hour] {
+				continue
+			}
+			for minute := minuteField.max; minute >= minuteField.min; minute-- {
+				if !c.minutes[minute] {
+					continue
+				}
+				occurrence := c.occurrence(date.Year(), date.Month(), date.Day(), hour, minute)
+				if !occurrence.After(t) {
+					return occurrence
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// occurrence returns the instant of a wall-clock time of the timezone: the first of the two instants of a
+// time repeated when the clocks go back, and the instant the clocks went forward for a time in the gap.
+func (c *Cron) occurrence(year int, month time.Month, day, hour, minute int) time.Time {
+	occurrence := time.Date(year, month, day, hour, minute, 0, 0, c.location)
+	// time.Date may pick either instant of a repeated time, so the offsets around it are tried too
+	wall := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	for _, around := range []time.Time{occurrence.Add(-12 * time.Hour), occurrence.Add(12 * time.Hour)} {
+		_, offset := around.Zone()
+		earlier := wall.Add(-time.Duration(offset) * time.Second).In(c.location)
+		if earlier.Before(occurrence) && earlier.Hour() == hour && earlier.Minute() == minute && earlier.Day() == day {
+			occurrence = earlier
+		}
+	}
+	return occurrence
+}
+
+// Window is a recurring window between the occurrences of two crons, e.g. business hours from
+// "0 8 * * MON-FRI" to "0 20 * * MON-FRI".
+type Window struct {
+	Start *Cron
+	End   *Cron
+}
+
+// ParseWindow parses the start and end crons of a window in the named timezone, UTC when empty.
+func ParseWindow(start, end, timezone string) (Window, error) {
+	startCron, err := Parse(start, timezone)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid start: %w", err)
+	}
+	endCron, err := Parse(end, timezone)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid end: %w", err)
+	}
+	return Window{Start: startCron, End: endCron}, nil
+}
+
+// Contains reports whether t is within the window: when the window started at or before t and has
+// not ended since. A window ending as it starts is closed.
+func (w Window) Contains(t time.Time) bool {
+	start := w.Start.Prev(t)
+	if start.IsZero() {
+		return false
+	}
+	end := w.End.Prev(t)
+	return end.IsZero() || start.After(end)
+}
+
+// loadLocation loads the named timezone, UTC when empty.
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %w", timezone, err)
+	}
+	return location, nil
+}
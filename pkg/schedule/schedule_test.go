@@ -0,0 +1,151 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at returns a wall-clock time of a timezone.
+func at(t *testing.T, timezone string, year int, month time.Month, day, hour, minute int) time.Time {
+	location, err := time.LoadLocation(timezone)
+	require.NoError(t, err)
+	return time.Date(year, month, day, hour, minute, 0, 0, location)
+}
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "0 8 * * MON-FRI", "*/15 8-18 1,15 jan-mar,dec 0,7", "5/10 * * * *"} {
+		_, err := Parse(spec, "Europe/London")
+		assert.NoError(t, err, spec)
+	}
+
+	for spec, expectedErr := range map[string]string{
+		"0 8 * *":      "must have 5 fields",
+		"60 8 * * *":   `invalid value "60" of the minute field`,
+		"0 8 0 * *":    `invalid value "0" of the day of month field`,
+		"0 8 * FOO *":  `invalid value "FOO" of the month field`,
+		"0 18-8 * * *": "must not end before it starts",
+		"*/0 * * * *":  `invalid step "0"`,
+	} {
+		_, err := Parse(spec, "")
+		assert.ErrorContains(t, err, expectedErr, spec)
+	}
+
+	_, err := Parse("0 8 * * *", "Mars/Olympus")
+	assert.ErrorContains(t, err, "invalid timezone Mars/Olympus")
+}
+
+func TestPrev(t *testing.T) {
+	weekdays, err := Parse("0 8 * * MON-FRI", "America/New_York")
+	require.NoError(t, err)
+	// Saturday 2024-06-15 goes back to Friday
+	assert.Equal(t, at(t, "America/New_York", 2024, time.June, 14, 8, 0), weekdays.Prev(at(t, "America/New_York", 2024, time.June, 15, 10, 0)))
+	// An occurrence is at or before t
+	assert.Equal(t, at(t, "America/New_York", 2024, time.June, 14, 8, 0), weekdays.Prev(at(t, "America/New_York", 2024, time.June, 14, 8, 0)))
+
+	// Both day fields restricted match either
+	either, err := Parse("0 0 13 * FRI", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.June, 7, 0, 0, 0, 0, time.UTC), either.Prev(time.Date(2024, time.June, 12, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, time.June, 13, 0, 0, 0, 0, time.UTC), either.Prev(time.Date(2024, time.June, 13, 12, 0, 0, 0, time.UTC)))
+
+	leapDay, err := Parse("0 0 29 2 *", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), leapDay.Prev(time.Date(2027, time.June, 1, 0, 0, 0, 0, time.UTC)))
+
+	never, err := Parse("0 0 31 2 *", "")
+	require.NoError(t, err)
+	assert.True(t, never.Prev(time.Now()).IsZero())
+}
+
+// TestPrev_DST tests that occurrences keep their wall-clock time across DST transitions.
+func TestPrev_DST(t *testing.T) {
+	morning, err := Parse("0 8 * * *", "Europe/London")
+	require.NoError(t, err)
+	// 08:00 is 07:00 UTC in summer and 08:00 UTC in winter
+	assert.Equal(t, time.Date(2024, time.March, 31, 7, 0, 0, 0, time.UTC), morning.Prev(time.Date(2024, time.March, 31, 7, 30, 0, 0, time.UTC)).UTC())
+	assert.Equal(t, time.Date(2024, time.March, 30, 8, 0, 0, 0, time.UTC), morning.Prev(time.Date(2024, time.March, 31, 6, 30, 0, 0, time.UTC)).UTC())
+
+	// 01:30 does not exist on 2024-03-31 in London and occurs once the clocks went forward
+	gap, err := Parse("30 1 * * *", "Europe/London")
+	require.NoError(t, err)
+	occurrence := gap.Prev(time.Date(2024, time.March, 31, 2, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.March, occurrence.Month())
+	assert.Equal(t, 31, occurrence.Day())
+	assert.False(t, occurrence.After(time.Date(2024, time.March, 31, 2, 0, 0, 0, time.UTC)))
+
+	// 01:30 occurs twice on 2024-10-27 in London, and the cron only the first time
+	repeated, err := Parse("30 1 * * *", "Europe/London")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC), repeated.Prev(time.Date(2024, time.October, 27, 1, 45, 0, 0, time.UTC)).UTC())
+}
+
+func TestWindow(t *testing.T) {
+	window, err := ParseWindow("0 8 * * MON-FRI", "0 20 * * MON-FRI", "Europe/London")
+	require.NoError(t, err)
+
+	assert.True(t, window.Contains(at(t, "Europe/London", 2024, time.June, 14, 8, 0)))
+	assert.True(t, window.Contains(at(t, "Europe/London", 2024, time.June, 14, 19, 59)))
+	assert.False(t, window.Contains(at(t, "Europe/London", 2024, time.June, 14, 20, 0)))
+	assert.False(t, window.Contains(at(t, "Europe/London", 2024, time.June, 14, 7, 59)))
+	assert.False(t, window.Contains(at(t, "Europe/London", 2024, time.June, 15, 12, 0)))
+
+	// The window opens at 08:00 London on either side of the end of summer time, 07:00 and 08:00 UTC
+	assert.True(t, window.Contains(time.Date(2024, time.October, 25, 7, 0, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, time.October, 28, 7, 0, 0, 0, time.UTC)))
+	assert.True(t, window.Contains(time.Date(2024, time.October, 28, 8, 0, 0, 0, time.UTC)))
+
+	// Windows may span midnight
+	overnight, err := ParseWindow("0 22 * * *", "0 6 * * *", "")
+	require.NoError(t, err)
+	assert.True(t, overnight.Contains(time.Date(2024, time.June, 15, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.Contains(time.Date(2024, time.June, 16, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, overnight.Contains(time.Date(2024, time.June, 16, 12, 0, 0, 0, time.UTC)))
+
+	_, err = ParseWindow("0 8 * * *", "0 25 * * *", "")
+	assert.ErrorContains(t, err, "invalid end")
+}
+
+func TestCalendar(t *testing.T) {
+	calendar, err := NewCalendar("Asia/Singapore")
+	require.NoError(t, err)
+	require.NoError(t, calendar.AddDate("2024-08-09", "National Day"))
+	assert.ErrorContains(t, calendar.AddDate("09/08/2024", ""), `invalid holiday "09/08/2024"`)
+
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20241225\r\nDTEND;VALUE=DATE:20241226\r\nSUMMARY:Christmas\r\n  Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250129\r\nDTEND;VALUE=DATE:20250131\r\nSUMMARY:Chinese New Year\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART:20250501T000000\r\nDTEND:20250502T000000\r\nSUMMARY:Labour Day\\, observed\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	require.NoError(t, calendar.AddICS([]byte(ics)))
+	assert.Equal(t, 5, calendar.Len())
+
+	for date, expected := range map[string]string{
+		"2024-08-09": "National Day",
+		"2024-12-25": "Christmas Day",
+		"2025-01-29": "Chinese New Year",
+		"2025-01-30": "Chinese New Year",
+		"2025-05-01": "Labour Day, observed",
+	} {
+		day, err := time.Parse(dateLayout, date)
+		require.NoError(t, err)
+		name, found := calendar.Holiday(at(t, "Asia/Singapore", day.Year(), day.Month(), day.Day(), 9, 0))
+		assert.True(t, found, date)
+		assert.Equal(t, expected, name, date)
+	}
+	_, found := calendar.Holiday(at(t, "Asia/Singapore", 2025, time.January, 31, 9, 0))
+	assert.False(t, found, "the end of all-day events is exclusive")
+
+	// Days are those of the timezone of the calendar: 2024-12-24 20:00 UTC is Christmas in Singapore
+	_, found = calendar.Holiday(time.Date(2024, time.December, 24, 20, 0, 0, 0, time.UTC))
+	assert.True(t, found)
+
+	var none *Calendar
+	_, found = none.Holiday(time.Now())
+	assert.False(t, found)
+	assert.Equal(t, 0, none.Len())
+
+	assert.ErrorContains(t, calendar.AddICS([]byte("BEGIN:VEVENT\nDTSTART:2025\nSUMMARY:Broken\nEND:VEVENT\n")), `line 4: event "Broken" has no valid DTSTART`)
+}